@@ -15,6 +15,135 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Publishes the public keys that verify our tokens, including keys that are about to sign. The legacy HS256 secret is never published.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/domain.JWKS"
+                        }
+                    }
+                }
+            }
+        },
+        "/.well-known/openid-configuration": {
+            "get": {
+                "description": "Publishes the OpenID Connect provider metadata.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oidc"
+                ],
+                "summary": "OpenID Connect discovery",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OpenIDConfiguration"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/keys": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists the rotated signing keys with their state, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List Signing Keys",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/controller.KeyResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/keys/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Generates a new signing key. It is published right away and starts signing after KEY_ACTIVATION_DELAY, when the current keys retire.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Rotate Signing Key",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controller.KeyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
         "/admin/users": {
             "post": {
                 "description": "Creates a new user.",
@@ -47,9 +176,9 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Requests",
+                        "description": "Bad Requests, with every violated rule when the password breaks the password policy",
                         "schema": {
-                            "$ref": "#/definitions/echo.HTTPError"
+                            "$ref": "#/definitions/controller.PasswordPolicyErrorResponse"
                         }
                     },
                     "500": {
@@ -192,12 +321,131 @@ const docTemplate = `{
                             "$ref": "#/definitions/domain.User"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request, with every violated rule when the password breaks the password policy",
+                        "schema": {
+                            "$ref": "#/definitions/controller.PasswordPolicyErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{user-id}/mfa": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Removes the TOTP factor, the recovery codes and the WebAuthn credentials of a user who lost them,\nthe user signs in with the password only until enrolling again.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reset MFA of User by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user-id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{user-id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Forgets the failed logins of a user, lifting a lockout of the account.\nA lockout of the address the failures came from stays in place.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Unlock User by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user-id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -213,6 +461,43 @@ const docTemplate = `{
                 }
             }
         },
+        "/auth/users/logout": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Signs out the session of the calling token or session cookie and clears the cookie.",
+                "tags": [
+                    "user"
+                ],
+                "summary": "User logout",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
         "/clients": {
             "get": {
                 "security": [
@@ -253,6 +538,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Not a user login token",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -267,7 +558,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Creates a new client associated with the authenticated user.",
+                "description": "Creates a new client associated with the authenticated user.\nRedirect URIs are required for the authorization code flow and must be absolute without a fragment.\nAudiences lists the other services the client may request tokens for.\nPrivileged permissions, token:introspect and token:exchange, are not covered by \"*\" and only administrators grant them.\nA client flagged dpopRequired is only issued DPoP-bound tokens (RFC 9449).\nClients registered for tls_client_auth (CA and subject DN) or self_signed_tls_client_auth (certificate\nthumbprint) authenticate with their TLS client certificate (RFC 8705) and get no secret.\nprivate_key_jwt clients get no secret either, they sign assertions with the keys in jwks (RFC 7523).\nclaimProviders picks the providers adding namespaced custom claims to the tokens of the client.\naccessTokenFormat opaque issues random reference tokens instead of JWTs, resource servers resolve them at /oauth/introspect.",
                 "consumes": [
                     "application/json"
                 ],
@@ -308,6 +599,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "403": {
+                        "description": "Not a user login token, or a privileged permission requested by a non-administrator",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -319,7 +616,7 @@ const docTemplate = `{
         },
         "/clients/login": {
             "post": {
-                "description": "Creates a new client associated with the authenticated user.",
+                "description": "Creates a new client associated with the authenticated user.\nWith a DPoP header (RFC 9449) the token is bound to the proof key and token_type is DPoP.\nOver mutual TLS the secret may be left out for a TLS client, the token is bound to the certificate (RFC 8705).\nA private_key_jwt client sends a signed assertion instead, its aud is the token endpoint (RFC 7523).\nscope requests a least-privilege token, every permission must be covered by the registered scope.",
                 "consumes": [
                     "application/json"
                 ],
@@ -331,6 +628,12 @@ const docTemplate = `{
                 ],
                 "summary": "Create Client",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "DPoP proof JWT",
+                        "name": "DPoP",
+                        "in": "header"
+                    },
                     {
                         "description": "Create Client Request",
                         "name": "request",
@@ -360,6 +663,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/echo.HTTPError"
                         }
                     },
+                    "429": {
+                        "description": "Too many failed logins of the client or from the address, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -427,33 +736,54 @@ const docTemplate = `{
                 }
             }
         },
-        "/resources": {
-            "post": {
+        "/clients/{client-id}/keys": {
+            "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Create Resource",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists the public keys a private_key_jwt client signs its assertions with.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "resourece"
+                    "client"
+                ],
+                "summary": "List Client Keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client-id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Create Resource",
                 "responses": {
                     "200": {
                         "description": "Success",
                         "schema": {
-                            "$ref": "#/definitions/domain.Resource"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ClientKey"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Bad Requests",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a user login token",
                         "schema": {
                             "$ref": "#/definitions/echo.HTTPError"
                         }
@@ -471,11 +801,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users/login": {
+            },
             "post": {
-                "description": "User Login",
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Registers a public JWK (EC P-256, RSA or Ed25519) for a client, the kid defaults to the RFC 7638 thumbprint.",
                 "consumes": [
                     "application/json"
                 ],
@@ -483,29 +816,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "client"
                 ],
-                "summary": "User login",
+                "summary": "Add Client Key",
                 "parameters": [
                     {
-                        "description": "User Login Request",
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client-id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Public JWK",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller.UserLoginRequest"
+                            "$ref": "#/definitions/domain.JWK"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Success",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/controller.UserLoginResponse"
+                            "$ref": "#/definitions/domain.ClientKey"
                         }
                     },
                     "400": {
-                        "description": "Bad Requests",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a user login token",
                         "schema": {
                             "$ref": "#/definitions/echo.HTTPError"
                         }
@@ -524,190 +876,2969 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controller.ClientLoginReponse": {
-            "type": "object",
-            "properties": {
-                "access_token": {
-                    "type": "string"
-                }
-            }
-        },
-        "controller.ClientLoinRequest": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string",
-                    "example": "6cc2b688-1246-4a62-a293-dae7e67d6097"
-                },
-                "secret": {
-                    "type": "string",
-                    "example": "o44z4KUzru7uW4jtzxVt84Ma8f76Mnwj"
-                }
-            }
         },
-        "controller.ClientResponse": {
-            "type": "object",
-            "properties": {
-                "client": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.Client"
+        "/clients/{client-id}/keys/{kid}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
                     }
-                }
-            }
-        },
-        "controller.CreateClientReponse": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "string",
-                    "example": "11111111-2222-4444-3333-555555555555"
-                },
-                "scope": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/domain.Permission"
+                ],
+                "description": "Removes a public key of a client, assertions signed with it are refused from then on.",
+                "tags": [
+                    "client"
+                ],
+                "summary": "Delete Client Key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client-id",
+                        "in": "path",
+                        "required": true
                     },
-                    "example": [
-                        "*"
-                    ]
-                },
-                "secret": {
-                    "type": "string",
-                    "example": "o44z4KUzru7uW4jtzxVt84Ma8f76Mnwj"
-                },
-                "userId": {
-                    "type": "string",
-                    "example": "11111111-2222-4444-3333-555555555555"
-                }
-            }
-        },
-        "controller.CreateClientRequest": {
-            "type": "object",
-            "required": [
-                "scope"
-            ],
-            "properties": {
-                "scope": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "$ref": "#/definitions/domain.Permission"
+                    {
+                        "type": "string",
+                        "description": "Key ID",
+                        "name": "kid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
                     },
-                    "example": [
-                        "resource:create"
-                    ]
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a user login token",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
                 }
             }
         },
-        "controller.CreateUserRequest": {
-            "type": "object",
-            "required": [
-                "account",
-                "name",
-                "password"
-            ],
-            "properties": {
-                "account": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
+        "/me/mfa/totp": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Generates a new TOTP secret, to be shown as a QR code of its otpauth URI. The factor is\nonly required at login once confirmed, enrolling again replaces an unconfirmed secret.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Enroll TOTP",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/controller.EnrollTOTPResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Already enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
                 }
             }
         },
-        "controller.UpdateClientRequest": {
-            "type": "object",
-            "required": [
-                "scope"
+        "/me/mfa/totp/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Turns the enrolled factor on with a first code of the authenticator app and returns the recovery codes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Confirm TOTP",
+                "parameters": [
+                    {
+                        "description": "Confirm TOTP Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.ConfirmTOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ConfirmTOTPResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not enrolled",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Already confirmed",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists the sessions the user is signed in with, the one of the calling token is flagged as current.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "List Sessions",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.SessionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Signs the user out everywhere, the calling session included.",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Revoke All Sessions",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/sessions/{session-id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Signs the user out of one session, its tokens are refused from then on.",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Revoke Session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "session-id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/webauthn/credentials": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Lists the passkeys and security keys of the user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "List WebAuthn credentials",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WebAuthnCredential"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/webauthn/credentials/{credential-id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Removes a passkey or security key of the user, it no longer signs in.\nOnly a login with a second factor within STEP_UP_MAX_AGE may do so, others are answered with\n401 and an insufficient_user_authentication challenge (RFC 9470).",
+                "tags": [
+                    "user"
+                ],
+                "summary": "Delete WebAuthn credential",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Credential ID, base64url",
+                        "name": "credential-id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, or no recent login with a second factor",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/webauthn/register": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Verifies the response of the authenticator to the registration options and stores its credential.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Finish WebAuthn registration",
+                "parameters": [
+                    {
+                        "description": "Finish WebAuthn Registration Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.FinishWebAuthnRegistrationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebAuthnCredential"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized, or invalid response or challenge",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "409": {
+                        "description": "Already registered",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/webauthn/register/options": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns the publicKey options of navigator.credentials.create() for a new passkey or security key,\nits response is then sent to /me/webauthn/register.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Begin WebAuthn registration",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebAuthnCreationOptions"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/authorize": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Validates an authorization code request (RFC 6749 section 4.1.1, PKCE S256 required) and returns what the user is asked to consent to.\nErrors about the client or redirect uri are shown to the user; any other error carries redirect_to for relaying it to the client.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Authorization request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Must be code",
+                        "name": "response_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered redirect URI",
+                        "name": "redirect_uri",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Space-delimited requested scope",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque value echoed back to the client",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code challenge",
+                        "name": "code_challenge",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Must be S256",
+                        "name": "code_challenge_method",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "OpenID Connect nonce, echoed in the ID token",
+                        "name": "nonce",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.ConsentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Records the decision of the logged-in user. On approval a single-use authorization code is issued for the approved subset of the requested scope.\nThe response tells the user agent where to send the user back to the client.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Authorization consent",
+                "parameters": [
+                    {
+                        "description": "Consent Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.ConsentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.AuthorizeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/device": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Looks up a pending device authorization by the user code shown on the device and returns what the user is asked to approve.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Device verification request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User code shown on the device, case and dashes do not matter",
+                        "name": "user_code",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.DeviceConsentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Records the decision of the logged-in user on a device authorization. On approval the polling device receives a token for the approved subset of the requested scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Device verification consent",
+                "parameters": [
+                    {
+                        "description": "Device Consent Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.DeviceConsentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/device_authorization": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Starts the device authorization grant of RFC 8628 for input-constrained clients such as CLI tools.\nThe user enters user_code at verification_uri while the device polls /oauth/token with the device_code grant, no faster than interval seconds.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth2 device authorization endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Space-delimited requested scope",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID for client_secret_post",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret for client_secret_post",
+                        "name": "client_secret",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+                        ],
+                        "type": "string",
+                        "description": "Client assertion type for private_key_jwt",
+                        "name": "client_assertion_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client assertion JWT for private_key_jwt",
+                        "name": "client_assertion",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.DeviceAuthorizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/introspect": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Reports whether an access token is active per RFC 7662, taking expiry, revocation and deletion of its user or client into account.\nThe caller authenticates as a client holding the token:introspect permission by name, \"*\" does not cover it.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth2 token introspection endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token to introspect",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "access_token",
+                            "refresh_token"
+                        ],
+                        "type": "string",
+                        "description": "Type of the token",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID for client_secret_post",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret for client_secret_post",
+                        "name": "client_secret",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+                        ],
+                        "type": "string",
+                        "description": "Client assertion type for private_key_jwt",
+                        "name": "client_assertion_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client assertion JWT for private_key_jwt",
+                        "name": "client_assertion",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Introspection"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/revoke": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Revokes an access or refresh token per RFC 7009. Revoking a refresh token revokes its whole family.\nClient authentication is optional; an authenticated client may only revoke its own access tokens and the user tokens issued to it (azp).\nInvalid, expired and unknown tokens are answered with 200 as the RFC requires.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth2 token revocation endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token to revoke",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "access_token",
+                            "refresh_token"
+                        ],
+                        "type": "string",
+                        "description": "Type of the token",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID for client_secret_post",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret for client_secret_post",
+                        "name": "client_secret",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+                        ],
+                        "type": "string",
+                        "description": "Client assertion type for private_key_jwt",
+                        "name": "client_assertion_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client assertion JWT for private_key_jwt",
+                        "name": "client_assertion",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Issues an access token per RFC 6749. Clients authenticate with HTTP Basic (client_secret_basic) or with client_id and client_secret in the body (client_secret_post).\nprivate_key_jwt clients send a client_assertion signed with a registered key, its aud is this endpoint (RFC 7523); TLS clients send only client_id over mutual TLS (RFC 8705).\nThe refresh_token grant rotates a user refresh token and needs no client authentication.\nThe authorization_code grant redeems a code from /oauth/authorize with its PKCE code_verifier; the token acts for the user with the client as azp.\nAn ID token is added when the openid scope was granted.\nClient tokens carry the requested resources as aud, each must be this server or registered for the client.\nThe device_code grant is polled by a device with the device_code from /oauth/device_authorization until the user decides (RFC 8628); it answers authorization_pending, slow_down, expired_token or access_denied meanwhile.\nWith a DPoP header (RFC 9449) the access token is bound to the proof key and token_type is DPoP; clients flagged dpopRequired must send one.\nThe token exchange grant (RFC 8693) trades a subject_token for one with a narrower scope and an act claim naming the actor: the subject of actor_token, or the calling client. The client needs the token:exchange permission by name, \"*\" does not cover it. A DPoP-bound or certificate-bound subject or actor token is only exchanged with a DPoP proof of its key or over its TLS client certificate, and the issued token stays bound to it.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth2 token endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "DPoP proof JWT",
+                        "name": "DPoP",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "client_credentials",
+                            "refresh_token",
+                            "authorization_code",
+                            "urn:ietf:params:oauth:grant-type:token-exchange",
+                            "urn:ietf:params:oauth:grant-type:device_code"
+                        ],
+                        "type": "string",
+                        "description": "Grant type",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Space-delimited requested scope",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refresh token for the refresh_token grant",
+                        "name": "refresh_token",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code for the authorization_code grant",
+                        "name": "code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Redirect URI the code was issued to",
+                        "name": "redirect_uri",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code verifier",
+                        "name": "code_verifier",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device code for the device_code grant",
+                        "name": "device_code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access token to exchange",
+                        "name": "subject_token",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "urn:ietf:params:oauth:token-type:access_token",
+                            "urn:ietf:params:oauth:token-type:jwt"
+                        ],
+                        "type": "string",
+                        "description": "Type of subject_token",
+                        "name": "subject_token_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Access token of the party acting for the subject",
+                        "name": "actor_token",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "urn:ietf:params:oauth:token-type:access_token",
+                            "urn:ietf:params:oauth:token-type:jwt"
+                        ],
+                        "type": "string",
+                        "description": "Type of actor_token",
+                        "name": "actor_token_type",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "urn:ietf:params:oauth:token-type:access_token"
+                        ],
+                        "type": "string",
+                        "description": "Only access tokens can be issued",
+                        "name": "requested_token_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Resource indicators (RFC 8707) the token is for, defaults to this server",
+                        "name": "resource",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Logical audiences the token is for, same rules as resource",
+                        "name": "audience",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID for client_secret_post",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret for client_secret_post",
+                        "name": "client_secret",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+                        ],
+                        "type": "string",
+                        "description": "Client assertion type for private_key_jwt",
+                        "name": "client_assertion_type",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client assertion JWT for private_key_jwt",
+                        "name": "client_assertion",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed logins of the client or from the address, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/controller.OAuthError"
+                        }
+                    }
+                }
+            }
+        },
+        "/resources": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create Resource",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "resourece"
+                ],
+                "summary": "Create Resource",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Resource"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Requests",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/userinfo": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns claims about the user the access token was issued for. Requires the openid scope; name and preferred_username need the profile scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oidc"
+                ],
+                "summary": "OpenID Connect userinfo",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserInfoResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Returns claims about the user the access token was issued for. Requires the openid scope; name and preferred_username need the profile scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oidc"
+                ],
+                "summary": "OpenID Connect userinfo",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserInfoResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "description": "User Login. In cookie mode no token is returned: the session is kept in an HttpOnly cookie\nand state-changing requests must echo the returned csrf_token in the X-CSRF-Token header.\nUsers enrolled in MFA get mfa_required, an mfa_token and the mfa_methods to answer it with instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "User login",
+                "parameters": [
+                    {
+                        "description": "User Login Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Requests",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed logins of the account or from the address, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/mfa": {
+            "post": {
+                "description": "Answers the challenge of a login with mfa_required by a TOTP code or a recovery code,\nthe login then completes in the mode it was started with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "User login second factor",
+                "parameters": [
+                    {
+                        "description": "User Login MFA Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserLoginMFARequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Requests",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid challenge or code",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many wrong codes of the user or from the address, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/mfa/webauthn": {
+            "post": {
+                "description": "Answers the challenge of a login with mfa_required by the response of the authenticator to the\noptions of /auth/users/mfa/webauthn/options, the login then completes in the mode it was started with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "User login second factor with WebAuthn",
+                "parameters": [
+                    {
+                        "description": "User Login MFA WebAuthn Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserLoginMFAWebAuthnRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Requests",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid challenge or WebAuthn response",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed answers of the user or from the address, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/mfa/webauthn/options": {
+            "post": {
+                "description": "Returns the publicKey options of navigator.credentials.get() that answer the challenge of a login\nwith mfa_required, only the credentials of the user are allowed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Begin user login second factor with WebAuthn",
+                "parameters": [
+                    {
+                        "description": "Begin User Login MFA WebAuthn Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.BeginLoginMFAWebAuthnRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebAuthnRequestOptions"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Requests",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid challenge",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/passkey": {
+            "post": {
+                "description": "Signs in without a password by the response of the authenticator to the options of\n/auth/users/passkey/options. The authenticator must have verified the user, which makes the\npasskey a second factor on its own and no other one is asked for.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "User passkey login",
+                "parameters": [
+                    {
+                        "description": "User Passkey Login Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserPasskeyLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/controller.UserLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Requests",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid challenge or WebAuthn response",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed logins with the credential or from the address, see Retry-After",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/passkey/options": {
+            "post": {
+                "description": "Returns the publicKey options of navigator.credentials.get() for a login without a password,\nthe authenticator offers the passkeys it holds for this service.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Begin passkey login",
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebAuthnRequestOptions"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/echo.HTTPError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller.AuthorizeResponse": {
+            "type": "object",
+            "properties": {
+                "redirect_to": {
+                    "type": "string",
+                    "example": "https://app.example.com/callback?code=SplxlOBeZQQYbYS6WxSbIA\u0026state=af0ifjsldkj"
+                }
+            }
+        },
+        "controller.BeginLoginMFAWebAuthnRequest": {
+            "type": "object",
+            "required": [
+                "mfa_token"
+            ],
+            "properties": {
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.ClientLoginReponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "resource:create"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "DPoP"
+                }
+            }
+        },
+        "controller.ClientLoinRequest": {
+            "type": "object",
+            "properties": {
+                "clientAssertion": {
+                    "type": "string"
+                },
+                "clientAssertionType": {
+                    "description": "ClientAssertion replaces the secret of a private_key_jwt client, the ID may then be left out",
+                    "type": "string",
+                    "example": "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "6cc2b688-1246-4a62-a293-dae7e67d6097"
+                },
+                "scope": {
+                    "description": "Scope narrows the token to part of the registered scope, the whole registered scope when empty",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "resource:create"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "o44z4KUzru7uW4jtzxVt84Ma8f76Mnwj"
+                }
+            }
+        },
+        "controller.ClientResponse": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Client"
+                    }
+                }
+            }
+        },
+        "controller.ConfirmTOTPRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "controller.ConfirmTOTPResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "description": "RecoveryCodes each sign in once in place of a TOTP code, they are never shown again",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abcd-efgh-ijkl-mnop"
+                    ]
+                }
+            }
+        },
+        "controller.ConsentRequest": {
+            "type": "object",
+            "required": [
+                "approved_scope"
+            ],
+            "properties": {
+                "approve": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "approved_scope": {
+                    "description": "ApprovedScope is the subset of the requested scope the user agreed to, empty approves all of it",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "resource:create"
+                    ]
+                },
+                "client_id": {
+                    "type": "string",
+                    "example": "6cc2b688-1246-4a62-a293-dae7e67d6097"
+                },
+                "code_challenge": {
+                    "type": "string",
+                    "example": "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+                },
+                "code_challenge_method": {
+                    "type": "string",
+                    "example": "S256"
+                },
+                "nonce": {
+                    "type": "string",
+                    "example": "n-0S6_WzA2Mj"
+                },
+                "redirect_uri": {
+                    "type": "string",
+                    "example": "https://app.example.com/callback"
+                },
+                "response_type": {
+                    "type": "string",
+                    "example": "code"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "resource:create"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "af0ifjsldkj"
+                }
+            }
+        },
+        "controller.ConsentResponse": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string",
+                    "example": "6cc2b688-1246-4a62-a293-dae7e67d6097"
+                },
+                "redirect_uri": {
+                    "type": "string",
+                    "example": "https://app.example.com/callback"
+                },
+                "scope": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "resource:create"
+                    ]
+                },
+                "state": {
+                    "type": "string",
+                    "example": "af0ifjsldkj"
+                }
+            }
+        },
+        "controller.CreateClientReponse": {
+            "type": "object",
+            "properties": {
+                "accessTokenFormat": {
+                    "description": "AccessTokenFormat is jwt or opaque, user tokens obtained without a client are always JWTs",
+                    "type": "string",
+                    "example": "jwt"
+                },
+                "audiences": {
+                    "description": "Audiences are the other services the client may request tokens for, this server is always allowed",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://api.example.com"
+                    ]
+                },
+                "claimProviders": {
+                    "description": "ClaimProviders name the claim providers adding custom claims to the tokens issued to the client",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "owner"
+                    ]
+                },
+                "dpopRequired": {
+                    "description": "DPoPRequired clients only get DPoP-bound tokens, never bearer tokens",
+                    "type": "boolean",
+                    "example": false
+                },
+                "id": {
+                    "type": "string",
+                    "example": "11111111-2222-4444-3333-555555555555"
+                },
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ClientKey"
+                    }
+                },
+                "redirectUris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://app.example.com/callback"
+                    ]
+                },
+                "scope": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "*"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "o44z4KUzru7uW4jtzxVt84Ma8f76Mnwj"
+                },
+                "tlsClientAuthCa": {
+                    "type": "string"
+                },
+                "tlsClientAuthSubjectDn": {
+                    "description": "TLSSubjectDN and TLSClientCA (PEM) identify the certificate of a tls_client_auth client",
+                    "type": "string",
+                    "example": "CN=service,O=Example"
+                },
+                "tlsClientCertificateThumbprint": {
+                    "description": "TLSCertificateThumbprint is the x5t#S256 of the certificate of a self_signed_tls_client_auth client",
+                    "type": "string"
+                },
+                "tokenEndpointAuthMethod": {
+                    "description": "AuthMethod is how the client authenticates at the token endpoint, only client_secret_basic clients have a secret.\nprivate_key_jwt clients sign assertions with one of their registered keys instead",
+                    "type": "string",
+                    "example": "client_secret_basic"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "11111111-2222-4444-3333-555555555555"
+                }
+            }
+        },
+        "controller.CreateClientRequest": {
+            "type": "object",
+            "required": [
+                "audiences",
+                "redirectUris",
+                "scope"
+            ],
+            "properties": {
+                "accessTokenFormat": {
+                    "description": "AccessTokenFormat defaults to jwt",
+                    "type": "string",
+                    "enum": [
+                        "jwt",
+                        "opaque"
+                    ],
+                    "example": "opaque"
+                },
+                "audiences": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://api.example.com"
+                    ]
+                },
+                "claimProviders": {
+                    "description": "ClaimProviders add custom claims to the tokens of the client, the server defaults when left out",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "owner"
+                    ]
+                },
+                "dpopRequired": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "jwks": {
+                    "description": "JWKS are public keys registered with the client, more can be added under /clients/{client-id}/keys",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.JWK"
+                    }
+                },
+                "redirectUris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://app.example.com/callback"
+                    ]
+                },
+                "scope": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "resource:create"
+                    ]
+                },
+                "tlsClientAuthCa": {
+                    "type": "string"
+                },
+                "tlsClientAuthSubjectDn": {
+                    "type": "string",
+                    "example": "CN=service,O=Example"
+                },
+                "tlsClientCertificateThumbprint": {
+                    "type": "string"
+                },
+                "tokenEndpointAuthMethod": {
+                    "description": "AuthMethod defaults to client_secret_basic, the tls fields are required by the TLS client authentication methods",
+                    "type": "string",
+                    "enum": [
+                        "client_secret_basic",
+                        "tls_client_auth",
+                        "self_signed_tls_client_auth",
+                        "private_key_jwt"
+                    ],
+                    "example": "client_secret_basic"
+                }
+            }
+        },
+        "controller.CreateUserRequest": {
+            "type": "object",
+            "required": [
+                "account",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.DeviceAuthorizationResponse": {
+            "type": "object",
+            "properties": {
+                "device_code": {
+                    "type": "string",
+                    "example": "GmRhmhcxhwAzkoEqiMEg_DnyEysNkuNhszIySk9eS"
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "interval": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "user_code": {
+                    "type": "string",
+                    "example": "WDJB-MJHT"
+                },
+                "verification_uri": {
+                    "type": "string",
+                    "example": "http://localhost:8000/oauth/device"
+                },
+                "verification_uri_complete": {
+                    "type": "string",
+                    "example": "http://localhost:8000/oauth/device?user_code=WDJB-MJHT"
+                }
+            }
+        },
+        "controller.DeviceConsentRequest": {
+            "type": "object",
+            "required": [
+                "approved_scope",
+                "user_code"
+            ],
+            "properties": {
+                "approve": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "approved_scope": {
+                    "description": "ApprovedScope is the subset of the requested scope the user agreed to, empty approves all of it",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "resource:create"
+                    ]
+                },
+                "user_code": {
+                    "type": "string",
+                    "example": "WDJB-MJHT"
+                }
+            }
+        },
+        "controller.DeviceConsentResponse": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string",
+                    "example": "6cc2b688-1246-4a62-a293-dae7e67d6097"
+                },
+                "scope": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "resource:create"
+                    ]
+                },
+                "user_code": {
+                    "type": "string",
+                    "example": "WDJB-MJHT"
+                }
+            }
+        },
+        "controller.EnrollTOTPResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/ClientApp:alice?algorithm=SHA1\u0026digits=6\u0026issuer=ClientApp\u0026period=30\u0026secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                },
+                "secret": {
+                    "description": "Secret is the base32 key to type into an authenticator app that cannot scan the URI",
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "controller.FinishWebAuthnRegistrationRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "credential": {
+                    "$ref": "#/definitions/controller.WebAuthnRegistrationCredential"
+                },
+                "name": {
+                    "description": "Name tells the credentials of the user apart",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "YubiKey 5"
+                }
+            }
+        },
+        "controller.KeyResponse": {
+            "type": "object",
+            "properties": {
+                "activatesAt": {
+                    "type": "string"
+                },
+                "alg": {
+                    "type": "string",
+                    "example": "ES256"
+                },
+                "createdAt": {
+                    "description": "Keys configured statically have zero timestamps and stay active forever.",
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string",
+                    "example": "Gv4qZ8wTb6hV0o3c1m7YpWkQn2sJ5dRrLx9uEaHfBiU"
+                },
+                "retiredAt": {
+                    "type": "string"
+                },
+                "state": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.KeyState"
+                        }
+                    ],
+                    "example": "active"
+                }
+            }
+        },
+        "controller.OAuthError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid_client"
+                },
+                "error_description": {
+                    "type": "string",
+                    "example": "client authentication failed"
+                },
+                "redirect_to": {
+                    "description": "RedirectTo is set by the authorization endpoint once the redirect uri is trusted,\nthe user agent relays the error to the client by navigating there.",
+                    "type": "string"
+                }
+            }
+        },
+        "controller.OpenIDConfiguration": {
+            "type": "object",
+            "properties": {
+                "authorization_endpoint": {
+                    "type": "string",
+                    "example": "http://localhost:8000/oauth/authorize"
+                },
+                "claims_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "sub",
+                        "name"
+                    ]
+                },
+                "code_challenge_methods_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "S256"
+                    ]
+                },
+                "device_authorization_endpoint": {
+                    "type": "string",
+                    "example": "http://localhost:8000/oauth/device_authorization"
+                },
+                "dpop_signing_alg_values_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ES256"
+                    ]
+                },
+                "grant_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "authorization_code"
+                    ]
+                },
+                "id_token_signing_alg_values_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ES256"
+                    ]
+                },
+                "introspection_endpoint": {
+                    "type": "string",
+                    "example": "http://localhost:8000/oauth/introspect"
+                },
+                "issuer": {
+                    "type": "string",
+                    "example": "ClientApp"
+                },
+                "jwks_uri": {
+                    "type": "string",
+                    "example": "http://localhost:8000/.well-known/jwks.json"
+                },
+                "response_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "code"
+                    ]
+                },
+                "revocation_endpoint": {
+                    "type": "string",
+                    "example": "http://localhost:8000/oauth/revoke"
+                },
+                "scopes_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "openid",
+                        "profile"
+                    ]
+                },
+                "subject_types_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "public"
+                    ]
+                },
+                "tls_client_certificate_bound_access_tokens": {
+                    "description": "TLSClientCertificateBoundAccessTokens tells that tokens issued over mutual TLS are bound to the certificate (RFC 8705)",
+                    "type": "boolean",
+                    "example": true
+                },
+                "token_endpoint": {
+                    "type": "string",
+                    "example": "http://localhost:8000/oauth/token"
+                },
+                "token_endpoint_auth_methods_supported": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "client_secret_basic"
+                    ]
+                },
+                "token_endpoint_auth_signing_alg_values_supported": {
+                    "description": "TokenEndpointAuthSigningAlgValuesSupported are the algorithms accepted for private_key_jwt assertions",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ES256"
+                    ]
+                },
+                "userinfo_endpoint": {
+                    "type": "string",
+                    "example": "http://localhost:8000/userinfo"
+                }
+            }
+        },
+        "controller.PasswordPolicyErrorResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "password does not meet the password policy"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "must be at least 12 characters long",
+                        "appears in known data breaches"
+                    ]
+                }
+            }
+        },
+        "controller.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Session"
+                    }
+                }
+            }
+        },
+        "controller.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "id_token": {
+                    "type": "string"
+                },
+                "issued_token_type": {
+                    "description": "IssuedTokenType is only set by the token exchange grant, RFC 8693 section 2.2.1.",
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "resource:create"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "DPoP"
+                }
+            }
+        },
+        "controller.UpdateClientRequest": {
+            "type": "object",
+            "required": [
+                "scope"
+            ],
+            "properties": {
+                "scope": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/domain.Permission"
+                    },
+                    "example": [
+                        "resource:create"
+                    ]
+                }
+            }
+        },
+        "controller.UpdateUserRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.UserInfoResponse": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "preferred_username": {
+                    "type": "string",
+                    "example": "johndoe123"
+                },
+                "sub": {
+                    "type": "string",
+                    "example": "11111111-2222-4444-3333-555555555555"
+                }
+            }
+        },
+        "controller.UserLoginMFARequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "description": "Code is the current code of the authenticator app, or one of the recovery codes",
+                    "type": "string",
+                    "example": "123456"
+                },
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.UserLoginMFAWebAuthnRequest": {
+            "type": "object",
+            "required": [
+                "mfa_token"
+            ],
+            "properties": {
+                "credential": {
+                    "$ref": "#/definitions/controller.WebAuthnLoginCredential"
+                },
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.UserLoginRequest": {
+            "type": "object",
+            "required": [
+                "account",
+                "password"
+            ],
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "mode": {
+                    "description": "Mode is token unless the web console asks for a cookie session",
+                    "type": "string",
+                    "enum": [
+                        "token",
+                        "cookie"
+                    ],
+                    "example": "cookie"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.UserLoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "csrf_token": {
+                    "description": "CSRFToken of a cookie session, to be sent in the X-CSRF-Token header of state-changing requests",
+                    "type": "string"
+                },
+                "mfa_methods": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "totp",
+                        "webauthn"
+                    ]
+                },
+                "mfa_required": {
+                    "description": "MFARequired is set when the password was right but the second factor is still to be given\ntogether with MFAToken, to /auth/users/mfa for totp or /auth/users/mfa/webauthn for webauthn",
+                    "type": "boolean"
+                },
+                "mfa_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.UserPasskeyLoginRequest": {
+            "type": "object",
+            "properties": {
+                "credential": {
+                    "$ref": "#/definitions/controller.WebAuthnLoginCredential"
+                },
+                "mode": {
+                    "description": "Mode is token unless the web console asks for a cookie session",
+                    "type": "string",
+                    "enum": [
+                        "token",
+                        "cookie"
+                    ],
+                    "example": "cookie"
+                }
+            }
+        },
+        "controller.WebAuthnAssertionResponse": {
+            "type": "object",
+            "required": [
+                "authenticatorData",
+                "clientDataJSON",
+                "signature"
+            ],
+            "properties": {
+                "authenticatorData": {
+                    "type": "string"
+                },
+                "clientDataJSON": {
+                    "type": "string"
+                },
+                "signature": {
+                    "type": "string"
+                },
+                "userHandle": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.WebAuthnAttestationResponse": {
+            "type": "object",
+            "required": [
+                "attestationObject",
+                "clientDataJSON"
+            ],
+            "properties": {
+                "attestationObject": {
+                    "type": "string"
+                },
+                "clientDataJSON": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller.WebAuthnLoginCredential": {
+            "type": "object",
+            "required": [
+                "id",
+                "type"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "AQIDBAUGBwgJCgsMDQ4PEA"
+                },
+                "response": {
+                    "$ref": "#/definitions/controller.WebAuthnAssertionResponse"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "public-key"
+                }
+            }
+        },
+        "controller.WebAuthnRegistrationCredential": {
+            "type": "object",
+            "required": [
+                "id",
+                "type"
             ],
             "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "AQIDBAUGBwgJCgsMDQ4PEA"
+                },
+                "response": {
+                    "$ref": "#/definitions/controller.WebAuthnAttestationResponse"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "public-key"
+                }
+            }
+        },
+        "domain.Actor": {
+            "type": "object",
+            "properties": {
+                "act": {
+                    "$ref": "#/definitions/domain.Actor"
+                },
+                "sub": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Client": {
+            "type": "object",
+            "properties": {
+                "accessTokenFormat": {
+                    "description": "AccessTokenFormat is jwt or opaque, user tokens obtained without a client are always JWTs",
+                    "type": "string",
+                    "example": "jwt"
+                },
+                "audiences": {
+                    "description": "Audiences are the other services the client may request tokens for, this server is always allowed",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://api.example.com"
+                    ]
+                },
+                "claimProviders": {
+                    "description": "ClaimProviders name the claim providers adding custom claims to the tokens issued to the client",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "owner"
+                    ]
+                },
+                "dpopRequired": {
+                    "description": "DPoPRequired clients only get DPoP-bound tokens, never bearer tokens",
+                    "type": "boolean",
+                    "example": false
+                },
+                "id": {
+                    "type": "string",
+                    "example": "11111111-2222-4444-3333-555555555555"
+                },
+                "redirectUris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://app.example.com/callback"
+                    ]
+                },
                 "scope": {
                     "type": "array",
-                    "minItems": 1,
                     "items": {
                         "$ref": "#/definitions/domain.Permission"
                     },
                     "example": [
-                        "resource:create"
+                        "*"
                     ]
-                }
-            }
-        },
-        "controller.UpdateUserRequest": {
-            "type": "object",
-            "properties": {
-                "name": {
+                },
+                "tlsClientAuthCa": {
                     "type": "string"
                 },
-                "password": {
+                "tlsClientAuthSubjectDn": {
+                    "description": "TLSSubjectDN and TLSClientCA (PEM) identify the certificate of a tls_client_auth client",
+                    "type": "string",
+                    "example": "CN=service,O=Example"
+                },
+                "tlsClientCertificateThumbprint": {
+                    "description": "TLSCertificateThumbprint is the x5t#S256 of the certificate of a self_signed_tls_client_auth client",
                     "type": "string"
+                },
+                "tokenEndpointAuthMethod": {
+                    "description": "AuthMethod is how the client authenticates at the token endpoint, only client_secret_basic clients have a secret.\nprivate_key_jwt clients sign assertions with one of their registered keys instead",
+                    "type": "string",
+                    "example": "client_secret_basic"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "11111111-2222-4444-3333-555555555555"
                 }
             }
         },
-        "controller.UserLoginRequest": {
+        "domain.ClientKey": {
             "type": "object",
-            "required": [
-                "account",
-                "password"
-            ],
             "properties": {
-                "account": {
-                    "type": "string"
+                "clientId": {
+                    "type": "string",
+                    "example": "11111111-2222-4444-3333-555555555555"
                 },
-                "password": {
-                    "type": "string"
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-06-27T15:04:05Z"
+                },
+                "jwk": {
+                    "$ref": "#/definitions/domain.JWK"
+                },
+                "kid": {
+                    "type": "string",
+                    "example": "Gv4qZ8wTb6hV0o3c1m7YpWkQn2sJ5dRrLx9uEaHfBiU"
                 }
             }
         },
-        "controller.UserLoginResponse": {
+        "domain.Confirmation": {
             "type": "object",
-            "required": [
-                "access_token"
-            ],
             "properties": {
-                "access_token": {
+                "jkt": {
+                    "type": "string"
+                },
+                "x5t#S256": {
                     "type": "string"
                 }
             }
         },
-        "domain.Client": {
+        "domain.Introspection": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "string",
-                    "example": "11111111-2222-4444-3333-555555555555"
+                "act": {
+                    "$ref": "#/definitions/domain.Actor"
                 },
-                "scope": {
+                "active": {
+                    "type": "boolean"
+                },
+                "aud": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.Permission"
+                        "type": "string"
                     },
                     "example": [
-                        "*"
+                        "http://localhost:8000"
                     ]
                 },
-                "userId": {
+                "client_id": {
+                    "type": "string",
+                    "example": "11111111-2222-4444-3333-555555555555"
+                },
+                "cnf": {
+                    "$ref": "#/definitions/domain.Confirmation"
+                },
+                "exp": {
+                    "type": "integer",
+                    "example": 1735689600
+                },
+                "iat": {
+                    "type": "integer",
+                    "example": 1735688700
+                },
+                "iss": {
+                    "type": "string",
+                    "example": "ClientApp"
+                },
+                "jti": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "resource:create"
+                },
+                "sub": {
                     "type": "string",
                     "example": "11111111-2222-4444-3333-555555555555"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.JwtType"
+                        }
+                    ],
+                    "example": "client"
+                }
+            }
+        },
+        "domain.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string",
+                    "example": "ES256"
+                },
+                "crv": {
+                    "type": "string",
+                    "example": "P-256"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string",
+                    "example": "Gv4qZ8wTb6hV0o3c1m7YpWkQn2sJ5dRrLx9uEaHfBiU"
+                },
+                "kty": {
+                    "type": "string",
+                    "example": "EC"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string",
+                    "example": "sig"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.JWK"
+                    }
                 }
             }
         },
+        "domain.JwtType": {
+            "type": "string",
+            "enum": [
+                "user",
+                "client",
+                "id_token"
+            ],
+            "x-enum-varnames": [
+                "UserType",
+                "ClientType",
+                "IDTokenType"
+            ]
+        },
+        "domain.KeyState": {
+            "type": "string",
+            "enum": [
+                "next",
+                "active",
+                "retired"
+            ],
+            "x-enum-varnames": [
+                "KeyNext",
+                "KeyActive",
+                "KeyRetired"
+            ]
+        },
         "domain.Permission": {
             "type": "string",
             "enum": [
                 "*",
-                "resource:create"
+                "resource:create",
+                "token:introspect",
+                "token:exchange",
+                "admin",
+                "openid",
+                "profile"
             ],
             "x-enum-varnames": [
                 "PermAll",
-                "PermCreateResource"
+                "PermCreateResource",
+                "PermIntrospect",
+                "PermExchange",
+                "PermAdmin",
+                "PermOpenID",
+                "PermProfile"
             ]
         },
         "domain.Resource": {
@@ -719,6 +3850,52 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.Session": {
+            "type": "object",
+            "properties": {
+                "amr": {
+                    "description": "AMR are the authentication methods of the login that started the session",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "pwd",
+                        "otp",
+                        "mfa"
+                    ]
+                },
+                "clientId": {
+                    "type": "string",
+                    "example": "11111111-2222-4444-3333-555555555555"
+                },
+                "current": {
+                    "description": "Current marks the session of the token the list was requested with",
+                    "type": "boolean"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "11111111-2222-4444-3333-555555555555"
+                },
+                "ipAddress": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "issuedAt": {
+                    "type": "string"
+                },
+                "lastSeenAt": {
+                    "type": "string"
+                },
+                "userAgent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0"
+                }
+            }
+        },
         "domain.User": {
             "type": "object",
             "properties": {
@@ -736,6 +3913,165 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.WebAuthnAuthenticatorSelection": {
+            "type": "object",
+            "properties": {
+                "residentKey": {
+                    "type": "string",
+                    "example": "preferred"
+                },
+                "userVerification": {
+                    "type": "string",
+                    "example": "preferred"
+                }
+            }
+        },
+        "domain.WebAuthnCreationOptions": {
+            "type": "object",
+            "properties": {
+                "attestation": {
+                    "type": "string",
+                    "example": "none"
+                },
+                "authenticatorSelection": {
+                    "$ref": "#/definitions/domain.WebAuthnAuthenticatorSelection"
+                },
+                "challenge": {
+                    "type": "string",
+                    "example": "3q2-7wEjRWeJq83v8SNFZ4mrze8BI0VniavN7wEjRWc"
+                },
+                "excludeCredentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WebAuthnCredentialDescriptor"
+                    }
+                },
+                "pubKeyCredParams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WebAuthnCredentialParameter"
+                    }
+                },
+                "rp": {
+                    "$ref": "#/definitions/domain.WebAuthnRelyingParty"
+                },
+                "timeout": {
+                    "type": "integer",
+                    "example": 300000
+                },
+                "user": {
+                    "$ref": "#/definitions/domain.WebAuthnUserEntity"
+                }
+            }
+        },
+        "domain.WebAuthnCredential": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "integer",
+                    "example": -7
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "base64url",
+                    "example": "AQIDBAUGBwgJCgsMDQ4PEA"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "YubiKey 5"
+                }
+            }
+        },
+        "domain.WebAuthnCredentialDescriptor": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "AQIDBAUGBwgJCgsMDQ4PEA"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "public-key"
+                }
+            }
+        },
+        "domain.WebAuthnCredentialParameter": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "integer",
+                    "example": -7
+                },
+                "type": {
+                    "type": "string",
+                    "example": "public-key"
+                }
+            }
+        },
+        "domain.WebAuthnRelyingParty": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "localhost"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ClientApp"
+                }
+            }
+        },
+        "domain.WebAuthnRequestOptions": {
+            "type": "object",
+            "properties": {
+                "allowCredentials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.WebAuthnCredentialDescriptor"
+                    }
+                },
+                "challenge": {
+                    "type": "string",
+                    "example": "3q2-7wEjRWeJq83v8SNFZ4mrze8BI0VniavN7wEjRWc"
+                },
+                "rpId": {
+                    "type": "string",
+                    "example": "localhost"
+                },
+                "timeout": {
+                    "type": "integer",
+                    "example": 300000
+                },
+                "userVerification": {
+                    "type": "string",
+                    "example": "required"
+                }
+            }
+        },
+        "domain.WebAuthnUserEntity": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string",
+                    "example": "Alice"
+                },
+                "id": {
+                    "description": "ID is the user handle, the base64url encoded bytes of the user ID",
+                    "type": "string",
+                    "example": "ESIzRFVmd4iZqrvM3e7_AA"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "alice"
+                }
+            }
+        },
         "echo.HTTPError": {
             "type": "object",
             "properties": {
@@ -744,6 +4080,9 @@ const docTemplate = `{
         }
     },
     "securityDefinitions": {
+        "BasicAuth": {
+            "type": "basic"
+        },
         "Bearer": {
             "description": "Type \"Bearer\" followed by a space and JWT token.",
             "type": "apiKey",
@@ -758,7 +4097,7 @@ var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "",
 	BasePath:         "/",
-	Schemes:          []string{"http"},
+	Schemes:          []string{"http", "https"},
 	Title:            "My Echo API",
 	Description:      "API documentation",
 	InfoInstanceName: "swagger",
@@ -3,20 +3,74 @@ package configs
 import (
 	"fmt"
 	"log"
+	"net"
+	"net/url"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type AppConfig struct {
-	Port             int
-	LogLevel         string
-	DbURL            string
-	MaxConn          int
-	MinConn          int
-	SecretKey        string
-	SecretExpiration int
-	Issuer           string
+	Port              int
+	LogLevel          string
+	DbURL             string
+	MaxConn           int
+	MinConn           int
+	SecretKey         string
+	SecretExpiration  int
+	Issuer            string
+	BaseURL           string
+	Audience          string
+	RefreshExpiration int
+	SigningKeyFiles   []string
+	KeyAlgorithm      string
+	KeyActivation     int
+	RevocationCache   int
+	OpaqueTokenCache  int
+	DPoPProofWindow   int
+	TLSCertFile       string
+	TLSKeyFile        string
+	ClaimsNamespace   string
+	ClaimProviders    []string
+	StaticClaims      map[string]string
+	// AdminAccounts are the accounts of the users whose login tokens hold the admin permission
+	AdminAccounts []string
+	// Peppers of password and client secret hashes by version, the highest is the current one
+	Peppers map[int]string
+
+	AuthorizationCodeExpiration int
+	DeviceCodeExpiration        int
+	DevicePollInterval          int
+	CookieSessionExpiration     int
+	MFAChallengeExpiration      int
+	StepUpMaxAge                int
+
+	// WebAuthn relying party the passkeys are scoped to and the origins of the pages allowed to use them
+	WebAuthnRPID    string
+	WebAuthnRPName  string
+	WebAuthnOrigins []string
+
+	// Argon2id cost of password and client secret hashes, memory in KiB
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+
+	// Password policy of the passwords administrators set, a breached password corpus is optional
+	PasswordMinLength           int
+	PasswordMinCharacterClasses int
+	PasswordHistory             int
+	BreachedPasswordsFile       string
+
+	// Brute force protection of the logins, durations in seconds
+	LoginMaxFailures      int
+	LoginMaxFailuresPerIP int
+	LoginLockout          int
+	LoginMaxLockout       int
+	LoginFailureWindow    int
+	// TrustedProxies may set X-Forwarded-For, the client address of everyone else is the peer address
+	TrustedProxies []*net.IPNet
 }
 
 func LoadConfig(envFilePath string) (*AppConfig, error) {
@@ -43,9 +97,35 @@ func LoadConfig(envFilePath string) (*AppConfig, error) {
 		return nil, err
 	}
 
+	// Static PEM private keys, the first one signs until a rotated key activates.
+	// SECRET_KEY (HS256) is optional, without any static key the key ring generates one.
+	var signingKeyFiles []string
+	for _, path := range strings.Split(getEnv(envMap, "SIGNING_KEY_FILES", ""), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			signingKeyFiles = append(signingKeyFiles, path)
+		}
+	}
 	secretKey := getEnv(envMap, "SECRET_KEY", "")
-	if secretKey == "" {
-		return nil, fmt.Errorf("SECRET_KEY cannot be empty.")
+
+	keyAlgorithm := getEnv(envMap, "KEY_ALGORITHM", "ES256")
+	switch keyAlgorithm {
+	case "RS256", "ES256", "EdDSA":
+	default:
+		return nil, fmt.Errorf("KEY_ALGORITHM must be one of RS256, ES256 or EdDSA.")
+	}
+
+	rawKeyActivation := getEnv(envMap, "KEY_ACTIVATION_DELAY", "600")
+	keyActivation, err := strconv.Atoi(rawKeyActivation)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts of the administrators, their login tokens hold the admin permission of the /admin APIs.
+	var adminAccounts []string
+	for _, account := range strings.Split(getEnv(envMap, "ADMIN_ACCOUNTS", ""), ",") {
+		if account = strings.TrimSpace(account); account != "" {
+			adminAccounts = append(adminAccounts, account)
+		}
 	}
 
 	issuer := getEnv(envMap, "ISSUER", "")
@@ -53,19 +133,333 @@ func LoadConfig(envFilePath string) (*AppConfig, error) {
 		return nil, fmt.Errorf("ISSUER cannot be empty.")
 	}
 
+	// PEM certificate and key of the TLS listener. Without them the server speaks plain HTTP and
+	// clients cannot authenticate with a TLS client certificate.
+	tlsCertFile := getEnv(envMap, "TLS_CERT_FILE", "")
+	tlsKeyFile := getEnv(envMap, "TLS_KEY_FILE", "")
+	if (tlsCertFile == "") != (tlsKeyFile == "") {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together.")
+	}
+	scheme := "http"
+	if tlsCertFile != "" {
+		scheme = "https"
+	}
+
+	// Public URL of the service, the endpoints in the OpenID Connect discovery document are built from it.
+	baseURL := strings.TrimSuffix(getEnv(envMap, "BASE_URL", fmt.Sprintf("%s://localhost:%d", scheme, port)), "/")
+
+	// aud of the tokens this server accepts, also the default aud of the tokens it issues.
+	audience := getEnv(envMap, "AUDIENCE", baseURL)
+
 	raawExpiration := getEnv(envMap, "SECRET_EXPIRATION", "900")
 	expiration, err := strconv.Atoi(raawExpiration)
 	if err != nil {
 		return nil, err
 	}
 
+	rawRefreshExpiration := getEnv(envMap, "REFRESH_EXPIRATION", "1209600")
+	refreshExpiration, err := strconv.Atoi(rawRefreshExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lifetime of the browser sessions of the web console, they are not renewed by use.
+	rawCookieSessionExpiration := getEnv(envMap, "COOKIE_SESSION_EXPIRATION", "28800")
+	cookieSessionExpiration, err := strconv.Atoi(rawCookieSessionExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	// Time a user has to answer the second factor challenge of a login whose password was verified.
+	rawMFAChallengeExpiration := getEnv(envMap, "MFA_CHALLENGE_EXPIRATION", "300")
+	mfaChallengeExpiration, err := strconv.Atoi(rawMFAChallengeExpiration)
+	if err != nil {
+		return nil, err
+	}
+	if mfaChallengeExpiration < 1 {
+		return nil, fmt.Errorf("MFA_CHALLENGE_EXPIRATION must be positive.")
+	}
+
+	// How long ago a user may have signed in with a second factor to remove one of their factors.
+	rawStepUpMaxAge := getEnv(envMap, "STEP_UP_MAX_AGE", "300")
+	stepUpMaxAge, err := strconv.Atoi(rawStepUpMaxAge)
+	if err != nil {
+		return nil, err
+	}
+	if stepUpMaxAge < 1 {
+		return nil, fmt.Errorf("STEP_UP_MAX_AGE must be positive.")
+	}
+
+	// Passkeys are bound to the relying party ID, a domain that cannot change once users registered
+	// them. It defaults to the host of BASE_URL, the origins to BASE_URL itself.
+	parsedBaseURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	webauthnRPID := getEnv(envMap, "WEBAUTHN_RP_ID", parsedBaseURL.Hostname())
+	if webauthnRPID == "" {
+		return nil, fmt.Errorf("WEBAUTHN_RP_ID cannot be empty.")
+	}
+	var webauthnOrigins []string
+	for _, origin := range strings.Split(getEnv(envMap, "WEBAUTHN_ORIGINS", baseURL), ",") {
+		if origin = strings.TrimSuffix(strings.TrimSpace(origin), "/"); origin == "" {
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || parsed.Host == "" || (parsed.Hostname() != webauthnRPID && !strings.HasSuffix(parsed.Hostname(), "."+webauthnRPID)) {
+			return nil, fmt.Errorf("WEBAUTHN_ORIGINS must be origins on WEBAUTHN_RP_ID or a subdomain of it.")
+		}
+		webauthnOrigins = append(webauthnOrigins, origin)
+	}
+
+	// Argon2id parameters of new password hashes, stored hashes made with other ones are
+	// upgraded at the next login of their user.
+	rawArgon2Memory := getEnv(envMap, "ARGON2_MEMORY", "65536")
+	argon2Memory, err := strconv.Atoi(rawArgon2Memory)
+	if err != nil {
+		return nil, err
+	}
+	rawArgon2Iterations := getEnv(envMap, "ARGON2_ITERATIONS", "3")
+	argon2Iterations, err := strconv.Atoi(rawArgon2Iterations)
+	if err != nil {
+		return nil, err
+	}
+	rawArgon2Parallelism := getEnv(envMap, "ARGON2_PARALLELISM", "2")
+	argon2Parallelism, err := strconv.Atoi(rawArgon2Parallelism)
+	if err != nil {
+		return nil, err
+	}
+	if argon2Iterations < 1 || argon2Parallelism < 1 || argon2Parallelism > 255 || argon2Memory < 8*argon2Parallelism {
+		return nil, fmt.Errorf("ARGON2_ITERATIONS must be positive, ARGON2_PARALLELISM between 1 and 255 and ARGON2_MEMORY at least 8 KiB per lane.")
+	}
+
+	// Password policy: minimum length, how many of lowercase, uppercase, digits and symbols must appear
+	// and how many of the latest passwords, the current one included, cannot be reused.
+	rawPasswordMinLength := getEnv(envMap, "PASSWORD_MIN_LENGTH", "12")
+	passwordMinLength, err := strconv.Atoi(rawPasswordMinLength)
+	if err != nil {
+		return nil, err
+	}
+	rawPasswordMinCharacterClasses := getEnv(envMap, "PASSWORD_MIN_CHARACTER_CLASSES", "3")
+	passwordMinCharacterClasses, err := strconv.Atoi(rawPasswordMinCharacterClasses)
+	if err != nil {
+		return nil, err
+	}
+	if passwordMinCharacterClasses < 0 || passwordMinCharacterClasses > 4 {
+		return nil, fmt.Errorf("PASSWORD_MIN_CHARACTER_CLASSES must be between 0 and 4.")
+	}
+	rawPasswordHistory := getEnv(envMap, "PASSWORD_HISTORY", "5")
+	passwordHistory, err := strconv.Atoi(rawPasswordHistory)
+	if err != nil {
+		return nil, err
+	}
+	if passwordMinLength < 0 || passwordHistory < 0 {
+		return nil, fmt.Errorf("PASSWORD_MIN_LENGTH and PASSWORD_HISTORY cannot be negative.")
+	}
+
+	// Offline copy of the Pwned Passwords corpus in the SHA-1 range format of the PwnedPasswordsDownloader,
+	// passwords found in it are rejected. Without it passwords are not screened.
+	breachedPasswordsFile := getEnv(envMap, "BREACHED_PASSWORDS_FILE", "")
+
+	// Failed logins of an account or client, and from a source IP, before it is locked out for
+	// LOGIN_LOCKOUT. Each further failure doubles the lockout up to LOGIN_MAX_LOCKOUT, failures are
+	// forgotten LOGIN_FAILURE_WINDOW after the last one and its lockout. 0 failures disables a limit.
+	rawLoginMaxFailures := getEnv(envMap, "LOGIN_MAX_FAILURES", "5")
+	loginMaxFailures, err := strconv.Atoi(rawLoginMaxFailures)
+	if err != nil {
+		return nil, err
+	}
+	rawLoginMaxFailuresPerIP := getEnv(envMap, "LOGIN_MAX_FAILURES_PER_IP", "20")
+	loginMaxFailuresPerIP, err := strconv.Atoi(rawLoginMaxFailuresPerIP)
+	if err != nil {
+		return nil, err
+	}
+	rawLoginLockout := getEnv(envMap, "LOGIN_LOCKOUT", "30")
+	loginLockout, err := strconv.Atoi(rawLoginLockout)
+	if err != nil {
+		return nil, err
+	}
+	rawLoginMaxLockout := getEnv(envMap, "LOGIN_MAX_LOCKOUT", "3600")
+	loginMaxLockout, err := strconv.Atoi(rawLoginMaxLockout)
+	if err != nil {
+		return nil, err
+	}
+	rawLoginFailureWindow := getEnv(envMap, "LOGIN_FAILURE_WINDOW", "900")
+	loginFailureWindow, err := strconv.Atoi(rawLoginFailureWindow)
+	if err != nil {
+		return nil, err
+	}
+	if loginLockout < 1 || loginMaxLockout < loginLockout || loginFailureWindow < 1 {
+		return nil, fmt.Errorf("LOGIN_LOCKOUT and LOGIN_FAILURE_WINDOW must be positive and LOGIN_MAX_LOCKOUT at least LOGIN_LOCKOUT.")
+	}
+
+	// Reverse proxies whose X-Forwarded-For is trusted as CIDRs or addresses, e.g. 10.0.0.0/8,::1.
+	// Failed logins are counted by client address, so no one else may pick it.
+	var trustedProxies []*net.IPNet
+	for _, proxy := range strings.Split(getEnv(envMap, "TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES must be a list of CIDRs or IP addresses.")
+		}
+		trustedProxies = append(trustedProxies, network)
+	}
+
+	// How long a "not revoked" lookup is trusted before the denylist is queried again.
+	rawRevocationCache := getEnv(envMap, "REVOCATION_CACHE_TTL", "10")
+	revocationCache, err := strconv.Atoi(rawRevocationCache)
+	if err != nil {
+		return nil, err
+	}
+
+	// How long a resolved opaque token is trusted before it is looked up again, this bounds how long
+	// a revocation made by another replica takes to be seen.
+	rawOpaqueTokenCache := getEnv(envMap, "OPAQUE_TOKEN_CACHE_TTL", "10")
+	opaqueTokenCache, err := strconv.Atoi(rawOpaqueTokenCache)
+	if err != nil {
+		return nil, err
+	}
+
+	// How far the iat of a DPoP proof may be from now, proofs are remembered that long against replay.
+	rawDPoPProofWindow := getEnv(envMap, "DPOP_PROOF_WINDOW", "60")
+	dpopProofWindow, err := strconv.Atoi(rawDPoPProofWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	// Custom claims are named ClaimsNamespace + name, so they cannot collide with registered claims (RFC 7519 section 4.2).
+	// CLAIM_PROVIDERS enrich first-party user tokens and are the default of new clients.
+	claimsNamespace := getEnv(envMap, "CLAIMS_NAMESPACE", baseURL+"/claims/")
+	var claimProviders []string
+	for _, name := range strings.Split(getEnv(envMap, "CLAIM_PROVIDERS", ""), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			claimProviders = append(claimProviders, name)
+		}
+	}
+
+	// Claims of the static provider as name=value pairs, e.g. tenant=acme,env=production.
+	staticClaims := map[string]string{}
+	for _, pair := range strings.Split(getEnv(envMap, "STATIC_CLAIMS", ""), ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("STATIC_CLAIMS must be a list of name=value pairs.")
+		}
+		staticClaims[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	// Peppers as version=value pairs in PEPPERS or version=path pairs in PEPPER_FILES, e.g. for
+	// docker secrets. Add a higher version to rotate, remove a version to retire it: hashes made
+	// with it no longer verify, users and clients that logged in since were moved to a newer one.
+	peppers := map[int]string{}
+	for _, source := range []string{"PEPPERS", "PEPPER_FILES"} {
+		for _, pair := range strings.Split(getEnv(envMap, source, ""), ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			rawVersion, value, ok := strings.Cut(pair, "=")
+			version, err := strconv.Atoi(strings.TrimSpace(rawVersion))
+			if !ok || err != nil || version < 0 || strings.TrimSpace(value) == "" {
+				return nil, fmt.Errorf("%s must be a list of version=value pairs with non-negative versions.", source)
+			}
+			if _, ok := peppers[version]; ok {
+				return nil, fmt.Errorf("pepper version %d is defined twice.", version)
+			}
+			value = strings.TrimSpace(value)
+			if source == "PEPPER_FILES" {
+				data, err := os.ReadFile(value)
+				if err != nil {
+					return nil, err
+				}
+				value = strings.TrimSpace(string(data))
+			}
+			peppers[version] = value
+		}
+	}
+	if len(peppers) == 0 {
+		return nil, fmt.Errorf("PEPPERS or PEPPER_FILES must define at least one pepper.")
+	}
+
+	rawAuthorizationCodeExpiration := getEnv(envMap, "AUTHORIZATION_CODE_EXPIRATION", "60")
+	authorizationCodeExpiration, err := strconv.Atoi(rawAuthorizationCodeExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	rawDeviceCodeExpiration := getEnv(envMap, "DEVICE_CODE_EXPIRATION", "600")
+	deviceCodeExpiration, err := strconv.Atoi(rawDeviceCodeExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	// Minimum seconds between two polls of a device, raised per request on slow_down.
+	rawDevicePollInterval := getEnv(envMap, "DEVICE_POLL_INTERVAL", "5")
+	devicePollInterval, err := strconv.Atoi(rawDevicePollInterval)
+	if err != nil {
+		return nil, err
+	}
+
 	return &AppConfig{
-		Port:             port,
-		MaxConn:          maxConn,
-		MinConn:          minConn,
-		LogLevel:         getEnv(envMap, "LOG_LEVEL", "INFO"),
-		SecretKey:        secretKey,
-		Issuer:           issuer,
-		SecretExpiration: expiration,
+		Port:              port,
+		MaxConn:           maxConn,
+		MinConn:           minConn,
+		LogLevel:          getEnv(envMap, "LOG_LEVEL", "INFO"),
+		SecretKey:         secretKey,
+		Issuer:            issuer,
+		BaseURL:           baseURL,
+		Audience:          audience,
+		SecretExpiration:  expiration,
+		RefreshExpiration: refreshExpiration,
+		SigningKeyFiles:   signingKeyFiles,
+		KeyAlgorithm:      keyAlgorithm,
+		KeyActivation:     keyActivation,
+		RevocationCache:   revocationCache,
+		OpaqueTokenCache:  opaqueTokenCache,
+		DPoPProofWindow:   dpopProofWindow,
+		TLSCertFile:       tlsCertFile,
+		TLSKeyFile:        tlsKeyFile,
+		ClaimsNamespace:   claimsNamespace,
+		ClaimProviders:    claimProviders,
+		StaticClaims:      staticClaims,
+		AdminAccounts:     adminAccounts,
+		Peppers:           peppers,
+
+		AuthorizationCodeExpiration: authorizationCodeExpiration,
+		DeviceCodeExpiration:        deviceCodeExpiration,
+		DevicePollInterval:          devicePollInterval,
+		CookieSessionExpiration:     cookieSessionExpiration,
+		MFAChallengeExpiration:      mfaChallengeExpiration,
+		StepUpMaxAge:                stepUpMaxAge,
+
+		WebAuthnRPID:    webauthnRPID,
+		WebAuthnRPName:  getEnv(envMap, "WEBAUTHN_RP_NAME", issuer),
+		WebAuthnOrigins: webauthnOrigins,
+
+		Argon2Memory:      argon2Memory,
+		Argon2Iterations:  argon2Iterations,
+		Argon2Parallelism: argon2Parallelism,
+
+		PasswordMinLength:           passwordMinLength,
+		PasswordMinCharacterClasses: passwordMinCharacterClasses,
+		PasswordHistory:             passwordHistory,
+		BreachedPasswordsFile:       breachedPasswordsFile,
+
+		LoginMaxFailures:      loginMaxFailures,
+		LoginMaxFailuresPerIP: loginMaxFailuresPerIP,
+		LoginLockout:          loginLockout,
+		LoginMaxLockout:       loginMaxLockout,
+		LoginFailureWindow:    loginFailureWindow,
+		TrustedProxies:        trustedProxies,
 	}, nil
 }
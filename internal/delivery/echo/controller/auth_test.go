@@ -0,0 +1,80 @@
+package controller_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	opaqueRepo "github.com/bright-pentium/go-client-practice/internal/repository/opaque"
+	revocationRepo "github.com/bright-pentium/go-client-practice/internal/repository/revocation"
+	sessionRepo "github.com/bright-pentium/go-client-practice/internal/repository/session"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testAudience = "http://localhost:8000"
+
+var testKeys = usecase.NewKeySet(usecase.NewHMACSigningKey("secret"))
+
+// testVerifier verifies the tokens signed by signToken, none of them is revoked or signed out yet.
+func testVerifier() (*usecase.TokenVerifier, *revocationRepo.MockRevocationRepository) {
+	mockRevocations := new(revocationRepo.MockRevocationRepository)
+	mockRevocations.On("IsTokenRevoked", mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	mockOpaque := new(opaqueRepo.MockOpaqueTokenRepository)
+	mockOpaque.On("GetOpaqueTokenByHash", mock.Anything, mock.Anything).Return(nil, domain.ErrOpaqueTokenNotFound).Maybe()
+	mockSessions := new(sessionRepo.MockSessionRepository)
+	mockSessions.On("TouchSession", mock.Anything, mock.Anything, mock.Anything).Return(&domain.Session{ExpiresAt: time.Now().Add(time.Hour)}, nil).Maybe()
+	sessions := usecase.NewSessionUseCase(mockSessions, nil, time.Minute)
+	return usecase.NewTokenVerifier(testKeys, usecase.NewOpaqueTokenUseCase(mockOpaque, time.Minute), usecase.NewRevocationUseCase(mockRevocations, time.Minute), sessions), mockRevocations
+}
+
+func testAuthenticator() *middleware.Authenticator {
+	verifier, _ := testVerifier()
+	return middleware.NewAuthenticator(verifier, nil, usecase.NewSessionUseCase(new(sessionRepo.MockSessionRepository), nil, time.Minute), testAudience, testAudience)
+}
+
+// userClaims is a login token of a user holding scope.
+func userClaims(scope ...domain.Permission) domain.JwtClaims {
+	now := time.Now()
+	return domain.JwtClaims{
+		Scope: domain.FormatScope(scope),
+		Type:  domain.UserType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uuid.NewString(),
+			Audience:  jwt.ClaimStrings{testAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+	}
+}
+
+func signToken(t *testing.T, claims domain.JwtClaims) string {
+	token, err := testKeys.Sign(claims)
+	require.NoError(t, err)
+	return token
+}
+
+// serve runs the request against e, with the bearer token unless it is empty.
+func serve(e *echo.Echo, method string, target string, token string) *httptest.ResponseRecorder {
+	return serveJSON(e, method, target, token, "")
+}
+
+// serveJSON is serve with a JSON request body.
+func serveJSON(e *echo.Echo, method string, target string, token string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
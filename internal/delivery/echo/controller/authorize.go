@@ -0,0 +1,217 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// AuthorizeController is the authorization endpoint of the code flow. The consent screen
+// lives in the frontend: it reads the request with GET and submits the decision with POST,
+// then navigates the browser to the returned redirect_to.
+type AuthorizeController struct {
+	usecase *usecase.AuthorizationUseCase
+	auth    *middleware.Authenticator
+	config  *configs.AppConfig
+}
+
+func NewAuthorizeController(usecase *usecase.AuthorizationUseCase, auth *middleware.Authenticator, config *configs.AppConfig) *AuthorizeController {
+	return &AuthorizeController{usecase: usecase, auth: auth, config: config}
+}
+
+func (a *AuthorizeController) RegisterRoutes(e *echo.Echo) {
+	api := e.Group("/oauth/authorize", a.auth.Middleware, middleware.RequireFirstPartyUser)
+	api.GET("", a.GetConsent)
+	api.POST("", a.Consent)
+}
+
+// AuthorizeRequest carries the parameters of RFC 6749 section 4.1.1 and RFC 7636 section 4.3.
+type AuthorizeRequest struct {
+	ResponseType        string `query:"response_type" json:"response_type" example:"code"`
+	ClientID            string `query:"client_id" json:"client_id" example:"6cc2b688-1246-4a62-a293-dae7e67d6097"`
+	RedirectURI         string `query:"redirect_uri" json:"redirect_uri" example:"https://app.example.com/callback"`
+	Scope               string `query:"scope" json:"scope" example:"resource:create"`
+	State               string `query:"state" json:"state" example:"af0ifjsldkj"`
+	CodeChallenge       string `query:"code_challenge" json:"code_challenge" example:"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"`
+	CodeChallengeMethod string `query:"code_challenge_method" json:"code_challenge_method" example:"S256"`
+	Nonce               string `query:"nonce" json:"nonce" example:"n-0S6_WzA2Mj"`
+}
+
+type ConsentRequest struct {
+	AuthorizeRequest
+	Approve bool `json:"approve" example:"true"`
+	// ApprovedScope is the subset of the requested scope the user agreed to, empty approves all of it
+	ApprovedScope []domain.Permission `json:"approved_scope" example:"resource:create" validate:"omitempty,dive,required,perm"`
+}
+
+type ConsentResponse struct {
+	ClientID    uuid.UUID           `json:"client_id" example:"6cc2b688-1246-4a62-a293-dae7e67d6097"`
+	RedirectURI string              `json:"redirect_uri" example:"https://app.example.com/callback"`
+	Scope       []domain.Permission `json:"scope" example:"resource:create"`
+	State       string              `json:"state,omitempty" example:"af0ifjsldkj"`
+}
+
+type AuthorizeResponse struct {
+	RedirectTo string `json:"redirect_to" example:"https://app.example.com/callback?code=SplxlOBeZQQYbYS6WxSbIA&state=af0ifjsldkj"`
+}
+
+// @Summary Authorization request
+// @Description Validates an authorization code request (RFC 6749 section 4.1.1, PKCE S256 required) and returns what the user is asked to consent to.
+// @Description Errors about the client or redirect uri are shown to the user; any other error carries redirect_to for relaying it to the client.
+// @Tags oauth
+// @Produce  json
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect URI"
+// @Param scope query string false "Space-delimited requested scope"
+// @Param state query string false "Opaque value echoed back to the client"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Param nonce query string false "OpenID Connect nonce, echoed in the ID token"
+// @Success 200 {object} ConsentResponse "Success"
+// @Failure 400 {object} OAuthError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} OAuthError "Internal Server Error"
+// @Security Bearer
+// @Router /oauth/authorize [get]
+func (a *AuthorizeController) GetConsent(ctx echo.Context) error {
+	req := new(AuthorizeRequest)
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	authReq, err := req.toDomain()
+	if err != nil {
+		return writeOAuthError(ctx, err)
+	}
+
+	client, scope, err := a.usecase.ValidateAuthorizationRequest(ctx.Request().Context(), authReq)
+	if err != nil {
+		return writeOAuthError(ctx, authorizeError(authReq, err))
+	}
+	return ctx.JSON(http.StatusOK, ConsentResponse{ClientID: client.ID, RedirectURI: authReq.RedirectURI, Scope: scope, State: authReq.State})
+}
+
+// @Summary Authorization consent
+// @Description Records the decision of the logged-in user. On approval a single-use authorization code is issued for the approved subset of the requested scope.
+// @Description The response tells the user agent where to send the user back to the client.
+// @Tags oauth
+// @Accept  json
+// @Produce  json
+// @Param request body ConsentRequest true "Consent Request"
+// @Success 200 {object} AuthorizeResponse "Success"
+// @Failure 400 {object} OAuthError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} OAuthError "Internal Server Error"
+// @Security Bearer
+// @Router /oauth/authorize [post]
+func (a *AuthorizeController) Consent(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+	req := new(ConsentRequest)
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	authReq, err := req.toDomain()
+	if err != nil {
+		return writeOAuthError(ctx, err)
+	}
+
+	if !req.Approve {
+		// the request still has to be trusted before anything is sent to its redirect uri
+		if _, _, err := a.usecase.ValidateAuthorizationRequest(ctx.Request().Context(), authReq); err != nil {
+			return writeOAuthError(ctx, authorizeError(authReq, err))
+		}
+		return ctx.JSON(http.StatusOK, AuthorizeResponse{
+			RedirectTo: redirectWith(authReq, url.Values{"error": {OAuthAccessDenied}, "error_description": {"the user denied the request"}}),
+		})
+	}
+
+	code, err := a.usecase.IssueAuthorizationCode(ctx.Request().Context(), userID, authTime(ctx), authReq, req.ApprovedScope)
+	if err != nil {
+		return writeOAuthError(ctx, authorizeError(authReq, err))
+	}
+	return ctx.JSON(http.StatusOK, AuthorizeResponse{RedirectTo: redirectWith(authReq, url.Values{"code": {code}})})
+}
+
+func (r *AuthorizeRequest) toDomain() (*domain.AuthorizationRequest, error) {
+	clientID, err := uuid.Parse(r.ClientID)
+	if err != nil {
+		return nil, newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "invalid client_id")
+	}
+	return &domain.AuthorizationRequest{
+		ResponseType:        r.ResponseType,
+		ClientID:            clientID,
+		RedirectURI:         r.RedirectURI,
+		Scope:               domain.ParseScope(r.Scope),
+		State:               r.State,
+		CodeChallenge:       r.CodeChallenge,
+		CodeChallengeMethod: r.CodeChallengeMethod,
+		Nonce:               r.Nonce,
+	}, nil
+}
+
+// authTime is when the user behind the login token authenticated, tokens minted
+// before auth_time was introduced fall back to their issue time.
+func authTime(ctx echo.Context) time.Time {
+	claims, _ := ctx.Get("claims").(*domain.JwtClaims)
+	switch {
+	case claims == nil:
+		return time.Now()
+	case claims.AuthTime != nil:
+		return claims.AuthTime.Time
+	case claims.IssuedAt != nil:
+		return claims.IssuedAt.Time
+	}
+	return time.Now()
+}
+
+// authorizeError maps usecase errors to RFC 6749 section 4.1.2.1. An unknown client or
+// unregistered redirect uri must never be redirected to.
+func authorizeError(req *domain.AuthorizationRequest, err error) error {
+	var oauthErr *OAuthError
+	switch {
+	case errors.Is(err, domain.ErrClientNotFound):
+		return newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "unknown client_id")
+	case errors.Is(err, domain.ErrInvalidRedirectURI):
+		return newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, err.Error())
+	case errors.Is(err, domain.ErrUnsupportedResponseType):
+		oauthErr = newOAuthError(http.StatusBadRequest, OAuthUnsupportedResponseType, err.Error())
+	case errors.Is(err, domain.ErrInvalidAuthorizationRequest):
+		oauthErr = newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, err.Error())
+	case errors.Is(err, domain.ErrInvalidScope):
+		oauthErr = newOAuthError(http.StatusBadRequest, OAuthInvalidScope, err.Error())
+	default:
+		return err
+	}
+	oauthErr.RedirectTo = redirectWith(req, url.Values{"error": {oauthErr.Code}, "error_description": {oauthErr.Description}})
+	return oauthErr
+}
+
+// redirectWith adds the response parameters and the state to the query of the redirect uri.
+func redirectWith(req *domain.AuthorizationRequest, params url.Values) string {
+	redirect, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		return req.RedirectURI
+	}
+	query := redirect.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	redirect.RawQuery = query.Encode()
+	return redirect.String()
+}
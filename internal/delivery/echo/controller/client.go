@@ -1,42 +1,40 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
-	"strings"
-	"time"
+	"slices"
 
 	"github.com/bright-pentium/go-client-practice/internal/configs"
 	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
 	"github.com/bright-pentium/go-client-practice/internal/domain"
 	"github.com/bright-pentium/go-client-practice/internal/usecase"
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
-	echojwt "github.com/labstack/echo-jwt"
 	"github.com/labstack/echo/v4"
 )
 
 type ClientController struct {
 	usecase *usecase.ClientUseCase
+	keyCase *usecase.ClientKeyUseCase
+	dpop    *usecase.DPoPUseCase
+	issuer  *usecase.TokenIssuer
+	auth    *middleware.Authenticator
 	config  *configs.AppConfig
 }
 
-func NewClientController(usecase *usecase.ClientUseCase, config *configs.AppConfig) *ClientController {
-	return &ClientController{usecase: usecase, config: config}
+func NewClientController(usecase *usecase.ClientUseCase, keyCase *usecase.ClientKeyUseCase, dpop *usecase.DPoPUseCase, issuer *usecase.TokenIssuer, auth *middleware.Authenticator, config *configs.AppConfig) *ClientController {
+	return &ClientController{usecase: usecase, keyCase: keyCase, dpop: dpop, issuer: issuer, auth: auth, config: config}
 }
 
 func (c *ClientController) RegisterRoutes(e *echo.Echo) {
 
-	api := e.Group("/clients", echojwt.WithConfig(echojwt.Config{
-		// ...
-		SigningKey: []byte(c.config.SecretKey),
-		NewClaimsFunc: func(c echo.Context) jwt.Claims {
-			return new(domain.JwtClaims)
-		},
-		// ...
-	}))
-	api.Use(middleware.JWTMiddleware)
+	// clients are registered by their user, never by a client or with a token delegated to one
+	api := e.Group("/clients", c.auth.SessionMiddleware, middleware.RequireFirstPartyUser)
 	api.GET("", c.ListClientsByUser)
 	api.POST("", c.CreateClient)
+	api.GET("/:client-id/keys", c.ListClientKeys)
+	api.POST("/:client-id/keys", c.AddClientKey)
+	api.DELETE("/:client-id/keys/:kid", c.DeleteClientKey)
 
 	e.POST("/clients/login", c.ClientLogin)
 
@@ -58,6 +56,7 @@ type ClientResponse struct {
 // @Success 200 {array} ClientResponse "Success"
 // @Failure 400 {object} echo.HTTPError "Bad Request"
 // @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Not a user login token"
 // @Failure 500 {object} echo.HTTPError "Internal Server Error"
 // @Security Bearer
 // @Router /clients [get]
@@ -74,16 +73,40 @@ func (c *ClientController) ListClientsByUser(ctx echo.Context) error {
 }
 
 type CreateClientRequest struct {
-	Scope []domain.Permission `json:"scope" example:"resource:create" validate:"required,min=1,dive,required,perm"`
+	Scope        []domain.Permission `json:"scope" example:"resource:create" validate:"required,min=1,dive,required,perm"`
+	RedirectURIs []string            `json:"redirectUris" example:"https://app.example.com/callback" validate:"omitempty,dive,required"`
+	Audiences    []string            `json:"audiences" example:"https://api.example.com" validate:"omitempty,dive,required"`
+	DPoPRequired bool                `json:"dpopRequired" example:"false"`
+	// AuthMethod defaults to client_secret_basic, the tls fields are required by the TLS client authentication methods
+	AuthMethod               string `json:"tokenEndpointAuthMethod" example:"client_secret_basic" validate:"omitempty,oneof=client_secret_basic tls_client_auth self_signed_tls_client_auth private_key_jwt"`
+	TLSSubjectDN             string `json:"tlsClientAuthSubjectDn" example:"CN=service,O=Example"`
+	TLSClientCA              string `json:"tlsClientAuthCa"`
+	TLSCertificateThumbprint string `json:"tlsClientCertificateThumbprint"`
+	// JWKS are public keys registered with the client, more can be added under /clients/{client-id}/keys
+	JWKS []domain.JWK `json:"jwks"`
+	// ClaimProviders add custom claims to the tokens of the client, the server defaults when left out
+	ClaimProviders []string `json:"claimProviders" example:"owner"`
+	// AccessTokenFormat defaults to jwt
+	AccessTokenFormat string `json:"accessTokenFormat" example:"opaque" validate:"omitempty,oneof=jwt opaque"`
 }
 
 type CreateClientReponse struct {
 	domain.Client
-	Secret string `json:"secret" example:"o44z4KUzru7uW4jtzxVt84Ma8f76Mnwj"`
+	Secret string             `json:"secret,omitempty" example:"o44z4KUzru7uW4jtzxVt84Ma8f76Mnwj"`
+	Keys   []domain.ClientKey `json:"keys,omitempty"`
 }
 
 // @Summary Create Client
 // @Description Creates a new client associated with the authenticated user.
+// @Description Redirect URIs are required for the authorization code flow and must be absolute without a fragment.
+// @Description Audiences lists the other services the client may request tokens for.
+// @Description Privileged permissions, token:introspect and token:exchange, are not covered by "*" and only administrators grant them.
+// @Description A client flagged dpopRequired is only issued DPoP-bound tokens (RFC 9449).
+// @Description Clients registered for tls_client_auth (CA and subject DN) or self_signed_tls_client_auth (certificate
+// @Description thumbprint) authenticate with their TLS client certificate (RFC 8705) and get no secret.
+// @Description private_key_jwt clients get no secret either, they sign assertions with the keys in jwks (RFC 7523).
+// @Description claimProviders picks the providers adding namespaced custom claims to the tokens of the client.
+// @Description accessTokenFormat opaque issues random reference tokens instead of JWTs, resource servers resolve them at /oauth/introspect.
 // @Tags client
 // @Accept  json
 // @Produce  json
@@ -91,6 +114,7 @@ type CreateClientReponse struct {
 // @Success 201 {object} CreateClientReponse "Created"
 // @Failure 400 {object} echo.HTTPError "Bad Request"
 // @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Not a user login token, or a privileged permission requested by a non-administrator"
 // @Failure 500 {object} echo.HTTPError "Internal Server Error"
 // @Security Bearer
 // @Router /clients [post]
@@ -103,39 +127,114 @@ func (c *ClientController) CreateClient(ctx echo.Context) error {
 	if err := ctx.Validate(req); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
+	if err := checkPrivilegedScope(ctx, req.Scope); err != nil {
+		return err
+	}
+	claimProviders := req.ClaimProviders
+	if claimProviders == nil {
+		claimProviders = c.config.ClaimProviders
+	}
+	if err := c.issuer.CheckProviders(claimProviders); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	// keys are checked up front so a client is not left half registered
+	for _, jwk := range req.JWKS {
+		if _, err := usecase.ValidateClientJWK(jwk); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	client, secret, err := c.usecase.CreateClient(ctx.Request().Context(), &domain.Client{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Scope:        req.Scope,
+		RedirectURIs: req.RedirectURIs,
+		Audiences:    req.Audiences,
+		DPoPRequired: req.DPoPRequired,
+		AuthMethod:   req.AuthMethod,
 
-	client, secret, err := c.usecase.CreateClient(ctx.Request().Context(), uuid.New(), userID, req.Scope)
+		TLSSubjectDN:             req.TLSSubjectDN,
+		TLSClientCA:              req.TLSClientCA,
+		TLSCertificateThumbprint: req.TLSCertificateThumbprint,
+		ClaimProviders:           claimProviders,
+		AccessTokenFormat:        req.AccessTokenFormat,
+	})
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRedirectURI) || errors.Is(err, domain.ErrInvalidClientData) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
+	keys := make([]domain.ClientKey, 0, len(req.JWKS))
+	for _, jwk := range req.JWKS {
+		key, err := c.keyCase.AddClientKey(ctx.Request().Context(), client.ID, userID, jwk)
+		if err != nil {
+			if errors.Is(err, domain.ErrClientKeyAlreadyExists) {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		keys = append(keys, *key)
+	}
+
 	return ctx.JSON(
 		http.StatusCreated,
 		CreateClientReponse{
 			Client: *client,
 			Secret: secret,
+			Keys:   keys,
 		},
 	)
 }
 
+// checkPrivilegedScope only lets administrators, with their login token, register clients holding
+// privileged permissions. The web console session never holds admin.
+func checkPrivilegedScope(ctx echo.Context, scope []domain.Permission) error {
+	claims, _ := ctx.Get("claims").(*domain.JwtClaims)
+	for _, perm := range scope {
+		if _, privileged := domain.PrivilegedPermissions[perm]; !privileged {
+			continue
+		}
+		if claims == nil || claims.Type != domain.UserType || claims.AuthorizedParty != "" || claims.Act != nil ||
+			!slices.Contains(domain.ParseScope(claims.Scope), domain.PermAdmin) {
+			return echo.NewHTTPError(http.StatusForbidden, "only administrators grant the permission: "+string(perm))
+		}
+	}
+	return nil
+}
+
 type ClientLoinRequest struct {
 	Secret string    `json:"secret" example:"o44z4KUzru7uW4jtzxVt84Ma8f76Mnwj"`
 	ID     uuid.UUID `json:"id" example:"6cc2b688-1246-4a62-a293-dae7e67d6097"`
+	// ClientAssertion replaces the secret of a private_key_jwt client, the ID may then be left out
+	ClientAssertionType string `json:"clientAssertionType" example:"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"`
+	ClientAssertion     string `json:"clientAssertion"`
+	// Scope narrows the token to part of the registered scope, the whole registered scope when empty
+	Scope []domain.Permission `json:"scope" example:"resource:create" validate:"omitempty,dive,perm"`
 }
 
 type ClientLoginReponse struct {
 	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type" example:"DPoP"`
+	Scope       string `json:"scope" example:"resource:create"`
 }
 
 // @Summary Create Client
 // @Description Creates a new client associated with the authenticated user.
+// @Description With a DPoP header (RFC 9449) the token is bound to the proof key and token_type is DPoP.
+// @Description Over mutual TLS the secret may be left out for a TLS client, the token is bound to the certificate (RFC 8705).
+// @Description A private_key_jwt client sends a signed assertion instead, its aud is the token endpoint (RFC 7523).
+// @Description scope requests a least-privilege token, every permission must be covered by the registered scope.
 // @Tags client
 // @Accept  json
 // @Produce  json
+// @Param DPoP header string false "DPoP proof JWT"
 // @Param request body ClientLoinRequest true "Create Client Request"
 // @Success 201 {object} ClientLoginReponse "Created"
 // @Failure 400 {object} echo.HTTPError "Bad Request"
 // @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 429 {object} echo.HTTPError "Too many failed logins of the client or from the address, see Retry-After"
 // @Failure 500 {object} echo.HTTPError "Internal Server Error"
 // @Router /clients/login [post]
 func (c *ClientController) ClientLogin(ctx echo.Context) error {
@@ -147,36 +246,50 @@ func (c *ClientController) ClientLogin(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	client, err := c.usecase.ClientLogin(ctx.Request().Context(), req.ID, req.Secret)
+	var client *domain.Client
+	var err error
+	if req.ClientAssertionType != "" || req.ClientAssertion != "" {
+		client, err = c.assertionLogin(ctx, req)
+	} else if req.Secret == "" && len(peerCertificates(ctx)) > 0 {
+		client, err = c.usecase.TLSClientLogin(ctx.Request().Context(), req.ID, peerCertificates(ctx))
+	} else {
+		client, err = c.usecase.ClientLogin(ctx.Request().Context(), req.ID, req.Secret, ctx.RealIP())
+	}
+	if err != nil {
+		if loginThrottled(ctx, err) {
+			return echo.NewHTTPError(http.StatusTooManyRequests, domain.ErrLoginThrottled.Error())
+		}
+		if errors.Is(err, domain.ErrClientLoginFail) || errors.Is(err, domain.ErrInvalidClientAssertion) {
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		} else {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	scope, err := usecase.NarrowScope(client.Scope, req.Scope)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidScope) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
-	scope := strings.Join(Map(client.Scope, func(p domain.Permission) string {
-		return string(p)
-	}), " ")
-
-	// Create token with claims
-	now := time.Now()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, domain.JwtClaims{
-		Name:  "",
-		Scope: scope,
-		Type:  domain.ClientType,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(c.config.SecretExpiration) * time.Second)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    c.config.Issuer,
-			Subject:   client.ID.String(),
-		},
-	})
+	claims := newClientClaims(c.config, client, scope, []string{c.config.Audience})
+	tokenType, err := bindDPoP(ctx, c.dpop, c.config, client, &claims)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidDPoPProof) || errors.Is(err, domain.ErrDPoPProofReplayed) || errors.Is(err, domain.ErrDPoPRequired) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	bindCertificate(ctx, &claims)
 
 	// Generate encoded token
-	tokenString, err := token.SignedString([]byte(c.config.SecretKey))
+	tokenString, err := c.issuer.Issue(ctx.Request().Context(), &domain.ClaimRequest{Client: client, GrantType: GrantTypeClientCredentials}, claims)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	return ctx.JSON(http.StatusOK, UserLoginResponse{AccessToken: tokenString})
+	return ctx.JSON(http.StatusOK, ClientLoginReponse{AccessToken: tokenString, TokenType: tokenType, Scope: claims.Scope})
 }
 
 type UpdateClientRequest struct {
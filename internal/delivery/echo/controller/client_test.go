@@ -0,0 +1,178 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/controller"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// bindValidator accepts every request, the tests here are not about input validation.
+type bindValidator struct{}
+
+func (bindValidator) Validate(interface{}) error { return nil }
+
+func TestCreateClientPrivilegedScope(t *testing.T) {
+	mockRepo := new(clientRepo.MockClientRepository)
+	mockRepo.On("CreateClient", mock.Anything, mock.AnythingOfType("*domain.Client")).
+		Return(func(_ context.Context, client *domain.Client) *domain.Client { return client }, nil).Maybe()
+	clientUsecase := usecase.NewClientUseCase(mockRepo, testHasher, testThrottle())
+	issuer := usecase.NewTokenIssuer(testKeys, nil, testAudience, nil)
+	e := echo.New()
+	e.Validator = bindValidator{}
+	controller.NewClientController(clientUsecase, nil, nil, issuer, testAuthenticator(), &configs.AppConfig{}).RegisterRoutes(e)
+
+	tests := []struct {
+		name   string
+		scope  []domain.Permission
+		body   string
+		status int
+	}{
+		{"user grants an ordinary permission", []domain.Permission{domain.PermAll}, `{"scope":["resource:create"]}`, http.StatusCreated},
+		{"user cannot grant introspection", []domain.Permission{domain.PermAll}, `{"scope":["token:introspect"]}`, http.StatusForbidden},
+		{"user cannot grant token exchange", []domain.Permission{domain.PermAll}, `{"scope":["resource:create","token:exchange"]}`, http.StatusForbidden},
+		{"administrator grants token exchange", []domain.Permission{domain.PermAll, domain.PermAdmin}, `{"scope":["token:exchange"]}`, http.StatusCreated},
+		{"administrator grants introspection", []domain.Permission{domain.PermAll, domain.PermAdmin}, `{"scope":["token:introspect"]}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveJSON(e, http.MethodPost, "/clients", signToken(t, userClaims(tt.scope...)), tt.body)
+
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestCreateClientFirstPartyOnly(t *testing.T) {
+	mockRepo := new(clientRepo.MockClientRepository)
+	clientUsecase := usecase.NewClientUseCase(mockRepo, testHasher, testThrottle())
+	issuer := usecase.NewTokenIssuer(testKeys, nil, testAudience, nil)
+	e := echo.New()
+	e.Validator = bindValidator{}
+	controller.NewClientController(clientUsecase, nil, nil, issuer, testAuthenticator(), &configs.AppConfig{}).RegisterRoutes(e)
+
+	t.Run("token delegated to a client", func(t *testing.T) {
+		claims := userClaims(domain.PermAll)
+		claims.AuthorizedParty = "6cc2b688-1246-4a62-a293-dae7e67d6097"
+
+		rec := serveJSON(e, http.MethodPost, "/clients", signToken(t, claims), `{"scope":["*"]}`)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("exchanged token with a narrowed scope", func(t *testing.T) {
+		claims := userClaims(domain.PermCreateResource)
+		claims.Act = &domain.Actor{Subject: "6cc2b688-1246-4a62-a293-dae7e67d6097"}
+
+		rec := serveJSON(e, http.MethodPost, "/clients", signToken(t, claims), `{"scope":["*"]}`)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("token of the device grant", func(t *testing.T) {
+		server := newOAuthServer()
+		device := newTestClient(t, server.clients, domain.PermAll)
+		user := &domain.User{ID: uuid.New(), Name: "Alice"}
+		authTime := time.Now()
+		approved := &domain.DeviceAuthorization{
+			ClientID:  device.ID,
+			Scope:     []domain.Permission{domain.PermAll},
+			Status:    domain.DeviceApproved,
+			UserID:    &user.ID,
+			AuthTime:  &authTime,
+			Interval:  5,
+			ExpiresAt: time.Now().Add(time.Minute),
+		}
+		server.devices.On("GetDeviceAuthorizationByDeviceCode", mock.Anything, mock.Anything).Return(approved, nil)
+		server.devices.On("RecordDevicePoll", mock.Anything, mock.Anything, 5).Return(nil)
+		server.devices.On("ConsumeDeviceAuthorization", mock.Anything, mock.Anything).Return(approved, nil)
+		server.users.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+		server.sessions.On("CreateSession", mock.Anything, mock.AnythingOfType("*domain.Session")).
+			Return(func(_ context.Context, session *domain.Session) *domain.Session { return session }, nil)
+
+		rec := postForm(server.e, "/oauth/token", device, url.Values{
+			"grant_type":  {controller.GrantTypeDeviceCode},
+			"device_code": {"device-code"},
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var token controller.TokenResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &token))
+
+		rec = serveJSON(e, http.MethodPost, "/clients", token.AccessToken, `{"scope":["*"]}`)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("token of a client", func(t *testing.T) {
+		claims := userClaims(domain.PermAll)
+		claims.Type = domain.ClientType
+
+		rec := serveJSON(e, http.MethodPost, "/clients", signToken(t, claims), `{"scope":["*"]}`)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	mockRepo.AssertNotCalled(t, "CreateClient", mock.Anything, mock.Anything)
+}
+
+func TestClientLoginScope(t *testing.T) {
+	mockRepo := new(clientRepo.MockClientRepository)
+	issuer := usecase.NewTokenIssuer(testKeys, nil, testAudience, nil)
+	e := echo.New()
+	e.Validator = bindValidator{}
+	config := &configs.AppConfig{Audience: testAudience, SecretExpiration: 60}
+	controller.NewClientController(usecase.NewClientUseCase(mockRepo, testHasher, testThrottle()), nil, nil, issuer, testAuthenticator(), config).RegisterRoutes(e)
+	wildcard := newTestClient(t, mockRepo, domain.PermAll)
+	narrow := newTestClient(t, mockRepo, domain.PermCreateResource)
+
+	tests := []struct {
+		name   string
+		client *domain.Client
+		scope  string
+		status int
+		want   string
+	}{
+		{"wildcard client narrows to a permission", wildcard, `["resource:create"]`, http.StatusOK, "resource:create"},
+		{"empty scope is the registered scope", narrow, `[]`, http.StatusOK, "resource:create"},
+		{"no scope is the registered scope", wildcard, `null`, http.StatusOK, "*"},
+		{"scope cannot be widened", narrow, `["*"]`, http.StatusBadRequest, ""},
+		{"wildcard does not cover a privileged permission", wildcard, `["token:introspect"]`, http.StatusBadRequest, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body := `{"id":"` + tc.client.ID.String() + `","secret":"` + testClientSecret + `","scope":` + tc.scope + `}`
+
+			rec := serveJSON(e, http.MethodPost, "/clients/login", "", body)
+
+			require.Equal(t, tc.status, rec.Code, rec.Body.String())
+			if tc.status != http.StatusOK {
+				assert.Contains(t, rec.Body.String(), domain.ErrInvalidScope.Error())
+				assert.NotContains(t, rec.Body.String(), "access_token")
+				return
+			}
+			var res controller.ClientLoginReponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.Equal(t, tc.want, res.Scope)
+
+			// the token carries the same scope as the response
+			claims := new(domain.JwtClaims)
+			_, err := jwt.ParseWithClaims(res.AccessToken, claims, testKeys.Keyfunc)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, claims.Scope)
+		})
+	}
+}
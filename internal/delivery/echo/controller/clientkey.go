@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// tokenEndpoint is the aud a client assertion must carry, at /oauth/token and /clients/login alike.
+func tokenEndpoint(config *configs.AppConfig) string {
+	return config.BaseURL + "/oauth/token"
+}
+
+// assertionLogin authenticates a private_key_jwt client of a /clients/login request.
+func (c *ClientController) assertionLogin(ctx echo.Context, req *ClientLoinRequest) (*domain.Client, error) {
+	switch {
+	case req.ClientAssertionType != domain.ClientAssertionType:
+		return nil, fmt.Errorf("%w: clientAssertionType must be %s", domain.ErrInvalidClientAssertion, domain.ClientAssertionType)
+	case req.ClientAssertion == "" || req.Secret != "":
+		return nil, fmt.Errorf("%w: a clientAssertion and no secret are required", domain.ErrInvalidClientAssertion)
+	}
+	client, err := c.keyCase.ClientAssertionLogin(ctx.Request().Context(), req.ClientAssertion, tokenEndpoint(c.config))
+	if err != nil {
+		return nil, err
+	}
+	if req.ID != uuid.Nil && req.ID != client.ID {
+		return nil, fmt.Errorf("%w: id does not match the assertion", domain.ErrInvalidClientAssertion)
+	}
+	return client, nil
+}
+
+// @Summary List Client Keys
+// @Description Lists the public keys a private_key_jwt client signs its assertions with.
+// @Tags client
+// @Produce  json
+// @Param client-id path string true "Client ID"
+// @Success 200 {array} domain.ClientKey "Success"
+// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Not a user login token"
+// @Failure 404 {object} echo.HTTPError "Not Found"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /clients/{client-id}/keys [get]
+func (c *ClientController) ListClientKeys(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+	clientID, err := uuid.Parse(ctx.Param("client-id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	keys, err := c.keyCase.ListClientKeys(ctx.Request().Context(), clientID, userID)
+	if err != nil {
+		return clientKeyError(err)
+	}
+	return ctx.JSON(http.StatusOK, keys)
+}
+
+// @Summary Add Client Key
+// @Description Registers a public JWK (EC P-256, RSA or Ed25519) for a client, the kid defaults to the RFC 7638 thumbprint.
+// @Tags client
+// @Accept  json
+// @Produce  json
+// @Param client-id path string true "Client ID"
+// @Param request body domain.JWK true "Public JWK"
+// @Success 201 {object} domain.ClientKey "Created"
+// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Not a user login token"
+// @Failure 404 {object} echo.HTTPError "Not Found"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /clients/{client-id}/keys [post]
+func (c *ClientController) AddClientKey(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+	clientID, err := uuid.Parse(ctx.Param("client-id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	jwk := new(domain.JWK)
+	if err := ctx.Bind(jwk); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	key, err := c.keyCase.AddClientKey(ctx.Request().Context(), clientID, userID, *jwk)
+	if err != nil {
+		return clientKeyError(err)
+	}
+	return ctx.JSON(http.StatusCreated, key)
+}
+
+// @Summary Delete Client Key
+// @Description Removes a public key of a client, assertions signed with it are refused from then on.
+// @Tags client
+// @Param client-id path string true "Client ID"
+// @Param kid path string true "Key ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Not a user login token"
+// @Failure 404 {object} echo.HTTPError "Not Found"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /clients/{client-id}/keys/{kid} [delete]
+func (c *ClientController) DeleteClientKey(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+	clientID, err := uuid.Parse(ctx.Param("client-id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := c.keyCase.DeleteClientKey(ctx.Request().Context(), clientID, userID, ctx.Param("kid")); err != nil {
+		return clientKeyError(err)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func clientKeyError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrClientNotFound) || errors.Is(err, domain.ErrClientKeyNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrInvalidJWK) || errors.Is(err, domain.ErrUnsupportedKey) || errors.Is(err, domain.ErrClientKeyAlreadyExists):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// DeviceController is the verification endpoint of the device authorization grant. Like the consent
+// screen of the code flow it lives in the frontend: the logged-in user looks the user code up with GET
+// and submits the decision with POST.
+type DeviceController struct {
+	usecase *usecase.DeviceAuthorizationUseCase
+	auth    *middleware.Authenticator
+	config  *configs.AppConfig
+}
+
+func NewDeviceController(usecase *usecase.DeviceAuthorizationUseCase, auth *middleware.Authenticator, config *configs.AppConfig) *DeviceController {
+	return &DeviceController{usecase: usecase, auth: auth, config: config}
+}
+
+func (d *DeviceController) RegisterRoutes(e *echo.Echo) {
+	api := e.Group("/oauth/device", d.auth.Middleware, middleware.RequireFirstPartyUser)
+	api.GET("", d.GetDeviceConsent)
+	api.POST("", d.DeviceConsent)
+}
+
+type DeviceConsentRequest struct {
+	UserCode string `json:"user_code" example:"WDJB-MJHT" validate:"required"`
+	Approve  bool   `json:"approve" example:"true"`
+	// ApprovedScope is the subset of the requested scope the user agreed to, empty approves all of it
+	ApprovedScope []domain.Permission `json:"approved_scope" example:"resource:create" validate:"omitempty,dive,required,perm"`
+}
+
+type DeviceConsentResponse struct {
+	UserCode string              `json:"user_code" example:"WDJB-MJHT"`
+	ClientID uuid.UUID           `json:"client_id" example:"6cc2b688-1246-4a62-a293-dae7e67d6097"`
+	Scope    []domain.Permission `json:"scope" example:"resource:create"`
+}
+
+// @Summary Device verification request
+// @Description Looks up a pending device authorization by the user code shown on the device and returns what the user is asked to approve.
+// @Tags oauth
+// @Produce  json
+// @Param user_code query string true "User code shown on the device, case and dashes do not matter"
+// @Success 200 {object} DeviceConsentResponse "Success"
+// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /oauth/device [get]
+func (d *DeviceController) GetDeviceConsent(ctx echo.Context) error {
+	userCode := ctx.QueryParam("user_code")
+	if userCode == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "missing user_code")
+	}
+
+	authorization, err := d.usecase.GetPendingDeviceAuthorization(ctx.Request().Context(), userCode)
+	if err != nil {
+		return deviceError(err)
+	}
+	return ctx.JSON(http.StatusOK, DeviceConsentResponse{
+		UserCode: usecase.FormatUserCode(authorization.UserCode),
+		ClientID: authorization.ClientID,
+		Scope:    authorization.Scope,
+	})
+}
+
+// @Summary Device verification consent
+// @Description Records the decision of the logged-in user on a device authorization. On approval the polling device receives a token for the approved subset of the requested scope.
+// @Tags oauth
+// @Accept  json
+// @Param request body DeviceConsentRequest true "Device Consent Request"
+// @Success 204 "No Content"
+// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /oauth/device [post]
+func (d *DeviceController) DeviceConsent(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+	req := new(DeviceConsentRequest)
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	var err error
+	if req.Approve {
+		err = d.usecase.ApproveDeviceAuthorization(ctx.Request().Context(), req.UserCode, userID, authTime(ctx), req.ApprovedScope)
+	} else {
+		err = d.usecase.DenyDeviceAuthorization(ctx.Request().Context(), req.UserCode, userID)
+	}
+	if err != nil {
+		return deviceError(err)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func deviceError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrUserCodeInvalid), errors.Is(err, domain.ErrInvalidScope):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	TokenTypeBearer = "Bearer"
+	TokenTypeDPoP   = "DPoP"
+)
+
+// bindDPoP applies RFC 9449 to a token about to be issued and returns its token_type. With a valid
+// DPoP proof the claims are bound to the proof key, without one a DPoP required client is refused.
+// client is nil for grants that do not authenticate a client.
+func bindDPoP(ctx echo.Context, dpop *usecase.DPoPUseCase, config *configs.AppConfig, client *domain.Client, claims *domain.JwtClaims) (string, error) {
+	jkt, err := dpopProofKey(ctx, dpop, config)
+	switch {
+	case err != nil:
+		return "", err
+	case jkt == "" && client != nil && client.DPoPRequired:
+		return "", domain.ErrDPoPRequired
+	case jkt == "":
+		return TokenTypeBearer, nil
+	}
+	claims.Cnf = &domain.Confirmation{JKT: jkt}
+	return TokenTypeDPoP, nil
+}
+
+// dpopProofKey verifies the DPoP proof of a token request and returns the thumbprint of its key, empty
+// without a proof. The proof is only verified once per request, a second check would see it replayed.
+func dpopProofKey(ctx echo.Context, dpop *usecase.DPoPUseCase, config *configs.AppConfig) (string, error) {
+	if jkt, ok := ctx.Get("dpopJKT").(string); ok {
+		return jkt, nil
+	}
+	proofs := ctx.Request().Header.Values("DPoP")
+	switch {
+	case len(proofs) == 0:
+		return "", nil
+	case len(proofs) > 1:
+		return "", fmt.Errorf("%w: more than one DPoP header", domain.ErrInvalidDPoPProof)
+	}
+
+	jkt, err := dpop.VerifyProof(proofs[0], ctx.Request().Method, config.BaseURL+ctx.Request().URL.Path, "")
+	if err != nil {
+		return "", err
+	}
+	ctx.Set("dpopJKT", jkt)
+	return jkt, nil
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+type KeyController struct {
+	keys    *usecase.KeySet
+	keyRing *usecase.KeyRingUseCase
+	auth    *middleware.Authenticator
+	config  *configs.AppConfig
+}
+
+func NewKeyController(keys *usecase.KeySet, keyRing *usecase.KeyRingUseCase, auth *middleware.Authenticator, config *configs.AppConfig) *KeyController {
+	return &KeyController{keys: keys, keyRing: keyRing, auth: auth, config: config}
+}
+
+func (k *KeyController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/.well-known/jwks.json", k.JWKS)
+
+	api := e.Group("/admin/keys", k.auth.AdminMiddleware)
+	api.GET("", k.ListKeys)
+	api.POST("/rotate", k.RotateKey)
+}
+
+// @Summary JSON Web Key Set
+// @Description Publishes the public keys that verify our tokens, including keys that are about to sign. The legacy HS256 secret is never published.
+// @Tags oauth
+// @Produce  json
+// @Success 200 {object} domain.JWKS "Success"
+// @Router /.well-known/jwks.json [get]
+func (k *KeyController) JWKS(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, k.keys.JWKS())
+}
+
+type KeyResponse struct {
+	domain.SigningKey
+	State domain.KeyState `json:"state" example:"active"`
+}
+
+func newKeyResponse(key domain.SigningKey, now time.Time) KeyResponse {
+	return KeyResponse{SigningKey: key, State: key.StateAt(now)}
+}
+
+// @Summary List Signing Keys
+// @Description Lists the rotated signing keys with their state, newest first.
+// @Tags admin
+// @Produce  json
+// @Success 200 {array} KeyResponse "Success"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /admin/keys [get]
+func (k *KeyController) ListKeys(ctx echo.Context) error {
+	keys, err := k.keyRing.ListKeys(ctx.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	now := time.Now()
+	resp := make([]KeyResponse, len(keys))
+	for i, key := range keys {
+		resp[i] = newKeyResponse(key, now)
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// @Summary Rotate Signing Key
+// @Description Generates a new signing key. It is published right away and starts signing after KEY_ACTIVATION_DELAY, when the current keys retire.
+// @Tags admin
+// @Produce  json
+// @Success 201 {object} KeyResponse "Created"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /admin/keys/rotate [post]
+func (k *KeyController) RotateKey(ctx echo.Context) error {
+	key, err := k.keyRing.Rotate(ctx.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.JSON(http.StatusCreated, newKeyResponse(*key, time.Now()))
+}
@@ -0,0 +1,47 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/controller"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	signingKeyRepo "github.com/bright-pentium/go-client-practice/internal/repository/signingkey"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestKeyAdminRoutes(t *testing.T) {
+	mockRepo := new(signingKeyRepo.MockSigningKeyRepository)
+	mockRepo.On("ListSigningKeys", mock.Anything).Return([]domain.SigningKey{}, nil)
+	keyRing := usecase.NewKeyRingUseCase(mockRepo, testKeys, nil, "ES256", time.Minute, time.Minute)
+	e := echo.New()
+	controller.NewKeyController(testKeys, keyRing, testAuthenticator(), &configs.AppConfig{}).RegisterRoutes(e)
+
+	t.Run("unauthenticated request is rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(e, http.MethodGet, "/admin/keys", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(e, http.MethodPost, "/admin/keys/rotate", "").Code)
+		mockRepo.AssertNotCalled(t, "CreateSigningKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("user without the admin permission is rejected", func(t *testing.T) {
+		token := signToken(t, userClaims(domain.PermAll))
+
+		assert.Equal(t, http.StatusForbidden, serve(e, http.MethodGet, "/admin/keys", token).Code)
+		assert.Equal(t, http.StatusForbidden, serve(e, http.MethodPost, "/admin/keys/rotate", token).Code)
+	})
+
+	t.Run("admin lists the keys", func(t *testing.T) {
+		token := signToken(t, userClaims(domain.PermAll, domain.PermAdmin))
+
+		assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/admin/keys", token).Code)
+	})
+
+	t.Run("the JWKS stays public", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/.well-known/jwks.json", "").Code)
+	})
+}
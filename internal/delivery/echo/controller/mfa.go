@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// MFAController lets users enroll an authenticator app as their second factor. Like the sessions, it is
+// only open to login tokens and cookie sessions of the user.
+type MFAController struct {
+	usecase     *usecase.MFAUseCase
+	userUsecase *usecase.UserUseCase
+	auth        *middleware.Authenticator
+	config      *configs.AppConfig
+}
+
+func NewMFAController(usecase *usecase.MFAUseCase, userUsecase *usecase.UserUseCase, auth *middleware.Authenticator, config *configs.AppConfig) *MFAController {
+	return &MFAController{usecase: usecase, userUsecase: userUsecase, auth: auth, config: config}
+}
+
+func (m *MFAController) RegisterRoutes(e *echo.Echo) {
+	api := e.Group("/me/mfa", m.auth.SessionMiddleware, middleware.RequireFirstPartyUser)
+	api.POST("/totp", m.EnrollTOTP)
+	api.POST("/totp/confirm", m.ConfirmTOTP)
+}
+
+type EnrollTOTPResponse struct {
+	// Secret is the base32 key to type into an authenticator app that cannot scan the URI
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/ClientApp:alice?algorithm=SHA1&digits=6&issuer=ClientApp&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+// @Summary Enroll TOTP
+// @Description Generates a new TOTP secret, to be shown as a QR code of its otpauth URI. The factor is
+// @Description only required at login once confirmed, enrolling again replaces an unconfirmed secret.
+// @Tags user
+// @Produce  json
+// @Success 201 {object} EnrollTOTPResponse "Created"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 409 {object} echo.HTTPError "Already enrolled"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /me/mfa/totp [post]
+func (m *MFAController) EnrollTOTP(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+
+	user, err := m.userUsecase.GetUserByID(ctx.Request().Context(), userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	secret, uri, err := m.usecase.EnrollTOTP(ctx.Request().Context(), user)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFAAlreadyEnrolled) {
+			return echo.NewHTTPError(http.StatusConflict, domain.ErrMFAAlreadyEnrolled.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.JSON(http.StatusCreated, EnrollTOTPResponse{Secret: secret, OTPAuthURI: uri})
+}
+
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" example:"123456" validate:"required"`
+}
+
+type ConfirmTOTPResponse struct {
+	// RecoveryCodes each sign in once in place of a TOTP code, they are never shown again
+	RecoveryCodes []string `json:"recovery_codes" example:"abcd-efgh-ijkl-mnop"`
+}
+
+// @Summary Confirm TOTP
+// @Description Turns the enrolled factor on with a first code of the authenticator app and returns the recovery codes.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body ConfirmTOTPRequest true "Confirm TOTP Request"
+// @Success 200 {object} ConfirmTOTPResponse "Success"
+// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 404 {object} echo.HTTPError "Not enrolled"
+// @Failure 409 {object} echo.HTTPError "Already confirmed"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /me/mfa/totp/confirm [post]
+func (m *MFAController) ConfirmTOTP(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+	req := new(ConfirmTOTPRequest)
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	codes, err := m.usecase.ConfirmTOTP(ctx.Request().Context(), userID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMFACodeInvalid):
+			return echo.NewHTTPError(http.StatusBadRequest, domain.ErrMFACodeInvalid.Error())
+		case errors.Is(err, domain.ErrMFANotEnrolled):
+			return echo.NewHTTPError(http.StatusNotFound, domain.ErrMFANotEnrolled.Error())
+		case errors.Is(err, domain.ErrMFAAlreadyEnrolled):
+			return echo.NewHTTPError(http.StatusConflict, domain.ErrMFAAlreadyEnrolled.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	return ctx.JSON(http.StatusOK, ConfirmTOTPResponse{RecoveryCodes: codes})
+}
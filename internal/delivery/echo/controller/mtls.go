@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"crypto/x509"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// peerCertificates returns the TLS client certificate chain of the request, leaf first, nil without one.
+func peerCertificates(ctx echo.Context) []*x509.Certificate {
+	if state := ctx.Request().TLS; state != nil {
+		return state.PeerCertificates
+	}
+	return nil
+}
+
+// bindCertificate binds the claims to the TLS client certificate of the request if any (RFC 8705 section 3),
+// whichever way the client authenticated.
+func bindCertificate(ctx echo.Context, claims *domain.JwtClaims) {
+	chain := peerCertificates(ctx)
+	if len(chain) == 0 {
+		return
+	}
+	if claims.Cnf == nil {
+		claims.Cnf = &domain.Confirmation{}
+	}
+	claims.Cnf.X5tS256 = domain.CertificateThumbprint(chain[0])
+}
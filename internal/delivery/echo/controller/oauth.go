@@ -0,0 +1,699 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+	GrantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+// Token type hints of RFC 7009 section 2.1.
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+// Error codes defined by RFC 6749 section 5.2.
+const (
+	OAuthInvalidRequest       = "invalid_request"
+	OAuthInvalidClient        = "invalid_client"
+	OAuthInvalidGrant         = "invalid_grant"
+	OAuthUnauthorizedClient   = "unauthorized_client"
+	OAuthUnsupportedGrantType = "unsupported_grant_type"
+	OAuthInvalidScope         = "invalid_scope"
+	OAuthServerError          = "server_error"
+	OAuthInvalidTarget        = "invalid_target"     // RFC 8707 section 2
+	OAuthInvalidDPoPProof     = "invalid_dpop_proof" // RFC 9449 section 5
+
+	// Authorization endpoint only, RFC 6749 section 4.1.2.1.
+	OAuthAccessDenied            = "access_denied"
+	OAuthUnsupportedResponseType = "unsupported_response_type"
+
+	// Device authorization grant only, RFC 8628 section 3.5.
+	OAuthAuthorizationPending = "authorization_pending"
+	OAuthSlowDown             = "slow_down"
+	OAuthExpiredToken         = "expired_token"
+)
+
+type OAuthController struct {
+	clientUsecase        *usecase.ClientUseCase
+	clientKeyUsecase     *usecase.ClientKeyUseCase
+	refreshUsecase       *usecase.RefreshTokenUseCase
+	sessionUsecase       *usecase.SessionUseCase
+	verifier             *usecase.TokenVerifier
+	introspectionUsecase *usecase.IntrospectionUseCase
+	authorizationUsecase *usecase.AuthorizationUseCase
+	exchangeUsecase      *usecase.TokenExchangeUseCase
+	deviceUsecase        *usecase.DeviceAuthorizationUseCase
+	dpopUsecase          *usecase.DPoPUseCase
+	issuer               *usecase.TokenIssuer
+	keys                 *usecase.KeySet
+	config               *configs.AppConfig
+}
+
+func NewOAuthController(
+	clientUsecase *usecase.ClientUseCase,
+	clientKeyUsecase *usecase.ClientKeyUseCase,
+	refreshUsecase *usecase.RefreshTokenUseCase,
+	sessionUsecase *usecase.SessionUseCase,
+	verifier *usecase.TokenVerifier,
+	introspectionUsecase *usecase.IntrospectionUseCase,
+	authorizationUsecase *usecase.AuthorizationUseCase,
+	exchangeUsecase *usecase.TokenExchangeUseCase,
+	deviceUsecase *usecase.DeviceAuthorizationUseCase,
+	dpopUsecase *usecase.DPoPUseCase,
+	issuer *usecase.TokenIssuer,
+	keys *usecase.KeySet,
+	config *configs.AppConfig,
+) *OAuthController {
+	return &OAuthController{
+		clientUsecase:        clientUsecase,
+		clientKeyUsecase:     clientKeyUsecase,
+		refreshUsecase:       refreshUsecase,
+		sessionUsecase:       sessionUsecase,
+		verifier:             verifier,
+		introspectionUsecase: introspectionUsecase,
+		authorizationUsecase: authorizationUsecase,
+		exchangeUsecase:      exchangeUsecase,
+		deviceUsecase:        deviceUsecase,
+		dpopUsecase:          dpopUsecase,
+		issuer:               issuer,
+		keys:                 keys,
+		config:               config,
+	}
+}
+
+func (o *OAuthController) RegisterRoutes(e *echo.Echo) {
+	e.POST("/oauth/token", o.Token)
+	e.POST("/oauth/revoke", o.Revoke)
+	e.POST("/oauth/introspect", o.Introspect)
+	e.POST("/oauth/device_authorization", o.DeviceAuthorization)
+}
+
+// OAuthError is the error response body of RFC 6749 section 5.2.
+type OAuthError struct {
+	Status      int    `json:"-"`
+	Code        string `json:"error" example:"invalid_client"`
+	Description string `json:"error_description,omitempty" example:"client authentication failed"`
+	// RedirectTo is set by the authorization endpoint once the redirect uri is trusted,
+	// the user agent relays the error to the client by navigating there.
+	RedirectTo string `json:"redirect_to,omitempty"`
+
+	// basic is set when the client tried HTTP Basic authentication,
+	// in which case a 401 must carry a WWW-Authenticate challenge.
+	basic bool
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func newOAuthError(status int, code string, description string) *OAuthError {
+	return &OAuthError{Status: status, Code: code, Description: description}
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type" example:"DPoP"`
+	ExpiresIn    int    `json:"expires_in" example:"900"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty" example:"resource:create"`
+	IDToken      string `json:"id_token,omitempty"`
+	// IssuedTokenType is only set by the token exchange grant, RFC 8693 section 2.2.1.
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+}
+
+// @Summary OAuth2 token endpoint
+// @Description Issues an access token per RFC 6749. Clients authenticate with HTTP Basic (client_secret_basic) or with client_id and client_secret in the body (client_secret_post).
+// @Description private_key_jwt clients send a client_assertion signed with a registered key, its aud is this endpoint (RFC 7523); TLS clients send only client_id over mutual TLS (RFC 8705).
+// @Description The refresh_token grant rotates a user refresh token and needs no client authentication.
+// @Description The authorization_code grant redeems a code from /oauth/authorize with its PKCE code_verifier; the token acts for the user with the client as azp.
+// @Description An ID token is added when the openid scope was granted.
+// @Description Client tokens carry the requested resources as aud, each must be this server or registered for the client.
+// @Description The device_code grant is polled by a device with the device_code from /oauth/device_authorization until the user decides (RFC 8628); it answers authorization_pending, slow_down, expired_token or access_denied meanwhile.
+// @Description With a DPoP header (RFC 9449) the access token is bound to the proof key and token_type is DPoP; clients flagged dpopRequired must send one.
+// @Description The token exchange grant (RFC 8693) trades a subject_token for one with a narrower scope and an act claim naming the actor: the subject of actor_token, or the calling client. The client needs the token:exchange permission by name, "*" does not cover it. A DPoP-bound or certificate-bound subject or actor token is only exchanged with a DPoP proof of its key or over its TLS client certificate, and the issued token stays bound to it.
+// @Tags oauth
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param DPoP header string false "DPoP proof JWT"
+// @Param grant_type formData string true "Grant type" Enums(client_credentials, refresh_token, authorization_code, urn:ietf:params:oauth:grant-type:token-exchange, urn:ietf:params:oauth:grant-type:device_code)
+// @Param scope formData string false "Space-delimited requested scope"
+// @Param refresh_token formData string false "Refresh token for the refresh_token grant"
+// @Param code formData string false "Authorization code for the authorization_code grant"
+// @Param redirect_uri formData string false "Redirect URI the code was issued to"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param device_code formData string false "Device code for the device_code grant"
+// @Param subject_token formData string false "Access token to exchange"
+// @Param subject_token_type formData string false "Type of subject_token" Enums(urn:ietf:params:oauth:token-type:access_token, urn:ietf:params:oauth:token-type:jwt)
+// @Param actor_token formData string false "Access token of the party acting for the subject"
+// @Param actor_token_type formData string false "Type of actor_token" Enums(urn:ietf:params:oauth:token-type:access_token, urn:ietf:params:oauth:token-type:jwt)
+// @Param requested_token_type formData string false "Only access tokens can be issued" Enums(urn:ietf:params:oauth:token-type:access_token)
+// @Param resource formData []string false "Resource indicators (RFC 8707) the token is for, defaults to this server" collectionFormat(multi)
+// @Param audience formData []string false "Logical audiences the token is for, same rules as resource" collectionFormat(multi)
+// @Param client_id formData string false "Client ID for client_secret_post"
+// @Param client_secret formData string false "Client secret for client_secret_post"
+// @Param client_assertion_type formData string false "Client assertion type for private_key_jwt" Enums(urn:ietf:params:oauth:client-assertion-type:jwt-bearer)
+// @Param client_assertion formData string false "Client assertion JWT for private_key_jwt"
+// @Success 200 {object} TokenResponse "Success"
+// @Failure 400 {object} OAuthError "Bad Request"
+// @Failure 401 {object} OAuthError "Unauthorized"
+// @Failure 429 {object} OAuthError "Too many failed logins of the client or from the address, see Retry-After"
+// @Failure 500 {object} OAuthError "Internal Server Error"
+// @Security BasicAuth
+// @Router /oauth/token [post]
+func (o *OAuthController) Token(ctx echo.Context) error {
+	if !strings.HasPrefix(ctx.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationForm) {
+		return writeOAuthError(ctx, newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "content type must be "+echo.MIMEApplicationForm))
+	}
+
+	var err error
+	switch grantType := ctx.FormValue("grant_type"); grantType {
+	case "":
+		err = newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "missing grant_type")
+	case GrantTypeClientCredentials:
+		err = o.clientCredentials(ctx)
+	case GrantTypeRefreshToken:
+		err = o.refreshToken(ctx)
+	case GrantTypeAuthorizationCode:
+		err = o.authorizationCode(ctx)
+	case GrantTypeTokenExchange:
+		err = o.tokenExchange(ctx)
+	case GrantTypeDeviceCode:
+		err = o.deviceCode(ctx)
+	default:
+		err = newOAuthError(http.StatusBadRequest, OAuthUnsupportedGrantType, "unsupported grant_type: "+grantType)
+	}
+	if err != nil {
+		return writeOAuthError(ctx, err)
+	}
+	return nil
+}
+
+func (o *OAuthController) clientCredentials(ctx echo.Context) error {
+	client, err := o.authenticateClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	scope, err := usecase.NarrowScope(client.Scope, domain.ParseScope(ctx.FormValue("scope")))
+	if err != nil {
+		return newOAuthError(http.StatusBadRequest, OAuthInvalidScope, err.Error())
+	}
+
+	audience, err := o.audience(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	return o.issueToken(ctx, nil, client, newClientClaims(o.config, client, scope, audience), TokenResponse{Scope: domain.FormatScope(scope)})
+}
+
+func (o *OAuthController) refreshToken(ctx echo.Context) error {
+	refreshToken := ctx.FormValue("refresh_token")
+	if refreshToken == "" {
+		return newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "missing refresh_token")
+	}
+
+	user, stored, nextRefreshToken, err := o.refreshUsecase.RotateRefreshToken(ctx.Request().Context(), refreshToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenInvalid) || errors.Is(err, domain.ErrRefreshTokenReused) {
+			return newOAuthError(http.StatusBadRequest, OAuthInvalidGrant, err.Error())
+		}
+		return err
+	}
+
+	// the token family is the session the user signed in with
+	session, err := o.sessionUsecase.ExtendSession(ctx.Request().Context(), stored.FamilyID, stored.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	claims := newUserClaims(o.config, user, stored.AuthTime)
+	if session != nil {
+		claims.SessionID = session.ID.String()
+		claims.AMR = session.AMR
+	}
+	return o.issueToken(ctx, user, nil, claims, TokenResponse{RefreshToken: nextRefreshToken, Scope: claims.Scope})
+}
+
+func (o *OAuthController) authorizationCode(ctx echo.Context) error {
+	client, err := o.authenticateClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	code, redirectURI, codeVerifier := ctx.FormValue("code"), ctx.FormValue("redirect_uri"), ctx.FormValue("code_verifier")
+	if code == "" || redirectURI == "" || codeVerifier == "" {
+		return newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "code, redirect_uri and code_verifier are required")
+	}
+
+	user, grant, err := o.authorizationUsecase.ExchangeAuthorizationCode(ctx.Request().Context(), client.ID, code, redirectURI, codeVerifier)
+	if err != nil {
+		if errors.Is(err, domain.ErrAuthorizationCodeInvalid) {
+			return newOAuthError(http.StatusBadRequest, OAuthInvalidGrant, err.Error())
+		}
+		return err
+	}
+
+	// No refresh token: rotation re-issues full user tokens, which a third-party client must never get.
+	audience, err := o.audience(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	resp := TokenResponse{Scope: domain.FormatScope(grant.Scope)}
+	if hasScope(grant.Scope, domain.PermOpenID) {
+		if resp.IDToken, err = o.keys.Sign(newIDTokenClaims(o.config, user, client, grant)); err != nil {
+			return err
+		}
+	}
+	claims := newDelegatedClaims(o.config, user, client, grant.Scope, audience)
+	if _, err := startSession(ctx, o.sessionUsecase, user, client, claims.ExpiresAt.Time, &claims); err != nil {
+		return err
+	}
+	return o.issueToken(ctx, user, client, claims, resp)
+}
+
+func (o *OAuthController) tokenExchange(ctx echo.Context) error {
+	client, err := o.authenticateClient(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := usecase.NarrowScope(client.Scope, []domain.Permission{domain.PermExchange}); err != nil {
+		return newOAuthError(http.StatusBadRequest, OAuthUnauthorizedClient, "missing required permission: "+string(domain.PermExchange))
+	}
+
+	req := &domain.TokenExchangeRequest{
+		SubjectToken:     ctx.FormValue("subject_token"),
+		SubjectTokenType: ctx.FormValue("subject_token_type"),
+		ActorToken:       ctx.FormValue("actor_token"),
+		ActorTokenType:   ctx.FormValue("actor_token_type"),
+		Scope:            domain.ParseScope(ctx.FormValue("scope")),
+	}
+	if req.SubjectToken == "" || req.SubjectTokenType == "" {
+		return newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "subject_token and subject_token_type are required")
+	}
+	if requested := ctx.FormValue("requested_token_type"); requested != "" && requested != domain.TokenTypeAccessToken {
+		return newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "unsupported requested_token_type: "+requested)
+	}
+	// the DPoP proof and the client certificate are taken before the exchange, so bound tokens are
+	// only exchanged by their holder
+	if req.DPoPKeyThumbprint, err = dpopProofKey(ctx, o.dpopUsecase, o.config); err != nil {
+		if errors.Is(err, domain.ErrInvalidDPoPProof) || errors.Is(err, domain.ErrDPoPProofReplayed) {
+			return newOAuthError(http.StatusBadRequest, OAuthInvalidDPoPProof, err.Error())
+		}
+		return err
+	}
+	if chain := peerCertificates(ctx); len(chain) > 0 {
+		req.CertificateThumbprint = domain.CertificateThumbprint(chain[0])
+	}
+
+	exchange, err := o.exchangeUsecase.Exchange(ctx.Request().Context(), client, req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidExchangeToken) {
+			return newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, err.Error())
+		}
+		if errors.Is(err, domain.ErrInvalidScope) {
+			return newOAuthError(http.StatusBadRequest, OAuthInvalidScope, err.Error())
+		}
+		return err
+	}
+
+	audience, err := o.audience(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	claims := newExchangedClaims(o.config, exchange, audience)
+	return o.issueToken(ctx, exchange.User, client, claims, TokenResponse{
+		ExpiresIn:       int(time.Until(claims.ExpiresAt.Time).Round(time.Second).Seconds()),
+		Scope:           claims.Scope,
+		IssuedTokenType: domain.TokenTypeAccessToken,
+	})
+}
+
+func (o *OAuthController) deviceCode(ctx echo.Context) error {
+	client, err := o.authenticateClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	deviceCode := ctx.FormValue("device_code")
+	if deviceCode == "" {
+		return newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "missing device_code")
+	}
+
+	user, grant, err := o.deviceUsecase.PollDeviceAuthorization(ctx.Request().Context(), client.ID, deviceCode)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrAuthorizationPending):
+			return newOAuthError(http.StatusBadRequest, OAuthAuthorizationPending, err.Error())
+		case errors.Is(err, domain.ErrSlowDown):
+			return newOAuthError(http.StatusBadRequest, OAuthSlowDown, err.Error())
+		case errors.Is(err, domain.ErrDeviceCodeExpired):
+			return newOAuthError(http.StatusBadRequest, OAuthExpiredToken, err.Error())
+		case errors.Is(err, domain.ErrDeviceAccessDenied):
+			return newOAuthError(http.StatusBadRequest, OAuthAccessDenied, err.Error())
+		case errors.Is(err, domain.ErrDeviceCodeInvalid):
+			return newOAuthError(http.StatusBadRequest, OAuthInvalidGrant, err.Error())
+		}
+		return err
+	}
+
+	// No refresh token, for the same reason as the authorization_code grant.
+	audience, err := o.audience(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	claims := newDelegatedClaims(o.config, user, client, grant.Scope, audience)
+	if _, err := startSession(ctx, o.sessionUsecase, user, client, claims.ExpiresAt.Time, &claims); err != nil {
+		return err
+	}
+	return o.issueToken(ctx, user, client, claims, TokenResponse{Scope: domain.FormatScope(grant.Scope)})
+}
+
+// audience resolves the resource (RFC 8707) and audience (RFC 8693) parameters, both may be repeated.
+func (o *OAuthController) audience(ctx echo.Context, client *domain.Client) ([]string, error) {
+	params, err := ctx.FormParams()
+	if err != nil {
+		return nil, newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, err.Error())
+	}
+	requested := append(params["resource"], params["audience"]...)
+
+	audience, err := usecase.ResolveAudience(client, requested, o.config.Audience)
+	if err != nil {
+		return nil, newOAuthError(http.StatusBadRequest, OAuthInvalidTarget, err.Error())
+	}
+	return audience, nil
+}
+
+// DeviceAuthorizationResponse is the response of RFC 8628 section 3.2.
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code" example:"GmRhmhcxhwAzkoEqiMEg_DnyEysNkuNhszIySk9eS"`
+	UserCode                string `json:"user_code" example:"WDJB-MJHT"`
+	VerificationURI         string `json:"verification_uri" example:"http://localhost:8000/oauth/device"`
+	VerificationURIComplete string `json:"verification_uri_complete" example:"http://localhost:8000/oauth/device?user_code=WDJB-MJHT"`
+	ExpiresIn               int    `json:"expires_in" example:"600"`
+	Interval                int    `json:"interval" example:"5"`
+}
+
+// @Summary OAuth2 device authorization endpoint
+// @Description Starts the device authorization grant of RFC 8628 for input-constrained clients such as CLI tools.
+// @Description The user enters user_code at verification_uri while the device polls /oauth/token with the device_code grant, no faster than interval seconds.
+// @Tags oauth
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param scope formData string false "Space-delimited requested scope"
+// @Param client_id formData string false "Client ID for client_secret_post"
+// @Param client_secret formData string false "Client secret for client_secret_post"
+// @Param client_assertion_type formData string false "Client assertion type for private_key_jwt" Enums(urn:ietf:params:oauth:client-assertion-type:jwt-bearer)
+// @Param client_assertion formData string false "Client assertion JWT for private_key_jwt"
+// @Success 200 {object} DeviceAuthorizationResponse "Success"
+// @Failure 400 {object} OAuthError "Bad Request"
+// @Failure 401 {object} OAuthError "Unauthorized"
+// @Failure 500 {object} OAuthError "Internal Server Error"
+// @Security BasicAuth
+// @Router /oauth/device_authorization [post]
+func (o *OAuthController) DeviceAuthorization(ctx echo.Context) error {
+	if !strings.HasPrefix(ctx.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationForm) {
+		return writeOAuthError(ctx, newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "content type must be "+echo.MIMEApplicationForm))
+	}
+	resp, err := o.deviceAuthorization(ctx)
+	if err != nil {
+		return writeOAuthError(ctx, err)
+	}
+	noStore(ctx)
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+func (o *OAuthController) deviceAuthorization(ctx echo.Context) (*DeviceAuthorizationResponse, error) {
+	client, err := o.authenticateClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	authorization, deviceCode, err := o.deviceUsecase.RequestDeviceAuthorization(ctx.Request().Context(), client, domain.ParseScope(ctx.FormValue("scope")))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidScope) {
+			return nil, newOAuthError(http.StatusBadRequest, OAuthInvalidScope, err.Error())
+		}
+		return nil, err
+	}
+
+	userCode := usecase.FormatUserCode(authorization.UserCode)
+	verificationURI := o.config.BaseURL + "/oauth/device"
+	return &DeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?" + url.Values{"user_code": {userCode}}.Encode(),
+		ExpiresIn:               int(time.Until(authorization.ExpiresAt).Round(time.Second).Seconds()),
+		Interval:                authorization.Interval,
+	}, nil
+}
+
+// @Summary OAuth2 token revocation endpoint
+// @Description Revokes an access or refresh token per RFC 7009. Revoking a refresh token revokes its whole family.
+// @Description Client authentication is optional; an authenticated client may only revoke its own access tokens and the user tokens issued to it (azp).
+// @Description Invalid, expired and unknown tokens are answered with 200 as the RFC requires.
+// @Tags oauth
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param token formData string true "Token to revoke"
+// @Param token_type_hint formData string false "Type of the token" Enums(access_token, refresh_token)
+// @Param client_id formData string false "Client ID for client_secret_post"
+// @Param client_secret formData string false "Client secret for client_secret_post"
+// @Param client_assertion_type formData string false "Client assertion type for private_key_jwt" Enums(urn:ietf:params:oauth:client-assertion-type:jwt-bearer)
+// @Param client_assertion formData string false "Client assertion JWT for private_key_jwt"
+// @Success 200 "Success"
+// @Failure 400 {object} OAuthError "Bad Request"
+// @Failure 401 {object} OAuthError "Unauthorized"
+// @Failure 500 {object} OAuthError "Internal Server Error"
+// @Security BasicAuth
+// @Router /oauth/revoke [post]
+func (o *OAuthController) Revoke(ctx echo.Context) error {
+	if !strings.HasPrefix(ctx.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationForm) {
+		return writeOAuthError(ctx, newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "content type must be "+echo.MIMEApplicationForm))
+	}
+	if err := o.revoke(ctx); err != nil {
+		return writeOAuthError(ctx, err)
+	}
+	noStore(ctx)
+	return ctx.NoContent(http.StatusOK)
+}
+
+func (o *OAuthController) revoke(ctx echo.Context) error {
+	token := ctx.FormValue("token")
+	if token == "" {
+		return newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "missing token")
+	}
+
+	var client *domain.Client
+	if _, _, basic := ctx.Request().BasicAuth(); basic || ctx.FormValue("client_id") != "" {
+		var err error
+		if client, err = o.authenticateClient(ctx); err != nil {
+			return err
+		}
+	}
+
+	// The hint only decides which lookup goes first: anything that does not resolve
+	// to a live access token, a JWT or an opaque one, is tried as a refresh token.
+	if ctx.FormValue("token_type_hint") != TokenTypeHintRefreshToken {
+		claims, err := o.verifier.Verify(ctx.Request().Context(), token)
+		if err == nil {
+			// RFC 7009 section 2.1: a client revokes its own tokens and the user tokens issued to it
+			if client != nil && claims.Subject != client.ID.String() && claims.AuthorizedParty != client.ID.String() {
+				return newOAuthError(http.StatusBadRequest, OAuthUnauthorizedClient, "token was not issued to this client")
+			}
+			return o.verifier.Revoke(ctx.Request().Context(), token, claims)
+		}
+		if !errors.Is(err, domain.ErrInvalidAccessToken) && !errors.Is(err, domain.ErrTokenRevoked) {
+			return err
+		}
+	}
+	return o.refreshUsecase.RevokeRefreshToken(ctx.Request().Context(), token)
+}
+
+// @Summary OAuth2 token introspection endpoint
+// @Description Reports whether an access token is active per RFC 7662, taking expiry, revocation and deletion of its user or client into account.
+// @Description The caller authenticates as a client holding the token:introspect permission by name, "*" does not cover it.
+// @Tags oauth
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param token formData string true "Token to introspect"
+// @Param token_type_hint formData string false "Type of the token" Enums(access_token, refresh_token)
+// @Param client_id formData string false "Client ID for client_secret_post"
+// @Param client_secret formData string false "Client secret for client_secret_post"
+// @Param client_assertion_type formData string false "Client assertion type for private_key_jwt" Enums(urn:ietf:params:oauth:client-assertion-type:jwt-bearer)
+// @Param client_assertion formData string false "Client assertion JWT for private_key_jwt"
+// @Success 200 {object} domain.Introspection "Success"
+// @Failure 400 {object} OAuthError "Bad Request"
+// @Failure 401 {object} OAuthError "Unauthorized"
+// @Failure 403 {object} OAuthError "Forbidden"
+// @Failure 500 {object} OAuthError "Internal Server Error"
+// @Security BasicAuth
+// @Router /oauth/introspect [post]
+func (o *OAuthController) Introspect(ctx echo.Context) error {
+	if !strings.HasPrefix(ctx.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationForm) {
+		return writeOAuthError(ctx, newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "content type must be "+echo.MIMEApplicationForm))
+	}
+	result, err := o.introspect(ctx)
+	if err != nil {
+		return writeOAuthError(ctx, err)
+	}
+	noStore(ctx)
+	return ctx.JSON(http.StatusOK, result)
+}
+
+func (o *OAuthController) introspect(ctx echo.Context) (*domain.Introspection, error) {
+	client, err := o.authenticateClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := usecase.NarrowScope(client.Scope, []domain.Permission{domain.PermIntrospect}); err != nil {
+		return nil, newOAuthError(http.StatusForbidden, OAuthUnauthorizedClient, "missing required permission: "+string(domain.PermIntrospect))
+	}
+
+	token := ctx.FormValue("token")
+	if token == "" {
+		return nil, newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "missing token")
+	}
+	// Refresh tokens are never meant for resource servers, they simply come back inactive.
+	return o.introspectionUsecase.Introspect(ctx.Request().Context(), token)
+}
+
+// authenticateClient resolves the client from HTTP Basic credentials (client_secret_basic), from the
+// request body (client_secret_post), from a signed assertion (private_key_jwt) or from the TLS client
+// certificate when the body only has a client_id. Using more than one method is rejected.
+func (o *OAuthController) authenticateClient(ctx echo.Context) (*domain.Client, error) {
+	rawID, secret, basic := ctx.Request().BasicAuth()
+	if ctx.FormValue("client_assertion_type") != "" || ctx.FormValue("client_assertion") != "" {
+		if basic || ctx.FormValue("client_secret") != "" {
+			return nil, newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "multiple client authentication methods")
+		}
+		return o.authenticateAssertion(ctx)
+	}
+	if basic {
+		if ctx.FormValue("client_secret") != "" {
+			return nil, newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "multiple client authentication methods")
+		}
+		// RFC 6749 section 2.3.1: credentials are form-encoded before being placed in the header
+		var idErr, secretErr error
+		rawID, idErr = url.QueryUnescape(rawID)
+		secret, secretErr = url.QueryUnescape(secret)
+		if idErr != nil || secretErr != nil {
+			return nil, &OAuthError{Status: http.StatusUnauthorized, Code: OAuthInvalidClient, Description: "malformed basic credentials", basic: true}
+		}
+	} else {
+		rawID, secret = ctx.FormValue("client_id"), ctx.FormValue("client_secret")
+		if rawID == "" || (secret == "" && len(peerCertificates(ctx)) == 0) {
+			return nil, newOAuthError(http.StatusUnauthorized, OAuthInvalidClient, "client authentication required")
+		}
+	}
+
+	clientID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, &OAuthError{Status: http.StatusUnauthorized, Code: OAuthInvalidClient, Description: "client authentication failed", basic: basic}
+	}
+
+	var client *domain.Client
+	if !basic && secret == "" {
+		// RFC 8705 section 2: only the client_id is sent, the TLS client certificate authenticates
+		client, err = o.clientUsecase.TLSClientLogin(ctx.Request().Context(), clientID, peerCertificates(ctx))
+	} else {
+		client, err = o.clientUsecase.ClientLogin(ctx.Request().Context(), clientID, secret, ctx.RealIP())
+	}
+	if err != nil {
+		if loginThrottled(ctx, err) {
+			return nil, newOAuthError(http.StatusTooManyRequests, OAuthInvalidClient, domain.ErrLoginThrottled.Error())
+		}
+		if errors.Is(err, domain.ErrClientLoginFail) {
+			return nil, &OAuthError{Status: http.StatusUnauthorized, Code: OAuthInvalidClient, Description: "client authentication failed", basic: basic}
+		}
+		return nil, err
+	}
+	return client, nil
+}
+
+// authenticateAssertion authenticates a private_key_jwt client (RFC 7523 section 2.2), a client_id
+// next to the assertion must name the same client.
+func (o *OAuthController) authenticateAssertion(ctx echo.Context) (*domain.Client, error) {
+	if ctx.FormValue("client_assertion_type") != domain.ClientAssertionType || ctx.FormValue("client_assertion") == "" {
+		return nil, newOAuthError(http.StatusBadRequest, OAuthInvalidRequest, "client_assertion_type must be "+domain.ClientAssertionType+" with a client_assertion")
+	}
+
+	client, err := o.clientKeyUsecase.ClientAssertionLogin(ctx.Request().Context(), ctx.FormValue("client_assertion"), tokenEndpoint(o.config))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidClientAssertion) {
+			return nil, newOAuthError(http.StatusUnauthorized, OAuthInvalidClient, err.Error())
+		}
+		return nil, err
+	}
+	if rawID := ctx.FormValue("client_id"); rawID != "" && rawID != client.ID.String() {
+		return nil, newOAuthError(http.StatusUnauthorized, OAuthInvalidClient, "client_id does not match the assertion")
+	}
+	return client, nil
+}
+
+// issueToken binds the claims to the DPoP key and the TLS client certificate of the request if any,
+// has the issuer enrich and sign them and writes the response. user is nil for client tokens,
+// client is nil for grants that do not authenticate a client.
+func (o *OAuthController) issueToken(ctx echo.Context, user *domain.User, client *domain.Client, claims domain.JwtClaims, resp TokenResponse) error {
+	tokenType, err := bindDPoP(ctx, o.dpopUsecase, o.config, client, &claims)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidDPoPProof) || errors.Is(err, domain.ErrDPoPProofReplayed) || errors.Is(err, domain.ErrDPoPRequired) {
+			return newOAuthError(http.StatusBadRequest, OAuthInvalidDPoPProof, err.Error())
+		}
+		return err
+	}
+	bindCertificate(ctx, &claims)
+	req := &domain.ClaimRequest{User: user, Client: client, GrantType: ctx.FormValue("grant_type")}
+	if resp.AccessToken, err = o.issuer.Issue(ctx.Request().Context(), req, claims); err != nil {
+		return err
+	}
+	resp.TokenType = tokenType
+	return o.writeToken(ctx, resp)
+}
+
+func (o *OAuthController) writeToken(ctx echo.Context, resp TokenResponse) error {
+	if resp.ExpiresIn == 0 {
+		resp.ExpiresIn = o.config.SecretExpiration
+	}
+	noStore(ctx)
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+func writeOAuthError(ctx echo.Context, err error) error {
+	var oauthErr *OAuthError
+	if !errors.As(err, &oauthErr) {
+		oauthErr = newOAuthError(http.StatusInternalServerError, OAuthServerError, err.Error())
+	}
+	if oauthErr.basic && oauthErr.Status == http.StatusUnauthorized {
+		ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+	noStore(ctx)
+	return ctx.JSON(oauthErr.Status, oauthErr)
+}
+
+// noStore marks a response as uncacheable, as required for token responses (RFC 6749 section 5.1).
+func noStore(ctx echo.Context) {
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+	ctx.Response().Header().Set("Pragma", "no-cache")
+}
@@ -0,0 +1,344 @@
+package controller_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/controller"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	deviceRepo "github.com/bright-pentium/go-client-practice/internal/repository/device"
+	attemptRepo "github.com/bright-pentium/go-client-practice/internal/repository/loginattempt"
+	revocationRepo "github.com/bright-pentium/go-client-practice/internal/repository/revocation"
+	sessionRepo "github.com/bright-pentium/go-client-practice/internal/repository/session"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testClientSecret = "supersecret"
+
+var testHasher = usecase.NewPepperedHasher(usecase.NewArgon2idHasher(usecase.Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1}), map[int]string{0: "pepper"})
+
+// testThrottle never locks out.
+func testThrottle() *usecase.LoginThrottleUseCase {
+	mockRepo := new(attemptRepo.MockLoginAttemptRepository)
+	mockRepo.On("ListLoginAttempts", mock.Anything, mock.Anything).Return([]domain.LoginAttempt{}, nil).Maybe()
+	mockRepo.On("RecordLoginFailure", mock.Anything, mock.Anything, mock.Anything).Return(&domain.LoginAttempt{Failures: 1}, nil).Maybe()
+	mockRepo.On("ResetLoginAttempts", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return usecase.NewLoginThrottleUseCase(mockRepo, domain.LoginThrottlePolicy{MaxFailures: 3, MaxFailuresPerIP: 10, Lockout: time.Second, MaxLockout: time.Minute, FailureWindow: time.Minute})
+}
+
+// newTestClient registers a client authenticating with testClientSecret.
+func newTestClient(t *testing.T, mockRepo *clientRepo.MockClientRepository, scope ...domain.Permission) *domain.Client {
+	hash, pepperVersion, err := testHasher.Hash(testClientSecret)
+	require.NoError(t, err)
+	client := &domain.Client{ID: uuid.New(), Scope: scope, SecretHash: hash, PepperVersion: pepperVersion}
+	mockRepo.On("GetClientByID", mock.Anything, client.ID).Return(client, nil).Maybe()
+	return client
+}
+
+// postForm posts the form to e, authenticated as the client with client_secret_basic.
+func postForm(e *echo.Echo, target string, client *domain.Client, form url.Values, header ...string) *httptest.ResponseRecorder {
+	return postFormTLS(e, target, client, form, nil, header...)
+}
+
+// postFormTLS is postForm over mutual TLS with the client certificate, if any.
+func postFormTLS(e *echo.Echo, target string, client *domain.Client, form url.Values, cert *x509.Certificate, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	if cert != nil {
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	}
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	req.SetBasicAuth(client.ID.String(), testClientSecret)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// dpopProof signs a DPoP proof for a POST to the path of this server with the key.
+func dpopProof(t *testing.T, key *ecdsa.PrivateKey, path string) string {
+	jwk, err := domain.NewJWK(&key.PublicKey)
+	require.NoError(t, err)
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, &domain.DPoPProof{
+		ID:         uuid.NewString(),
+		HTTPMethod: http.MethodPost,
+		HTTPURI:    testAudience + path,
+		IssuedAt:   jwt.NewNumericDate(time.Now()),
+	})
+	token.Header["typ"] = domain.DPoPProofType
+	token.Header["jwk"] = jwk
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+// newTestCertificate is a self-signed TLS client certificate.
+func newTestCertificate(t *testing.T) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "gateway"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+// oauthServer serves the OAuth endpoints over mocked clients, users, revocations, device authorizations and sessions.
+type oauthServer struct {
+	e           *echo.Echo
+	clients     *clientRepo.MockClientRepository
+	users       *userRepo.MockUserRepository
+	revocations *revocationRepo.MockRevocationRepository
+	devices     *deviceRepo.MockDeviceAuthorizationRepository
+	sessions    *sessionRepo.MockSessionRepository
+}
+
+func newOAuthServer() *oauthServer {
+	server := &oauthServer{
+		e:        echo.New(),
+		clients:  new(clientRepo.MockClientRepository),
+		users:    new(userRepo.MockUserRepository),
+		devices:  new(deviceRepo.MockDeviceAuthorizationRepository),
+		sessions: new(sessionRepo.MockSessionRepository),
+	}
+	verifier, mockRevocations := testVerifier()
+	server.revocations = mockRevocations
+	config := &configs.AppConfig{Audience: testAudience, BaseURL: testAudience, SecretExpiration: 60}
+	controller.NewOAuthController(
+		usecase.NewClientUseCase(server.clients, testHasher, testThrottle()),
+		nil, nil,
+		usecase.NewSessionUseCase(server.sessions, nil, time.Minute),
+		verifier,
+		usecase.NewIntrospectionUseCase(verifier, server.users, server.clients),
+		nil,
+		usecase.NewTokenExchangeUseCase(verifier, server.users, server.clients),
+		usecase.NewDeviceAuthorizationUseCase(server.devices, server.users, time.Minute, 5),
+		usecase.NewDPoPUseCase(time.Minute),
+		usecase.NewTokenIssuer(testKeys, nil, testAudience, nil),
+		testKeys,
+		config,
+	).RegisterRoutes(server.e)
+	return server
+}
+
+func TestRevoke(t *testing.T) {
+	server := newOAuthServer()
+	e, mockRevocations := server.e, server.revocations
+	mockRevocations.On("RevokeToken", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	client := newTestClient(t, server.clients)
+	other := newTestClient(t, server.clients)
+
+	t.Run("client revokes its own token", func(t *testing.T) {
+		claims := userClaims(domain.PermCreateResource)
+		claims.Type = domain.ClientType
+		claims.Subject = client.ID.String()
+
+		rec := postForm(e, "/oauth/revoke", client, url.Values{"token": {signToken(t, claims)}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRevocations.AssertCalled(t, "RevokeToken", mock.Anything, claims.ID, mock.Anything)
+	})
+
+	t.Run("client revokes a user token issued to it", func(t *testing.T) {
+		claims := userClaims(domain.PermCreateResource)
+		claims.AuthorizedParty = client.ID.String()
+
+		rec := postForm(e, "/oauth/revoke", client, url.Values{"token": {signToken(t, claims)}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockRevocations.AssertCalled(t, "RevokeToken", mock.Anything, claims.ID, mock.Anything)
+	})
+
+	t.Run("token issued to another client is not revoked", func(t *testing.T) {
+		claims := userClaims(domain.PermCreateResource)
+		claims.AuthorizedParty = other.ID.String()
+
+		rec := postForm(e, "/oauth/revoke", client, url.Values{"token": {signToken(t, claims)}})
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "unauthorized_client")
+		mockRevocations.AssertNotCalled(t, "RevokeToken", mock.Anything, claims.ID, mock.Anything)
+	})
+}
+
+func TestIntrospectPermission(t *testing.T) {
+	server := newOAuthServer()
+	e, mockClientRepo := server.e, server.clients
+	subject := newTestClient(t, mockClientRepo, domain.PermCreateResource)
+	claims := userClaims(domain.PermCreateResource)
+	claims.Type = domain.ClientType
+	claims.Subject = subject.ID.String()
+	token := signToken(t, claims)
+
+	t.Run("client holding token:introspect", func(t *testing.T) {
+		client := newTestClient(t, mockClientRepo, domain.PermIntrospect)
+
+		rec := postForm(e, "/oauth/introspect", client, url.Values{"token": {token}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"active":true`)
+	})
+
+	t.Run("wildcard does not cover token:introspect", func(t *testing.T) {
+		client := newTestClient(t, mockClientRepo, domain.PermAll)
+
+		rec := postForm(e, "/oauth/introspect", client, url.Values{"token": {token}})
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "unauthorized_client")
+	})
+}
+
+func TestTokenExchangePermission(t *testing.T) {
+	server := newOAuthServer()
+	subject := newTestClient(t, server.clients, domain.PermCreateResource)
+	claims := userClaims(domain.PermCreateResource)
+	claims.Type = domain.ClientType
+	claims.Subject = subject.ID.String()
+	form := url.Values{
+		"grant_type":         {controller.GrantTypeTokenExchange},
+		"subject_token":      {signToken(t, claims)},
+		"subject_token_type": {domain.TokenTypeAccessToken},
+	}
+
+	t.Run("client holding token:exchange", func(t *testing.T) {
+		client := newTestClient(t, server.clients, domain.PermExchange)
+
+		rec := postForm(server.e, "/oauth/token", client, form)
+
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"issued_token_type":"`+domain.TokenTypeAccessToken+`"`)
+	})
+
+	t.Run("wildcard does not cover token:exchange", func(t *testing.T) {
+		client := newTestClient(t, server.clients, domain.PermAll)
+
+		rec := postForm(server.e, "/oauth/token", client, form)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "unauthorized_client")
+	})
+}
+
+func TestTokenExchangeDPoPBound(t *testing.T) {
+	server := newOAuthServer()
+	client := newTestClient(t, server.clients, domain.PermExchange)
+	subject := newTestClient(t, server.clients, domain.PermCreateResource)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwk, err := domain.NewJWK(&key.PublicKey)
+	require.NoError(t, err)
+	jkt, err := jwk.Thumbprint()
+	require.NoError(t, err)
+
+	claims := userClaims(domain.PermCreateResource)
+	claims.Type = domain.ClientType
+	claims.Subject = subject.ID.String()
+	claims.Cnf = &domain.Confirmation{JKT: jkt}
+	form := url.Values{
+		"grant_type":         {controller.GrantTypeTokenExchange},
+		"subject_token":      {signToken(t, claims)},
+		"subject_token_type": {domain.TokenTypeAccessToken},
+	}
+
+	t.Run("holder of the key gets a token bound to it", func(t *testing.T) {
+		rec := postForm(server.e, "/oauth/token", client, form, "DPoP", dpopProof(t, key, "/oauth/token"))
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp controller.TokenResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, controller.TokenTypeDPoP, resp.TokenType)
+		issued, err := testKeys.Parse(resp.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, &domain.Confirmation{JKT: jkt}, issued.Cnf)
+	})
+
+	t.Run("without a proof of the key", func(t *testing.T) {
+		rec := postForm(server.e, "/oauth/token", client, form)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_request")
+	})
+
+	t.Run("with a proof of another key", func(t *testing.T) {
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		rec := postForm(server.e, "/oauth/token", client, form, "DPoP", dpopProof(t, other, "/oauth/token"))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_request")
+	})
+}
+
+func TestTokenExchangeCertificateBound(t *testing.T) {
+	server := newOAuthServer()
+	client := newTestClient(t, server.clients, domain.PermExchange)
+	subject := newTestClient(t, server.clients, domain.PermCreateResource)
+	cert := newTestCertificate(t)
+	thumbprint := domain.CertificateThumbprint(cert)
+
+	claims := userClaims(domain.PermCreateResource)
+	claims.Type = domain.ClientType
+	claims.Subject = subject.ID.String()
+	claims.Cnf = &domain.Confirmation{X5tS256: thumbprint}
+	form := url.Values{
+		"grant_type":         {controller.GrantTypeTokenExchange},
+		"subject_token":      {signToken(t, claims)},
+		"subject_token_type": {domain.TokenTypeAccessToken},
+	}
+
+	t.Run("over the certificate the token is bound to", func(t *testing.T) {
+		rec := postFormTLS(server.e, "/oauth/token", client, form, cert)
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp controller.TokenResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		issued, err := testKeys.Parse(resp.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, &domain.Confirmation{X5tS256: thumbprint}, issued.Cnf)
+	})
+
+	t.Run("without a client certificate", func(t *testing.T) {
+		rec := postForm(server.e, "/oauth/token", client, form)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_request")
+	})
+
+	t.Run("over another client certificate", func(t *testing.T) {
+		rec := postFormTLS(server.e, "/oauth/token", client, form, newTestCertificate(t))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_request")
+	})
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// OIDCController serves the OpenID Connect provider metadata and the userinfo endpoint.
+type OIDCController struct {
+	usecase *usecase.UserUseCase
+	keys    *usecase.KeySet
+	auth    *middleware.Authenticator
+	config  *configs.AppConfig
+}
+
+func NewOIDCController(usecase *usecase.UserUseCase, keys *usecase.KeySet, auth *middleware.Authenticator, config *configs.AppConfig) *OIDCController {
+	return &OIDCController{usecase: usecase, keys: keys, auth: auth, config: config}
+}
+
+func (o *OIDCController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/.well-known/openid-configuration", o.Discovery)
+
+	userinfo := middleware.RequirePermission(domain.PermOpenID)
+	e.GET("/userinfo", o.UserInfo, o.auth.Middleware, userinfo)
+	e.POST("/userinfo", o.UserInfo, o.auth.Middleware, userinfo)
+}
+
+// OpenIDConfiguration is the provider metadata of OpenID Connect Discovery 1.0 section 3.
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer" example:"ClientApp"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint" example:"http://localhost:8000/oauth/authorize"`
+	TokenEndpoint                     string   `json:"token_endpoint" example:"http://localhost:8000/oauth/token"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint" example:"http://localhost:8000/userinfo"`
+	JwksURI                           string   `json:"jwks_uri" example:"http://localhost:8000/.well-known/jwks.json"`
+	RevocationEndpoint                string   `json:"revocation_endpoint" example:"http://localhost:8000/oauth/revoke"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint" example:"http://localhost:8000/oauth/introspect"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint" example:"http://localhost:8000/oauth/device_authorization"`
+	ScopesSupported                   []string `json:"scopes_supported" example:"openid,profile"`
+	ResponseTypesSupported            []string `json:"response_types_supported" example:"code"`
+	GrantTypesSupported               []string `json:"grant_types_supported" example:"authorization_code"`
+	SubjectTypesSupported             []string `json:"subject_types_supported" example:"public"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported" example:"ES256"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported" example:"client_secret_basic"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported" example:"S256"`
+	DPoPSigningAlgValuesSupported     []string `json:"dpop_signing_alg_values_supported" example:"ES256"`
+	ClaimsSupported                   []string `json:"claims_supported" example:"sub,name"`
+
+	// TLSClientCertificateBoundAccessTokens tells that tokens issued over mutual TLS are bound to the certificate (RFC 8705)
+	TLSClientCertificateBoundAccessTokens bool `json:"tls_client_certificate_bound_access_tokens" example:"true"`
+	// TokenEndpointAuthSigningAlgValuesSupported are the algorithms accepted for private_key_jwt assertions
+	TokenEndpointAuthSigningAlgValuesSupported []string `json:"token_endpoint_auth_signing_alg_values_supported" example:"ES256"`
+}
+
+// @Summary OpenID Connect discovery
+// @Description Publishes the OpenID Connect provider metadata.
+// @Tags oidc
+// @Produce  json
+// @Success 200 {object} OpenIDConfiguration "Success"
+// @Router /.well-known/openid-configuration [get]
+func (o *OIDCController) Discovery(ctx echo.Context) error {
+	baseURL := o.config.BaseURL
+
+	scopes := make([]string, 0, len(domain.ValidPermissions))
+	for perm := range domain.ValidPermissions {
+		// privileged permissions are not for clients to ask for
+		if _, privileged := domain.PrivilegedPermissions[perm]; perm != domain.PermAll && !privileged {
+			scopes = append(scopes, string(perm))
+		}
+	}
+	sort.Strings(scopes)
+
+	// only published keys can verify an ID token, the legacy HS256 secret cannot
+	algorithms := []string{}
+	seen := map[string]bool{}
+	for _, key := range o.keys.JWKS().Keys {
+		if !seen[key.Alg] {
+			seen[key.Alg] = true
+			algorithms = append(algorithms, key.Alg)
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, OpenIDConfiguration{
+		Issuer:                            o.config.Issuer,
+		AuthorizationEndpoint:             baseURL + "/oauth/authorize",
+		TokenEndpoint:                     baseURL + "/oauth/token",
+		UserinfoEndpoint:                  baseURL + "/userinfo",
+		JwksURI:                           baseURL + "/.well-known/jwks.json",
+		RevocationEndpoint:                baseURL + "/oauth/revoke",
+		IntrospectionEndpoint:             baseURL + "/oauth/introspect",
+		DeviceAuthorizationEndpoint:       baseURL + "/oauth/device_authorization",
+		ScopesSupported:                   scopes,
+		ResponseTypesSupported:            []string{usecase.ResponseTypeCode},
+		GrantTypesSupported:               []string{GrantTypeAuthorizationCode, GrantTypeClientCredentials, GrantTypeRefreshToken, GrantTypeTokenExchange, GrantTypeDeviceCode},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  algorithms,
+		TokenEndpointAuthMethodsSupported: []string{domain.AuthMethodClientSecretBasic, "client_secret_post", domain.AuthMethodTLSClientAuth, domain.AuthMethodSelfSignedTLSClientAuth, domain.AuthMethodPrivateKeyJWT},
+		CodeChallengeMethodsSupported:     []string{domain.CodeChallengeS256},
+		DPoPSigningAlgValuesSupported:     usecase.DPoPAlgorithms,
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "azp", "name", "preferred_username"},
+
+		TLSClientCertificateBoundAccessTokens:      true,
+		TokenEndpointAuthSigningAlgValuesSupported: usecase.ClientAssertionAlgorithms,
+	})
+}
+
+// UserInfoResponse is the userinfo response of OpenID Connect Core 1.0 section 5.3.2.
+type UserInfoResponse struct {
+	Sub               string `json:"sub" example:"11111111-2222-4444-3333-555555555555"`
+	Name              string `json:"name,omitempty" example:"John Doe"`
+	PreferredUsername string `json:"preferred_username,omitempty" example:"johndoe123"`
+}
+
+// @Summary OpenID Connect userinfo
+// @Description Returns claims about the user the access token was issued for. Requires the openid scope; name and preferred_username need the profile scope.
+// @Tags oidc
+// @Produce  json
+// @Success 200 {object} UserInfoResponse "Success"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /userinfo [get]
+// @Router /userinfo [post]
+func (o *OIDCController) UserInfo(ctx echo.Context) error {
+	claims, _ := ctx.Get("claims").(*domain.JwtClaims)
+	if claims.Type != domain.UserType {
+		return echo.NewHTTPError(http.StatusForbidden, "the token does not represent a user")
+	}
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+
+	user, err := o.usecase.GetUserByID(ctx.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	resp := UserInfoResponse{Sub: user.ID.String()}
+	for _, perm := range domain.ParseScope(claims.Scope) {
+		if perm.Grants(domain.PermProfile) {
+			resp.Name = user.Name
+			resp.PreferredUsername = user.Account
+			break
+		}
+	}
+	return ctx.JSON(http.StatusOK, resp)
+}
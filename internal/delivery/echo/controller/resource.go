@@ -7,30 +7,21 @@ import (
 	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
 	"github.com/bright-pentium/go-client-practice/internal/domain"
 	"github.com/bright-pentium/go-client-practice/internal/usecase"
-	"github.com/golang-jwt/jwt/v4"
-	echojwt "github.com/labstack/echo-jwt"
 	"github.com/labstack/echo/v4"
 )
 
 type ResourceControler struct {
 	usecase *usecase.ResourceUseCase
+	auth    *middleware.Authenticator
 	config  *configs.AppConfig
 }
 
-func NewResourceControler(usecase *usecase.ResourceUseCase, config *configs.AppConfig) *ResourceControler {
-	return &ResourceControler{usecase: usecase, config: config}
+func NewResourceControler(usecase *usecase.ResourceUseCase, auth *middleware.Authenticator, config *configs.AppConfig) *ResourceControler {
+	return &ResourceControler{usecase: usecase, auth: auth, config: config}
 }
 
 func (r *ResourceControler) RegisterRoutes(e *echo.Echo) {
-	api := e.Group("/resources", echojwt.WithConfig(echojwt.Config{
-		// ...
-		SigningKey: []byte(r.config.SecretKey),
-		NewClaimsFunc: func(c echo.Context) jwt.Claims {
-			return new(domain.JwtClaims)
-		},
-		// ...
-	}))
-	api.Use(middleware.JWTMiddleware)
+	api := e.Group("/resources", r.auth.SessionMiddleware)
 	api.Use(middleware.RequirePermission(domain.PermCreateResource))
 	api.POST("", r.CreateResource)
 }
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// SessionController lets users see where they are signed in and sign out remotely. Only login tokens
+// are admitted, a client acting for the user must not be able to sign them out.
+type SessionController struct {
+	usecase *usecase.SessionUseCase
+	auth    *middleware.Authenticator
+	config  *configs.AppConfig
+}
+
+func NewSessionController(usecase *usecase.SessionUseCase, auth *middleware.Authenticator, config *configs.AppConfig) *SessionController {
+	return &SessionController{usecase: usecase, auth: auth, config: config}
+}
+
+func (s *SessionController) RegisterRoutes(e *echo.Echo) {
+	api := e.Group("/me/sessions", s.auth.Middleware, middleware.RequireFirstPartyUser)
+	api.GET("", s.ListSessions)
+	api.DELETE("", s.RevokeAllSessions)
+	api.DELETE("/:session-id", s.RevokeSession)
+}
+
+type SessionResponse struct {
+	Sessions []domain.Session `json:"sessions"`
+}
+
+// @Summary List Sessions
+// @Description Lists the sessions the user is signed in with, the one of the calling token is flagged as current.
+// @Tags user
+// @Produce  json
+// @Success 200 {object} SessionResponse "Success"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /me/sessions [get]
+func (s *SessionController) ListSessions(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+	claims, _ := ctx.Get("claims").(*domain.JwtClaims)
+
+	sessions, err := s.usecase.ListSessions(ctx.Request().Context(), userID, claims.SessionID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, SessionResponse{Sessions: sessions})
+}
+
+// @Summary Revoke Session
+// @Description Signs the user out of one session, its tokens are refused from then on.
+// @Tags user
+// @Param session-id path string true "Session ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 404 {object} echo.HTTPError "Not Found"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /me/sessions/{session-id} [delete]
+func (s *SessionController) RevokeSession(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+	sessionID, err := uuid.Parse(ctx.Param("session-id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := s.usecase.RevokeSession(ctx.Request().Context(), userID, sessionID); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// @Summary Revoke All Sessions
+// @Description Signs the user out everywhere, the calling session included.
+// @Tags user
+// @Success 204 "No Content"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /me/sessions [delete]
+func (s *SessionController) RevokeAllSessions(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+
+	if err := s.usecase.RevokeAllSessions(ctx.Request().Context(), userID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// startSession records the session the claims are issued in and ties them to it with the sid claim.
+// client is nil for logins of the user, the session keeps the amr of the claims for the tokens refreshed in it.
+func startSession(ctx echo.Context, sessions *usecase.SessionUseCase, user *domain.User, client *domain.Client, expiresAt time.Time, claims *domain.JwtClaims) (*domain.Session, error) {
+	session := &domain.Session{UserID: user.ID, UserAgent: ctx.Request().UserAgent(), IPAddress: ctx.RealIP(), ExpiresAt: expiresAt, AMR: claims.AMR}
+	if client != nil {
+		session.ClientID = &client.ID
+	}
+	session, err := sessions.StartSession(ctx.Request().Context(), session)
+	if err != nil {
+		return nil, err
+	}
+	claims.SessionID = session.ID.String()
+	return session, nil
+}
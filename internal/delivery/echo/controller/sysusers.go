@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
 	"github.com/bright-pentium/go-client-practice/internal/domain"
 	"github.com/bright-pentium/go-client-practice/internal/usecase"
 	"github.com/google/uuid"
@@ -12,12 +13,14 @@ import (
 )
 
 type SysUserControler struct {
-	usecase *usecase.SysUserUseCase
-	config  *configs.AppConfig
+	usecase    *usecase.SysUserUseCase
+	mfaUsecase *usecase.MFAUseCase
+	auth       *middleware.Authenticator
+	config     *configs.AppConfig
 }
 
-func NewSysUserControler(usecase *usecase.SysUserUseCase, config *configs.AppConfig) *SysUserControler {
-	return &SysUserControler{usecase: usecase, config: config}
+func NewSysUserControler(usecase *usecase.SysUserUseCase, mfaUsecase *usecase.MFAUseCase, auth *middleware.Authenticator, config *configs.AppConfig) *SysUserControler {
+	return &SysUserControler{usecase: usecase, mfaUsecase: mfaUsecase, auth: auth, config: config}
 }
 
 func (u *SysUserControler) RegisterRoutes(e *echo.Echo) {
@@ -25,6 +28,8 @@ func (u *SysUserControler) RegisterRoutes(e *echo.Echo) {
 	api.GET("/:user-id", u.GetUserByID)
 	api.PATCH("/:user-id", u.UpdateUserByID)
 	api.DELETE("/:user-id", u.DeleteUserByID)
+	api.POST("/:user-id/unlock", u.UnlockUser, u.auth.AdminMiddleware)
+	api.DELETE("/:user-id/mfa", u.ResetMFA, u.auth.AdminMiddleware)
 	api.POST("", u.CreateUser)
 }
 
@@ -35,6 +40,24 @@ type CreateUserRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// PasswordPolicyErrorResponse lists every rule of the password policy the password breaks.
+type PasswordPolicyErrorResponse struct {
+	Message    string   `json:"message" example:"password does not meet the password policy"`
+	Violations []string `json:"violations" example:"must be at least 12 characters long,appears in known data breaches"`
+}
+
+// passwordPolicyError answers a password breaking the password policy with every rule it breaks.
+func passwordPolicyError(err error) (*echo.HTTPError, bool) {
+	var policyErr *domain.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return nil, false
+	}
+	return echo.NewHTTPError(http.StatusBadRequest, PasswordPolicyErrorResponse{
+		Message:    domain.ErrPasswordPolicy.Error(),
+		Violations: policyErr.Violations,
+	}), true
+}
+
 // @Summary Create a User
 // @Description Creates a new user.
 // @Tags admin
@@ -42,7 +65,7 @@ type CreateUserRequest struct {
 // @Produce  json
 // @Param request body CreateUserRequest true "User creation request"
 // @Success 200 {object} domain.User "Success"
-// @Success 400 {object} echo.HTTPError "Bad Requests"
+// @Success 400 {object} PasswordPolicyErrorResponse "Bad Requests, with every violated rule when the password breaks the password policy"
 // @Success 500 {object} echo.HTTPError "Internal Error"
 // @Router /admin/users [post]
 func (u *SysUserControler) CreateUser(ctx echo.Context) error {
@@ -55,6 +78,9 @@ func (u *SysUserControler) CreateUser(ctx echo.Context) error {
 	}
 	user, err := u.usecase.CreateUser(ctx.Request().Context(), req.Name, req.Account, req.Password)
 	if err != nil {
+		if httpErr, ok := passwordPolicyError(err); ok {
+			return httpErr
+		}
 		if errors.Is(err, domain.ErrUserAlreadyExists) || errors.Is(err, domain.ErrInvalidUserData) {
 			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 		} else {
@@ -104,7 +130,7 @@ type UpdateUserRequest struct {
 // @Param user-id path string true "User ID"
 // @Param request body UpdateUserRequest true "Update user request"
 // @Success 200 {object} domain.User "Success"
-// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 400 {object} PasswordPolicyErrorResponse "Bad Request, with every violated rule when the password breaks the password policy"
 // @Failure 404 {object} echo.HTTPError "Not Found"
 // @Failure 500 {object} echo.HTTPError "Internal Server Error"
 // @Router /admin/users/{user-id} [patch]
@@ -122,6 +148,9 @@ func (u *SysUserControler) UpdateUserByID(ctx echo.Context) error {
 	}
 	user, err := u.usecase.UpdateUserByID(ctx.Request().Context(), userID, req.Name, req.Password)
 	if err != nil {
+		if httpErr, ok := passwordPolicyError(err); ok {
+			return httpErr
+		}
 		if errors.Is(err, domain.ErrUserNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, err.Error())
 		} else {
@@ -131,6 +160,36 @@ func (u *SysUserControler) UpdateUserByID(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, user)
 }
 
+// @Summary Unlock User by ID
+// @Description Forgets the failed logins of a user, lifting a lockout of the account.
+// @Description A lockout of the address the failures came from stays in place.
+// @Tags admin
+// @Produce  json
+// @Param user-id path string true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 404 {object} echo.HTTPError "Not Found"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /admin/users/{user-id}/unlock [post]
+func (u *SysUserControler) UnlockUser(ctx echo.Context) error {
+	userID, err := uuid.Parse(ctx.Param("user-id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := u.usecase.UnlockUser(ctx.Request().Context(), userID); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		} else {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
 // @Summary Delete User by ID
 // @Description Delete a user by their ID.
 // @Tags admin
@@ -158,3 +217,31 @@ func (u *SysUserControler) DeleteUserByID(ctx echo.Context) error {
 	}
 	return ctx.NoContent(http.StatusNoContent)
 }
+
+// @Summary Reset MFA of User by ID
+// @Description Removes the TOTP factor, the recovery codes and the WebAuthn credentials of a user who lost them,
+// @Description the user signs in with the password only until enrolling again.
+// @Tags admin
+// @Param user-id path string true "User ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 404 {object} echo.HTTPError "Not Found"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /admin/users/{user-id}/mfa [delete]
+func (u *SysUserControler) ResetMFA(ctx echo.Context) error {
+	userID, err := uuid.Parse(ctx.Param("user-id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := u.mfaUsecase.ResetMFA(ctx.Request().Context(), userID); err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,79 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/controller"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	mfaRepo "github.com/bright-pentium/go-client-practice/internal/repository/mfa"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	webauthnRepo "github.com/bright-pentium/go-client-practice/internal/repository/webauthn"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUnlockUserRoute(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Account: "alice"}
+	mockUserRepo := new(userRepo.MockUserRepository)
+	mockUserRepo.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+	sysUsers := usecase.NewSysUserUseCase(mockUserRepo, nil, nil, testHasher, nil, testThrottle())
+	e := echo.New()
+	controller.NewSysUserControler(sysUsers, nil, testAuthenticator(), &configs.AppConfig{}).RegisterRoutes(e)
+	target := "/admin/users/" + user.ID.String() + "/unlock"
+
+	t.Run("unauthenticated request is rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(e, http.MethodPost, target, "").Code)
+		mockUserRepo.AssertNotCalled(t, "GetUserByID", mock.Anything, user.ID)
+	})
+
+	t.Run("user without the admin permission is rejected", func(t *testing.T) {
+		token := signToken(t, userClaims(domain.PermAll))
+
+		assert.Equal(t, http.StatusForbidden, serve(e, http.MethodPost, target, token).Code)
+		mockUserRepo.AssertNotCalled(t, "GetUserByID", mock.Anything, user.ID)
+	})
+
+	t.Run("admin unlocks the user", func(t *testing.T) {
+		token := signToken(t, userClaims(domain.PermAll, domain.PermAdmin))
+
+		assert.Equal(t, http.StatusNoContent, serve(e, http.MethodPost, target, token).Code)
+	})
+}
+
+func TestResetMFARoute(t *testing.T) {
+	userID := uuid.New()
+	mockMFARepo := new(mfaRepo.MockMFARepository)
+	mockMFARepo.On("DeleteMFA", mock.Anything, userID).Return(nil)
+	mockWebAuthnRepo := new(webauthnRepo.MockWebAuthnRepository)
+	mockWebAuthnRepo.On("DeleteCredentialsByUser", mock.Anything, userID).Return(int64(0), nil)
+	webauthn := usecase.NewWebAuthnUseCase(mockWebAuthnRepo, nil, testThrottle(), domain.WebAuthnRelyingParty{ID: "localhost"}, []string{testAudience}, time.Minute)
+	mfa := usecase.NewMFAUseCase(mockMFARepo, nil, testThrottle(), webauthn, "ClientApp", time.Minute)
+	e := echo.New()
+	controller.NewSysUserControler(nil, mfa, testAuthenticator(), &configs.AppConfig{}).RegisterRoutes(e)
+	target := "/admin/users/" + userID.String() + "/mfa"
+
+	t.Run("unauthenticated request is rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(e, http.MethodDelete, target, "").Code)
+		mockMFARepo.AssertNotCalled(t, "DeleteMFA", mock.Anything, userID)
+	})
+
+	t.Run("user without the admin permission is rejected", func(t *testing.T) {
+		token := signToken(t, userClaims(domain.PermAll))
+
+		assert.Equal(t, http.StatusForbidden, serve(e, http.MethodDelete, target, token).Code)
+		mockMFARepo.AssertNotCalled(t, "DeleteMFA", mock.Anything, userID)
+	})
+
+	t.Run("admin resets the factors", func(t *testing.T) {
+		token := signToken(t, userClaims(domain.PermAll, domain.PermAdmin))
+
+		assert.Equal(t, http.StatusNoContent, serve(e, http.MethodDelete, target, token).Code)
+		mockMFARepo.AssertCalled(t, "DeleteMFA", mock.Anything, userID)
+	})
+}
@@ -0,0 +1,21 @@
+package controller
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// loginThrottled reports whether err is a locked out login, in which case the Retry-After header of
+// the 429 answering it is set in whole seconds (RFC 9110 section 10.2.3).
+func loginThrottled(ctx echo.Context, err error) bool {
+	var throttled *domain.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	ctx.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	return true
+}
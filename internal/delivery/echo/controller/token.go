@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"slices"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// newRegisteredClaims fills the registered claims shared by every access token we issue.
+// Tokens are for this server unless the caller narrows the audience.
+func newRegisteredClaims(config *configs.AppConfig, subject string) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(config.SecretExpiration) * time.Second)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		Issuer:    config.Issuer,
+		Subject:   subject,
+		Audience:  jwt.ClaimStrings{config.Audience},
+		ID:        uuid.NewString(),
+	}
+}
+
+// newUserClaims is a first-party token of a user who logged in at authTime, admin accounts also hold
+// the admin permission.
+func newUserClaims(config *configs.AppConfig, user *domain.User, authTime time.Time) domain.JwtClaims {
+	scope := []domain.Permission{domain.PermAll}
+	if slices.Contains(config.AdminAccounts, user.Account) {
+		scope = append(scope, domain.PermAdmin)
+	}
+	return domain.JwtClaims{
+		Name:             user.Name,
+		Scope:            domain.FormatScope(scope),
+		Type:             domain.UserType,
+		AuthTime:         jwt.NewNumericDate(authTime),
+		RegisteredClaims: newRegisteredClaims(config, user.ID.String()),
+	}
+}
+
+func newClientClaims(config *configs.AppConfig, client *domain.Client, scope []domain.Permission, audience []string) domain.JwtClaims {
+	claims := domain.JwtClaims{
+		Name:             "",
+		Scope:            domain.FormatScope(scope),
+		Type:             domain.ClientType,
+		RegisteredClaims: newRegisteredClaims(config, client.ID.String()),
+	}
+	claims.Audience = audience
+	return claims
+}
+
+// newDelegatedClaims is a user token obtained by a third-party client, limited to the consented scope.
+func newDelegatedClaims(config *configs.AppConfig, user *domain.User, client *domain.Client, scope []domain.Permission, audience []string) domain.JwtClaims {
+	claims := domain.JwtClaims{
+		Scope:            domain.FormatScope(scope),
+		Type:             domain.UserType,
+		AuthorizedParty:  client.ID.String(),
+		RegisteredClaims: newRegisteredClaims(config, user.ID.String()),
+	}
+	claims.Audience = audience
+	if hasScope(scope, domain.PermProfile) {
+		claims.Name = user.Name
+	}
+	return claims
+}
+
+// newIDTokenClaims is the OpenID Connect ID token of an authorization code grant, the audience is the client.
+// Name claims are only released with the profile scope.
+func newIDTokenClaims(config *configs.AppConfig, user *domain.User, client *domain.Client, grant *domain.AuthorizationCode) domain.JwtClaims {
+	claims := domain.JwtClaims{
+		Type:             domain.IDTokenType,
+		AuthorizedParty:  client.ID.String(),
+		Nonce:            grant.Nonce,
+		AuthTime:         jwt.NewNumericDate(grant.AuthTime),
+		RegisteredClaims: newRegisteredClaims(config, user.ID.String()),
+	}
+	claims.Audience = jwt.ClaimStrings{client.ID.String()}
+	if hasScope(grant.Scope, domain.PermProfile) {
+		claims.Name = user.Name
+		claims.PreferredUsername = user.Account
+	}
+	return claims
+}
+
+// newExchangedClaims mints the token of a token exchange like Login and the client grants do,
+// narrowed to the exchanged scope and carrying the act chain. It never outlives the subject token
+// and stays bound to the key of a sender-constrained one.
+func newExchangedClaims(config *configs.AppConfig, exchange *domain.TokenExchange, audience []string) domain.JwtClaims {
+	var claims domain.JwtClaims
+	if exchange.User != nil {
+		authTime := time.Now()
+		if exchange.Subject.AuthTime != nil {
+			authTime = exchange.Subject.AuthTime.Time
+		}
+		claims = newUserClaims(config, exchange.User, authTime)
+		claims.AuthorizedParty = exchange.Subject.AuthorizedParty
+		claims.AMR = exchange.Subject.AMR
+		if claims.AuthorizedParty != "" && !hasScope(exchange.Scope, domain.PermProfile) {
+			claims.Name = ""
+		}
+	} else {
+		claims = newClientClaims(config, exchange.Client, exchange.Scope, audience)
+	}
+	claims.Scope = domain.FormatScope(exchange.Scope)
+	claims.Audience = audience
+	claims.Act = exchange.Actor
+	if cnf := exchange.Subject.Cnf; cnf != nil {
+		claims.Cnf = &domain.Confirmation{JKT: cnf.JKT, X5tS256: cnf.X5tS256}
+	}
+	// signing out of the session also invalidates the tokens exchanged from it
+	claims.SessionID = exchange.Subject.SessionID
+	if exp := exchange.Subject.ExpiresAt; exp != nil && exp.Before(claims.ExpiresAt.Time) {
+		claims.ExpiresAt = exp
+	}
+	return claims
+}
+
+// hasScope reports whether the permission was granted explicitly, "*" does not count.
+// OpenID Connect behaviour must be asked for by name.
+func hasScope(scope []domain.Permission, perm domain.Permission) bool {
+	for _, p := range scope {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
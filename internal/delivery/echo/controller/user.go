@@ -6,36 +6,67 @@ import (
 	"time"
 
 	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
 	"github.com/bright-pentium/go-client-practice/internal/domain"
 	"github.com/bright-pentium/go-client-practice/internal/usecase"
-	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
 type UserControler struct {
-	usecase *usecase.UserUseCase
-	config  *configs.AppConfig
+	usecase         *usecase.UserUseCase
+	mfaUsecase      *usecase.MFAUseCase
+	webauthnUsecase *usecase.WebAuthnUseCase
+	refreshUsecase  *usecase.RefreshTokenUseCase
+	sessionUsecase  *usecase.SessionUseCase
+	issuer          *usecase.TokenIssuer
+	auth            *middleware.Authenticator
+	config          *configs.AppConfig
 }
 
-func NewUserControler(usecase *usecase.UserUseCase, config *configs.AppConfig) *UserControler {
-	return &UserControler{usecase: usecase, config: config}
+func NewUserControler(usecase *usecase.UserUseCase, mfaUsecase *usecase.MFAUseCase, webauthnUsecase *usecase.WebAuthnUseCase, refreshUsecase *usecase.RefreshTokenUseCase, sessionUsecase *usecase.SessionUseCase, issuer *usecase.TokenIssuer, auth *middleware.Authenticator, config *configs.AppConfig) *UserControler {
+	return &UserControler{usecase: usecase, mfaUsecase: mfaUsecase, webauthnUsecase: webauthnUsecase, refreshUsecase: refreshUsecase, sessionUsecase: sessionUsecase, issuer: issuer, auth: auth, config: config}
 }
 
 func (u *UserControler) RegisterRoutes(e *echo.Echo) {
 	e.POST("/auth/users/login", u.Login)
+	e.POST("/auth/users/mfa", u.LoginMFA)
+	e.POST("/auth/users/mfa/webauthn/options", u.BeginLoginMFAWebAuthn)
+	e.POST("/auth/users/mfa/webauthn", u.LoginMFAWebAuthn)
+	e.POST("/auth/users/passkey/options", u.BeginPasskeyLogin)
+	e.POST("/auth/users/passkey", u.PasskeyLogin)
+	e.POST("/auth/users/logout", u.Logout, u.auth.SessionMiddleware, middleware.RequireFirstPartyUser)
 }
 
+// Login modes: tokens are returned in the body, a cookie session keeps them out of reach of the page.
+const (
+	LoginModeToken  = "token"
+	LoginModeCookie = "cookie"
+)
+
 type UserLoginRequest struct {
 	Account  string `json:"account" validate:"required"`
 	Password string `json:"password" validate:"required"`
+	// Mode is token unless the web console asks for a cookie session
+	Mode string `json:"mode" example:"cookie" validate:"omitempty,oneof=token cookie"`
 }
 
 type UserLoginResponse struct {
-	AccessToken string `json:"access_token" validate:"required"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// CSRFToken of a cookie session, to be sent in the X-CSRF-Token header of state-changing requests
+	CSRFToken string `json:"csrf_token,omitempty"`
+	// MFARequired is set when the password was right but the second factor is still to be given
+	// together with MFAToken, to /auth/users/mfa for totp or /auth/users/mfa/webauthn for webauthn
+	MFARequired bool     `json:"mfa_required,omitempty"`
+	MFAToken    string   `json:"mfa_token,omitempty"`
+	MFAMethods  []string `json:"mfa_methods,omitempty" example:"totp,webauthn"`
 }
 
 // @Summary User login
-// @Description User Login
+// @Description User Login. In cookie mode no token is returned: the session is kept in an HttpOnly cookie
+// @Description and state-changing requests must echo the returned csrf_token in the X-CSRF-Token header.
+// @Description Users enrolled in MFA get mfa_required, an mfa_token and the mfa_methods to answer it with instead.
 // @Tags user
 // @Accept  json
 // @Produce  json
@@ -43,6 +74,7 @@ type UserLoginResponse struct {
 // @Success 200 {object} UserLoginResponse "Success"
 // @Failure 400 {object} echo.HTTPError "Bad Requests"
 // @Failure 404 {object} echo.HTTPError "Not Found"
+// @Failure 429 {object} echo.HTTPError "Too many failed logins of the account or from the address, see Retry-After"
 // @Failure 500 {object} echo.HTTPError "Internal Server Error"
 // @Router /users/login [post]
 func (u *UserControler) Login(ctx echo.Context) error {
@@ -54,8 +86,11 @@ func (u *UserControler) Login(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	user, err := u.usecase.LoginUser(ctx.Request().Context(), req.Account, req.Password)
+	user, err := u.usecase.LoginUser(ctx.Request().Context(), req.Account, req.Password, ctx.RealIP())
 	if err != nil {
+		if loginThrottled(ctx, err) {
+			return echo.NewHTTPError(http.StatusTooManyRequests, domain.ErrLoginThrottled.Error())
+		}
 		if errors.Is(err, domain.ErrUserLoginFail) {
 			return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
 		} else {
@@ -63,25 +98,277 @@ func (u *UserControler) Login(ctx echo.Context) error {
 		}
 	}
 
-	// Create token with claims
-	now := time.Now()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, domain.JwtClaims{
-		Name:  user.Name,
-		Scope: string(domain.PermAll),
-		Type:  domain.UserType,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(u.config.SecretExpiration) * time.Second)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    u.config.Issuer,
-			Subject:   user.ID.String(),
-		},
-	})
+	authTime := time.Now()
+	mfaToken, methods, err := u.mfaUsecase.StartChallenge(ctx.Request().Context(), user, req.Mode, authTime)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if mfaToken != "" {
+		return ctx.JSON(http.StatusOK, UserLoginResponse{MFARequired: true, MFAToken: mfaToken, MFAMethods: methods})
+	}
+	return u.completeLogin(ctx, user, req.Mode, authTime, []string{domain.AMRPassword})
+}
+
+type UserLoginMFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	// Code is the current code of the authenticator app, or one of the recovery codes
+	Code string `json:"code" example:"123456" validate:"required"`
+}
+
+// @Summary User login second factor
+// @Description Answers the challenge of a login with mfa_required by a TOTP code or a recovery code,
+// @Description the login then completes in the mode it was started with.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body UserLoginMFARequest true "User Login MFA Request"
+// @Success 200 {object} UserLoginResponse "Success"
+// @Failure 400 {object} echo.HTTPError "Bad Requests"
+// @Failure 401 {object} echo.HTTPError "Invalid challenge or code"
+// @Failure 429 {object} echo.HTTPError "Too many wrong codes of the user or from the address, see Retry-After"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Router /users/mfa [post]
+func (u *UserControler) LoginMFA(ctx echo.Context) error {
+	req := new(UserLoginMFARequest)
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	user, challenge, err := u.mfaUsecase.VerifyChallenge(ctx.Request().Context(), req.MFAToken, req.Code, ctx.RealIP())
+	if err != nil {
+		if loginThrottled(ctx, err) {
+			return echo.NewHTTPError(http.StatusTooManyRequests, domain.ErrLoginThrottled.Error())
+		}
+		switch {
+		case errors.Is(err, domain.ErrMFAChallengeInvalid):
+			return echo.NewHTTPError(http.StatusUnauthorized, domain.ErrMFAChallengeInvalid.Error())
+		case errors.Is(err, domain.ErrMFACodeInvalid):
+			return echo.NewHTTPError(http.StatusUnauthorized, domain.ErrMFACodeInvalid.Error())
+		default:
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+	return u.completeLogin(ctx, user, challenge.Mode, challenge.AuthTime, []string{domain.AMRPassword, domain.AMROTP, domain.AMRMFA})
+}
+
+type BeginLoginMFAWebAuthnRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+// @Summary Begin user login second factor with WebAuthn
+// @Description Returns the publicKey options of navigator.credentials.get() that answer the challenge of a login
+// @Description with mfa_required, only the credentials of the user are allowed.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body BeginLoginMFAWebAuthnRequest true "Begin User Login MFA WebAuthn Request"
+// @Success 200 {object} domain.WebAuthnRequestOptions "Success"
+// @Failure 400 {object} echo.HTTPError "Bad Requests"
+// @Failure 401 {object} echo.HTTPError "Invalid challenge"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Router /users/mfa/webauthn/options [post]
+func (u *UserControler) BeginLoginMFAWebAuthn(ctx echo.Context) error {
+	req := new(BeginLoginMFAWebAuthnRequest)
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	options, err := u.mfaUsecase.BeginWebAuthnChallenge(ctx.Request().Context(), req.MFAToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFAChallengeInvalid) {
+			return echo.NewHTTPError(http.StatusUnauthorized, domain.ErrMFAChallengeInvalid.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, options)
+}
+
+type UserLoginMFAWebAuthnRequest struct {
+	MFAToken   string                  `json:"mfa_token" validate:"required"`
+	Credential WebAuthnLoginCredential `json:"credential"`
+}
+
+// @Summary User login second factor with WebAuthn
+// @Description Answers the challenge of a login with mfa_required by the response of the authenticator to the
+// @Description options of /auth/users/mfa/webauthn/options, the login then completes in the mode it was started with.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body UserLoginMFAWebAuthnRequest true "User Login MFA WebAuthn Request"
+// @Success 200 {object} UserLoginResponse "Success"
+// @Failure 400 {object} echo.HTTPError "Bad Requests"
+// @Failure 401 {object} echo.HTTPError "Invalid challenge or WebAuthn response"
+// @Failure 429 {object} echo.HTTPError "Too many failed answers of the user or from the address, see Retry-After"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Router /users/mfa/webauthn [post]
+func (u *UserControler) LoginMFAWebAuthn(ctx echo.Context) error {
+	req := new(UserLoginMFAWebAuthnRequest)
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	assertion, err := req.Credential.decode()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	user, challenge, err := u.mfaUsecase.VerifyWebAuthnChallenge(ctx.Request().Context(), req.MFAToken, assertion, ctx.RealIP())
+	if err != nil {
+		if loginThrottled(ctx, err) {
+			return echo.NewHTTPError(http.StatusTooManyRequests, domain.ErrLoginThrottled.Error())
+		}
+		if errors.Is(err, domain.ErrMFAChallengeInvalid) {
+			return echo.NewHTTPError(http.StatusUnauthorized, domain.ErrMFAChallengeInvalid.Error())
+		}
+		return webauthnError(err)
+	}
+	return u.completeLogin(ctx, user, challenge.Mode, challenge.AuthTime, []string{domain.AMRPassword, domain.AMRHardwareKey, domain.AMRMFA})
+}
+
+// @Summary Begin passkey login
+// @Description Returns the publicKey options of navigator.credentials.get() for a login without a password,
+// @Description the authenticator offers the passkeys it holds for this service.
+// @Tags user
+// @Produce  json
+// @Success 200 {object} domain.WebAuthnRequestOptions "Success"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Router /users/passkey/options [post]
+func (u *UserControler) BeginPasskeyLogin(ctx echo.Context) error {
+	options, err := u.webauthnUsecase.BeginLogin(ctx.Request().Context(), nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, options)
+}
+
+type UserPasskeyLoginRequest struct {
+	Credential WebAuthnLoginCredential `json:"credential"`
+	// Mode is token unless the web console asks for a cookie session
+	Mode string `json:"mode" example:"cookie" validate:"omitempty,oneof=token cookie"`
+}
+
+// @Summary User passkey login
+// @Description Signs in without a password by the response of the authenticator to the options of
+// @Description /auth/users/passkey/options. The authenticator must have verified the user, which makes the
+// @Description passkey a second factor on its own and no other one is asked for.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body UserPasskeyLoginRequest true "User Passkey Login Request"
+// @Success 200 {object} UserLoginResponse "Success"
+// @Failure 400 {object} echo.HTTPError "Bad Requests"
+// @Failure 401 {object} echo.HTTPError "Invalid challenge or WebAuthn response"
+// @Failure 429 {object} echo.HTTPError "Too many failed logins with the credential or from the address, see Retry-After"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Router /users/passkey [post]
+func (u *UserControler) PasskeyLogin(ctx echo.Context) error {
+	req := new(UserPasskeyLoginRequest)
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	assertion, err := req.Credential.decode()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	user, err := u.webauthnUsecase.Login(ctx.Request().Context(), assertion, ctx.RealIP())
+	if err != nil {
+		if loginThrottled(ctx, err) {
+			return echo.NewHTTPError(http.StatusTooManyRequests, domain.ErrLoginThrottled.Error())
+		}
+		return webauthnError(err)
+	}
+	return u.completeLogin(ctx, user, req.Mode, time.Now(), []string{domain.AMRHardwareKey, domain.AMRMFA})
+}
+
+// completeLogin signs the user in with the authentication methods amr, in a cookie session or with tokens.
+func (u *UserControler) completeLogin(ctx echo.Context, user *domain.User, mode string, authTime time.Time, amr []string) error {
+	if mode == LoginModeCookie {
+		return u.cookieLogin(ctx, user, amr)
+	}
 
 	// Generate encoded token
-	tokenString, err := token.SignedString([]byte(u.config.SecretKey))
+	claims := newUserClaims(u.config, user, authTime)
+	claims.AMR = amr
+	// the session lasts as long as its refresh tokens can be rotated
+	session, err := startSession(ctx, u.sessionUsecase, user, nil, authTime.Add(time.Duration(u.config.RefreshExpiration)*time.Second), &claims)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
-	return ctx.JSON(http.StatusOK, UserLoginResponse{AccessToken: tokenString})
+	tokenString, err := u.issuer.Issue(ctx.Request().Context(), &domain.ClaimRequest{User: user, GrantType: "password"}, claims)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	refreshToken, err := u.refreshUsecase.IssueRefreshToken(ctx.Request().Context(), session.ID, user.ID, authTime)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, UserLoginResponse{AccessToken: tokenString, RefreshToken: refreshToken})
+}
+
+func (u *UserControler) cookieLogin(ctx echo.Context, user *domain.User, amr []string) error {
+	expiration := time.Duration(u.config.CookieSessionExpiration) * time.Second
+	session, cookie, csrf, err := u.sessionUsecase.StartCookieSession(ctx.Request().Context(), &domain.Session{
+		UserID:    user.ID,
+		UserAgent: ctx.Request().UserAgent(),
+		IPAddress: ctx.RealIP(),
+		ExpiresAt: time.Now().Add(expiration),
+		AMR:       amr,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     middleware.SessionCookie,
+		Value:    cookie,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return ctx.JSON(http.StatusOK, UserLoginResponse{CSRFToken: csrf})
+}
+
+// @Summary User logout
+// @Description Signs out the session of the calling token or session cookie and clears the cookie.
+// @Tags user
+// @Success 204 "No Content"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /auth/users/logout [post]
+func (u *UserControler) Logout(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+	claims, _ := ctx.Get("claims").(*domain.JwtClaims)
+
+	if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+		if err := u.sessionUsecase.RevokeSession(ctx.Request().Context(), userID, sessionID); err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	ctx.SetCookie(&http.Cookie{
+		Name:     middleware.SessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return ctx.NoContent(http.StatusNoContent)
 }
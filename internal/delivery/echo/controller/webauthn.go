@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// WebAuthnController lets users register passkeys and security keys, which then are a second factor
+// and sign in without a password. Like the TOTP factor, it is only open to login tokens and cookie
+// sessions of the user.
+type WebAuthnController struct {
+	usecase     *usecase.WebAuthnUseCase
+	userUsecase *usecase.UserUseCase
+	auth        *middleware.Authenticator
+	config      *configs.AppConfig
+}
+
+func NewWebAuthnController(usecase *usecase.WebAuthnUseCase, userUsecase *usecase.UserUseCase, auth *middleware.Authenticator, config *configs.AppConfig) *WebAuthnController {
+	return &WebAuthnController{usecase: usecase, userUsecase: userUsecase, auth: auth, config: config}
+}
+
+func (w *WebAuthnController) RegisterRoutes(e *echo.Echo) {
+	api := e.Group("/me/webauthn", w.auth.SessionMiddleware, middleware.RequireFirstPartyUser)
+	api.POST("/register/options", w.BeginRegistration)
+	api.POST("/register", w.FinishRegistration)
+	api.GET("/credentials", w.ListCredentials)
+	api.DELETE("/credentials/:credential-id", w.DeleteCredential, middleware.RequireRecentMFA(time.Duration(w.config.StepUpMaxAge)*time.Second))
+}
+
+type WebAuthnAttestationResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" validate:"required"`
+	AttestationObject string `json:"attestationObject" validate:"required"`
+}
+
+// WebAuthnRegistrationCredential is the PublicKeyCredential of navigator.credentials.create() in the
+// form of its toJSON(), binary fields are base64url encoded.
+type WebAuthnRegistrationCredential struct {
+	ID       string                      `json:"id" example:"AQIDBAUGBwgJCgsMDQ4PEA" validate:"required"`
+	Type     string                      `json:"type" example:"public-key" validate:"required,eq=public-key"`
+	Response WebAuthnAttestationResponse `json:"response"`
+}
+
+func (c *WebAuthnRegistrationCredential) decode() (*domain.WebAuthnAttestation, error) {
+	var attestation domain.WebAuthnAttestation
+	var err error
+	if attestation.CredentialID, err = decodeBase64URL(c.ID); err != nil {
+		return nil, err
+	}
+	if attestation.ClientDataJSON, err = decodeBase64URL(c.Response.ClientDataJSON); err != nil {
+		return nil, err
+	}
+	if attestation.AttestationObject, err = decodeBase64URL(c.Response.AttestationObject); err != nil {
+		return nil, err
+	}
+	return &attestation, nil
+}
+
+type WebAuthnAssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" validate:"required"`
+	AuthenticatorData string `json:"authenticatorData" validate:"required"`
+	Signature         string `json:"signature" validate:"required"`
+	UserHandle        string `json:"userHandle"`
+}
+
+// WebAuthnLoginCredential is the PublicKeyCredential of navigator.credentials.get() in the form of its
+// toJSON(), binary fields are base64url encoded.
+type WebAuthnLoginCredential struct {
+	ID       string                    `json:"id" example:"AQIDBAUGBwgJCgsMDQ4PEA" validate:"required"`
+	Type     string                    `json:"type" example:"public-key" validate:"required,eq=public-key"`
+	Response WebAuthnAssertionResponse `json:"response"`
+}
+
+func (c *WebAuthnLoginCredential) decode() (*domain.WebAuthnAssertion, error) {
+	var assertion domain.WebAuthnAssertion
+	var err error
+	if assertion.CredentialID, err = decodeBase64URL(c.ID); err != nil {
+		return nil, err
+	}
+	if assertion.ClientDataJSON, err = decodeBase64URL(c.Response.ClientDataJSON); err != nil {
+		return nil, err
+	}
+	if assertion.AuthenticatorData, err = decodeBase64URL(c.Response.AuthenticatorData); err != nil {
+		return nil, err
+	}
+	if assertion.Signature, err = decodeBase64URL(c.Response.Signature); err != nil {
+		return nil, err
+	}
+	if assertion.UserHandle, err = decodeBase64URL(c.Response.UserHandle); err != nil {
+		return nil, err
+	}
+	return &assertion, nil
+}
+
+// decodeBase64URL decodes the unpadded base64url of WebAuthn JSON, padded input is taken as well.
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+// webauthnError maps the errors of a ceremony to their status, the detail of an invalid response is
+// not given away.
+func webauthnError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrWebAuthnInvalid):
+		return echo.NewHTTPError(http.StatusUnauthorized, domain.ErrWebAuthnInvalid.Error())
+	case errors.Is(err, domain.ErrWebAuthnChallengeInvalid):
+		return echo.NewHTTPError(http.StatusUnauthorized, domain.ErrWebAuthnChallengeInvalid.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// @Summary Begin WebAuthn registration
+// @Description Returns the publicKey options of navigator.credentials.create() for a new passkey or security key,
+// @Description its response is then sent to /me/webauthn/register.
+// @Tags user
+// @Produce  json
+// @Success 200 {object} domain.WebAuthnCreationOptions "Success"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /me/webauthn/register/options [post]
+func (w *WebAuthnController) BeginRegistration(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+
+	user, err := w.userUsecase.GetUserByID(ctx.Request().Context(), userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	options, err := w.usecase.BeginRegistration(ctx.Request().Context(), user)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, options)
+}
+
+type FinishWebAuthnRegistrationRequest struct {
+	// Name tells the credentials of the user apart
+	Name       string                         `json:"name" example:"YubiKey 5" validate:"required,max=64"`
+	Credential WebAuthnRegistrationCredential `json:"credential"`
+}
+
+// @Summary Finish WebAuthn registration
+// @Description Verifies the response of the authenticator to the registration options and stores its credential.
+// @Tags user
+// @Accept  json
+// @Produce  json
+// @Param request body FinishWebAuthnRegistrationRequest true "Finish WebAuthn Registration Request"
+// @Success 201 {object} domain.WebAuthnCredential "Created"
+// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized, or invalid response or challenge"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 409 {object} echo.HTTPError "Already registered"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /me/webauthn/register [post]
+func (w *WebAuthnController) FinishRegistration(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+	req := new(FinishWebAuthnRegistrationRequest)
+	if err := ctx.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := ctx.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	attestation, err := req.Credential.decode()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	credential, err := w.usecase.FinishRegistration(ctx.Request().Context(), userID, req.Name, attestation)
+	if err != nil {
+		if errors.Is(err, domain.ErrWebAuthnCredentialExists) {
+			return echo.NewHTTPError(http.StatusConflict, domain.ErrWebAuthnCredentialExists.Error())
+		}
+		return webauthnError(err)
+	}
+	return ctx.JSON(http.StatusCreated, credential)
+}
+
+// @Summary List WebAuthn credentials
+// @Description Lists the passkeys and security keys of the user.
+// @Tags user
+// @Produce  json
+// @Success 200 {array} domain.WebAuthnCredential "Success"
+// @Failure 401 {object} echo.HTTPError "Unauthorized"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /me/webauthn/credentials [get]
+func (w *WebAuthnController) ListCredentials(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+
+	credentials, err := w.usecase.ListCredentials(ctx.Request().Context(), userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.JSON(http.StatusOK, credentials)
+}
+
+// @Summary Delete WebAuthn credential
+// @Description Removes a passkey or security key of the user, it no longer signs in.
+// @Description Only a login with a second factor within STEP_UP_MAX_AGE may do so, others are answered with
+// @Description 401 and an insufficient_user_authentication challenge (RFC 9470).
+// @Tags user
+// @Param credential-id path string true "Credential ID, base64url"
+// @Success 204 "No Content"
+// @Failure 400 {object} echo.HTTPError "Bad Request"
+// @Failure 401 {object} echo.HTTPError "Unauthorized, or no recent login with a second factor"
+// @Failure 403 {object} echo.HTTPError "Forbidden"
+// @Failure 404 {object} echo.HTTPError "Not Found"
+// @Failure 500 {object} echo.HTTPError "Internal Server Error"
+// @Security Bearer
+// @Router /me/webauthn/credentials/{credential-id} [delete]
+func (w *WebAuthnController) DeleteCredential(ctx echo.Context) error {
+	userID, _ := ctx.Get("userID").(uuid.UUID)
+	credentialID, err := decodeBase64URL(ctx.Param("credential-id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err := w.usecase.DeleteCredential(ctx.Request().Context(), userID, credentialID); err != nil {
+		if errors.Is(err, domain.ErrWebAuthnCredentialNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, domain.ErrWebAuthnCredentialNotFound.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
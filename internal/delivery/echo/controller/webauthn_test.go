@@ -0,0 +1,56 @@
+package controller_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/configs"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/controller"
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	webauthnRepo "github.com/bright-pentium/go-client-practice/internal/repository/webauthn"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeleteWebAuthnCredentialStepUp(t *testing.T) {
+	mockRepo := new(webauthnRepo.MockWebAuthnRepository)
+	mockRepo.On("DeleteCredential", mock.Anything, []byte{1, 2, 3}, mock.Anything).Return(nil)
+	webauthn := usecase.NewWebAuthnUseCase(mockRepo, nil, testThrottle(), domain.WebAuthnRelyingParty{ID: "localhost"}, []string{testAudience}, time.Minute)
+	e := echo.New()
+	controller.NewWebAuthnController(webauthn, nil, testAuthenticator(), &configs.AppConfig{StepUpMaxAge: 300}).RegisterRoutes(e)
+	target := "/me/webauthn/credentials/AQID"
+
+	login := func(authTime time.Time, amr ...string) string {
+		claims := userClaims(domain.PermAll)
+		claims.AuthTime = jwt.NewNumericDate(authTime)
+		claims.AMR = amr
+		return signToken(t, claims)
+	}
+
+	t.Run("password login is asked to step up", func(t *testing.T) {
+		rec := serve(e, http.MethodDelete, target, login(time.Now(), domain.AMRPassword))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), `error="insufficient_user_authentication"`)
+		mockRepo.AssertNotCalled(t, "DeleteCredential", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("second factor login that is no longer recent is asked to step up", func(t *testing.T) {
+		rec := serve(e, http.MethodDelete, target, login(time.Now().Add(-time.Hour), domain.AMRPassword, domain.AMROTP, domain.AMRMFA))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), "max_age=300")
+		mockRepo.AssertNotCalled(t, "DeleteCredential", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("recent second factor login deletes the credential", func(t *testing.T) {
+		rec := serve(e, http.MethodDelete, target, login(time.Now(), domain.AMRPassword, domain.AMROTP, domain.AMRMFA))
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		mockRepo.AssertCalled(t, "DeleteCredential", mock.Anything, []byte{1, 2, 3}, mock.Anything)
+	})
+}
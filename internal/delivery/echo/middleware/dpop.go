@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/labstack/echo/v4"
+)
+
+// DPoPMiddleware enforces RFC 9449 on protected routes, it runs once the Authenticator resolved the token. A token bound
+// with cnf.jkt must come with the DPoP scheme and a fresh proof signed by that key, whose htu is
+// the request path under baseURL. The DPoP scheme in turn requires a bound token.
+func DPoPMiddleware(dpop *usecase.DPoPUseCase, baseURL string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, _ := c.Get("claims").(*domain.JwtClaims)
+			token, _ := c.Get("token").(string)
+			if claims == nil || token == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing JWT claims")
+			}
+
+			scheme, _, _ := strings.Cut(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+			isDPoP := strings.EqualFold(scheme, "DPoP")
+			bound := claims.Cnf != nil && claims.Cnf.JKT != ""
+			switch {
+			case !bound && !isDPoP:
+				return next(c)
+			case !bound:
+				return dpopChallenge(c, "the DPoP scheme requires a DPoP-bound token")
+			case !isDPoP:
+				return dpopChallenge(c, "a DPoP-bound token must be sent with the DPoP scheme")
+			}
+
+			proofs := c.Request().Header.Values("DPoP")
+			if len(proofs) != 1 {
+				return dpopChallenge(c, "exactly one DPoP proof is required")
+			}
+			jkt, err := dpop.VerifyProof(proofs[0], c.Request().Method, baseURL+c.Request().URL.Path, token)
+			if err != nil {
+				if errors.Is(err, domain.ErrInvalidDPoPProof) || errors.Is(err, domain.ErrDPoPProofReplayed) {
+					return dpopChallenge(c, err.Error())
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			}
+			if jkt != claims.Cnf.JKT {
+				return dpopChallenge(c, "the DPoP proof is signed by another key than the token is bound to")
+			}
+			return next(c)
+		}
+	}
+}
+
+// dpopChallenge answers 401 with the WWW-Authenticate challenge of RFC 9449 section 7.1.
+func dpopChallenge(c echo.Context, description string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `DPoP error="invalid_dpop_proof", algs="`+strings.Join(usecase.DPoPAlgorithms, " ")+`"`)
+	return echo.NewHTTPError(http.StatusUnauthorized, description)
+}
@@ -1,32 +1,124 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+// SessionCookie carries the browser session of the web console. The __Host- prefix makes browsers
+// only accept it Secure, for the whole path and without a Domain, so no other origin can set it.
+const SessionCookie = "__Host-session"
+
+// CSRFHeader carries the CSRF token of the browser session on state-changing requests.
+const CSRFHeader = "X-CSRF-Token"
+
+// Authenticator guards protected groups: it resolves the bearer or DPoP token, a JWT or an
+// opaque token, to its claims, runs DPoPMiddleware and MTLSMiddleware on them and rejects
+// tokens meant for another audience.
+type Authenticator struct {
+	verifier *usecase.TokenVerifier
+	sessions *usecase.SessionUseCase
+	dpop     echo.MiddlewareFunc
+	audience string
+}
+
+func NewAuthenticator(verifier *usecase.TokenVerifier, dpop *usecase.DPoPUseCase, sessions *usecase.SessionUseCase, audience string, baseURL string) *Authenticator {
+	return &Authenticator{
+		verifier: verifier,
+		sessions: sessions,
+		dpop:     DPoPMiddleware(dpop, baseURL),
+		audience: audience,
+	}
+}
+
+func (a *Authenticator) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return a.authenticate(a.dpop(MTLSMiddleware(a.checkClaims(next))))
+}
+
+// AdminMiddleware is Middleware for the /admin APIs, which only admit login tokens of administrators.
+// Session cookies are not taken, a forged request of the web console cannot reach them.
+func (a *Authenticator) AdminMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return a.Middleware(RequireFirstPartyUser(RequirePermission(domain.PermAdmin)(next)))
+}
+
+// SessionMiddleware is Middleware for the groups the web console calls, which also admit the
+// session cookie. A request with an Authorization header is always authenticated by its token.
+func (a *Authenticator) SessionMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	bearer, cookie := a.Middleware(next), a.authenticateCookie(next)
+	return func(c echo.Context) error {
+		if c.Request().Header.Get(echo.HeaderAuthorization) == "" {
+			if _, err := c.Cookie(SessionCookie); err == nil {
+				return cookie(c)
+			}
+		}
+		return bearer(c)
+	}
+}
+
+// authenticateCookie resolves the browser session of the cookie, checks the CSRF token of
+// state-changing requests and stores the session as the claims of a first-party login.
+func (a *Authenticator) authenticateCookie(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		// Get the JWT token
-		userToken := c.Get("user")
-		if userToken == nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, "missing JWT token")
+		cookie, err := c.Cookie(SessionCookie)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized, "missing session cookie")
 		}
 
-		token, ok := userToken.(*jwt.Token)
-		if !ok {
-			return echo.NewHTTPError(http.StatusUnauthorized, "invalid JWT token")
+		method := c.Request().Method
+		safe := method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+		session, err := a.sessions.ResolveCookie(c.Request().Context(), cookie.Value, c.Request().Header.Get(CSRFHeader), !safe)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrSessionNotFound) || errors.Is(err, domain.ErrTokenRevoked):
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid session: "+err.Error())
+			case errors.Is(err, domain.ErrCSRFTokenInvalid):
+				return echo.NewHTTPError(http.StatusForbidden, err.Error())
+			default:
+				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			}
 		}
 
-		claims, ok := token.Claims.(*domain.JwtClaims)
-		if !ok {
-			return echo.NewHTTPError(http.StatusUnauthorized, "invalid JWT claims")
+		c.Set("userID", session.UserID)
+		c.Set("claims", &domain.JwtClaims{
+			Scope:     string(domain.PermAll),
+			Type:      domain.UserType,
+			AuthTime:  jwt.NewNumericDate(session.CreatedAt),
+			AMR:       session.AMR,
+			SessionID: session.ID.String(),
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   session.UserID.String(),
+				ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+			},
+		})
+		return next(c)
+	}
+}
+
+// authenticate resolves the token of the Authorization header, expiry and revocation included,
+// and stores it with its claims and subject in the context.
+func (a *Authenticator) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		scheme, token, _ := strings.Cut(c.Request().Header.Get(echo.HeaderAuthorization), " ")
+		if (!strings.EqualFold(scheme, "Bearer") && !strings.EqualFold(scheme, "DPoP")) || token == "" {
+			return echo.NewHTTPError(http.StatusUnauthorized, "missing or malformed access token")
+		}
+
+		claims, err := a.verifier.Verify(c.Request().Context(), token)
+		if err != nil {
+			if errors.Is(err, domain.ErrInvalidAccessToken) || errors.Is(err, domain.ErrTokenRevoked) {
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 		}
 
 		// Parse subject (user ID)
@@ -35,13 +127,25 @@ func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID in token: "+err.Error())
 		}
 
-		// Store userID and claims in context
+		// Store the token, userID and claims in context
+		c.Set("token", token)
 		c.Set("userID", userID)
 		c.Set("claims", claims)
 		return next(c)
 	}
 }
 
+// checkClaims applies what the token itself cannot tell: it must be meant for this server.
+func (a *Authenticator) checkClaims(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		claims, _ := c.Get("claims").(*domain.JwtClaims)
+		if !claims.VerifyAudience(a.audience, true) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "token audience does not include this server")
+		}
+		return next(c)
+	}
+}
+
 func RequirePermission(required domain.Permission) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -57,8 +161,8 @@ func RequirePermission(required domain.Permission) echo.MiddlewareFunc {
 			}
 
 			// Check if required permission is present
-			for _, perm := range strings.Split(claims.Scope, " ") {
-				if domain.Permission(perm) == domain.PermAll || domain.Permission(perm) == required {
+			for _, perm := range domain.ParseScope(claims.Scope) {
+				if perm.Grants(required) {
 					return next(c)
 				}
 			}
@@ -67,3 +171,39 @@ func RequirePermission(required domain.Permission) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// RequireFirstPartyUser only admits tokens the user obtained by logging in directly,
+// so a third-party client cannot use its delegated or exchanged token to grant itself more access.
+func RequireFirstPartyUser(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		claims, ok := c.Get("claims").(*domain.JwtClaims)
+		if !ok {
+			return echo.NewHTTPError(http.StatusUnauthorized, "missing JWT claims")
+		}
+		if claims.Type != domain.UserType || claims.AuthorizedParty != "" || claims.Act != nil {
+			return echo.NewHTTPError(http.StatusForbidden, "a user login token is required")
+		}
+		return next(c)
+	}
+}
+
+// RequireRecentMFA admits logins that used a second factor no longer than maxAge ago, a stolen session
+// or a password login cannot take away the factors of the user. Others are asked to step up
+// (RFC 9470).
+func RequireRecentMFA(maxAge time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := c.Get("claims").(*domain.JwtClaims)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "missing JWT claims")
+			}
+			if slices.Contains(claims.AMR, domain.AMRMFA) && claims.AuthTime != nil && time.Since(claims.AuthTime.Time) <= maxAge {
+				return next(c)
+			}
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, fmt.Sprintf(
+				`Bearer error="insufficient_user_authentication", error_description="a recent login with a second factor is required", acr_values="mfa", max_age=%d`,
+				int(maxAge.Seconds())))
+			return echo.NewHTTPError(http.StatusUnauthorized, "a recent login with a second factor is required")
+		}
+	}
+}
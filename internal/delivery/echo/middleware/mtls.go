@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/labstack/echo/v4"
+)
+
+// MTLSMiddleware enforces RFC 8705 section 3 on protected routes, it runs once the Authenticator resolved
+// the token. A token bound with cnf.x5t#S256 is only accepted over a TLS connection with the certificate it is bound to.
+func MTLSMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		claims, _ := c.Get("claims").(*domain.JwtClaims)
+		if claims == nil {
+			return echo.NewHTTPError(http.StatusUnauthorized, "missing JWT claims")
+		}
+		if claims.Cnf == nil || claims.Cnf.X5tS256 == "" {
+			return next(c)
+		}
+
+		state := c.Request().TLS
+		if state == nil || len(state.PeerCertificates) == 0 {
+			return echo.NewHTTPError(http.StatusUnauthorized, domain.ErrCertificateMismatch.Error())
+		}
+		thumbprint := domain.CertificateThumbprint(state.PeerCertificates[0])
+		if subtle.ConstantTimeCompare([]byte(thumbprint), []byte(claims.Cnf.X5tS256)) != 1 {
+			return echo.NewHTTPError(http.StatusUnauthorized, domain.ErrCertificateMismatch.Error())
+		}
+		return next(c)
+	}
+}
@@ -2,33 +2,52 @@
 // @version 1.0
 // @description API documentation
 // @BasePath /
-// @schemes http
+// @schemes http https
 
 // @securityDefinitions.apikey Bearer
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
+// @securityDefinitions.basic BasicAuth
+
 package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	_ "github.com/bright-pentium/go-client-practice/docs/swaggo"
 	"github.com/bright-pentium/go-client-practice/internal/configs"
 	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/controller"
+	"github.com/bright-pentium/go-client-practice/internal/delivery/echo/middleware"
 	"github.com/bright-pentium/go-client-practice/internal/domain"
+	authorizationRepo "github.com/bright-pentium/go-client-practice/internal/repository/authorization"
+	breachRepo "github.com/bright-pentium/go-client-practice/internal/repository/breach"
 	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	clientKeyRepo "github.com/bright-pentium/go-client-practice/internal/repository/clientkey"
+	deviceRepo "github.com/bright-pentium/go-client-practice/internal/repository/device"
+	loginAttemptRepo "github.com/bright-pentium/go-client-practice/internal/repository/loginattempt"
+	mfaRepo "github.com/bright-pentium/go-client-practice/internal/repository/mfa"
+	opaqueRepo "github.com/bright-pentium/go-client-practice/internal/repository/opaque"
+	passwordHistoryRepo "github.com/bright-pentium/go-client-practice/internal/repository/passwordhistory"
+	refreshRepo "github.com/bright-pentium/go-client-practice/internal/repository/refresh"
+	revocationRepo "github.com/bright-pentium/go-client-practice/internal/repository/revocation"
+	sessionRepo "github.com/bright-pentium/go-client-practice/internal/repository/session"
+	signingKeyRepo "github.com/bright-pentium/go-client-practice/internal/repository/signingkey"
 	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	webauthnRepo "github.com/bright-pentium/go-client-practice/internal/repository/webauthn"
 
 	"github.com/bright-pentium/go-client-practice/internal/usecase"
 	"github.com/go-playground/validator"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
 
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
@@ -77,13 +96,49 @@ func validPermission(fl validator.FieldLevel) bool {
 
 func NewServer(config *configs.AppConfig) *EchoServer {
 	e := echo.New()
+	// X-Forwarded-For is only trusted from the configured proxies, so clients cannot pick the ip
+	// their failed logins are counted under
+	e.IPExtractor = echo.ExtractIPDirect()
+	if len(config.TrustedProxies) > 0 {
+		trust := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+		for _, proxy := range config.TrustedProxies {
+			trust = append(trust, echo.TrustIPRange(proxy))
+		}
+		e.IPExtractor = echo.ExtractIPFromXFFHeader(trust...)
+	}
 	v := validator.New()
 	v.RegisterValidation("perm", validPermission)
 	e.Validator = &CustomValidator{validator: v}
 	return &EchoServer{echo: e, config: config}
 }
 
+// loadStaticKeys reads the configured PEM signing keys. The HS256 secret, when set,
+// is kept last so it only signs while no asymmetric key is active.
+func loadStaticKeys(config *configs.AppConfig) ([]domain.SigningKey, error) {
+	keys := make([]domain.SigningKey, 0, len(config.SigningKeyFiles)+1)
+	for _, path := range config.SigningKeyFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := usecase.ParseSigningKeyPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys = append(keys, *key)
+	}
+	if config.SecretKey != "" {
+		keys = append(keys, usecase.NewHMACSigningKey(config.SecretKey))
+	}
+	return keys, nil
+}
+
 func (s *EchoServer) Serving(ctx context.Context) error {
+	staticKeys, err := loadStaticKeys(s.config)
+	if err != nil {
+		return err
+	}
+
 	// TODO(bright) refactor config and Serving logic
 	// suppport multiple db configs, some other repo may not need pxpool
 	config, err := pgxpool.ParseConfig(s.config.DbURL)
@@ -105,24 +160,136 @@ func (s *EchoServer) Serving(ctx context.Context) error {
 
 	userRepo := userRepo.NewPgxUserRepository(pgxpool)
 	clientRepo := clientRepo.NewPgxClientRepository(pgxpool)
+	clientKeyRepo := clientKeyRepo.NewPgxClientKeyRepository(pgxpool)
+	refreshRepo := refreshRepo.NewPgxRefreshTokenRepository(pgxpool)
+	signingKeyRepo := signingKeyRepo.NewPgxSigningKeyRepository(pgxpool)
+	revocationRepo := revocationRepo.NewPgxRevocationRepository(pgxpool)
+	authorizationRepo := authorizationRepo.NewPgxAuthorizationCodeRepository(pgxpool)
+	deviceRepo := deviceRepo.NewPgxDeviceAuthorizationRepository(pgxpool)
+	opaqueRepo := opaqueRepo.NewPgxOpaqueTokenRepository(pgxpool)
+	sessionRepo := sessionRepo.NewPgxSessionRepository(pgxpool)
+	passwordHistoryRepo := passwordHistoryRepo.NewPgxPasswordHistoryRepository(pgxpool)
+	loginAttemptRepo := loginAttemptRepo.NewPgxLoginAttemptRepository(pgxpool)
+	mfaRepo := mfaRepo.NewPgxMFARepository(pgxpool)
+	webauthnRepo := webauthnRepo.NewPgxWebAuthnRepository(pgxpool)
+
+	keys := usecase.NewKeySet(staticKeys...)
+	keyRing := usecase.NewKeyRingUseCase(
+		signingKeyRepo,
+		keys,
+		staticKeys,
+		s.config.KeyAlgorithm,
+		time.Duration(s.config.KeyActivation)*time.Second,
+		time.Duration(s.config.SecretExpiration)*time.Second,
+	)
+	if err := keyRing.EnsureActiveKey(ctx); err != nil {
+		return err
+	}
+	go keyRing.Run(ctx, time.Minute)
+	revocationUsecase := usecase.NewRevocationUseCase(revocationRepo, time.Duration(s.config.RevocationCache)*time.Second)
+	go revocationUsecase.Run(ctx, time.Minute)
+	dpopUsecase := usecase.NewDPoPUseCase(time.Duration(s.config.DPoPProofWindow) * time.Second)
+	go dpopUsecase.Run(ctx, time.Minute)
+	opaqueUsecase := usecase.NewOpaqueTokenUseCase(opaqueRepo, time.Duration(s.config.OpaqueTokenCache)*time.Second)
+	go opaqueUsecase.Run(ctx, time.Minute)
+	sessionUsecase := usecase.NewSessionUseCase(sessionRepo, refreshRepo, time.Duration(s.config.RevocationCache)*time.Second)
+	go sessionUsecase.Run(ctx, time.Minute)
+	verifier := usecase.NewTokenVerifier(keys, opaqueUsecase, revocationUsecase, sessionUsecase)
+	auth := middleware.NewAuthenticator(verifier, dpopUsecase, sessionUsecase, s.config.Audience, s.config.BaseURL)
+	issuer := usecase.NewTokenIssuer(keys, opaqueUsecase, s.config.ClaimsNamespace, s.config.ClaimProviders)
+	issuer.Register(usecase.NewStaticClaimProvider(s.config.StaticClaims))
+	issuer.Register(usecase.NewClientOwnerClaimProvider(userRepo))
+	if err := issuer.CheckProviders(s.config.ClaimProviders); err != nil {
+		return err
+	}
 
-	SysUserUseCase := usecase.NewSysUserUseCase(userRepo)
-	userUsecase := usecase.NewUserUseCase(userRepo)
-	clientUsecase := usecase.NewClientUseCase(clientRepo)
+	// bcrypt hashes from before Argon2id keep verifying until their user logs in again
+	hasher := usecase.NewPepperedHasher(usecase.NewFallbackHasher(
+		usecase.NewArgon2idHasher(usecase.Argon2idParams{
+			Memory:      uint32(s.config.Argon2Memory),
+			Iterations:  uint32(s.config.Argon2Iterations),
+			Parallelism: uint8(s.config.Argon2Parallelism),
+		}),
+		usecase.NewBcryptHasher(bcrypt.DefaultCost),
+	), s.config.Peppers)
+	// passwords are only screened against breaches with a corpus configured
+	var breaches breachRepo.IBreachedPasswordRepository
+	if s.config.BreachedPasswordsFile != "" {
+		fileBreachRepo, err := breachRepo.NewFileBreachedPasswordRepository(s.config.BreachedPasswordsFile)
+		if err != nil {
+			return err
+		}
+		breaches = fileBreachRepo
+	}
+	policyUsecase := usecase.NewPasswordPolicyUseCase(domain.PasswordPolicy{
+		MinLength:           s.config.PasswordMinLength,
+		MinCharacterClasses: s.config.PasswordMinCharacterClasses,
+		History:             s.config.PasswordHistory,
+	}, passwordHistoryRepo, breaches, hasher)
+	throttleUsecase := usecase.NewLoginThrottleUseCase(loginAttemptRepo, domain.LoginThrottlePolicy{
+		MaxFailures:      s.config.LoginMaxFailures,
+		MaxFailuresPerIP: s.config.LoginMaxFailuresPerIP,
+		Lockout:          time.Duration(s.config.LoginLockout) * time.Second,
+		MaxLockout:       time.Duration(s.config.LoginMaxLockout) * time.Second,
+		FailureWindow:    time.Duration(s.config.LoginFailureWindow) * time.Second,
+	})
+	go throttleUsecase.Run(ctx, time.Minute)
+	SysUserUseCase := usecase.NewSysUserUseCase(userRepo, refreshRepo, sessionRepo, hasher, policyUsecase, throttleUsecase)
+	userUsecase := usecase.NewUserUseCase(userRepo, hasher, throttleUsecase)
+	clientUsecase := usecase.NewClientUseCase(clientRepo, hasher, throttleUsecase)
+	clientKeyUsecase := usecase.NewClientKeyUseCase(clientKeyRepo, clientRepo)
+	go clientKeyUsecase.Run(ctx, time.Minute)
 	resourcetUsecase := usecase.NewResourceUseCase()
+	refreshUsecase := usecase.NewRefreshTokenUseCase(refreshRepo, userRepo, time.Duration(s.config.RefreshExpiration)*time.Second)
+	introspectionUsecase := usecase.NewIntrospectionUseCase(verifier, userRepo, clientRepo)
+	authorizationUsecase := usecase.NewAuthorizationUseCase(authorizationRepo, clientRepo, userRepo, time.Duration(s.config.AuthorizationCodeExpiration)*time.Second)
+	exchangeUsecase := usecase.NewTokenExchangeUseCase(verifier, userRepo, clientRepo)
+	deviceUsecase := usecase.NewDeviceAuthorizationUseCase(deviceRepo, userRepo, time.Duration(s.config.DeviceCodeExpiration)*time.Second, s.config.DevicePollInterval)
+	go deviceUsecase.Run(ctx, time.Minute)
+	webauthnUsecase := usecase.NewWebAuthnUseCase(webauthnRepo, userRepo, throttleUsecase,
+		domain.WebAuthnRelyingParty{ID: s.config.WebAuthnRPID, Name: s.config.WebAuthnRPName}, s.config.WebAuthnOrigins,
+		time.Duration(s.config.MFAChallengeExpiration)*time.Second,
+	)
+	go webauthnUsecase.Run(ctx, time.Minute)
+	mfaUsecase := usecase.NewMFAUseCase(mfaRepo, userRepo, throttleUsecase, webauthnUsecase, s.config.Issuer, time.Duration(s.config.MFAChallengeExpiration)*time.Second)
+	go mfaUsecase.Run(ctx, time.Minute)
 
-	sysUserControler := controller.NewSysUserControler(SysUserUseCase, s.config)
+	sysUserControler := controller.NewSysUserControler(SysUserUseCase, mfaUsecase, auth, s.config)
 	sysUserControler.RegisterRoutes(s.echo)
 
-	userControler := controller.NewUserControler(userUsecase, s.config)
+	userControler := controller.NewUserControler(userUsecase, mfaUsecase, webauthnUsecase, refreshUsecase, sessionUsecase, issuer, auth, s.config)
 	userControler.RegisterRoutes(s.echo)
 
-	clientControler := controller.NewClientController(clientUsecase, s.config)
+	sessionControler := controller.NewSessionController(sessionUsecase, auth, s.config)
+	sessionControler.RegisterRoutes(s.echo)
+
+	mfaControler := controller.NewMFAController(mfaUsecase, userUsecase, auth, s.config)
+	mfaControler.RegisterRoutes(s.echo)
+
+	webauthnControler := controller.NewWebAuthnController(webauthnUsecase, userUsecase, auth, s.config)
+	webauthnControler.RegisterRoutes(s.echo)
+
+	clientControler := controller.NewClientController(clientUsecase, clientKeyUsecase, dpopUsecase, issuer, auth, s.config)
 	clientControler.RegisterRoutes(s.echo)
 
-	resourceControler := controller.NewResourceControler(resourcetUsecase, s.config)
+	resourceControler := controller.NewResourceControler(resourcetUsecase, auth, s.config)
 	resourceControler.RegisterRoutes(s.echo)
 
+	oauthControler := controller.NewOAuthController(clientUsecase, clientKeyUsecase, refreshUsecase, sessionUsecase, verifier, introspectionUsecase, authorizationUsecase, exchangeUsecase, deviceUsecase, dpopUsecase, issuer, keys, s.config)
+	oauthControler.RegisterRoutes(s.echo)
+
+	authorizeControler := controller.NewAuthorizeController(authorizationUsecase, auth, s.config)
+	authorizeControler.RegisterRoutes(s.echo)
+
+	deviceControler := controller.NewDeviceController(deviceUsecase, auth, s.config)
+	deviceControler.RegisterRoutes(s.echo)
+
+	keyControler := controller.NewKeyController(keys, keyRing, auth, s.config)
+	keyControler.RegisterRoutes(s.echo)
+
+	oidcControler := controller.NewOIDCController(userUsecase, keys, auth, s.config)
+	oidcControler.RegisterRoutes(s.echo)
+
 	s.echo.GET("/swagger/*", echoSwagger.WrapHandler)
 
 	// Channel to capture server start errors
@@ -130,7 +297,7 @@ func (s *EchoServer) Serving(ctx context.Context) error {
 
 	// Start Echo server in a goroutine
 	go func() {
-		errChan <- s.echo.Start(fmt.Sprintf(":%d", s.config.Port))
+		errChan <- s.start()
 	}()
 
 	select {
@@ -144,3 +311,26 @@ func (s *EchoServer) Serving(ctx context.Context) error {
 		return err
 	}
 }
+
+// start listens on the configured port, over TLS when a certificate is configured. Client certificates
+// are requested but not verified in the handshake: self-signed ones are legitimate (RFC 8705 section 2.2),
+// the client usecase decides which client a certificate authenticates.
+func (s *EchoServer) start() error {
+	address := fmt.Sprintf(":%d", s.config.Port)
+	if s.config.TLSCertFile == "" {
+		return s.echo.Start(address)
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.config.TLSCertFile, s.config.TLSKeyFile)
+	if err != nil {
+		return err
+	}
+	// TLSServer is what echo shuts down together with the plain server
+	s.echo.TLSServer.Addr = address
+	s.echo.TLSServer.TLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequestClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+	return s.echo.StartServer(s.echo.TLSServer)
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CodeChallengeS256 is the only PKCE method accepted (RFC 7636 section 4.2).
+const CodeChallengeS256 = "S256"
+
+// AuthorizationRequest is the validated query of the authorization endpoint (RFC 6749 section 4.1.1).
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            uuid.UUID
+	RedirectURI         string
+	Scope               []Permission
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
+}
+
+type AuthorizationCode struct {
+	CodeHash      []byte
+	ClientID      uuid.UUID
+	UserID        uuid.UUID
+	RedirectURI   string
+	Scope         []Permission
+	CodeChallenge string
+	Nonce         string
+	AuthTime      time.Time
+	ExpiresAt     time.Time
+	UsedAt        *time.Time
+	CreatedAt     time.Time
+}
+
+var (
+	// Returned when an authorization code doesnot exists or was already consumed
+	ErrAuthorizationCodeNotFound = errors.New("authorization code is not found")
+
+	// Returned when an authorization code is expired, issued to another client or redirect uri, or fails PKCE
+	ErrAuthorizationCodeInvalid = errors.New("authorization code is invalid")
+
+	// Returned when an authorization request is malformed, e.g. missing PKCE parameters
+	ErrInvalidAuthorizationRequest = errors.New("invalid authorization request")
+
+	// Returned when the response_type is anything but code
+	ErrUnsupportedResponseType = errors.New("unsupported response type")
+
+	// other error occured in authorization code domain, including pg system error
+	ErrGeneralAuthorizationCode = errors.New("general authorization code data")
+)
@@ -0,0 +1,21 @@
+package domain
+
+import "errors"
+
+// ClaimRequest is what a claim provider learns about an access token being issued. User is nil for
+// client tokens, Client is the client the token is issued to and nil for first-party user logins.
+// Claims are the standard claims already set, GrantType is the grant or login the token comes from.
+type ClaimRequest struct {
+	User      *User
+	Client    *Client
+	GrantType string
+	Claims    JwtClaims
+}
+
+var (
+	// Returned when a claim provider sets a reserved claim, it is a configuration error
+	ErrReservedClaim = errors.New("reserved claim")
+
+	// Returned when a client names a claim provider that is not registered
+	ErrUnknownClaimProvider = errors.New("unknown claim provider")
+)
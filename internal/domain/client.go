@@ -6,11 +6,44 @@ import (
 	"github.com/google/uuid"
 )
 
+// Token endpoint authentication methods (RFC 7591 section 2, RFC 8705 section 2, RFC 7523 section 2.2).
+// client_secret_basic clients may send their secret in the request body as well.
+const (
+	AuthMethodClientSecretBasic       = "client_secret_basic"
+	AuthMethodTLSClientAuth           = "tls_client_auth"
+	AuthMethodSelfSignedTLSClientAuth = "self_signed_tls_client_auth"
+	AuthMethodPrivateKeyJWT           = "private_key_jwt"
+)
+
 type Client struct {
-	ID         uuid.UUID    `json:"id" example:"11111111-2222-4444-3333-555555555555"`
-	UserID     uuid.UUID    `json:"userId" example:"11111111-2222-4444-3333-555555555555"`
-	SecretHash []byte       `json:"-"`
-	Scope      []Permission `json:"scope" example:"*"`
+	ID         uuid.UUID `json:"id" example:"11111111-2222-4444-3333-555555555555"`
+	UserID     uuid.UUID `json:"userId" example:"11111111-2222-4444-3333-555555555555"`
+	SecretHash []byte    `json:"-"`
+	// PepperVersion is the version of the pepper SecretHash was made with
+	PepperVersion int          `json:"-"`
+	Scope         []Permission `json:"scope" example:"*"`
+	RedirectURIs  []string     `json:"redirectUris" example:"https://app.example.com/callback"`
+	// Audiences are the other services the client may request tokens for, this server is always allowed
+	Audiences []string `json:"audiences" example:"https://api.example.com"`
+	// DPoPRequired clients only get DPoP-bound tokens, never bearer tokens
+	DPoPRequired bool `json:"dpopRequired" example:"false"`
+	// AuthMethod is how the client authenticates at the token endpoint, only client_secret_basic clients have a secret.
+	// private_key_jwt clients sign assertions with one of their registered keys instead
+	AuthMethod string `json:"tokenEndpointAuthMethod" example:"client_secret_basic"`
+	// TLSSubjectDN and TLSClientCA (PEM) identify the certificate of a tls_client_auth client
+	TLSSubjectDN string `json:"tlsClientAuthSubjectDn,omitempty" example:"CN=service,O=Example"`
+	TLSClientCA  string `json:"tlsClientAuthCa,omitempty"`
+	// TLSCertificateThumbprint is the x5t#S256 of the certificate of a self_signed_tls_client_auth client
+	TLSCertificateThumbprint string `json:"tlsClientCertificateThumbprint,omitempty"`
+	// ClaimProviders name the claim providers adding custom claims to the tokens issued to the client
+	ClaimProviders []string `json:"claimProviders" example:"owner"`
+	// AccessTokenFormat is jwt or opaque, user tokens obtained without a client are always JWTs
+	AccessTokenFormat string `json:"accessTokenFormat" example:"jwt"`
+}
+
+// UsesSecret tells whether the client authenticates with its secret rather than a TLS client certificate.
+func (c *Client) UsesSecret() bool {
+	return c.AuthMethod == "" || c.AuthMethod == AuthMethodClientSecretBasic
 }
 
 var (
@@ -20,6 +53,12 @@ var (
 	// Returned when provided client data violates constraints (e.g., empty name, invalid format)
 	ErrInvalidClientData = errors.New("invalid client data")
 
+	// Returned when a redirect uri is not absolute, has a fragment or is not registered for the client
+	ErrInvalidRedirectURI = errors.New("invalid redirect uri")
+
+	// Returned when a requested resource or audience is not allowed for the client
+	ErrInvalidTarget = errors.New("invalid target")
+
 	// Returned when a client doesnot exists
 	ErrClientNotFound = errors.New("client is not found")
 
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClientAssertionType is the client_assertion_type of a JWT client assertion (RFC 7523 section 2.2).
+const ClientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// ClientKey is a public key a private_key_jwt client signs its assertions with, Kid is unique per client.
+type ClientKey struct {
+	ClientID  uuid.UUID `json:"clientId" example:"11111111-2222-4444-3333-555555555555"`
+	Kid       string    `json:"kid" example:"Gv4qZ8wTb6hV0o3c1m7YpWkQn2sJ5dRrLx9uEaHfBiU"`
+	JWK       JWK       `json:"jwk"`
+	CreatedAt time.Time `json:"createdAt" example:"2025-06-27T15:04:05Z"`
+}
+
+var (
+	// Returned when a client key does not exist
+	ErrClientKeyNotFound = errors.New("client key is not found")
+
+	// Returned when the client already has a key with the same kid
+	ErrClientKeyAlreadyExists = errors.New("client key already exists")
+
+	// Returned when a client assertion is malformed, not signed by a key of the client or replayed
+	ErrInvalidClientAssertion = errors.New("invalid client assertion")
+
+	// other error occured in client key domain, including pg system error
+	ErrGeneralClientKey = errors.New("general client key data")
+)
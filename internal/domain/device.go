@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeviceAuthorizationStatus string
+
+const (
+	DevicePending  DeviceAuthorizationStatus = "pending"
+	DeviceApproved DeviceAuthorizationStatus = "approved"
+	DeviceDenied   DeviceAuthorizationStatus = "denied"
+	DeviceConsumed DeviceAuthorizationStatus = "consumed"
+)
+
+// DeviceAuthorization is a pending or decided request of the device authorization grant (RFC 8628).
+type DeviceAuthorization struct {
+	DeviceCodeHash []byte
+	UserCode       string
+	ClientID       uuid.UUID
+	Scope          []Permission
+	Status         DeviceAuthorizationStatus
+	UserID         *uuid.UUID
+	AuthTime       *time.Time
+	// Interval is the minimum number of seconds between two polls of the token endpoint
+	Interval     int
+	LastPolledAt *time.Time
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+var (
+	// Returned when a device or user code doesnot exists, or the request is no longer pending when deciding on it
+	ErrDeviceAuthorizationNotFound = errors.New("device authorization is not found")
+
+	// Returned when a device code is unknown, issued to another client or already redeemed
+	ErrDeviceCodeInvalid = errors.New("device code is invalid")
+
+	// Returned when a user code is unknown, expired or already decided on
+	ErrUserCodeInvalid = errors.New("user code is invalid")
+
+	// Returned while the user has not decided on the device authorization yet
+	ErrAuthorizationPending = errors.New("authorization pending")
+
+	// Returned when the device polls faster than its interval
+	ErrSlowDown = errors.New("polling too fast")
+
+	// Returned when the device code expired before the user approved it
+	ErrDeviceCodeExpired = errors.New("device code is expired")
+
+	// Returned when the user denied the device authorization
+	ErrDeviceAccessDenied = errors.New("device authorization is denied")
+
+	// other error occured in device authorization domain, including pg system error
+	ErrGeneralDeviceAuthorization = errors.New("general device authorization data")
+)
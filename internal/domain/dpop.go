@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// DPoPProofType is the typ header of a DPoP proof (RFC 9449 section 4.2).
+const DPoPProofType = "dpop+jwt"
+
+// Confirmation is the cnf claim of a sender-constrained token (RFC 7800), JKT is the
+// RFC 7638 thumbprint of the DPoP key the token is bound to, X5tS256 the thumbprint of
+// the TLS client certificate (RFC 8705 section 3.1).
+type Confirmation struct {
+	JKT     string `json:"jkt,omitempty"`
+	X5tS256 string `json:"x5t#S256,omitempty"`
+}
+
+// DPoPProof is the payload of a DPoP proof JWT (RFC 9449 section 4.2).
+type DPoPProof struct {
+	ID         string           `json:"jti"`
+	HTTPMethod string           `json:"htm"`
+	HTTPURI    string           `json:"htu"`
+	IssuedAt   *jwt.NumericDate `json:"iat"`
+	// AccessTokenHash is the base64url SHA-256 of the access token, set on requests to protected resources
+	AccessTokenHash string `json:"ath,omitempty"`
+}
+
+// Valid is left to the DPoP usecase: freshness is judged against its own window, not exp or nbf.
+func (p *DPoPProof) Valid() error {
+	return nil
+}
+
+var (
+	// Returned when a DPoP proof is malformed, badly signed, stale or does not match the request
+	ErrInvalidDPoPProof = errors.New("invalid DPoP proof")
+
+	// Returned when a DPoP proof is presented a second time
+	ErrDPoPProofReplayed = errors.New("DPoP proof replayed")
+
+	// Returned when a client flagged as DPoP required asks for a token without a proof
+	ErrDPoPRequired = errors.New("DPoP proof required")
+)
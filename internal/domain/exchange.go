@@ -0,0 +1,38 @@
+package domain
+
+import "errors"
+
+// Token type identifiers of RFC 8693 section 3.
+const (
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeJWT         = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// TokenExchangeRequest is the token exchange request of RFC 8693 section 2.1.
+type TokenExchangeRequest struct {
+	SubjectToken     string
+	SubjectTokenType string
+	ActorToken       string
+	ActorTokenType   string
+	Scope            []Permission
+	// DPoPKeyThumbprint is the key of the DPoP proof of the request, a DPoP-bound subject or actor
+	// token is only exchanged by its holder
+	DPoPKeyThumbprint string
+	// CertificateThumbprint is the x5t#S256 of the TLS client certificate of the request, a
+	// certificate-bound subject or actor token is only exchanged over that certificate
+	CertificateThumbprint string
+}
+
+// TokenExchange is the outcome of a token exchange: exactly one of User and Client is the subject.
+type TokenExchange struct {
+	Subject *JwtClaims
+	User    *User
+	Client  *Client
+	Scope   []Permission
+	Actor   *Actor
+}
+
+var (
+	// Returned when a subject or actor token is not one of our valid access tokens
+	ErrInvalidExchangeToken = errors.New("invalid token for exchange")
+)
@@ -0,0 +1,19 @@
+package domain
+
+// Introspection is the token introspection response of RFC 7662 section 2.2.
+// Only Active is set for a token that is not active.
+type Introspection struct {
+	Active    bool          `json:"active"`
+	Scope     string        `json:"scope,omitempty" example:"resource:create"`
+	ClientID  string        `json:"client_id,omitempty" example:"11111111-2222-4444-3333-555555555555"`
+	TokenType string        `json:"token_type,omitempty" example:"Bearer"`
+	Exp       int64         `json:"exp,omitempty" example:"1735689600"`
+	Iat       int64         `json:"iat,omitempty" example:"1735688700"`
+	Sub       string        `json:"sub,omitempty" example:"11111111-2222-4444-3333-555555555555"`
+	Iss       string        `json:"iss,omitempty" example:"ClientApp"`
+	Aud       []string      `json:"aud,omitempty" example:"http://localhost:8000"`
+	Jti       string        `json:"jti,omitempty"`
+	Type      JwtType       `json:"type,omitempty" example:"client"`
+	Act       *Actor        `json:"act,omitempty"`
+	Cnf       *Confirmation `json:"cnf,omitempty"`
+}
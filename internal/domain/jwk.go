@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a public JSON Web Key (RFC 7517). Private members are never set.
+type JWK struct {
+	Kty string `json:"kty" example:"EC"`
+	Kid string `json:"kid,omitempty" example:"Gv4qZ8wTb6hV0o3c1m7YpWkQn2sJ5dRrLx9uEaHfBiU"`
+	Use string `json:"use,omitempty" example:"sig"`
+	Alg string `json:"alg,omitempty" example:"ES256"`
+	Crv string `json:"crv,omitempty" example:"P-256"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	// Returned when a key type or curve is not one we sign or verify with
+	ErrUnsupportedKey = errors.New("unsupported key")
+
+	// Returned when a JWK is missing members or carries malformed ones
+	ErrInvalidJWK = errors.New("invalid jwk")
+)
+
+var b64 = base64.RawURLEncoding
+
+// NewJWK describes a public key as a JWK. Kid, Use and Alg are left to the caller.
+func NewJWK(pub crypto.PublicKey) (JWK, error) {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   b64.EncodeToString(key.N.Bytes()),
+			E:   b64.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return JWK{}, fmt.Errorf("%w: curve %s", ErrUnsupportedKey, key.Curve.Params().Name)
+		}
+		raw, err := key.ECDH()
+		if err != nil {
+			return JWK{}, fmt.Errorf("%w: %s", ErrUnsupportedKey, err)
+		}
+		// uncompressed point: 0x04 || X || Y
+		point := raw.Bytes()
+		return JWK{
+			Kty: "EC",
+			Crv: "P-256",
+			X:   b64.EncodeToString(point[1:33]),
+			Y:   b64.EncodeToString(point[33:]),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   b64.EncodeToString(key),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("%w: %T", ErrUnsupportedKey, pub)
+	}
+}
+
+// PublicKey decodes the JWK into an *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, errN := b64.DecodeString(k.N)
+		e, errE := b64.DecodeString(k.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("%w: malformed RSA key", ErrInvalidJWK)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("%w: curve %s", ErrUnsupportedKey, k.Crv)
+		}
+		x, errX := b64.DecodeString(k.X)
+		y, errY := b64.DecodeString(k.Y)
+		if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("%w: malformed EC key", ErrInvalidJWK)
+		}
+		// ecdh rejects points that are not on the curve
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidJWK, err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("%w: curve %s", ErrUnsupportedKey, k.Crv)
+		}
+		x, err := b64.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: malformed OKP key", ErrInvalidJWK)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("%w: kty %s", ErrUnsupportedKey, k.Kty)
+	}
+}
+
+// Thumbprint computes the RFC 7638 SHA-256 thumbprint, base64url encoded.
+func (k JWK) Thumbprint() (string, error) {
+	// required members only, in lexicographic order
+	var members interface{}
+	switch k.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{k.E, k.Kty, k.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{k.Crv, k.Kty, k.X, k.Y}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{k.Crv, k.Kty, k.X}
+	default:
+		return "", fmt.Errorf("%w: kty %s", ErrUnsupportedKey, k.Kty)
+	}
+	raw, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return b64.EncodeToString(sum[:]), nil
+}
@@ -1,12 +1,96 @@
 package domain
 
-import "github.com/golang-jwt/jwt/v4"
+import (
+	"encoding/json"
+
+	"github.com/golang-jwt/jwt/v4"
+)
 
 type JwtClaims struct {
 	Name  string  `json:"name,omitempty"`
 	Scope string  `json:"scope,omitempty"`
 	Type  JwtType `json:"type,omitempty" `
+	// AuthorizedParty is the client acting on behalf of the user, set on tokens issued through the authorization code flow
+	AuthorizedParty string `json:"azp,omitempty"`
+
+	// OpenID Connect claims, AuthTime is also carried by user access tokens so consent can pass it on
+	PreferredUsername string           `json:"preferred_username,omitempty"`
+	Nonce             string           `json:"nonce,omitempty"`
+	AuthTime          *jwt.NumericDate `json:"auth_time,omitempty"`
+	// AMR are the authentication methods of the login (RFC 8176), pwd and, once the second factor passed, otp or hwk and mfa
+	AMR []string `json:"amr,omitempty"`
+
+	// Act records who is acting for the subject after a token exchange
+	Act *Actor `json:"act,omitempty"`
+	// Cnf binds the token to a DPoP key, it is then only accepted with a proof signed by that key
+	Cnf *Confirmation `json:"cnf,omitempty"`
+	// SessionID is the session the token was issued under, the token dies with it
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
+
+	// Custom are the namespaced claims added by claim providers, they are serialized next to the others
+	Custom map[string]interface{} `json:"-"`
+}
+
+// ReservedClaims are the claim names with a meaning to this server, custom claims can never take them.
+var ReservedClaims = map[string]struct{}{
+	"iss": {}, "sub": {}, "aud": {}, "exp": {}, "nbf": {}, "iat": {}, "jti": {},
+	"name": {}, "scope": {}, "type": {}, "azp": {}, "preferred_username": {}, "nonce": {}, "auth_time": {},
+	"act": {}, "cnf": {}, "client_id": {}, "sid": {}, "amr": {},
+}
+
+// jwtClaims has the fields of JwtClaims without its JSON methods.
+type jwtClaims JwtClaims
+
+func (c JwtClaims) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(jwtClaims(c))
+	if err != nil || len(c.Custom) == 0 {
+		return raw, err
+	}
+	merged := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(raw, &merged); err != nil {
+		return nil, err
+	}
+	for name, value := range c.Custom {
+		if _, reserved := ReservedClaims[name]; reserved {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		merged[name] = encoded
+	}
+	return json.Marshal(merged)
+}
+
+// UnmarshalJSON keeps the claims this server does not know in Custom.
+func (c *JwtClaims) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*jwtClaims)(c)); err != nil {
+		return err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	c.Custom = nil
+	for name, value := range all {
+		if _, reserved := ReservedClaims[name]; reserved {
+			continue
+		}
+		if c.Custom == nil {
+			c.Custom = make(map[string]interface{})
+		}
+		c.Custom[name] = value
+	}
+	return nil
+}
+
+// Actor is the act claim of RFC 8693 section 4.1, the outermost actor is the current one
+// and nested actors are the prior ones of the delegation chain.
+type Actor struct {
+	Subject string `json:"sub"`
+	Actor   *Actor `json:"act,omitempty"`
 }
 
 type JwtType string
@@ -14,4 +98,6 @@ type JwtType string
 const (
 	UserType   JwtType = "user"
 	ClientType JwtType = "client"
+	// IDTokenType marks OpenID Connect ID tokens, which must never be accepted as access tokens
+	IDTokenType JwtType = "id_token"
 )
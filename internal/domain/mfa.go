@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Authentication method references of the amr claim (RFC 8176 section 2).
+const (
+	AMRPassword = "pwd"
+	AMROTP      = "otp"
+	AMRMFA      = "mfa"
+)
+
+// TOTP is the time-based one-time password factor of a user (RFC 6238), with the HMAC-SHA1 key
+// shared with the authenticator app.
+type TOTP struct {
+	UserID       uuid.UUID
+	Secret       []byte
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
+
+// MFAChallenge is a login whose password was verified and that waits for the second factor.
+type MFAChallenge struct {
+	ChallengeHash []byte
+	UserID        uuid.UUID
+	// Mode is the login mode the user asked for, token or cookie
+	Mode      string
+	AuthTime  time.Time
+	ExpiresAt time.Time
+}
+
+var (
+	// Returned when enrolling a user who already confirmed a TOTP factor
+	ErrMFAAlreadyEnrolled = errors.New("mfa is already enrolled")
+
+	// Returned when the user has no TOTP factor, or none waiting for confirmation
+	ErrMFANotEnrolled = errors.New("mfa is not enrolled")
+
+	// Returned when a TOTP or recovery code is wrong, was already used or is out of its time window
+	ErrMFACodeInvalid = errors.New("invalid mfa code")
+
+	// Returned when an mfa challenge is unknown, expired or was already answered
+	ErrMFAChallengeInvalid = errors.New("invalid mfa challenge")
+
+	// other error occured in mfa domain, including pg system error
+	ErrGeneralMFA = errors.New("general mfa data")
+)
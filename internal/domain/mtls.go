@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+)
+
+// CertificateThumbprint is the x5t#S256 of a certificate: the base64url SHA-256 of its DER encoding (RFC 8705 section 3.1).
+func CertificateThumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return b64.EncodeToString(sum[:])
+}
+
+var (
+	// Returned when a certificate-bound token is presented without the certificate it is bound to
+	ErrCertificateMismatch = errors.New("certificate does not match the token binding")
+)
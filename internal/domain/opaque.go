@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Access token formats a client can be issued. Opaque tokens are random references resolved by
+// this server, so their holders cannot read the claims and revoking them takes effect at once.
+const (
+	TokenFormatJWT    = "jwt"
+	TokenFormatOpaque = "opaque"
+)
+
+// OpaqueToken is the stored state of an opaque access token, only the hash of the token is kept.
+// Subject, Type and Scope repeat the principal and scope of Claims so they can be queried.
+type OpaqueToken struct {
+	TokenHash []byte
+	Subject   string
+	Type      JwtType
+	Scope     string
+	Claims    JwtClaims
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+var (
+	// Returned when an opaque token doesnot exists
+	ErrOpaqueTokenNotFound = errors.New("opaque token is not found")
+
+	// other error occured in opaque token domain, including pg system error
+	ErrGeneralOpaqueToken = errors.New("general opaque token data")
+
+	// Returned when a presented access token is malformed, expired, unknown or not signed by us
+	ErrInvalidAccessToken = errors.New("invalid access token")
+)
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordPolicy is what a password set by an administrator must satisfy.
+type PasswordPolicy struct {
+	MinLength int
+	// MinCharacterClasses is how many of lowercase letters, uppercase letters, digits and symbols must appear
+	MinCharacterClasses int
+	// History is how many of the latest passwords of a user, the current one included, cannot be reused
+	History int
+}
+
+// PasswordHistoryEntry is a previous password hash of a user, kept to prevent its reuse.
+type PasswordHistoryEntry struct {
+	UserID        uuid.UUID
+	PasswordHash  []byte
+	PepperVersion int
+	CreatedAt     time.Time
+}
+
+// PasswordPolicyError lists every rule of the password policy a password breaks.
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return ErrPasswordPolicy.Error() + ": " + strings.Join(e.Violations, "; ")
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrPasswordPolicy
+}
+
+var (
+	// Returned, as a PasswordPolicyError, when a password breaks the password policy
+	ErrPasswordPolicy = errors.New("password does not meet the password policy")
+
+	// other error occured in password history, including pg system error
+	ErrGeneralPasswordHistory = errors.New("general password history data")
+
+	// Returned when the breached password corpus cannot be read
+	ErrGeneralBreachedPassword = errors.New("general breached password data")
+)
@@ -1,13 +1,72 @@
 package domain
 
+import (
+	"errors"
+	"strings"
+)
+
 type Permission string
 
 const (
 	PermAll            Permission = "*"
 	PermCreateResource Permission = "resource:create"
+	PermIntrospect     Permission = "token:introspect"
+	PermExchange       Permission = "token:exchange"
+	// PermAdmin admits the /admin APIs, only the login tokens of the configured admin accounts hold it
+	PermAdmin Permission = "admin"
+
+	// OpenID Connect scopes, openid admits the userinfo endpoint and profile releases the name claims
+	PermOpenID  Permission = "openid"
+	PermProfile Permission = "profile"
 )
 
 var ValidPermissions = map[Permission]struct{}{
 	PermAll:            {},
 	PermCreateResource: {},
+	PermIntrospect:     {},
+	PermExchange:       {},
+	PermAdmin:          {},
+	PermOpenID:         {},
+	PermProfile:        {},
+}
+
+// PrivilegedPermissions are not granted by "*", they must be held by name. Users cannot give them to
+// their own clients, only administrators register clients holding them and admin is never granted.
+var PrivilegedPermissions = map[Permission]struct{}{
+	PermIntrospect: {},
+	PermExchange:   {},
+	PermAdmin:      {},
+}
+
+var (
+	// Returned when a requested scope is unknown or exceeds the granted scope
+	ErrInvalidScope = errors.New("invalid scope")
+)
+
+// Grants reports whether holding p satisfies the required permission.
+func (p Permission) Grants(required Permission) bool {
+	if p == required {
+		return true
+	}
+	_, privileged := PrivilegedPermissions[required]
+	return p == PermAll && !privileged
+}
+
+// ParseScope splits a space-delimited scope string (RFC 6749 section 3.3).
+func ParseScope(scope string) []Permission {
+	fields := strings.Fields(scope)
+	perms := make([]Permission, len(fields))
+	for i, f := range fields {
+		perms[i] = Permission(f)
+	}
+	return perms
+}
+
+// FormatScope joins permissions into a space-delimited scope string.
+func FormatScope(perms []Permission) string {
+	fields := make([]string, len(perms))
+	for i, p := range perms {
+		fields[i] = string(p)
+	}
+	return strings.Join(fields, " ")
 }
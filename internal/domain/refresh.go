@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshToken struct {
+	ID        uuid.UUID
+	FamilyID  uuid.UUID
+	UserID    uuid.UUID
+	TokenHash []byte
+	AuthTime  time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+var (
+	// Returned when a refresh token doesnot exists
+	ErrRefreshTokenNotFound = errors.New("refresh token is not found")
+
+	// Returned when a refresh token is unknown, expired or revoked
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+
+	// Returned when an already rotated refresh token is presented again, the whole family is revoked
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+	// other error occured in refresh token domain, including pg system error
+	ErrGeneralRefreshToken = errors.New("general refresh token data")
+)
@@ -0,0 +1,11 @@
+package domain
+
+import "errors"
+
+var (
+	// Returned when a presented token was revoked before it expired
+	ErrTokenRevoked = errors.New("token has been revoked")
+
+	// other error occured in revocation domain, including pg system error
+	ErrGeneralRevocation = errors.New("general revocation data")
+)
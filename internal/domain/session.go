@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a login of a user, or a grant of a client acting for the user. Access tokens name it in
+// their sid claim and a first-party login shares its ID with its refresh token family, so revoking the
+// session signs out every token issued under it.
+type Session struct {
+	ID         uuid.UUID  `json:"id" example:"11111111-2222-4444-3333-555555555555"`
+	UserID     uuid.UUID  `json:"-"`
+	ClientID   *uuid.UUID `json:"clientId,omitempty" example:"11111111-2222-4444-3333-555555555555"`
+	UserAgent  string     `json:"userAgent" example:"Mozilla/5.0"`
+	IPAddress  string     `json:"ipAddress" example:"203.0.113.7"`
+	CreatedAt  time.Time  `json:"issuedAt"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"-"`
+	// CookieHash and CSRFHash are set for browser sessions of the web console, only hashes are stored
+	CookieHash []byte `json:"-"`
+	CSRFHash   []byte `json:"-"`
+	// AMR are the authentication methods of the login that started the session
+	AMR []string `json:"amr,omitempty" example:"pwd,otp,mfa"`
+	// Current marks the session of the token the list was requested with
+	Current bool `json:"current"`
+}
+
+var (
+	// Returned when a session doesnot exists or belongs to another user
+	ErrSessionNotFound = errors.New("session is not found")
+
+	// Returned when a state-changing request of a browser session lacks its CSRF token
+	ErrCSRFTokenInvalid = errors.New("invalid csrf token")
+
+	// other error occured in session domain, including pg system error
+	ErrGeneralSession = errors.New("general session data")
+)
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"crypto"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Signing algorithms we issue tokens with.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+type KeyState string
+
+const (
+	// published in the JWKS but not signing yet, so verifiers can cache it ahead of time
+	KeyNext KeyState = "next"
+	// signing new tokens
+	KeyActive KeyState = "active"
+	// no longer signing, still verifying until the tokens it signed have expired
+	KeyRetired KeyState = "retired"
+)
+
+type SigningKey struct {
+	KID       string `json:"kid" example:"Gv4qZ8wTb6hV0o3c1m7YpWkQn2sJ5dRrLx9uEaHfBiU"`
+	Algorithm string `json:"alg" example:"ES256"`
+
+	// Key is a crypto.Signer for asymmetric algorithms and the shared secret ([]byte) for HS256.
+	Key interface{} `json:"-"`
+
+	// Keys configured statically have zero timestamps and stay active forever.
+	CreatedAt   time.Time  `json:"createdAt"`
+	ActivatesAt time.Time  `json:"activatesAt"`
+	RetiredAt   *time.Time `json:"retiredAt,omitempty"`
+}
+
+var (
+	// Returned when a token references a key that is not in the key set
+	ErrSigningKeyNotFound = errors.New("signing key is not found")
+
+	// other error occured in signing key domain, including pg system error
+	ErrGeneralSigningKey = errors.New("general signing key data")
+)
+
+func (k SigningKey) Method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// Symmetric reports whether the key is a shared secret, which must never be published.
+func (k SigningKey) Symmetric() bool {
+	return k.Algorithm == AlgHS256
+}
+
+// VerificationKey returns what jwt needs to verify a signature made with this key.
+func (k SigningKey) VerificationKey() interface{} {
+	if signer, ok := k.Key.(crypto.Signer); ok {
+		return signer.Public()
+	}
+	return k.Key
+}
+
+func (k SigningKey) StateAt(now time.Time) KeyState {
+	if now.Before(k.ActivatesAt) {
+		return KeyNext
+	}
+	if k.RetiredAt != nil && !now.Before(*k.RetiredAt) {
+		return KeyRetired
+	}
+	return KeyActive
+}
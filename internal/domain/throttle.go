@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// LoginThrottlePolicy is when failed logins lock an account, a client or a source IP out. Once the
+// failures reach a threshold every further one locks out for twice as long, up to MaxLockout.
+type LoginThrottlePolicy struct {
+	MaxFailures      int
+	MaxFailuresPerIP int
+	Lockout          time.Duration
+	MaxLockout       time.Duration
+	// FailureWindow is how long after the last failure, and the end of its lockout, failures are forgotten
+	FailureWindow time.Duration
+}
+
+// LoginAttempt counts the failed logins under a key, such as an account name or a source IP.
+type LoginAttempt struct {
+	Key           string
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   *time.Time
+}
+
+// LoginThrottledError is returned instead of trying a login while it is locked out. It is also the
+// login failure Err, so callers not telling the two apart learn nothing about the lockout.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *LoginThrottledError) Error() string {
+	return ErrLoginThrottled.Error()
+}
+
+func (e *LoginThrottledError) Unwrap() []error {
+	return []error{ErrLoginThrottled, e.Err}
+}
+
+var (
+	// Returned, as a LoginThrottledError, when too many logins failed
+	ErrLoginThrottled = errors.New("too many failed logins, retry later")
+
+	// other error occured in login attempts, including pg system error
+	ErrGeneralLoginAttempt = errors.New("general login attempt data")
+)
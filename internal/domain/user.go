@@ -11,6 +11,8 @@ type User struct {
 	Name         string    `json:"name" example:"John Doe"`
 	Account      string    `json:"account" example:"johndoe123"`
 	PasswordHash []byte    `json:"-"` // typically not included in JSON responses
+	// PepperVersion is the version of the pepper PasswordHash was made with
+	PepperVersion int `json:"-"`
 }
 
 var (
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Authentication method reference of a login with a WebAuthn credential (RFC 8176 section 2), the
+// private key never leaves the authenticator.
+const AMRHardwareKey = "hwk"
+
+// Second factors a login with mfa_required can be answered with.
+const (
+	MFAMethodTOTP     = "totp"
+	MFAMethodWebAuthn = "webauthn"
+)
+
+// WebAuthn ceremonies, the type of the client data signed by the authenticator.
+const (
+	WebAuthnCreate = "webauthn.create"
+	WebAuthnGet    = "webauthn.get"
+)
+
+// WebAuthnCredential is a passkey or security key of a user. PublicKey is the COSE key the
+// authenticator generated at registration, SignCount its signature counter as last seen.
+type WebAuthnCredential struct {
+	ID         Base64URL  `json:"id" swaggertype:"string" format:"base64url" example:"AQIDBAUGBwgJCgsMDQ4PEA"`
+	UserID     uuid.UUID  `json:"-"`
+	Name       string     `json:"name" example:"YubiKey 5"`
+	PublicKey  []byte     `json:"-"`
+	Algorithm  int        `json:"alg" example:"-7"`
+	SignCount  uint32     `json:"-"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// Base64URL is binary data that is unpadded base64url in JSON, like the binary fields of WebAuthn JSON.
+type Base64URL []byte
+
+func (b Base64URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// WebAuthnChallenge is the random challenge of a ceremony, bound to the user it was issued for.
+// UserID is nil for passwordless logins, where the authenticator tells who the user is.
+type WebAuthnChallenge struct {
+	ChallengeHash []byte
+	UserID        *uuid.UUID
+	Ceremony      string
+	ExpiresAt     time.Time
+}
+
+// WebAuthnAttestation is the response of navigator.credentials.create(), decoded from base64url.
+type WebAuthnAttestation struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AttestationObject []byte
+}
+
+// WebAuthnAssertion is the response of navigator.credentials.get(), decoded from base64url.
+type WebAuthnAssertion struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+	UserHandle        []byte
+}
+
+// WebAuthnCredentialDescriptor names a credential in creation and request options.
+type WebAuthnCredentialDescriptor struct {
+	Type string `json:"type" example:"public-key"`
+	ID   string `json:"id" example:"AQIDBAUGBwgJCgsMDQ4PEA"`
+}
+
+type WebAuthnRelyingParty struct {
+	ID   string `json:"id" example:"localhost"`
+	Name string `json:"name" example:"ClientApp"`
+}
+
+type WebAuthnUserEntity struct {
+	// ID is the user handle, the base64url encoded bytes of the user ID
+	ID          string `json:"id" example:"ESIzRFVmd4iZqrvM3e7_AA"`
+	Name        string `json:"name" example:"alice"`
+	DisplayName string `json:"displayName" example:"Alice"`
+}
+
+type WebAuthnCredentialParameter struct {
+	Type string `json:"type" example:"public-key"`
+	Alg  int    `json:"alg" example:"-7"`
+}
+
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey" example:"preferred"`
+	UserVerification string `json:"userVerification" example:"preferred"`
+}
+
+// WebAuthnCreationOptions are the publicKey options of navigator.credentials.create(), in the JSON form
+// of PublicKeyCredential.parseCreationOptionsFromJSON().
+type WebAuthnCreationOptions struct {
+	RP                     WebAuthnRelyingParty           `json:"rp"`
+	User                   WebAuthnUserEntity             `json:"user"`
+	Challenge              string                         `json:"challenge" example:"3q2-7wEjRWeJq83v8SNFZ4mrze8BI0VniavN7wEjRWc"`
+	PubKeyCredParams       []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                          `json:"timeout" example:"300000"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation" example:"none"`
+}
+
+// WebAuthnRequestOptions are the publicKey options of navigator.credentials.get(), in the JSON form
+// of PublicKeyCredential.parseRequestOptionsFromJSON().
+type WebAuthnRequestOptions struct {
+	Challenge        string                         `json:"challenge" example:"3q2-7wEjRWeJq83v8SNFZ4mrze8BI0VniavN7wEjRWc"`
+	Timeout          int64                          `json:"timeout" example:"300000"`
+	RPID             string                         `json:"rpId" example:"localhost"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                         `json:"userVerification" example:"required"`
+}
+
+var (
+	// Returned when a credential doesnot exists or belongs to another user
+	ErrWebAuthnCredentialNotFound = errors.New("webauthn credential is not found")
+
+	// Returned when registering a credential that is already registered
+	ErrWebAuthnCredentialExists = errors.New("webauthn credential is already registered")
+
+	// Returned when a WebAuthn response does not verify: wrong origin, relying party, signature or counter
+	ErrWebAuthnInvalid = errors.New("invalid webauthn response")
+
+	// Returned when a WebAuthn challenge is unknown, expired, already used or issued for another ceremony
+	ErrWebAuthnChallengeInvalid = errors.New("invalid webauthn challenge")
+
+	// other error occured in webauthn domain, including pg system error
+	ErrGeneralWebAuthn = errors.New("general webauthn data")
+)
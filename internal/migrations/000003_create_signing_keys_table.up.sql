@@ -0,0 +1,9 @@
+-- signing_keys table, a key is "next" before activates_at and "retired" after retired_at
+CREATE TABLE signing_keys (
+    kid TEXT PRIMARY KEY,
+    algorithm TEXT NOT NULL,
+    private_key BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    activates_at TIMESTAMPTZ NOT NULL,
+    retired_at TIMESTAMPTZ
+);
@@ -0,0 +1,3 @@
+DROP TABLE authorization_codes;
+
+ALTER TABLE clients DROP COLUMN redirect_uris;
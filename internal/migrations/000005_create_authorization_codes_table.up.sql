@@ -0,0 +1,17 @@
+-- redirect URIs registered for the authorization code flow, compared by exact match
+ALTER TABLE clients ADD COLUMN redirect_uris TEXT[] NOT NULL DEFAULT '{}';
+
+-- authorization_codes table, only the hash of a code is stored and it can be consumed once
+CREATE TABLE authorization_codes (
+    code_hash BYTEA PRIMARY KEY,
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT[] NOT NULL,
+    code_challenge TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX authorization_codes_expires_at_idx ON authorization_codes (expires_at);
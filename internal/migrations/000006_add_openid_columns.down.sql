@@ -0,0 +1,4 @@
+ALTER TABLE authorization_codes DROP COLUMN auth_time;
+ALTER TABLE authorization_codes DROP COLUMN nonce;
+
+ALTER TABLE refresh_tokens DROP COLUMN auth_time;
@@ -0,0 +1,8 @@
+-- auth_time is when the user actually logged in, carried along every rotation of a refresh token family
+ALTER TABLE refresh_tokens ADD COLUMN auth_time TIMESTAMPTZ;
+UPDATE refresh_tokens SET auth_time = created_at;
+ALTER TABLE refresh_tokens ALTER COLUMN auth_time SET NOT NULL;
+
+-- OpenID Connect request parameters remembered until the code is redeemed
+ALTER TABLE authorization_codes ADD COLUMN nonce TEXT NOT NULL DEFAULT '';
+ALTER TABLE authorization_codes ADD COLUMN auth_time TIMESTAMPTZ NOT NULL DEFAULT now();
@@ -0,0 +1,2 @@
+-- audiences a client may request tokens for besides this server (RFC 8707 resource indicators)
+ALTER TABLE clients ADD COLUMN audiences TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,17 @@
+-- device_authorizations table (RFC 8628), only the hash of a device code is stored.
+-- user_id, scope and auth_time are filled in when the user decides on the verification page.
+CREATE TABLE device_authorizations (
+    device_code_hash BYTEA PRIMARY KEY,
+    user_code TEXT NOT NULL UNIQUE,
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    scope TEXT[] NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    auth_time TIMESTAMPTZ,
+    poll_interval INTEGER NOT NULL,
+    last_polled_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX device_authorizations_expires_at_idx ON device_authorizations (expires_at);
@@ -0,0 +1,2 @@
+-- clients flagged as DPoP required are never issued bearer tokens
+ALTER TABLE clients ADD COLUMN dpop_required BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,6 @@
+DELETE FROM clients WHERE secret_hash IS NULL;
+ALTER TABLE clients DROP COLUMN tls_client_certificate_thumbprint;
+ALTER TABLE clients DROP COLUMN tls_client_auth_ca;
+ALTER TABLE clients DROP COLUMN tls_client_auth_subject_dn;
+ALTER TABLE clients DROP COLUMN token_endpoint_auth_method;
+ALTER TABLE clients ALTER COLUMN secret_hash SET NOT NULL;
@@ -0,0 +1,6 @@
+-- clients authenticating with a TLS client certificate (RFC 8705) have no secret
+ALTER TABLE clients ALTER COLUMN secret_hash DROP NOT NULL;
+ALTER TABLE clients ADD COLUMN token_endpoint_auth_method TEXT NOT NULL DEFAULT 'client_secret_basic';
+ALTER TABLE clients ADD COLUMN tls_client_auth_subject_dn TEXT NOT NULL DEFAULT '';
+ALTER TABLE clients ADD COLUMN tls_client_auth_ca TEXT NOT NULL DEFAULT '';
+ALTER TABLE clients ADD COLUMN tls_client_certificate_thumbprint TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+-- client_keys table, public JWKs a private_key_jwt client signs its assertions with (RFC 7523)
+CREATE TABLE client_keys (
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    kid TEXT NOT NULL,
+    jwk JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (client_id, kid)
+);
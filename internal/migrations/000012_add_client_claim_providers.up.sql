@@ -0,0 +1,2 @@
+-- claim providers adding custom claims to the tokens of the client, existing clients get none
+ALTER TABLE clients ADD COLUMN claim_providers TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+ALTER TABLE clients DROP COLUMN access_token_format;
+DROP TABLE IF EXISTS opaque_tokens;
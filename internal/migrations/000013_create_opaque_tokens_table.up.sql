@@ -0,0 +1,17 @@
+-- opaque_tokens table, only the hash of a token is stored; claims holds the whole claim set it resolves to
+CREATE TABLE opaque_tokens (
+    token_hash BYTEA PRIMARY KEY,
+    subject TEXT NOT NULL,
+    type TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    claims JSONB NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX opaque_tokens_subject_idx ON opaque_tokens (subject);
+CREATE INDEX opaque_tokens_expires_at_idx ON opaque_tokens (expires_at);
+
+-- access token format of the client, existing clients keep getting JWTs
+ALTER TABLE clients ADD COLUMN access_token_format TEXT NOT NULL DEFAULT 'jwt';
@@ -0,0 +1,2 @@
+ALTER TABLE sessions DROP COLUMN csrf_hash;
+ALTER TABLE sessions DROP COLUMN cookie_hash;
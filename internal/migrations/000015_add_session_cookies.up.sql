@@ -0,0 +1,4 @@
+-- browser sessions of the web console are found by the hash of their cookie,
+-- the hash of their CSRF token is checked on state-changing requests
+ALTER TABLE sessions ADD COLUMN cookie_hash BYTEA UNIQUE;
+ALTER TABLE sessions ADD COLUMN csrf_hash BYTEA;
@@ -0,0 +1,2 @@
+ALTER TABLE clients DROP COLUMN pepper_version;
+ALTER TABLE users DROP COLUMN pepper_version;
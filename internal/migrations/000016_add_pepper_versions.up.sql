@@ -0,0 +1,3 @@
+-- version of the pepper each hash was made with, 0 is the pepper used before peppers were versioned
+ALTER TABLE users ADD COLUMN pepper_version INT NOT NULL DEFAULT 0;
+ALTER TABLE clients ADD COLUMN pepper_version INT NOT NULL DEFAULT 0;
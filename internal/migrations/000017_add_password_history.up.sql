@@ -0,0 +1,10 @@
+-- previous password hashes of each user, checked so a password change cannot reuse a recent one
+CREATE TABLE password_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash BYTEA NOT NULL,
+    pepper_version INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX password_history_user_id_idx ON password_history (user_id, id DESC);
@@ -0,0 +1,9 @@
+-- failed logins per account, client or source ip, shared by every replica
+CREATE TABLE login_attempts (
+    key TEXT PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ
+);
+
+CREATE INDEX login_attempts_last_failure_at_idx ON login_attempts (last_failure_at);
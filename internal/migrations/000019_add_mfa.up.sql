@@ -0,0 +1,33 @@
+-- TOTP factor of a user, it only counts once confirmed_at is set by a first valid code
+CREATE TABLE user_totp (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret BYTEA NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    -- time step of the last accepted code, a code is never accepted twice
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- single-use recovery codes, only their hashes are stored
+CREATE TABLE recovery_codes (
+    code_hash BYTEA PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX recovery_codes_user_id_idx ON recovery_codes (user_id);
+
+-- logins waiting for their second factor, only hashes of the challenge tokens are stored
+CREATE TABLE mfa_challenges (
+    challenge_hash BYTEA PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    mode TEXT NOT NULL,
+    auth_time TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX mfa_challenges_expires_at_idx ON mfa_challenges (expires_at);
+
+-- authentication methods of the login that started the session (RFC 8176)
+ALTER TABLE sessions ADD COLUMN amr TEXT[];
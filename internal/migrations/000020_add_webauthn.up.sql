@@ -0,0 +1,23 @@
+-- WebAuthn credentials of a user, the public key is the COSE key of the authenticator
+CREATE TABLE webauthn_credentials (
+    id BYTEA PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    public_key BYTEA NOT NULL,
+    algorithm INTEGER NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX webauthn_credentials_user_id_idx ON webauthn_credentials (user_id);
+
+-- challenges of pending ceremonies, user_id is null for passwordless logins
+CREATE TABLE webauthn_challenges (
+    challenge_hash BYTEA PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    ceremony TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX webauthn_challenges_expires_at_idx ON webauthn_challenges (expires_at);
@@ -0,0 +1,14 @@
+package authorization
+
+import (
+	"context"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+)
+
+type IAuthorizationCodeRepository interface {
+	CreateAuthorizationCode(ctx context.Context, code *domain.AuthorizationCode) (*domain.AuthorizationCode, error)
+
+	// ConsumeAuthorizationCode marks the code used and returns it, a code can be consumed only once.
+	ConsumeAuthorizationCode(ctx context.Context, codeHash []byte) (*domain.AuthorizationCode, error)
+}
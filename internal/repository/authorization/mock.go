@@ -0,0 +1,155 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package authorization
+
+import (
+	context "context"
+
+	domain "github.com/bright-pentium/go-client-practice/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockAuthorizationCodeRepository is an autogenerated mock type for the IAuthorizationCodeRepository type
+type MockAuthorizationCodeRepository struct {
+	mock.Mock
+}
+
+type MockAuthorizationCodeRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAuthorizationCodeRepository) EXPECT() *MockAuthorizationCodeRepository_Expecter {
+	return &MockAuthorizationCodeRepository_Expecter{mock: &_m.Mock}
+}
+
+// ConsumeAuthorizationCode provides a mock function with given fields: ctx, codeHash
+func (_m *MockAuthorizationCodeRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash []byte) (*domain.AuthorizationCode, error) {
+	ret := _m.Called(ctx, codeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeAuthorizationCode")
+	}
+
+	var r0 *domain.AuthorizationCode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*domain.AuthorizationCode, error)); ok {
+		return rf(ctx, codeHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *domain.AuthorizationCode); ok {
+		r0 = rf(ctx, codeHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuthorizationCode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, codeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthorizationCodeRepository_ConsumeAuthorizationCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeAuthorizationCode'
+type MockAuthorizationCodeRepository_ConsumeAuthorizationCode_Call struct {
+	*mock.Call
+}
+
+// ConsumeAuthorizationCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - codeHash []byte
+func (_e *MockAuthorizationCodeRepository_Expecter) ConsumeAuthorizationCode(ctx interface{}, codeHash interface{}) *MockAuthorizationCodeRepository_ConsumeAuthorizationCode_Call {
+	return &MockAuthorizationCodeRepository_ConsumeAuthorizationCode_Call{Call: _e.mock.On("ConsumeAuthorizationCode", ctx, codeHash)}
+}
+
+func (_c *MockAuthorizationCodeRepository_ConsumeAuthorizationCode_Call) Run(run func(ctx context.Context, codeHash []byte)) *MockAuthorizationCodeRepository_ConsumeAuthorizationCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockAuthorizationCodeRepository_ConsumeAuthorizationCode_Call) Return(_a0 *domain.AuthorizationCode, _a1 error) *MockAuthorizationCodeRepository_ConsumeAuthorizationCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthorizationCodeRepository_ConsumeAuthorizationCode_Call) RunAndReturn(run func(context.Context, []byte) (*domain.AuthorizationCode, error)) *MockAuthorizationCodeRepository_ConsumeAuthorizationCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateAuthorizationCode provides a mock function with given fields: ctx, code
+func (_m *MockAuthorizationCodeRepository) CreateAuthorizationCode(ctx context.Context, code *domain.AuthorizationCode) (*domain.AuthorizationCode, error) {
+	ret := _m.Called(ctx, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAuthorizationCode")
+	}
+
+	var r0 *domain.AuthorizationCode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuthorizationCode) (*domain.AuthorizationCode, error)); ok {
+		return rf(ctx, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.AuthorizationCode) *domain.AuthorizationCode); ok {
+		r0 = rf(ctx, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuthorizationCode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.AuthorizationCode) error); ok {
+		r1 = rf(ctx, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthorizationCodeRepository_CreateAuthorizationCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAuthorizationCode'
+type MockAuthorizationCodeRepository_CreateAuthorizationCode_Call struct {
+	*mock.Call
+}
+
+// CreateAuthorizationCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - code *domain.AuthorizationCode
+func (_e *MockAuthorizationCodeRepository_Expecter) CreateAuthorizationCode(ctx interface{}, code interface{}) *MockAuthorizationCodeRepository_CreateAuthorizationCode_Call {
+	return &MockAuthorizationCodeRepository_CreateAuthorizationCode_Call{Call: _e.mock.On("CreateAuthorizationCode", ctx, code)}
+}
+
+func (_c *MockAuthorizationCodeRepository_CreateAuthorizationCode_Call) Run(run func(ctx context.Context, code *domain.AuthorizationCode)) *MockAuthorizationCodeRepository_CreateAuthorizationCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.AuthorizationCode))
+	})
+	return _c
+}
+
+func (_c *MockAuthorizationCodeRepository_CreateAuthorizationCode_Call) Return(_a0 *domain.AuthorizationCode, _a1 error) *MockAuthorizationCodeRepository_CreateAuthorizationCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthorizationCodeRepository_CreateAuthorizationCode_Call) RunAndReturn(run func(context.Context, *domain.AuthorizationCode) (*domain.AuthorizationCode, error)) *MockAuthorizationCodeRepository_CreateAuthorizationCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockAuthorizationCodeRepository creates a new instance of MockAuthorizationCodeRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAuthorizationCodeRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAuthorizationCodeRepository {
+	mock := &MockAuthorizationCodeRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
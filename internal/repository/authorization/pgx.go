@@ -0,0 +1,63 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxAuthorizationCodeRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxAuthorizationCodeRepository(dbpool *pgxpool.Pool) *PgxAuthorizationCodeRepository {
+	return &PgxAuthorizationCodeRepository{
+		dbpool: dbpool,
+	}
+}
+
+// authorizationCodeColumns is the column list every query returns, in the order scanAuthorizationCode reads it.
+const authorizationCodeColumns = `code_hash, client_id, user_id, redirect_uri, scope, code_challenge, nonce, auth_time, expires_at, used_at, created_at`
+
+func scanAuthorizationCode(row pgx.Row) (*domain.AuthorizationCode, error) {
+	var code domain.AuthorizationCode
+	err := row.Scan(
+		&code.CodeHash, &code.ClientID, &code.UserID, &code.RedirectURI, &code.Scope, &code.CodeChallenge,
+		&code.Nonce, &code.AuthTime, &code.ExpiresAt, &code.UsedAt, &code.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (repo *PgxAuthorizationCodeRepository) CreateAuthorizationCode(ctx context.Context, code *domain.AuthorizationCode) (*domain.AuthorizationCode, error) {
+	errfmt := "%w: %s"
+	query := `INSERT INTO authorization_codes (code_hash, client_id, user_id, redirect_uri, scope, code_challenge, nonce, auth_time, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING ` + authorizationCodeColumns
+	created, err := scanAuthorizationCode(repo.dbpool.QueryRow(
+		ctx, query, code.CodeHash, code.ClientID, code.UserID, code.RedirectURI, code.Scope, code.CodeChallenge, code.Nonce, code.AuthTime, code.ExpiresAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralAuthorizationCode, err.Error())
+	}
+	return created, nil
+}
+
+func (repo *PgxAuthorizationCodeRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash []byte) (*domain.AuthorizationCode, error) {
+	errfmt := "%w: %s"
+	// the used_at guard makes concurrent redemptions of the same code race to a single winner
+	query := `UPDATE authorization_codes SET used_at = now() WHERE code_hash = $1 AND used_at IS NULL RETURNING ` + authorizationCodeColumns
+	code, err := scanAuthorizationCode(repo.dbpool.QueryRow(ctx, query, codeHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(errfmt, domain.ErrAuthorizationCodeNotFound, err.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralAuthorizationCode, err.Error())
+	}
+	return code, nil
+}
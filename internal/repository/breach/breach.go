@@ -0,0 +1,11 @@
+package breach
+
+import (
+	"context"
+	"crypto/sha1"
+)
+
+type IBreachedPasswordRepository interface {
+	// CountBreaches returns how often the password of the SHA-1 hash appeared in known data breaches, 0 if never
+	CountBreaches(ctx context.Context, hash [sha1.Size]byte) (int, error)
+}
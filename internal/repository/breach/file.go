@@ -0,0 +1,118 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+)
+
+// prefixLength is the length in hex digits of the prefixes of the range API, 16^5 ranges in total.
+const (
+	prefixLength = 5
+	prefixCount  = 1 << (4 * prefixLength)
+)
+
+// FileBreachedPasswordRepository looks passwords up in an offline copy of the Pwned Passwords corpus,
+// as the PwnedPasswordsDownloader writes it: the responses of every range sorted by prefix, each line
+// being the prefix and the suffix of a SHA-1 hash in uppercase hex followed by ':' and a count.
+// The file is indexed once by prefix, a lookup only reads the range of its hash.
+type FileBreachedPasswordRepository struct {
+	file *os.File
+	// offsets[p] is where range p starts and offsets[p+1] where it ends
+	offsets []int64
+}
+
+func NewFileBreachedPasswordRepository(path string) (*FileBreachedPasswordRepository, error) {
+	errfmt := "%w: %s"
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralBreachedPassword, err.Error())
+	}
+	offsets, err := indexRanges(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralBreachedPassword, fmt.Sprintf("%s: %s", path, err.Error()))
+	}
+	return &FileBreachedPasswordRepository{file: file, offsets: offsets}, nil
+}
+
+func indexRanges(file *os.File) ([]int64, error) {
+	offsets := make([]int64, prefixCount+1)
+	reader := bufio.NewReaderSize(file, 1<<20)
+	var offset int64
+	next := 0 // the first range whose start is not known yet
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			prefix, perr := parsePrefix(line)
+			if perr != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNumber, perr.Error())
+			}
+			if prefix < next-1 {
+				return nil, fmt.Errorf("line %d: hashes are not sorted", lineNumber)
+			}
+			for ; next <= prefix; next++ {
+				offsets[next] = offset
+			}
+			offset += int64(len(line))
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for ; next <= prefixCount; next++ {
+		offsets[next] = offset
+	}
+	return offsets, nil
+}
+
+func parsePrefix(line []byte) (int, error) {
+	hash, _, ok := bytes.Cut(bytes.TrimSpace(line), []byte(":"))
+	if !ok || len(hash) != 2*sha1.Size {
+		return 0, fmt.Errorf("expected a SHA-1 hash and a count")
+	}
+	prefix, err := strconv.ParseUint(string(hash[:prefixLength]), 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("malformed SHA-1 hash")
+	}
+	return int(prefix), nil
+}
+
+func (repo *FileBreachedPasswordRepository) CountBreaches(ctx context.Context, hash [sha1.Size]byte) (int, error) {
+	errfmt := "%w: %s"
+	encoded := bytes.ToUpper([]byte(hex.EncodeToString(hash[:])))
+	prefix, err := strconv.ParseUint(string(encoded[:prefixLength]), 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf(errfmt, domain.ErrGeneralBreachedPassword, err.Error())
+	}
+
+	start, end := repo.offsets[prefix], repo.offsets[prefix+1]
+	section := make([]byte, end-start)
+	if _, err := repo.file.ReadAt(section, start); err != nil && !errors.Is(err, io.EOF) {
+		return 0, fmt.Errorf(errfmt, domain.ErrGeneralBreachedPassword, err.Error())
+	}
+	for _, line := range bytes.Split(section, []byte("\n")) {
+		candidate, rawCount, ok := bytes.Cut(bytes.TrimSpace(line), []byte(":"))
+		if !ok || !bytes.EqualFold(candidate, encoded) {
+			continue
+		}
+		count, err := strconv.Atoi(string(rawCount))
+		if err != nil {
+			return 0, fmt.Errorf(errfmt, domain.ErrGeneralBreachedPassword, err.Error())
+		}
+		return count, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,93 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package breach
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockBreachedPasswordRepository is an autogenerated mock type for the IBreachedPasswordRepository type
+type MockBreachedPasswordRepository struct {
+	mock.Mock
+}
+
+type MockBreachedPasswordRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockBreachedPasswordRepository) EXPECT() *MockBreachedPasswordRepository_Expecter {
+	return &MockBreachedPasswordRepository_Expecter{mock: &_m.Mock}
+}
+
+// CountBreaches provides a mock function with given fields: ctx, hash
+func (_m *MockBreachedPasswordRepository) CountBreaches(ctx context.Context, hash [20]byte) (int, error) {
+	ret := _m.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountBreaches")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, [20]byte) (int, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, [20]byte) int); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, [20]byte) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBreachedPasswordRepository_CountBreaches_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountBreaches'
+type MockBreachedPasswordRepository_CountBreaches_Call struct {
+	*mock.Call
+}
+
+// CountBreaches is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hash [20]byte
+func (_e *MockBreachedPasswordRepository_Expecter) CountBreaches(ctx interface{}, hash interface{}) *MockBreachedPasswordRepository_CountBreaches_Call {
+	return &MockBreachedPasswordRepository_CountBreaches_Call{Call: _e.mock.On("CountBreaches", ctx, hash)}
+}
+
+func (_c *MockBreachedPasswordRepository_CountBreaches_Call) Run(run func(ctx context.Context, hash [20]byte)) *MockBreachedPasswordRepository_CountBreaches_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([20]byte))
+	})
+	return _c
+}
+
+func (_c *MockBreachedPasswordRepository_CountBreaches_Call) Return(_a0 int, _a1 error) *MockBreachedPasswordRepository_CountBreaches_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBreachedPasswordRepository_CountBreaches_Call) RunAndReturn(run func(context.Context, [20]byte) (int, error)) *MockBreachedPasswordRepository_CountBreaches_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockBreachedPasswordRepository creates a new instance of MockBreachedPasswordRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockBreachedPasswordRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockBreachedPasswordRepository {
+	mock := &MockBreachedPasswordRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
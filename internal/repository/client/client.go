@@ -8,8 +8,9 @@ import (
 )
 
 type IClientRepository interface {
-	CreateClient(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission, secretHash []byte) (*domain.Client, error)
-	UpdateClientByIDandUser(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission, secretHash []byte) (*domain.Client, error)
+	CreateClient(ctx context.Context, client *domain.Client) (*domain.Client, error)
+	// UpdateClientByIDandUser leaves the scope and the secret hash alone when nil, pepperVersion goes with the secret hash
+	UpdateClientByIDandUser(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission, secretHash []byte, pepperVersion int) (*domain.Client, error)
 
 	// cqs
 	GetClientByID(ctx context.Context, ID uuid.UUID) (*domain.Client, error)
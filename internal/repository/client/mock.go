@@ -24,9 +24,9 @@ func (_m *MockClientRepository) EXPECT() *MockClientRepository_Expecter {
 	return &MockClientRepository_Expecter{mock: &_m.Mock}
 }
 
-// CreateClient provides a mock function with given fields: ctx, ID, userID, scope, secretHash
-func (_m *MockClientRepository) CreateClient(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission, secretHash []byte) (*domain.Client, error) {
-	ret := _m.Called(ctx, ID, userID, scope, secretHash)
+// CreateClient provides a mock function with given fields: ctx, _a1
+func (_m *MockClientRepository) CreateClient(ctx context.Context, _a1 *domain.Client) (*domain.Client, error) {
+	ret := _m.Called(ctx, _a1)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateClient")
@@ -34,19 +34,19 @@ func (_m *MockClientRepository) CreateClient(ctx context.Context, ID uuid.UUID,
 
 	var r0 *domain.Client
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte) (*domain.Client, error)); ok {
-		return rf(ctx, ID, userID, scope, secretHash)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Client) (*domain.Client, error)); ok {
+		return rf(ctx, _a1)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte) *domain.Client); ok {
-		r0 = rf(ctx, ID, userID, scope, secretHash)
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Client) *domain.Client); ok {
+		r0 = rf(ctx, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.Client)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte) error); ok {
-		r1 = rf(ctx, ID, userID, scope, secretHash)
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.Client) error); ok {
+		r1 = rf(ctx, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -61,17 +61,14 @@ type MockClientRepository_CreateClient_Call struct {
 
 // CreateClient is a helper method to define mock.On call
 //   - ctx context.Context
-//   - ID uuid.UUID
-//   - userID uuid.UUID
-//   - scope []domain.Permission
-//   - secretHash []byte
-func (_e *MockClientRepository_Expecter) CreateClient(ctx interface{}, ID interface{}, userID interface{}, scope interface{}, secretHash interface{}) *MockClientRepository_CreateClient_Call {
-	return &MockClientRepository_CreateClient_Call{Call: _e.mock.On("CreateClient", ctx, ID, userID, scope, secretHash)}
+//   - _a1 *domain.Client
+func (_e *MockClientRepository_Expecter) CreateClient(ctx interface{}, _a1 interface{}) *MockClientRepository_CreateClient_Call {
+	return &MockClientRepository_CreateClient_Call{Call: _e.mock.On("CreateClient", ctx, _a1)}
 }
 
-func (_c *MockClientRepository_CreateClient_Call) Run(run func(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission, secretHash []byte)) *MockClientRepository_CreateClient_Call {
+func (_c *MockClientRepository_CreateClient_Call) Run(run func(ctx context.Context, _a1 *domain.Client)) *MockClientRepository_CreateClient_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(uuid.UUID), args[3].([]domain.Permission), args[4].([]byte))
+		run(args[0].(context.Context), args[1].(*domain.Client))
 	})
 	return _c
 }
@@ -81,7 +78,7 @@ func (_c *MockClientRepository_CreateClient_Call) Return(_a0 *domain.Client, _a1
 	return _c
 }
 
-func (_c *MockClientRepository_CreateClient_Call) RunAndReturn(run func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte) (*domain.Client, error)) *MockClientRepository_CreateClient_Call {
+func (_c *MockClientRepository_CreateClient_Call) RunAndReturn(run func(context.Context, *domain.Client) (*domain.Client, error)) *MockClientRepository_CreateClient_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -252,9 +249,9 @@ func (_c *MockClientRepository_ListClientsByUser_Call) RunAndReturn(run func(con
 	return _c
 }
 
-// UpdateClientByIDandUser provides a mock function with given fields: ctx, ID, userID, scope, secretHash
-func (_m *MockClientRepository) UpdateClientByIDandUser(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission, secretHash []byte) (*domain.Client, error) {
-	ret := _m.Called(ctx, ID, userID, scope, secretHash)
+// UpdateClientByIDandUser provides a mock function with given fields: ctx, ID, userID, scope, secretHash, pepperVersion
+func (_m *MockClientRepository) UpdateClientByIDandUser(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission, secretHash []byte, pepperVersion int) (*domain.Client, error) {
+	ret := _m.Called(ctx, ID, userID, scope, secretHash, pepperVersion)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateClientByIDandUser")
@@ -262,19 +259,19 @@ func (_m *MockClientRepository) UpdateClientByIDandUser(ctx context.Context, ID
 
 	var r0 *domain.Client
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte) (*domain.Client, error)); ok {
-		return rf(ctx, ID, userID, scope, secretHash)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte, int) (*domain.Client, error)); ok {
+		return rf(ctx, ID, userID, scope, secretHash, pepperVersion)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte) *domain.Client); ok {
-		r0 = rf(ctx, ID, userID, scope, secretHash)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte, int) *domain.Client); ok {
+		r0 = rf(ctx, ID, userID, scope, secretHash, pepperVersion)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.Client)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte) error); ok {
-		r1 = rf(ctx, ID, userID, scope, secretHash)
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte, int) error); ok {
+		r1 = rf(ctx, ID, userID, scope, secretHash, pepperVersion)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -293,13 +290,14 @@ type MockClientRepository_UpdateClientByIDandUser_Call struct {
 //   - userID uuid.UUID
 //   - scope []domain.Permission
 //   - secretHash []byte
-func (_e *MockClientRepository_Expecter) UpdateClientByIDandUser(ctx interface{}, ID interface{}, userID interface{}, scope interface{}, secretHash interface{}) *MockClientRepository_UpdateClientByIDandUser_Call {
-	return &MockClientRepository_UpdateClientByIDandUser_Call{Call: _e.mock.On("UpdateClientByIDandUser", ctx, ID, userID, scope, secretHash)}
+//   - pepperVersion int
+func (_e *MockClientRepository_Expecter) UpdateClientByIDandUser(ctx interface{}, ID interface{}, userID interface{}, scope interface{}, secretHash interface{}, pepperVersion interface{}) *MockClientRepository_UpdateClientByIDandUser_Call {
+	return &MockClientRepository_UpdateClientByIDandUser_Call{Call: _e.mock.On("UpdateClientByIDandUser", ctx, ID, userID, scope, secretHash, pepperVersion)}
 }
 
-func (_c *MockClientRepository_UpdateClientByIDandUser_Call) Run(run func(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission, secretHash []byte)) *MockClientRepository_UpdateClientByIDandUser_Call {
+func (_c *MockClientRepository_UpdateClientByIDandUser_Call) Run(run func(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission, secretHash []byte, pepperVersion int)) *MockClientRepository_UpdateClientByIDandUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(uuid.UUID), args[3].([]domain.Permission), args[4].([]byte))
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(uuid.UUID), args[3].([]domain.Permission), args[4].([]byte), args[5].(int))
 	})
 	return _c
 }
@@ -309,7 +307,7 @@ func (_c *MockClientRepository_UpdateClientByIDandUser_Call) Return(_a0 *domain.
 	return _c
 }
 
-func (_c *MockClientRepository_UpdateClientByIDandUser_Call) RunAndReturn(run func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte) (*domain.Client, error)) *MockClientRepository_UpdateClientByIDandUser_Call {
+func (_c *MockClientRepository_UpdateClientByIDandUser_Call) RunAndReturn(run func(context.Context, uuid.UUID, uuid.UUID, []domain.Permission, []byte, int) (*domain.Client, error)) *MockClientRepository_UpdateClientByIDandUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
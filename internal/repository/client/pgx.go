@@ -23,10 +23,24 @@ func NewPgxClientRepository(dbpool *pgxpool.Pool) *PgxClientRepository {
 	}
 }
 
+// clientColumns is the column list every query returns, in the order scanClient reads it.
+const clientColumns = `id, user_id, scope, secret_hash, redirect_uris, audiences, dpop_required,
+	token_endpoint_auth_method, tls_client_auth_subject_dn, tls_client_auth_ca, tls_client_certificate_thumbprint, claim_providers, access_token_format, pepper_version`
+
+func scanClient(row pgx.Row) (*domain.Client, error) {
+	var client domain.Client
+	if err := row.Scan(&client.ID, &client.UserID, &client.Scope, &client.SecretHash, &client.RedirectURIs, &client.Audiences, &client.DPoPRequired,
+		&client.AuthMethod, &client.TLSSubjectDN, &client.TLSClientCA, &client.TLSCertificateThumbprint, &client.ClaimProviders, &client.AccessTokenFormat, &client.PepperVersion,
+	); err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
 func (repo *PgxClientRepository) ListClientsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Client, error) {
 	clients := make([]domain.Client, 0)
 	errfmt := "%w: %s"
-	query := `SELECT id, user_id, scope, secret_hash FROM clients WHERE user_id=$1`
+	query := `SELECT ` + clientColumns + ` FROM clients WHERE user_id=$1`
 	rows, err := repo.dbpool.Query(ctx, query, userID)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -38,11 +52,11 @@ func (repo *PgxClientRepository) ListClientsByUser(ctx context.Context, userID u
 	defer rows.Close()
 
 	for rows.Next() {
-		var client domain.Client
-		if err := rows.Scan(&client.ID, &client.UserID, &client.Scope, &client.SecretHash); err != nil {
+		client, err := scanClient(rows)
+		if err != nil {
 			return nil, fmt.Errorf(errfmt, domain.ErrGeneralClient, err.Error())
 		}
-		clients = append(clients, client)
+		clients = append(clients, *client)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -53,13 +67,14 @@ func (repo *PgxClientRepository) ListClientsByUser(ctx context.Context, userID u
 
 }
 
-func (repo *PgxClientRepository) CreateClient(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission, secretHash []byte) (*domain.Client, error) {
+func (repo *PgxClientRepository) CreateClient(ctx context.Context, client *domain.Client) (*domain.Client, error) {
 	errfmt := "%w: %s"
-	query := `INSERT INTO clients (id, user_id, scope, secret_hash) VALUES ($1, $2, $3, $4) RETURNING id, user_id, scope, secret_hash`
+	query := `INSERT INTO clients (` + clientColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING ` + clientColumns
 
-	var client domain.Client
-	err := repo.dbpool.QueryRow(ctx, query, ID.String(), userID.String(), scope, secretHash).
-		Scan(&client.ID, &client.UserID, &client.Scope, &client.SecretHash)
+	created, err := scanClient(repo.dbpool.QueryRow(
+		ctx, query, client.ID.String(), client.UserID.String(), client.Scope, client.SecretHash, nonNil(client.RedirectURIs), nonNil(client.Audiences), client.DPoPRequired,
+		client.AuthMethod, client.TLSSubjectDN, client.TLSClientCA, client.TLSCertificateThumbprint, nonNil(client.ClaimProviders), client.AccessTokenFormat, client.PepperVersion,
+	))
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
@@ -75,23 +90,21 @@ func (repo *PgxClientRepository) CreateClient(ctx context.Context, ID uuid.UUID,
 		return nil, fmt.Errorf(errfmt, domain.ErrGeneralClient, err.Error())
 	}
 
-	return &client, nil
+	return created, nil
 }
 
 func (repo *PgxClientRepository) GetClientByID(ctx context.Context, ID uuid.UUID) (*domain.Client, error) {
 	errfmt := "%w: %s"
-	query := `SELECT id, user_id, scope, secret_hash FROM clients WHERE id = $1`
+	query := `SELECT ` + clientColumns + ` FROM clients WHERE id = $1`
 
-	var client domain.Client
-	err := repo.dbpool.QueryRow(ctx, query, ID.String()).
-		Scan(&client.ID, &client.UserID, &client.Scope, &client.SecretHash)
+	client, err := scanClient(repo.dbpool.QueryRow(ctx, query, ID.String()))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf(errfmt, domain.ErrUserNotFound, err.Error())
+			return nil, fmt.Errorf(errfmt, domain.ErrClientNotFound, err.Error())
 		}
-		return nil, fmt.Errorf(errfmt, domain.ErrGeneralUser, err.Error())
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralClient, err.Error())
 	}
-	return &client, nil
+	return client, nil
 }
 
 func (repo *PgxClientRepository) UpdateClientByIDandUser(
@@ -100,6 +113,7 @@ func (repo *PgxClientRepository) UpdateClientByIDandUser(
 	userId uuid.UUID,
 	scope []domain.Permission,
 	secretHash []byte,
+	pepperVersion int,
 ) (*domain.Client, error) {
 	errfmt := "%w: %s"
 
@@ -115,9 +129,9 @@ func (repo *PgxClientRepository) UpdateClientByIDandUser(
 	}
 
 	if secretHash != nil {
-		updates = append(updates, fmt.Sprintf("secret_hash = $%d", argIndex))
-		args = append(args, secretHash)
-		argIndex++
+		updates = append(updates, fmt.Sprintf("secret_hash = $%d, pepper_version = $%d", argIndex, argIndex+1))
+		args = append(args, secretHash, pepperVersion)
+		argIndex += 2
 	}
 
 	if len(updates) == 0 {
@@ -125,13 +139,10 @@ func (repo *PgxClientRepository) UpdateClientByIDandUser(
 	}
 
 	query += strings.Join(updates, ", ")
-	query += fmt.Sprintf(" WHERE id = $%d AND user_id = $%d RETURNING id, user_id, scope, secret_hash", argIndex, argIndex+1)
+	query += fmt.Sprintf(" WHERE id = $%d AND user_id = $%d RETURNING "+clientColumns, argIndex, argIndex+1)
 	args = append(args, ID, userId)
 
-	var client domain.Client
-	err := repo.dbpool.QueryRow(ctx, query, args...).Scan(
-		&client.ID, &client.UserID, &client.Scope, &client.SecretHash,
-	)
+	client, err := scanClient(repo.dbpool.QueryRow(ctx, query, args...))
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -140,7 +151,7 @@ func (repo *PgxClientRepository) UpdateClientByIDandUser(
 		return nil, fmt.Errorf(errfmt, domain.ErrGeneralUser, err.Error())
 	}
 
-	return &client, nil
+	return client, nil
 }
 
 func (repo *PgxClientRepository) DeleteClientByIDandUser(ctx context.Context, ID uuid.UUID, userID uuid.UUID) error {
@@ -154,3 +165,11 @@ func (repo *PgxClientRepository) DeleteClientByIDandUser(ctx context.Context, ID
 	}
 	return nil
 }
+
+// nonNil keeps NOT NULL array columns from receiving a SQL NULL.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
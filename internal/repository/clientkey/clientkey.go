@@ -0,0 +1,14 @@
+package clientkey
+
+import (
+	"context"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+)
+
+type IClientKeyRepository interface {
+	CreateClientKey(ctx context.Context, key *domain.ClientKey) (*domain.ClientKey, error)
+	ListClientKeys(ctx context.Context, clientID uuid.UUID) ([]domain.ClientKey, error)
+	DeleteClientKey(ctx context.Context, clientID uuid.UUID, kid string) error
+}
@@ -0,0 +1,205 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package clientkey
+
+import (
+	context "context"
+
+	domain "github.com/bright-pentium/go-client-practice/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// MockClientKeyRepository is an autogenerated mock type for the IClientKeyRepository type
+type MockClientKeyRepository struct {
+	mock.Mock
+}
+
+type MockClientKeyRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockClientKeyRepository) EXPECT() *MockClientKeyRepository_Expecter {
+	return &MockClientKeyRepository_Expecter{mock: &_m.Mock}
+}
+
+// CreateClientKey provides a mock function with given fields: ctx, key
+func (_m *MockClientKeyRepository) CreateClientKey(ctx context.Context, key *domain.ClientKey) (*domain.ClientKey, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateClientKey")
+	}
+
+	var r0 *domain.ClientKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ClientKey) (*domain.ClientKey, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.ClientKey) *domain.ClientKey); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.ClientKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.ClientKey) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockClientKeyRepository_CreateClientKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateClientKey'
+type MockClientKeyRepository_CreateClientKey_Call struct {
+	*mock.Call
+}
+
+// CreateClientKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key *domain.ClientKey
+func (_e *MockClientKeyRepository_Expecter) CreateClientKey(ctx interface{}, key interface{}) *MockClientKeyRepository_CreateClientKey_Call {
+	return &MockClientKeyRepository_CreateClientKey_Call{Call: _e.mock.On("CreateClientKey", ctx, key)}
+}
+
+func (_c *MockClientKeyRepository_CreateClientKey_Call) Run(run func(ctx context.Context, key *domain.ClientKey)) *MockClientKeyRepository_CreateClientKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.ClientKey))
+	})
+	return _c
+}
+
+func (_c *MockClientKeyRepository_CreateClientKey_Call) Return(_a0 *domain.ClientKey, _a1 error) *MockClientKeyRepository_CreateClientKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockClientKeyRepository_CreateClientKey_Call) RunAndReturn(run func(context.Context, *domain.ClientKey) (*domain.ClientKey, error)) *MockClientKeyRepository_CreateClientKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteClientKey provides a mock function with given fields: ctx, clientID, kid
+func (_m *MockClientKeyRepository) DeleteClientKey(ctx context.Context, clientID uuid.UUID, kid string) error {
+	ret := _m.Called(ctx, clientID, kid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteClientKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) error); ok {
+		r0 = rf(ctx, clientID, kid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockClientKeyRepository_DeleteClientKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteClientKey'
+type MockClientKeyRepository_DeleteClientKey_Call struct {
+	*mock.Call
+}
+
+// DeleteClientKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - clientID uuid.UUID
+//   - kid string
+func (_e *MockClientKeyRepository_Expecter) DeleteClientKey(ctx interface{}, clientID interface{}, kid interface{}) *MockClientKeyRepository_DeleteClientKey_Call {
+	return &MockClientKeyRepository_DeleteClientKey_Call{Call: _e.mock.On("DeleteClientKey", ctx, clientID, kid)}
+}
+
+func (_c *MockClientKeyRepository_DeleteClientKey_Call) Run(run func(ctx context.Context, clientID uuid.UUID, kid string)) *MockClientKeyRepository_DeleteClientKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockClientKeyRepository_DeleteClientKey_Call) Return(_a0 error) *MockClientKeyRepository_DeleteClientKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockClientKeyRepository_DeleteClientKey_Call) RunAndReturn(run func(context.Context, uuid.UUID, string) error) *MockClientKeyRepository_DeleteClientKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListClientKeys provides a mock function with given fields: ctx, clientID
+func (_m *MockClientKeyRepository) ListClientKeys(ctx context.Context, clientID uuid.UUID) ([]domain.ClientKey, error) {
+	ret := _m.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListClientKeys")
+	}
+
+	var r0 []domain.ClientKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]domain.ClientKey, error)); ok {
+		return rf(ctx, clientID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []domain.ClientKey); ok {
+		r0 = rf(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ClientKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockClientKeyRepository_ListClientKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListClientKeys'
+type MockClientKeyRepository_ListClientKeys_Call struct {
+	*mock.Call
+}
+
+// ListClientKeys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - clientID uuid.UUID
+func (_e *MockClientKeyRepository_Expecter) ListClientKeys(ctx interface{}, clientID interface{}) *MockClientKeyRepository_ListClientKeys_Call {
+	return &MockClientKeyRepository_ListClientKeys_Call{Call: _e.mock.On("ListClientKeys", ctx, clientID)}
+}
+
+func (_c *MockClientKeyRepository_ListClientKeys_Call) Run(run func(ctx context.Context, clientID uuid.UUID)) *MockClientKeyRepository_ListClientKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockClientKeyRepository_ListClientKeys_Call) Return(_a0 []domain.ClientKey, _a1 error) *MockClientKeyRepository_ListClientKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockClientKeyRepository_ListClientKeys_Call) RunAndReturn(run func(context.Context, uuid.UUID) ([]domain.ClientKey, error)) *MockClientKeyRepository_ListClientKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockClientKeyRepository creates a new instance of MockClientKeyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockClientKeyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockClientKeyRepository {
+	mock := &MockClientKeyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,91 @@
+package clientkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxClientKeyRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxClientKeyRepository(dbpool *pgxpool.Pool) *PgxClientKeyRepository {
+	return &PgxClientKeyRepository{
+		dbpool: dbpool,
+	}
+}
+
+// clientKeyColumns is the column list every query returns, in the order scanClientKey reads it.
+const clientKeyColumns = `client_id, kid, jwk, created_at`
+
+func scanClientKey(row pgx.Row) (*domain.ClientKey, error) {
+	var key domain.ClientKey
+	if err := row.Scan(&key.ClientID, &key.Kid, &key.JWK, &key.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (repo *PgxClientKeyRepository) CreateClientKey(ctx context.Context, key *domain.ClientKey) (*domain.ClientKey, error) {
+	errfmt := "%w: %s"
+	query := `INSERT INTO client_keys (client_id, kid, jwk) VALUES ($1, $2, $3) RETURNING ` + clientKeyColumns
+
+	created, err := scanClientKey(repo.dbpool.QueryRow(ctx, query, key.ClientID, key.Kid, key.JWK))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				// unique_violation
+				return nil, fmt.Errorf(errfmt, domain.ErrClientKeyAlreadyExists, pgErr.Error())
+			case "23503":
+				// foreign_key_violation
+				return nil, fmt.Errorf(errfmt, domain.ErrClientNotFound, pgErr.Error())
+			}
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralClientKey, err.Error())
+	}
+	return created, nil
+}
+
+func (repo *PgxClientKeyRepository) ListClientKeys(ctx context.Context, clientID uuid.UUID) ([]domain.ClientKey, error) {
+	errfmt := "%w: %s"
+	keys := make([]domain.ClientKey, 0)
+	query := `SELECT ` + clientKeyColumns + ` FROM client_keys WHERE client_id = $1 ORDER BY created_at`
+	rows, err := repo.dbpool.Query(ctx, query, clientID)
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralClientKey, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		key, err := scanClientKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf(errfmt, domain.ErrGeneralClientKey, err.Error())
+		}
+		keys = append(keys, *key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralClientKey, err.Error())
+	}
+	return keys, nil
+}
+
+func (repo *PgxClientKeyRepository) DeleteClientKey(ctx context.Context, clientID uuid.UUID, kid string) error {
+	query := `DELETE FROM client_keys WHERE client_id = $1 AND kid = $2`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, clientID, kid)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralClientKey, err.Error())
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: kid '%s'", domain.ErrClientKeyNotFound, kid)
+	}
+	return nil
+}
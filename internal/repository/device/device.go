@@ -0,0 +1,19 @@
+package device
+
+import (
+	"context"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+)
+
+type IDeviceAuthorizationRepository interface {
+	CreateDeviceAuthorization(ctx context.Context, authorization *domain.DeviceAuthorization) (*domain.DeviceAuthorization, error)
+	GetDeviceAuthorizationByDeviceCode(ctx context.Context, deviceCodeHash []byte) (*domain.DeviceAuthorization, error)
+	GetDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*domain.DeviceAuthorization, error)
+	// DecideDeviceAuthorization stores the status, user, scope and auth time of a decision, a request can be decided only once.
+	DecideDeviceAuthorization(ctx context.Context, authorization *domain.DeviceAuthorization) (*domain.DeviceAuthorization, error)
+	RecordDevicePoll(ctx context.Context, deviceCodeHash []byte, interval int) error
+	// ConsumeDeviceAuthorization marks an approved request redeemed and returns it, it can be consumed only once.
+	ConsumeDeviceAuthorization(ctx context.Context, deviceCodeHash []byte) (*domain.DeviceAuthorization, error)
+	DeleteExpiredDeviceAuthorizations(ctx context.Context) (int64, error)
+}
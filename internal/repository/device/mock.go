@@ -0,0 +1,437 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package device
+
+import (
+	context "context"
+
+	domain "github.com/bright-pentium/go-client-practice/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockDeviceAuthorizationRepository is an autogenerated mock type for the IDeviceAuthorizationRepository type
+type MockDeviceAuthorizationRepository struct {
+	mock.Mock
+}
+
+type MockDeviceAuthorizationRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDeviceAuthorizationRepository) EXPECT() *MockDeviceAuthorizationRepository_Expecter {
+	return &MockDeviceAuthorizationRepository_Expecter{mock: &_m.Mock}
+}
+
+// ConsumeDeviceAuthorization provides a mock function with given fields: ctx, deviceCodeHash
+func (_m *MockDeviceAuthorizationRepository) ConsumeDeviceAuthorization(ctx context.Context, deviceCodeHash []byte) (*domain.DeviceAuthorization, error) {
+	ret := _m.Called(ctx, deviceCodeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeDeviceAuthorization")
+	}
+
+	var r0 *domain.DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*domain.DeviceAuthorization, error)); ok {
+		return rf(ctx, deviceCodeHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *domain.DeviceAuthorization); ok {
+		r0 = rf(ctx, deviceCodeHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.DeviceAuthorization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, deviceCodeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDeviceAuthorizationRepository_ConsumeDeviceAuthorization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeDeviceAuthorization'
+type MockDeviceAuthorizationRepository_ConsumeDeviceAuthorization_Call struct {
+	*mock.Call
+}
+
+// ConsumeDeviceAuthorization is a helper method to define mock.On call
+//   - ctx context.Context
+//   - deviceCodeHash []byte
+func (_e *MockDeviceAuthorizationRepository_Expecter) ConsumeDeviceAuthorization(ctx interface{}, deviceCodeHash interface{}) *MockDeviceAuthorizationRepository_ConsumeDeviceAuthorization_Call {
+	return &MockDeviceAuthorizationRepository_ConsumeDeviceAuthorization_Call{Call: _e.mock.On("ConsumeDeviceAuthorization", ctx, deviceCodeHash)}
+}
+
+func (_c *MockDeviceAuthorizationRepository_ConsumeDeviceAuthorization_Call) Run(run func(ctx context.Context, deviceCodeHash []byte)) *MockDeviceAuthorizationRepository_ConsumeDeviceAuthorization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_ConsumeDeviceAuthorization_Call) Return(_a0 *domain.DeviceAuthorization, _a1 error) *MockDeviceAuthorizationRepository_ConsumeDeviceAuthorization_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_ConsumeDeviceAuthorization_Call) RunAndReturn(run func(context.Context, []byte) (*domain.DeviceAuthorization, error)) *MockDeviceAuthorizationRepository_ConsumeDeviceAuthorization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateDeviceAuthorization provides a mock function with given fields: ctx, authorization
+func (_m *MockDeviceAuthorizationRepository) CreateDeviceAuthorization(ctx context.Context, authorization *domain.DeviceAuthorization) (*domain.DeviceAuthorization, error) {
+	ret := _m.Called(ctx, authorization)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateDeviceAuthorization")
+	}
+
+	var r0 *domain.DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.DeviceAuthorization) (*domain.DeviceAuthorization, error)); ok {
+		return rf(ctx, authorization)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.DeviceAuthorization) *domain.DeviceAuthorization); ok {
+		r0 = rf(ctx, authorization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.DeviceAuthorization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.DeviceAuthorization) error); ok {
+		r1 = rf(ctx, authorization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDeviceAuthorizationRepository_CreateDeviceAuthorization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateDeviceAuthorization'
+type MockDeviceAuthorizationRepository_CreateDeviceAuthorization_Call struct {
+	*mock.Call
+}
+
+// CreateDeviceAuthorization is a helper method to define mock.On call
+//   - ctx context.Context
+//   - authorization *domain.DeviceAuthorization
+func (_e *MockDeviceAuthorizationRepository_Expecter) CreateDeviceAuthorization(ctx interface{}, authorization interface{}) *MockDeviceAuthorizationRepository_CreateDeviceAuthorization_Call {
+	return &MockDeviceAuthorizationRepository_CreateDeviceAuthorization_Call{Call: _e.mock.On("CreateDeviceAuthorization", ctx, authorization)}
+}
+
+func (_c *MockDeviceAuthorizationRepository_CreateDeviceAuthorization_Call) Run(run func(ctx context.Context, authorization *domain.DeviceAuthorization)) *MockDeviceAuthorizationRepository_CreateDeviceAuthorization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.DeviceAuthorization))
+	})
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_CreateDeviceAuthorization_Call) Return(_a0 *domain.DeviceAuthorization, _a1 error) *MockDeviceAuthorizationRepository_CreateDeviceAuthorization_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_CreateDeviceAuthorization_Call) RunAndReturn(run func(context.Context, *domain.DeviceAuthorization) (*domain.DeviceAuthorization, error)) *MockDeviceAuthorizationRepository_CreateDeviceAuthorization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DecideDeviceAuthorization provides a mock function with given fields: ctx, authorization
+func (_m *MockDeviceAuthorizationRepository) DecideDeviceAuthorization(ctx context.Context, authorization *domain.DeviceAuthorization) (*domain.DeviceAuthorization, error) {
+	ret := _m.Called(ctx, authorization)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DecideDeviceAuthorization")
+	}
+
+	var r0 *domain.DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.DeviceAuthorization) (*domain.DeviceAuthorization, error)); ok {
+		return rf(ctx, authorization)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.DeviceAuthorization) *domain.DeviceAuthorization); ok {
+		r0 = rf(ctx, authorization)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.DeviceAuthorization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.DeviceAuthorization) error); ok {
+		r1 = rf(ctx, authorization)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDeviceAuthorizationRepository_DecideDeviceAuthorization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DecideDeviceAuthorization'
+type MockDeviceAuthorizationRepository_DecideDeviceAuthorization_Call struct {
+	*mock.Call
+}
+
+// DecideDeviceAuthorization is a helper method to define mock.On call
+//   - ctx context.Context
+//   - authorization *domain.DeviceAuthorization
+func (_e *MockDeviceAuthorizationRepository_Expecter) DecideDeviceAuthorization(ctx interface{}, authorization interface{}) *MockDeviceAuthorizationRepository_DecideDeviceAuthorization_Call {
+	return &MockDeviceAuthorizationRepository_DecideDeviceAuthorization_Call{Call: _e.mock.On("DecideDeviceAuthorization", ctx, authorization)}
+}
+
+func (_c *MockDeviceAuthorizationRepository_DecideDeviceAuthorization_Call) Run(run func(ctx context.Context, authorization *domain.DeviceAuthorization)) *MockDeviceAuthorizationRepository_DecideDeviceAuthorization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.DeviceAuthorization))
+	})
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_DecideDeviceAuthorization_Call) Return(_a0 *domain.DeviceAuthorization, _a1 error) *MockDeviceAuthorizationRepository_DecideDeviceAuthorization_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_DecideDeviceAuthorization_Call) RunAndReturn(run func(context.Context, *domain.DeviceAuthorization) (*domain.DeviceAuthorization, error)) *MockDeviceAuthorizationRepository_DecideDeviceAuthorization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpiredDeviceAuthorizations provides a mock function with given fields: ctx
+func (_m *MockDeviceAuthorizationRepository) DeleteExpiredDeviceAuthorizations(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredDeviceAuthorizations")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDeviceAuthorizationRepository_DeleteExpiredDeviceAuthorizations_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpiredDeviceAuthorizations'
+type MockDeviceAuthorizationRepository_DeleteExpiredDeviceAuthorizations_Call struct {
+	*mock.Call
+}
+
+// DeleteExpiredDeviceAuthorizations is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockDeviceAuthorizationRepository_Expecter) DeleteExpiredDeviceAuthorizations(ctx interface{}) *MockDeviceAuthorizationRepository_DeleteExpiredDeviceAuthorizations_Call {
+	return &MockDeviceAuthorizationRepository_DeleteExpiredDeviceAuthorizations_Call{Call: _e.mock.On("DeleteExpiredDeviceAuthorizations", ctx)}
+}
+
+func (_c *MockDeviceAuthorizationRepository_DeleteExpiredDeviceAuthorizations_Call) Run(run func(ctx context.Context)) *MockDeviceAuthorizationRepository_DeleteExpiredDeviceAuthorizations_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_DeleteExpiredDeviceAuthorizations_Call) Return(_a0 int64, _a1 error) *MockDeviceAuthorizationRepository_DeleteExpiredDeviceAuthorizations_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_DeleteExpiredDeviceAuthorizations_Call) RunAndReturn(run func(context.Context) (int64, error)) *MockDeviceAuthorizationRepository_DeleteExpiredDeviceAuthorizations_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDeviceAuthorizationByDeviceCode provides a mock function with given fields: ctx, deviceCodeHash
+func (_m *MockDeviceAuthorizationRepository) GetDeviceAuthorizationByDeviceCode(ctx context.Context, deviceCodeHash []byte) (*domain.DeviceAuthorization, error) {
+	ret := _m.Called(ctx, deviceCodeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeviceAuthorizationByDeviceCode")
+	}
+
+	var r0 *domain.DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*domain.DeviceAuthorization, error)); ok {
+		return rf(ctx, deviceCodeHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *domain.DeviceAuthorization); ok {
+		r0 = rf(ctx, deviceCodeHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.DeviceAuthorization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, deviceCodeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDeviceAuthorizationRepository_GetDeviceAuthorizationByDeviceCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDeviceAuthorizationByDeviceCode'
+type MockDeviceAuthorizationRepository_GetDeviceAuthorizationByDeviceCode_Call struct {
+	*mock.Call
+}
+
+// GetDeviceAuthorizationByDeviceCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - deviceCodeHash []byte
+func (_e *MockDeviceAuthorizationRepository_Expecter) GetDeviceAuthorizationByDeviceCode(ctx interface{}, deviceCodeHash interface{}) *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByDeviceCode_Call {
+	return &MockDeviceAuthorizationRepository_GetDeviceAuthorizationByDeviceCode_Call{Call: _e.mock.On("GetDeviceAuthorizationByDeviceCode", ctx, deviceCodeHash)}
+}
+
+func (_c *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByDeviceCode_Call) Run(run func(ctx context.Context, deviceCodeHash []byte)) *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByDeviceCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByDeviceCode_Call) Return(_a0 *domain.DeviceAuthorization, _a1 error) *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByDeviceCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByDeviceCode_Call) RunAndReturn(run func(context.Context, []byte) (*domain.DeviceAuthorization, error)) *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByDeviceCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDeviceAuthorizationByUserCode provides a mock function with given fields: ctx, userCode
+func (_m *MockDeviceAuthorizationRepository) GetDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*domain.DeviceAuthorization, error) {
+	ret := _m.Called(ctx, userCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeviceAuthorizationByUserCode")
+	}
+
+	var r0 *domain.DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*domain.DeviceAuthorization, error)); ok {
+		return rf(ctx, userCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *domain.DeviceAuthorization); ok {
+		r0 = rf(ctx, userCode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.DeviceAuthorization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockDeviceAuthorizationRepository_GetDeviceAuthorizationByUserCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDeviceAuthorizationByUserCode'
+type MockDeviceAuthorizationRepository_GetDeviceAuthorizationByUserCode_Call struct {
+	*mock.Call
+}
+
+// GetDeviceAuthorizationByUserCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userCode string
+func (_e *MockDeviceAuthorizationRepository_Expecter) GetDeviceAuthorizationByUserCode(ctx interface{}, userCode interface{}) *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByUserCode_Call {
+	return &MockDeviceAuthorizationRepository_GetDeviceAuthorizationByUserCode_Call{Call: _e.mock.On("GetDeviceAuthorizationByUserCode", ctx, userCode)}
+}
+
+func (_c *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByUserCode_Call) Run(run func(ctx context.Context, userCode string)) *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByUserCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByUserCode_Call) Return(_a0 *domain.DeviceAuthorization, _a1 error) *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByUserCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByUserCode_Call) RunAndReturn(run func(context.Context, string) (*domain.DeviceAuthorization, error)) *MockDeviceAuthorizationRepository_GetDeviceAuthorizationByUserCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordDevicePoll provides a mock function with given fields: ctx, deviceCodeHash, interval
+func (_m *MockDeviceAuthorizationRepository) RecordDevicePoll(ctx context.Context, deviceCodeHash []byte, interval int) error {
+	ret := _m.Called(ctx, deviceCodeHash, interval)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordDevicePoll")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, int) error); ok {
+		r0 = rf(ctx, deviceCodeHash, interval)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockDeviceAuthorizationRepository_RecordDevicePoll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordDevicePoll'
+type MockDeviceAuthorizationRepository_RecordDevicePoll_Call struct {
+	*mock.Call
+}
+
+// RecordDevicePoll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - deviceCodeHash []byte
+//   - interval int
+func (_e *MockDeviceAuthorizationRepository_Expecter) RecordDevicePoll(ctx interface{}, deviceCodeHash interface{}, interval interface{}) *MockDeviceAuthorizationRepository_RecordDevicePoll_Call {
+	return &MockDeviceAuthorizationRepository_RecordDevicePoll_Call{Call: _e.mock.On("RecordDevicePoll", ctx, deviceCodeHash, interval)}
+}
+
+func (_c *MockDeviceAuthorizationRepository_RecordDevicePoll_Call) Run(run func(ctx context.Context, deviceCodeHash []byte, interval int)) *MockDeviceAuthorizationRepository_RecordDevicePoll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_RecordDevicePoll_Call) Return(_a0 error) *MockDeviceAuthorizationRepository_RecordDevicePoll_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockDeviceAuthorizationRepository_RecordDevicePoll_Call) RunAndReturn(run func(context.Context, []byte, int) error) *MockDeviceAuthorizationRepository_RecordDevicePoll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockDeviceAuthorizationRepository creates a new instance of MockDeviceAuthorizationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDeviceAuthorizationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDeviceAuthorizationRepository {
+	mock := &MockDeviceAuthorizationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
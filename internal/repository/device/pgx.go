@@ -0,0 +1,104 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxDeviceAuthorizationRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxDeviceAuthorizationRepository(dbpool *pgxpool.Pool) *PgxDeviceAuthorizationRepository {
+	return &PgxDeviceAuthorizationRepository{
+		dbpool: dbpool,
+	}
+}
+
+// deviceAuthorizationColumns is the column list every query returns, in the order scanDeviceAuthorization reads it.
+const deviceAuthorizationColumns = `device_code_hash, user_code, client_id, scope, status, user_id, auth_time, poll_interval, last_polled_at, expires_at, created_at`
+
+func scanDeviceAuthorization(row pgx.Row) (*domain.DeviceAuthorization, error) {
+	var authorization domain.DeviceAuthorization
+	err := row.Scan(
+		&authorization.DeviceCodeHash, &authorization.UserCode, &authorization.ClientID, &authorization.Scope, &authorization.Status,
+		&authorization.UserID, &authorization.AuthTime, &authorization.Interval, &authorization.LastPolledAt, &authorization.ExpiresAt, &authorization.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &authorization, nil
+}
+
+// queryDeviceAuthorization runs a query returning a single device authorization, no rows is ErrDeviceAuthorizationNotFound.
+func (repo *PgxDeviceAuthorizationRepository) queryDeviceAuthorization(ctx context.Context, query string, args ...any) (*domain.DeviceAuthorization, error) {
+	errfmt := "%w: %s"
+	authorization, err := scanDeviceAuthorization(repo.dbpool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(errfmt, domain.ErrDeviceAuthorizationNotFound, err.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralDeviceAuthorization, err.Error())
+	}
+	return authorization, nil
+}
+
+func (repo *PgxDeviceAuthorizationRepository) CreateDeviceAuthorization(ctx context.Context, authorization *domain.DeviceAuthorization) (*domain.DeviceAuthorization, error) {
+	errfmt := "%w: %s"
+	query := `INSERT INTO device_authorizations (device_code_hash, user_code, client_id, scope, status, poll_interval, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING ` + deviceAuthorizationColumns
+	created, err := scanDeviceAuthorization(repo.dbpool.QueryRow(
+		ctx, query, authorization.DeviceCodeHash, authorization.UserCode, authorization.ClientID, authorization.Scope,
+		authorization.Status, authorization.Interval, authorization.ExpiresAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralDeviceAuthorization, err.Error())
+	}
+	return created, nil
+}
+
+func (repo *PgxDeviceAuthorizationRepository) GetDeviceAuthorizationByDeviceCode(ctx context.Context, deviceCodeHash []byte) (*domain.DeviceAuthorization, error) {
+	query := `SELECT ` + deviceAuthorizationColumns + ` FROM device_authorizations WHERE device_code_hash = $1`
+	return repo.queryDeviceAuthorization(ctx, query, deviceCodeHash)
+}
+
+func (repo *PgxDeviceAuthorizationRepository) GetDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*domain.DeviceAuthorization, error) {
+	query := `SELECT ` + deviceAuthorizationColumns + ` FROM device_authorizations WHERE user_code = $1`
+	return repo.queryDeviceAuthorization(ctx, query, userCode)
+}
+
+func (repo *PgxDeviceAuthorizationRepository) DecideDeviceAuthorization(ctx context.Context, authorization *domain.DeviceAuthorization) (*domain.DeviceAuthorization, error) {
+	// the status guard makes concurrent decisions on the same request race to a single winner
+	query := `UPDATE device_authorizations SET status = $2, user_id = $3, scope = $4, auth_time = $5
+		WHERE user_code = $1 AND status = 'pending' AND expires_at > now() RETURNING ` + deviceAuthorizationColumns
+	return repo.queryDeviceAuthorization(
+		ctx, query, authorization.UserCode, authorization.Status, authorization.UserID, authorization.Scope, authorization.AuthTime,
+	)
+}
+
+func (repo *PgxDeviceAuthorizationRepository) RecordDevicePoll(ctx context.Context, deviceCodeHash []byte, interval int) error {
+	query := `UPDATE device_authorizations SET last_polled_at = now(), poll_interval = $2 WHERE device_code_hash = $1`
+	if _, err := repo.dbpool.Exec(ctx, query, deviceCodeHash, interval); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralDeviceAuthorization, err.Error())
+	}
+	return nil
+}
+
+func (repo *PgxDeviceAuthorizationRepository) ConsumeDeviceAuthorization(ctx context.Context, deviceCodeHash []byte) (*domain.DeviceAuthorization, error) {
+	query := `UPDATE device_authorizations SET status = 'consumed' WHERE device_code_hash = $1 AND status = 'approved' RETURNING ` + deviceAuthorizationColumns
+	return repo.queryDeviceAuthorization(ctx, query, deviceCodeHash)
+}
+
+func (repo *PgxDeviceAuthorizationRepository) DeleteExpiredDeviceAuthorizations(ctx context.Context) (int64, error) {
+	query := `DELETE FROM device_authorizations WHERE expires_at <= now()`
+	cmdTag, err := repo.dbpool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", domain.ErrGeneralDeviceAuthorization, err.Error())
+	}
+	return cmdTag.RowsAffected(), nil
+}
@@ -0,0 +1,21 @@
+package loginattempt
+
+import (
+	"context"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+)
+
+type ILoginAttemptRepository interface {
+	// ListLoginAttempts returns the attempts recorded under any of the keys, keys without failures are left out
+	ListLoginAttempts(ctx context.Context, keys []string) ([]domain.LoginAttempt, error)
+	// RecordLoginFailure counts a failure under key, failures are counted from 1 again when the last one
+	// and its lockout ended before forgetBefore
+	RecordLoginFailure(ctx context.Context, key string, forgetBefore time.Time) (*domain.LoginAttempt, error)
+	// LockLogin locks key out until lockedUntil, a longer lockout already recorded is kept
+	LockLogin(ctx context.Context, key string, lockedUntil time.Time) error
+	ResetLoginAttempts(ctx context.Context, key string) error
+	// DeleteLoginAttempts deletes the attempts whose last failure and lockout ended before forgetBefore
+	DeleteLoginAttempts(ctx context.Context, forgetBefore time.Time) (int64, error)
+}
@@ -0,0 +1,311 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package loginattempt
+
+import (
+	context "context"
+
+	domain "github.com/bright-pentium/go-client-practice/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockLoginAttemptRepository is an autogenerated mock type for the ILoginAttemptRepository type
+type MockLoginAttemptRepository struct {
+	mock.Mock
+}
+
+type MockLoginAttemptRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockLoginAttemptRepository) EXPECT() *MockLoginAttemptRepository_Expecter {
+	return &MockLoginAttemptRepository_Expecter{mock: &_m.Mock}
+}
+
+// DeleteLoginAttempts provides a mock function with given fields: ctx, forgetBefore
+func (_m *MockLoginAttemptRepository) DeleteLoginAttempts(ctx context.Context, forgetBefore time.Time) (int64, error) {
+	ret := _m.Called(ctx, forgetBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteLoginAttempts")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, forgetBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, forgetBefore)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, forgetBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockLoginAttemptRepository_DeleteLoginAttempts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteLoginAttempts'
+type MockLoginAttemptRepository_DeleteLoginAttempts_Call struct {
+	*mock.Call
+}
+
+// DeleteLoginAttempts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - forgetBefore time.Time
+func (_e *MockLoginAttemptRepository_Expecter) DeleteLoginAttempts(ctx interface{}, forgetBefore interface{}) *MockLoginAttemptRepository_DeleteLoginAttempts_Call {
+	return &MockLoginAttemptRepository_DeleteLoginAttempts_Call{Call: _e.mock.On("DeleteLoginAttempts", ctx, forgetBefore)}
+}
+
+func (_c *MockLoginAttemptRepository_DeleteLoginAttempts_Call) Run(run func(ctx context.Context, forgetBefore time.Time)) *MockLoginAttemptRepository_DeleteLoginAttempts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptRepository_DeleteLoginAttempts_Call) Return(_a0 int64, _a1 error) *MockLoginAttemptRepository_DeleteLoginAttempts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockLoginAttemptRepository_DeleteLoginAttempts_Call) RunAndReturn(run func(context.Context, time.Time) (int64, error)) *MockLoginAttemptRepository_DeleteLoginAttempts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListLoginAttempts provides a mock function with given fields: ctx, keys
+func (_m *MockLoginAttemptRepository) ListLoginAttempts(ctx context.Context, keys []string) ([]domain.LoginAttempt, error) {
+	ret := _m.Called(ctx, keys)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListLoginAttempts")
+	}
+
+	var r0 []domain.LoginAttempt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]domain.LoginAttempt, error)); ok {
+		return rf(ctx, keys)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []domain.LoginAttempt); ok {
+		r0 = rf(ctx, keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.LoginAttempt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, keys)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockLoginAttemptRepository_ListLoginAttempts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListLoginAttempts'
+type MockLoginAttemptRepository_ListLoginAttempts_Call struct {
+	*mock.Call
+}
+
+// ListLoginAttempts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keys []string
+func (_e *MockLoginAttemptRepository_Expecter) ListLoginAttempts(ctx interface{}, keys interface{}) *MockLoginAttemptRepository_ListLoginAttempts_Call {
+	return &MockLoginAttemptRepository_ListLoginAttempts_Call{Call: _e.mock.On("ListLoginAttempts", ctx, keys)}
+}
+
+func (_c *MockLoginAttemptRepository_ListLoginAttempts_Call) Run(run func(ctx context.Context, keys []string)) *MockLoginAttemptRepository_ListLoginAttempts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptRepository_ListLoginAttempts_Call) Return(_a0 []domain.LoginAttempt, _a1 error) *MockLoginAttemptRepository_ListLoginAttempts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockLoginAttemptRepository_ListLoginAttempts_Call) RunAndReturn(run func(context.Context, []string) ([]domain.LoginAttempt, error)) *MockLoginAttemptRepository_ListLoginAttempts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LockLogin provides a mock function with given fields: ctx, key, lockedUntil
+func (_m *MockLoginAttemptRepository) LockLogin(ctx context.Context, key string, lockedUntil time.Time) error {
+	ret := _m.Called(ctx, key, lockedUntil)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockLogin")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, key, lockedUntil)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockLoginAttemptRepository_LockLogin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LockLogin'
+type MockLoginAttemptRepository_LockLogin_Call struct {
+	*mock.Call
+}
+
+// LockLogin is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - lockedUntil time.Time
+func (_e *MockLoginAttemptRepository_Expecter) LockLogin(ctx interface{}, key interface{}, lockedUntil interface{}) *MockLoginAttemptRepository_LockLogin_Call {
+	return &MockLoginAttemptRepository_LockLogin_Call{Call: _e.mock.On("LockLogin", ctx, key, lockedUntil)}
+}
+
+func (_c *MockLoginAttemptRepository_LockLogin_Call) Run(run func(ctx context.Context, key string, lockedUntil time.Time)) *MockLoginAttemptRepository_LockLogin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptRepository_LockLogin_Call) Return(_a0 error) *MockLoginAttemptRepository_LockLogin_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockLoginAttemptRepository_LockLogin_Call) RunAndReturn(run func(context.Context, string, time.Time) error) *MockLoginAttemptRepository_LockLogin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordLoginFailure provides a mock function with given fields: ctx, key, forgetBefore
+func (_m *MockLoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, forgetBefore time.Time) (*domain.LoginAttempt, error) {
+	ret := _m.Called(ctx, key, forgetBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordLoginFailure")
+	}
+
+	var r0 *domain.LoginAttempt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (*domain.LoginAttempt, error)); ok {
+		return rf(ctx, key, forgetBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) *domain.LoginAttempt); ok {
+		r0 = rf(ctx, key, forgetBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.LoginAttempt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, key, forgetBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockLoginAttemptRepository_RecordLoginFailure_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordLoginFailure'
+type MockLoginAttemptRepository_RecordLoginFailure_Call struct {
+	*mock.Call
+}
+
+// RecordLoginFailure is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - forgetBefore time.Time
+func (_e *MockLoginAttemptRepository_Expecter) RecordLoginFailure(ctx interface{}, key interface{}, forgetBefore interface{}) *MockLoginAttemptRepository_RecordLoginFailure_Call {
+	return &MockLoginAttemptRepository_RecordLoginFailure_Call{Call: _e.mock.On("RecordLoginFailure", ctx, key, forgetBefore)}
+}
+
+func (_c *MockLoginAttemptRepository_RecordLoginFailure_Call) Run(run func(ctx context.Context, key string, forgetBefore time.Time)) *MockLoginAttemptRepository_RecordLoginFailure_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptRepository_RecordLoginFailure_Call) Return(_a0 *domain.LoginAttempt, _a1 error) *MockLoginAttemptRepository_RecordLoginFailure_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockLoginAttemptRepository_RecordLoginFailure_Call) RunAndReturn(run func(context.Context, string, time.Time) (*domain.LoginAttempt, error)) *MockLoginAttemptRepository_RecordLoginFailure_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetLoginAttempts provides a mock function with given fields: ctx, key
+func (_m *MockLoginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetLoginAttempts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockLoginAttemptRepository_ResetLoginAttempts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetLoginAttempts'
+type MockLoginAttemptRepository_ResetLoginAttempts_Call struct {
+	*mock.Call
+}
+
+// ResetLoginAttempts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *MockLoginAttemptRepository_Expecter) ResetLoginAttempts(ctx interface{}, key interface{}) *MockLoginAttemptRepository_ResetLoginAttempts_Call {
+	return &MockLoginAttemptRepository_ResetLoginAttempts_Call{Call: _e.mock.On("ResetLoginAttempts", ctx, key)}
+}
+
+func (_c *MockLoginAttemptRepository_ResetLoginAttempts_Call) Run(run func(ctx context.Context, key string)) *MockLoginAttemptRepository_ResetLoginAttempts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptRepository_ResetLoginAttempts_Call) Return(_a0 error) *MockLoginAttemptRepository_ResetLoginAttempts_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockLoginAttemptRepository_ResetLoginAttempts_Call) RunAndReturn(run func(context.Context, string) error) *MockLoginAttemptRepository_ResetLoginAttempts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockLoginAttemptRepository creates a new instance of MockLoginAttemptRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockLoginAttemptRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockLoginAttemptRepository {
+	mock := &MockLoginAttemptRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,97 @@
+package loginattempt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxLoginAttemptRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxLoginAttemptRepository(dbpool *pgxpool.Pool) *PgxLoginAttemptRepository {
+	return &PgxLoginAttemptRepository{
+		dbpool: dbpool,
+	}
+}
+
+// loginAttemptColumns is the column list every query returns, in the order scanLoginAttempt reads it.
+const loginAttemptColumns = `key, failures, last_failure_at, locked_until`
+
+func scanLoginAttempt(row pgx.Row) (*domain.LoginAttempt, error) {
+	var attempt domain.LoginAttempt
+	if err := row.Scan(&attempt.Key, &attempt.Failures, &attempt.LastFailureAt, &attempt.LockedUntil); err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (repo *PgxLoginAttemptRepository) ListLoginAttempts(ctx context.Context, keys []string) ([]domain.LoginAttempt, error) {
+	errfmt := "%w: %s"
+	attempts := make([]domain.LoginAttempt, 0)
+	query := `SELECT ` + loginAttemptColumns + ` FROM login_attempts WHERE key = ANY($1)`
+	rows, err := repo.dbpool.Query(ctx, query, keys)
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralLoginAttempt, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		attempt, err := scanLoginAttempt(rows)
+		if err != nil {
+			return nil, fmt.Errorf(errfmt, domain.ErrGeneralLoginAttempt, err.Error())
+		}
+		attempts = append(attempts, *attempt)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralLoginAttempt, err.Error())
+	}
+	return attempts, nil
+}
+
+func (repo *PgxLoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, forgetBefore time.Time) (*domain.LoginAttempt, error) {
+	errfmt := "%w: %s"
+	// concurrent failures of several replicas are serialized by the upsert, none of them is lost
+	query := `INSERT INTO login_attempts (key, failures, last_failure_at) VALUES ($1, 1, now())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN GREATEST(login_attempts.last_failure_at, login_attempts.locked_until) < $2 THEN 1 ELSE login_attempts.failures + 1 END,
+			locked_until = CASE WHEN GREATEST(login_attempts.last_failure_at, login_attempts.locked_until) < $2 THEN NULL ELSE login_attempts.locked_until END,
+			last_failure_at = now()
+		RETURNING ` + loginAttemptColumns
+
+	attempt, err := scanLoginAttempt(repo.dbpool.QueryRow(ctx, query, key, forgetBefore))
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralLoginAttempt, err.Error())
+	}
+	return attempt, nil
+}
+
+func (repo *PgxLoginAttemptRepository) LockLogin(ctx context.Context, key string, lockedUntil time.Time) error {
+	query := `UPDATE login_attempts SET locked_until = GREATEST(locked_until, $2) WHERE key = $1`
+	if _, err := repo.dbpool.Exec(ctx, query, key, lockedUntil); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralLoginAttempt, err.Error())
+	}
+	return nil
+}
+
+func (repo *PgxLoginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	query := `DELETE FROM login_attempts WHERE key = $1`
+	if _, err := repo.dbpool.Exec(ctx, query, key); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralLoginAttempt, err.Error())
+	}
+	return nil
+}
+
+func (repo *PgxLoginAttemptRepository) DeleteLoginAttempts(ctx context.Context, forgetBefore time.Time) (int64, error) {
+	query := `DELETE FROM login_attempts WHERE GREATEST(last_failure_at, locked_until) < $1`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, forgetBefore)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", domain.ErrGeneralLoginAttempt, err.Error())
+	}
+	return cmdTag.RowsAffected(), nil
+}
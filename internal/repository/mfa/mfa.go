@@ -0,0 +1,31 @@
+package mfa
+
+import (
+	"context"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+)
+
+type IMFARepository interface {
+	// CreateTOTP stores a new unconfirmed TOTP factor of the user, replacing an unconfirmed one.
+	// ErrMFAAlreadyEnrolled is returned when the user has a confirmed factor.
+	CreateTOTP(ctx context.Context, userID uuid.UUID, secret []byte) (*domain.TOTP, error)
+	GetTOTP(ctx context.Context, userID uuid.UUID) (*domain.TOTP, error)
+	// ConfirmTOTP confirms the factor of the user together with its first recovery codes
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, recoveryCodeHashes [][]byte) error
+	// UseTOTPStep records the time step of an accepted code, ErrMFACodeInvalid is returned when a code
+	// of that step or a later one was already accepted.
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error
+	// UseRecoveryCode spends an unused recovery code of the user, or returns ErrMFACodeInvalid
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash []byte) error
+	// DeleteMFA removes the TOTP factor and the recovery codes of the user
+	DeleteMFA(ctx context.Context, userID uuid.UUID) error
+
+	CreateMFAChallenge(ctx context.Context, challenge *domain.MFAChallenge) error
+	GetMFAChallengeByHash(ctx context.Context, challengeHash []byte) (*domain.MFAChallenge, error)
+	// DeleteMFAChallenge answers the challenge, concurrent answers race to a single winner and the
+	// others get ErrMFAChallengeInvalid
+	DeleteMFAChallenge(ctx context.Context, challengeHash []byte) error
+	DeleteExpiredMFAChallenges(ctx context.Context) (int64, error)
+}
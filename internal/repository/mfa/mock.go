@@ -0,0 +1,559 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package mfa
+
+import (
+	context "context"
+
+	domain "github.com/bright-pentium/go-client-practice/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// MockMFARepository is an autogenerated mock type for the IMFARepository type
+type MockMFARepository struct {
+	mock.Mock
+}
+
+type MockMFARepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockMFARepository) EXPECT() *MockMFARepository_Expecter {
+	return &MockMFARepository_Expecter{mock: &_m.Mock}
+}
+
+// ConfirmTOTP provides a mock function with given fields: ctx, userID, recoveryCodeHashes
+func (_m *MockMFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, recoveryCodeHashes [][]byte) error {
+	ret := _m.Called(ctx, userID, recoveryCodeHashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmTOTP")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, [][]byte) error); ok {
+		r0 = rf(ctx, userID, recoveryCodeHashes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMFARepository_ConfirmTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConfirmTOTP'
+type MockMFARepository_ConfirmTOTP_Call struct {
+	*mock.Call
+}
+
+// ConfirmTOTP is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+//   - recoveryCodeHashes [][]byte
+func (_e *MockMFARepository_Expecter) ConfirmTOTP(ctx interface{}, userID interface{}, recoveryCodeHashes interface{}) *MockMFARepository_ConfirmTOTP_Call {
+	return &MockMFARepository_ConfirmTOTP_Call{Call: _e.mock.On("ConfirmTOTP", ctx, userID, recoveryCodeHashes)}
+}
+
+func (_c *MockMFARepository_ConfirmTOTP_Call) Run(run func(ctx context.Context, userID uuid.UUID, recoveryCodeHashes [][]byte)) *MockMFARepository_ConfirmTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].([][]byte))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_ConfirmTOTP_Call) Return(_a0 error) *MockMFARepository_ConfirmTOTP_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMFARepository_ConfirmTOTP_Call) RunAndReturn(run func(context.Context, uuid.UUID, [][]byte) error) *MockMFARepository_ConfirmTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateMFAChallenge provides a mock function with given fields: ctx, challenge
+func (_m *MockMFARepository) CreateMFAChallenge(ctx context.Context, challenge *domain.MFAChallenge) error {
+	ret := _m.Called(ctx, challenge)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateMFAChallenge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.MFAChallenge) error); ok {
+		r0 = rf(ctx, challenge)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMFARepository_CreateMFAChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateMFAChallenge'
+type MockMFARepository_CreateMFAChallenge_Call struct {
+	*mock.Call
+}
+
+// CreateMFAChallenge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - challenge *domain.MFAChallenge
+func (_e *MockMFARepository_Expecter) CreateMFAChallenge(ctx interface{}, challenge interface{}) *MockMFARepository_CreateMFAChallenge_Call {
+	return &MockMFARepository_CreateMFAChallenge_Call{Call: _e.mock.On("CreateMFAChallenge", ctx, challenge)}
+}
+
+func (_c *MockMFARepository_CreateMFAChallenge_Call) Run(run func(ctx context.Context, challenge *domain.MFAChallenge)) *MockMFARepository_CreateMFAChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.MFAChallenge))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_CreateMFAChallenge_Call) Return(_a0 error) *MockMFARepository_CreateMFAChallenge_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMFARepository_CreateMFAChallenge_Call) RunAndReturn(run func(context.Context, *domain.MFAChallenge) error) *MockMFARepository_CreateMFAChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateTOTP provides a mock function with given fields: ctx, userID, secret
+func (_m *MockMFARepository) CreateTOTP(ctx context.Context, userID uuid.UUID, secret []byte) (*domain.TOTP, error) {
+	ret := _m.Called(ctx, userID, secret)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTOTP")
+	}
+
+	var r0 *domain.TOTP
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []byte) (*domain.TOTP, error)); ok {
+		return rf(ctx, userID, secret)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []byte) *domain.TOTP); ok {
+		r0 = rf(ctx, userID, secret)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TOTP)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, []byte) error); ok {
+		r1 = rf(ctx, userID, secret)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockMFARepository_CreateTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateTOTP'
+type MockMFARepository_CreateTOTP_Call struct {
+	*mock.Call
+}
+
+// CreateTOTP is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+//   - secret []byte
+func (_e *MockMFARepository_Expecter) CreateTOTP(ctx interface{}, userID interface{}, secret interface{}) *MockMFARepository_CreateTOTP_Call {
+	return &MockMFARepository_CreateTOTP_Call{Call: _e.mock.On("CreateTOTP", ctx, userID, secret)}
+}
+
+func (_c *MockMFARepository_CreateTOTP_Call) Run(run func(ctx context.Context, userID uuid.UUID, secret []byte)) *MockMFARepository_CreateTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_CreateTOTP_Call) Return(_a0 *domain.TOTP, _a1 error) *MockMFARepository_CreateTOTP_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockMFARepository_CreateTOTP_Call) RunAndReturn(run func(context.Context, uuid.UUID, []byte) (*domain.TOTP, error)) *MockMFARepository_CreateTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpiredMFAChallenges provides a mock function with given fields: ctx
+func (_m *MockMFARepository) DeleteExpiredMFAChallenges(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredMFAChallenges")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockMFARepository_DeleteExpiredMFAChallenges_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpiredMFAChallenges'
+type MockMFARepository_DeleteExpiredMFAChallenges_Call struct {
+	*mock.Call
+}
+
+// DeleteExpiredMFAChallenges is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockMFARepository_Expecter) DeleteExpiredMFAChallenges(ctx interface{}) *MockMFARepository_DeleteExpiredMFAChallenges_Call {
+	return &MockMFARepository_DeleteExpiredMFAChallenges_Call{Call: _e.mock.On("DeleteExpiredMFAChallenges", ctx)}
+}
+
+func (_c *MockMFARepository_DeleteExpiredMFAChallenges_Call) Run(run func(ctx context.Context)) *MockMFARepository_DeleteExpiredMFAChallenges_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_DeleteExpiredMFAChallenges_Call) Return(_a0 int64, _a1 error) *MockMFARepository_DeleteExpiredMFAChallenges_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockMFARepository_DeleteExpiredMFAChallenges_Call) RunAndReturn(run func(context.Context) (int64, error)) *MockMFARepository_DeleteExpiredMFAChallenges_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteMFA provides a mock function with given fields: ctx, userID
+func (_m *MockMFARepository) DeleteMFA(ctx context.Context, userID uuid.UUID) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMFA")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMFARepository_DeleteMFA_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMFA'
+type MockMFARepository_DeleteMFA_Call struct {
+	*mock.Call
+}
+
+// DeleteMFA is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockMFARepository_Expecter) DeleteMFA(ctx interface{}, userID interface{}) *MockMFARepository_DeleteMFA_Call {
+	return &MockMFARepository_DeleteMFA_Call{Call: _e.mock.On("DeleteMFA", ctx, userID)}
+}
+
+func (_c *MockMFARepository_DeleteMFA_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockMFARepository_DeleteMFA_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_DeleteMFA_Call) Return(_a0 error) *MockMFARepository_DeleteMFA_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMFARepository_DeleteMFA_Call) RunAndReturn(run func(context.Context, uuid.UUID) error) *MockMFARepository_DeleteMFA_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteMFAChallenge provides a mock function with given fields: ctx, challengeHash
+func (_m *MockMFARepository) DeleteMFAChallenge(ctx context.Context, challengeHash []byte) error {
+	ret := _m.Called(ctx, challengeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMFAChallenge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) error); ok {
+		r0 = rf(ctx, challengeHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMFARepository_DeleteMFAChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteMFAChallenge'
+type MockMFARepository_DeleteMFAChallenge_Call struct {
+	*mock.Call
+}
+
+// DeleteMFAChallenge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - challengeHash []byte
+func (_e *MockMFARepository_Expecter) DeleteMFAChallenge(ctx interface{}, challengeHash interface{}) *MockMFARepository_DeleteMFAChallenge_Call {
+	return &MockMFARepository_DeleteMFAChallenge_Call{Call: _e.mock.On("DeleteMFAChallenge", ctx, challengeHash)}
+}
+
+func (_c *MockMFARepository_DeleteMFAChallenge_Call) Run(run func(ctx context.Context, challengeHash []byte)) *MockMFARepository_DeleteMFAChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_DeleteMFAChallenge_Call) Return(_a0 error) *MockMFARepository_DeleteMFAChallenge_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMFARepository_DeleteMFAChallenge_Call) RunAndReturn(run func(context.Context, []byte) error) *MockMFARepository_DeleteMFAChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMFAChallengeByHash provides a mock function with given fields: ctx, challengeHash
+func (_m *MockMFARepository) GetMFAChallengeByHash(ctx context.Context, challengeHash []byte) (*domain.MFAChallenge, error) {
+	ret := _m.Called(ctx, challengeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMFAChallengeByHash")
+	}
+
+	var r0 *domain.MFAChallenge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*domain.MFAChallenge, error)); ok {
+		return rf(ctx, challengeHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *domain.MFAChallenge); ok {
+		r0 = rf(ctx, challengeHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.MFAChallenge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, challengeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockMFARepository_GetMFAChallengeByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMFAChallengeByHash'
+type MockMFARepository_GetMFAChallengeByHash_Call struct {
+	*mock.Call
+}
+
+// GetMFAChallengeByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - challengeHash []byte
+func (_e *MockMFARepository_Expecter) GetMFAChallengeByHash(ctx interface{}, challengeHash interface{}) *MockMFARepository_GetMFAChallengeByHash_Call {
+	return &MockMFARepository_GetMFAChallengeByHash_Call{Call: _e.mock.On("GetMFAChallengeByHash", ctx, challengeHash)}
+}
+
+func (_c *MockMFARepository_GetMFAChallengeByHash_Call) Run(run func(ctx context.Context, challengeHash []byte)) *MockMFARepository_GetMFAChallengeByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_GetMFAChallengeByHash_Call) Return(_a0 *domain.MFAChallenge, _a1 error) *MockMFARepository_GetMFAChallengeByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockMFARepository_GetMFAChallengeByHash_Call) RunAndReturn(run func(context.Context, []byte) (*domain.MFAChallenge, error)) *MockMFARepository_GetMFAChallengeByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTOTP provides a mock function with given fields: ctx, userID
+func (_m *MockMFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*domain.TOTP, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTOTP")
+	}
+
+	var r0 *domain.TOTP
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (*domain.TOTP, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) *domain.TOTP); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TOTP)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockMFARepository_GetTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTOTP'
+type MockMFARepository_GetTOTP_Call struct {
+	*mock.Call
+}
+
+// GetTOTP is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockMFARepository_Expecter) GetTOTP(ctx interface{}, userID interface{}) *MockMFARepository_GetTOTP_Call {
+	return &MockMFARepository_GetTOTP_Call{Call: _e.mock.On("GetTOTP", ctx, userID)}
+}
+
+func (_c *MockMFARepository_GetTOTP_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockMFARepository_GetTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_GetTOTP_Call) Return(_a0 *domain.TOTP, _a1 error) *MockMFARepository_GetTOTP_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockMFARepository_GetTOTP_Call) RunAndReturn(run func(context.Context, uuid.UUID) (*domain.TOTP, error)) *MockMFARepository_GetTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UseRecoveryCode provides a mock function with given fields: ctx, userID, codeHash
+func (_m *MockMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash []byte) error {
+	ret := _m.Called(ctx, userID, codeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseRecoveryCode")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []byte) error); ok {
+		r0 = rf(ctx, userID, codeHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMFARepository_UseRecoveryCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseRecoveryCode'
+type MockMFARepository_UseRecoveryCode_Call struct {
+	*mock.Call
+}
+
+// UseRecoveryCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+//   - codeHash []byte
+func (_e *MockMFARepository_Expecter) UseRecoveryCode(ctx interface{}, userID interface{}, codeHash interface{}) *MockMFARepository_UseRecoveryCode_Call {
+	return &MockMFARepository_UseRecoveryCode_Call{Call: _e.mock.On("UseRecoveryCode", ctx, userID, codeHash)}
+}
+
+func (_c *MockMFARepository_UseRecoveryCode_Call) Run(run func(ctx context.Context, userID uuid.UUID, codeHash []byte)) *MockMFARepository_UseRecoveryCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_UseRecoveryCode_Call) Return(_a0 error) *MockMFARepository_UseRecoveryCode_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMFARepository_UseRecoveryCode_Call) RunAndReturn(run func(context.Context, uuid.UUID, []byte) error) *MockMFARepository_UseRecoveryCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UseTOTPStep provides a mock function with given fields: ctx, userID, step
+func (_m *MockMFARepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	ret := _m.Called(ctx, userID, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseTOTPStep")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int64) error); ok {
+		r0 = rf(ctx, userID, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockMFARepository_UseTOTPStep_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseTOTPStep'
+type MockMFARepository_UseTOTPStep_Call struct {
+	*mock.Call
+}
+
+// UseTOTPStep is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+//   - step int64
+func (_e *MockMFARepository_Expecter) UseTOTPStep(ctx interface{}, userID interface{}, step interface{}) *MockMFARepository_UseTOTPStep_Call {
+	return &MockMFARepository_UseTOTPStep_Call{Call: _e.mock.On("UseTOTPStep", ctx, userID, step)}
+}
+
+func (_c *MockMFARepository_UseTOTPStep_Call) Run(run func(ctx context.Context, userID uuid.UUID, step int64)) *MockMFARepository_UseTOTPStep_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_UseTOTPStep_Call) Return(_a0 error) *MockMFARepository_UseTOTPStep_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockMFARepository_UseTOTPStep_Call) RunAndReturn(run func(context.Context, uuid.UUID, int64) error) *MockMFARepository_UseTOTPStep_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockMFARepository creates a new instance of MockMFARepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockMFARepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockMFARepository {
+	mock := &MockMFARepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,190 @@
+package mfa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxMFARepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxMFARepository(dbpool *pgxpool.Pool) *PgxMFARepository {
+	return &PgxMFARepository{
+		dbpool: dbpool,
+	}
+}
+
+// totpColumns is the column list every TOTP query returns, in the order scanTOTP reads it.
+const totpColumns = `user_id, secret, confirmed_at, last_used_step, created_at`
+
+func scanTOTP(row pgx.Row) (*domain.TOTP, error) {
+	var totp domain.TOTP
+	if err := row.Scan(&totp.UserID, &totp.Secret, &totp.ConfirmedAt, &totp.LastUsedStep, &totp.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+func (repo *PgxMFARepository) CreateTOTP(ctx context.Context, userID uuid.UUID, secret []byte) (*domain.TOTP, error) {
+	errfmt := "%w: %s"
+	// a confirmed factor is left alone, the upsert then returns no row
+	query := `INSERT INTO user_totp (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, created_at = now()
+		WHERE user_totp.confirmed_at IS NULL
+		RETURNING ` + totpColumns
+
+	totp, err := scanTOTP(repo.dbpool.QueryRow(ctx, query, userID, secret))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(errfmt, domain.ErrMFAAlreadyEnrolled, err.Error())
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			// foreign_key_violation
+			return nil, fmt.Errorf(errfmt, domain.ErrUserNotFound, pgErr.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralMFA, err.Error())
+	}
+	return totp, nil
+}
+
+func (repo *PgxMFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*domain.TOTP, error) {
+	errfmt := "%w: %s"
+	query := `SELECT ` + totpColumns + ` FROM user_totp WHERE user_id = $1`
+
+	totp, err := scanTOTP(repo.dbpool.QueryRow(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(errfmt, domain.ErrMFANotEnrolled, err.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralMFA, err.Error())
+	}
+	return totp, nil
+}
+
+func (repo *PgxMFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, recoveryCodeHashes [][]byte) error {
+	errfmt := "%w: %s"
+	// one statement, the factor is never confirmed without its recovery codes
+	query := `WITH confirmed AS (
+			UPDATE user_totp SET confirmed_at = now() WHERE user_id = $1 AND confirmed_at IS NULL RETURNING user_id
+		), cleared AS (
+			DELETE FROM recovery_codes WHERE user_id IN (SELECT user_id FROM confirmed)
+		)
+		INSERT INTO recovery_codes (code_hash, user_id) SELECT code_hash, user_id FROM confirmed, unnest($2::bytea[]) AS code_hash`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, userID, recoveryCodeHashes)
+	if err != nil {
+		return fmt.Errorf(errfmt, domain.ErrGeneralMFA, err.Error())
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf(errfmt, domain.ErrMFANotEnrolled, "no factor waiting for confirmation")
+	}
+	return nil
+}
+
+func (repo *PgxMFARepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	errfmt := "%w: %s"
+	query := `UPDATE user_totp SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf(errfmt, domain.ErrGeneralMFA, err.Error())
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf(errfmt, domain.ErrMFACodeInvalid, "code was already used")
+	}
+	return nil
+}
+
+func (repo *PgxMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash []byte) error {
+	errfmt := "%w: %s"
+	query := `UPDATE recovery_codes SET used_at = now() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return fmt.Errorf(errfmt, domain.ErrGeneralMFA, err.Error())
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf(errfmt, domain.ErrMFACodeInvalid, "unknown or used recovery code")
+	}
+	return nil
+}
+
+func (repo *PgxMFARepository) DeleteMFA(ctx context.Context, userID uuid.UUID) error {
+	errfmt := "%w: %s"
+	query := `WITH codes AS (DELETE FROM recovery_codes WHERE user_id = $1) DELETE FROM user_totp WHERE user_id = $1`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf(errfmt, domain.ErrGeneralMFA, err.Error())
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf(errfmt, domain.ErrMFANotEnrolled, userID.String())
+	}
+	return nil
+}
+
+// mfaChallengeColumns is the column list every challenge query returns, in the order scanMFAChallenge reads it.
+const mfaChallengeColumns = `challenge_hash, user_id, mode, auth_time, expires_at`
+
+func scanMFAChallenge(row pgx.Row) (*domain.MFAChallenge, error) {
+	var challenge domain.MFAChallenge
+	if err := row.Scan(&challenge.ChallengeHash, &challenge.UserID, &challenge.Mode, &challenge.AuthTime, &challenge.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (repo *PgxMFARepository) CreateMFAChallenge(ctx context.Context, challenge *domain.MFAChallenge) error {
+	errfmt := "%w: %s"
+	query := `INSERT INTO mfa_challenges (challenge_hash, user_id, mode, auth_time, expires_at) VALUES ($1, $2, $3, $4, $5)`
+	if _, err := repo.dbpool.Exec(ctx, query, challenge.ChallengeHash, challenge.UserID, challenge.Mode, challenge.AuthTime, challenge.ExpiresAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			// foreign_key_violation
+			return fmt.Errorf(errfmt, domain.ErrUserNotFound, pgErr.Error())
+		}
+		return fmt.Errorf(errfmt, domain.ErrGeneralMFA, err.Error())
+	}
+	return nil
+}
+
+func (repo *PgxMFARepository) GetMFAChallengeByHash(ctx context.Context, challengeHash []byte) (*domain.MFAChallenge, error) {
+	errfmt := "%w: %s"
+	query := `SELECT ` + mfaChallengeColumns + ` FROM mfa_challenges WHERE challenge_hash = $1`
+
+	challenge, err := scanMFAChallenge(repo.dbpool.QueryRow(ctx, query, challengeHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(errfmt, domain.ErrMFAChallengeInvalid, err.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralMFA, err.Error())
+	}
+	return challenge, nil
+}
+
+func (repo *PgxMFARepository) DeleteMFAChallenge(ctx context.Context, challengeHash []byte) error {
+	errfmt := "%w: %s"
+	query := `DELETE FROM mfa_challenges WHERE challenge_hash = $1`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, challengeHash)
+	if err != nil {
+		return fmt.Errorf(errfmt, domain.ErrGeneralMFA, err.Error())
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf(errfmt, domain.ErrMFAChallengeInvalid, "challenge was already answered")
+	}
+	return nil
+}
+
+func (repo *PgxMFARepository) DeleteExpiredMFAChallenges(ctx context.Context) (int64, error) {
+	query := `DELETE FROM mfa_challenges WHERE expires_at <= now()`
+	cmdTag, err := repo.dbpool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", domain.ErrGeneralMFA, err.Error())
+	}
+	return cmdTag.RowsAffected(), nil
+}
@@ -0,0 +1,258 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package opaque
+
+import (
+	context "context"
+
+	domain "github.com/bright-pentium/go-client-practice/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockOpaqueTokenRepository is an autogenerated mock type for the IOpaqueTokenRepository type
+type MockOpaqueTokenRepository struct {
+	mock.Mock
+}
+
+type MockOpaqueTokenRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOpaqueTokenRepository) EXPECT() *MockOpaqueTokenRepository_Expecter {
+	return &MockOpaqueTokenRepository_Expecter{mock: &_m.Mock}
+}
+
+// CreateOpaqueToken provides a mock function with given fields: ctx, token
+func (_m *MockOpaqueTokenRepository) CreateOpaqueToken(ctx context.Context, token *domain.OpaqueToken) (*domain.OpaqueToken, error) {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOpaqueToken")
+	}
+
+	var r0 *domain.OpaqueToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.OpaqueToken) (*domain.OpaqueToken, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.OpaqueToken) *domain.OpaqueToken); ok {
+		r0 = rf(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.OpaqueToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.OpaqueToken) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockOpaqueTokenRepository_CreateOpaqueToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateOpaqueToken'
+type MockOpaqueTokenRepository_CreateOpaqueToken_Call struct {
+	*mock.Call
+}
+
+// CreateOpaqueToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token *domain.OpaqueToken
+func (_e *MockOpaqueTokenRepository_Expecter) CreateOpaqueToken(ctx interface{}, token interface{}) *MockOpaqueTokenRepository_CreateOpaqueToken_Call {
+	return &MockOpaqueTokenRepository_CreateOpaqueToken_Call{Call: _e.mock.On("CreateOpaqueToken", ctx, token)}
+}
+
+func (_c *MockOpaqueTokenRepository_CreateOpaqueToken_Call) Run(run func(ctx context.Context, token *domain.OpaqueToken)) *MockOpaqueTokenRepository_CreateOpaqueToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.OpaqueToken))
+	})
+	return _c
+}
+
+func (_c *MockOpaqueTokenRepository_CreateOpaqueToken_Call) Return(_a0 *domain.OpaqueToken, _a1 error) *MockOpaqueTokenRepository_CreateOpaqueToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockOpaqueTokenRepository_CreateOpaqueToken_Call) RunAndReturn(run func(context.Context, *domain.OpaqueToken) (*domain.OpaqueToken, error)) *MockOpaqueTokenRepository_CreateOpaqueToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpiredOpaqueTokens provides a mock function with given fields: ctx
+func (_m *MockOpaqueTokenRepository) DeleteExpiredOpaqueTokens(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredOpaqueTokens")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockOpaqueTokenRepository_DeleteExpiredOpaqueTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpiredOpaqueTokens'
+type MockOpaqueTokenRepository_DeleteExpiredOpaqueTokens_Call struct {
+	*mock.Call
+}
+
+// DeleteExpiredOpaqueTokens is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockOpaqueTokenRepository_Expecter) DeleteExpiredOpaqueTokens(ctx interface{}) *MockOpaqueTokenRepository_DeleteExpiredOpaqueTokens_Call {
+	return &MockOpaqueTokenRepository_DeleteExpiredOpaqueTokens_Call{Call: _e.mock.On("DeleteExpiredOpaqueTokens", ctx)}
+}
+
+func (_c *MockOpaqueTokenRepository_DeleteExpiredOpaqueTokens_Call) Run(run func(ctx context.Context)) *MockOpaqueTokenRepository_DeleteExpiredOpaqueTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockOpaqueTokenRepository_DeleteExpiredOpaqueTokens_Call) Return(_a0 int64, _a1 error) *MockOpaqueTokenRepository_DeleteExpiredOpaqueTokens_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockOpaqueTokenRepository_DeleteExpiredOpaqueTokens_Call) RunAndReturn(run func(context.Context) (int64, error)) *MockOpaqueTokenRepository_DeleteExpiredOpaqueTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOpaqueTokenByHash provides a mock function with given fields: ctx, tokenHash
+func (_m *MockOpaqueTokenRepository) GetOpaqueTokenByHash(ctx context.Context, tokenHash []byte) (*domain.OpaqueToken, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOpaqueTokenByHash")
+	}
+
+	var r0 *domain.OpaqueToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*domain.OpaqueToken, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *domain.OpaqueToken); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.OpaqueToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockOpaqueTokenRepository_GetOpaqueTokenByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOpaqueTokenByHash'
+type MockOpaqueTokenRepository_GetOpaqueTokenByHash_Call struct {
+	*mock.Call
+}
+
+// GetOpaqueTokenByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenHash []byte
+func (_e *MockOpaqueTokenRepository_Expecter) GetOpaqueTokenByHash(ctx interface{}, tokenHash interface{}) *MockOpaqueTokenRepository_GetOpaqueTokenByHash_Call {
+	return &MockOpaqueTokenRepository_GetOpaqueTokenByHash_Call{Call: _e.mock.On("GetOpaqueTokenByHash", ctx, tokenHash)}
+}
+
+func (_c *MockOpaqueTokenRepository_GetOpaqueTokenByHash_Call) Run(run func(ctx context.Context, tokenHash []byte)) *MockOpaqueTokenRepository_GetOpaqueTokenByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockOpaqueTokenRepository_GetOpaqueTokenByHash_Call) Return(_a0 *domain.OpaqueToken, _a1 error) *MockOpaqueTokenRepository_GetOpaqueTokenByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockOpaqueTokenRepository_GetOpaqueTokenByHash_Call) RunAndReturn(run func(context.Context, []byte) (*domain.OpaqueToken, error)) *MockOpaqueTokenRepository_GetOpaqueTokenByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeOpaqueToken provides a mock function with given fields: ctx, tokenHash
+func (_m *MockOpaqueTokenRepository) RevokeOpaqueToken(ctx context.Context, tokenHash []byte) error {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeOpaqueToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) error); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockOpaqueTokenRepository_RevokeOpaqueToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeOpaqueToken'
+type MockOpaqueTokenRepository_RevokeOpaqueToken_Call struct {
+	*mock.Call
+}
+
+// RevokeOpaqueToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenHash []byte
+func (_e *MockOpaqueTokenRepository_Expecter) RevokeOpaqueToken(ctx interface{}, tokenHash interface{}) *MockOpaqueTokenRepository_RevokeOpaqueToken_Call {
+	return &MockOpaqueTokenRepository_RevokeOpaqueToken_Call{Call: _e.mock.On("RevokeOpaqueToken", ctx, tokenHash)}
+}
+
+func (_c *MockOpaqueTokenRepository_RevokeOpaqueToken_Call) Run(run func(ctx context.Context, tokenHash []byte)) *MockOpaqueTokenRepository_RevokeOpaqueToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockOpaqueTokenRepository_RevokeOpaqueToken_Call) Return(_a0 error) *MockOpaqueTokenRepository_RevokeOpaqueToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockOpaqueTokenRepository_RevokeOpaqueToken_Call) RunAndReturn(run func(context.Context, []byte) error) *MockOpaqueTokenRepository_RevokeOpaqueToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOpaqueTokenRepository creates a new instance of MockOpaqueTokenRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOpaqueTokenRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOpaqueTokenRepository {
+	mock := &MockOpaqueTokenRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
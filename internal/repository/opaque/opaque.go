@@ -0,0 +1,14 @@
+package opaque
+
+import (
+	"context"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+)
+
+type IOpaqueTokenRepository interface {
+	CreateOpaqueToken(ctx context.Context, token *domain.OpaqueToken) (*domain.OpaqueToken, error)
+	GetOpaqueTokenByHash(ctx context.Context, tokenHash []byte) (*domain.OpaqueToken, error)
+	RevokeOpaqueToken(ctx context.Context, tokenHash []byte) error
+	DeleteExpiredOpaqueTokens(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,73 @@
+package opaque
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxOpaqueTokenRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxOpaqueTokenRepository(dbpool *pgxpool.Pool) *PgxOpaqueTokenRepository {
+	return &PgxOpaqueTokenRepository{
+		dbpool: dbpool,
+	}
+}
+
+// opaqueTokenColumns is the column list every query returns, in the order scanOpaqueToken reads it.
+const opaqueTokenColumns = `token_hash, subject, type, scope, claims, expires_at, revoked_at, created_at`
+
+func scanOpaqueToken(row pgx.Row) (*domain.OpaqueToken, error) {
+	var token domain.OpaqueToken
+	if err := row.Scan(&token.TokenHash, &token.Subject, &token.Type, &token.Scope, &token.Claims, &token.ExpiresAt, &token.RevokedAt, &token.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (repo *PgxOpaqueTokenRepository) CreateOpaqueToken(ctx context.Context, token *domain.OpaqueToken) (*domain.OpaqueToken, error) {
+	query := `INSERT INTO opaque_tokens (token_hash, subject, type, scope, claims, expires_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING ` + opaqueTokenColumns
+
+	created, err := scanOpaqueToken(repo.dbpool.QueryRow(ctx, query, token.TokenHash, token.Subject, token.Type, token.Scope, token.Claims, token.ExpiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrGeneralOpaqueToken, err.Error())
+	}
+	return created, nil
+}
+
+func (repo *PgxOpaqueTokenRepository) GetOpaqueTokenByHash(ctx context.Context, tokenHash []byte) (*domain.OpaqueToken, error) {
+	errfmt := "%w: %s"
+	query := `SELECT ` + opaqueTokenColumns + ` FROM opaque_tokens WHERE token_hash = $1`
+
+	token, err := scanOpaqueToken(repo.dbpool.QueryRow(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(errfmt, domain.ErrOpaqueTokenNotFound, err.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralOpaqueToken, err.Error())
+	}
+	return token, nil
+}
+
+func (repo *PgxOpaqueTokenRepository) RevokeOpaqueToken(ctx context.Context, tokenHash []byte) error {
+	query := `UPDATE opaque_tokens SET revoked_at = now() WHERE token_hash = $1 AND revoked_at IS NULL`
+	if _, err := repo.dbpool.Exec(ctx, query, tokenHash); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralOpaqueToken, err.Error())
+	}
+	return nil
+}
+
+func (repo *PgxOpaqueTokenRepository) DeleteExpiredOpaqueTokens(ctx context.Context) (int64, error) {
+	query := `DELETE FROM opaque_tokens WHERE expires_at <= now()`
+	cmdTag, err := repo.dbpool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", domain.ErrGeneralOpaqueToken, err.Error())
+	}
+	return cmdTag.RowsAffected(), nil
+}
@@ -0,0 +1,149 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package passwordhistory
+
+import (
+	context "context"
+
+	domain "github.com/bright-pentium/go-client-practice/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// MockPasswordHistoryRepository is an autogenerated mock type for the IPasswordHistoryRepository type
+type MockPasswordHistoryRepository struct {
+	mock.Mock
+}
+
+type MockPasswordHistoryRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPasswordHistoryRepository) EXPECT() *MockPasswordHistoryRepository_Expecter {
+	return &MockPasswordHistoryRepository_Expecter{mock: &_m.Mock}
+}
+
+// AddPasswordHash provides a mock function with given fields: ctx, userID, passwordHash, pepperVersion, keep
+func (_m *MockPasswordHistoryRepository) AddPasswordHash(ctx context.Context, userID uuid.UUID, passwordHash []byte, pepperVersion int, keep int) error {
+	ret := _m.Called(ctx, userID, passwordHash, pepperVersion, keep)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddPasswordHash")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, []byte, int, int) error); ok {
+		r0 = rf(ctx, userID, passwordHash, pepperVersion, keep)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockPasswordHistoryRepository_AddPasswordHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddPasswordHash'
+type MockPasswordHistoryRepository_AddPasswordHash_Call struct {
+	*mock.Call
+}
+
+// AddPasswordHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+//   - passwordHash []byte
+//   - pepperVersion int
+//   - keep int
+func (_e *MockPasswordHistoryRepository_Expecter) AddPasswordHash(ctx interface{}, userID interface{}, passwordHash interface{}, pepperVersion interface{}, keep interface{}) *MockPasswordHistoryRepository_AddPasswordHash_Call {
+	return &MockPasswordHistoryRepository_AddPasswordHash_Call{Call: _e.mock.On("AddPasswordHash", ctx, userID, passwordHash, pepperVersion, keep)}
+}
+
+func (_c *MockPasswordHistoryRepository_AddPasswordHash_Call) Run(run func(ctx context.Context, userID uuid.UUID, passwordHash []byte, pepperVersion int, keep int)) *MockPasswordHistoryRepository_AddPasswordHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].([]byte), args[3].(int), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *MockPasswordHistoryRepository_AddPasswordHash_Call) Return(_a0 error) *MockPasswordHistoryRepository_AddPasswordHash_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockPasswordHistoryRepository_AddPasswordHash_Call) RunAndReturn(run func(context.Context, uuid.UUID, []byte, int, int) error) *MockPasswordHistoryRepository_AddPasswordHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListPasswordHashes provides a mock function with given fields: ctx, userID, limit
+func (_m *MockPasswordHistoryRepository) ListPasswordHashes(ctx context.Context, userID uuid.UUID, limit int) ([]domain.PasswordHistoryEntry, error) {
+	ret := _m.Called(ctx, userID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPasswordHashes")
+	}
+
+	var r0 []domain.PasswordHistoryEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int) ([]domain.PasswordHistoryEntry, error)); ok {
+		return rf(ctx, userID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int) []domain.PasswordHistoryEntry); ok {
+		r0 = rf(ctx, userID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PasswordHistoryEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, int) error); ok {
+		r1 = rf(ctx, userID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockPasswordHistoryRepository_ListPasswordHashes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListPasswordHashes'
+type MockPasswordHistoryRepository_ListPasswordHashes_Call struct {
+	*mock.Call
+}
+
+// ListPasswordHashes is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+//   - limit int
+func (_e *MockPasswordHistoryRepository_Expecter) ListPasswordHashes(ctx interface{}, userID interface{}, limit interface{}) *MockPasswordHistoryRepository_ListPasswordHashes_Call {
+	return &MockPasswordHistoryRepository_ListPasswordHashes_Call{Call: _e.mock.On("ListPasswordHashes", ctx, userID, limit)}
+}
+
+func (_c *MockPasswordHistoryRepository_ListPasswordHashes_Call) Run(run func(ctx context.Context, userID uuid.UUID, limit int)) *MockPasswordHistoryRepository_ListPasswordHashes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockPasswordHistoryRepository_ListPasswordHashes_Call) Return(_a0 []domain.PasswordHistoryEntry, _a1 error) *MockPasswordHistoryRepository_ListPasswordHashes_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockPasswordHistoryRepository_ListPasswordHashes_Call) RunAndReturn(run func(context.Context, uuid.UUID, int) ([]domain.PasswordHistoryEntry, error)) *MockPasswordHistoryRepository_ListPasswordHashes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockPasswordHistoryRepository creates a new instance of MockPasswordHistoryRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPasswordHistoryRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPasswordHistoryRepository {
+	mock := &MockPasswordHistoryRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,15 @@
+package passwordhistory
+
+import (
+	"context"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+)
+
+type IPasswordHistoryRepository interface {
+	// AddPasswordHash records a previous password hash of the user and keeps only the latest keep entries
+	AddPasswordHash(ctx context.Context, userID uuid.UUID, passwordHash []byte, pepperVersion int, keep int) error
+	// ListPasswordHashes returns at most limit previous password hashes of the user, latest first
+	ListPasswordHashes(ctx context.Context, userID uuid.UUID, limit int) ([]domain.PasswordHistoryEntry, error)
+}
@@ -0,0 +1,67 @@
+package passwordhistory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxPasswordHistoryRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxPasswordHistoryRepository(dbpool *pgxpool.Pool) *PgxPasswordHistoryRepository {
+	return &PgxPasswordHistoryRepository{
+		dbpool: dbpool,
+	}
+}
+
+func (repo *PgxPasswordHistoryRepository) AddPasswordHash(ctx context.Context, userID uuid.UUID, passwordHash []byte, pepperVersion int, keep int) error {
+	errfmt := "%w: %s"
+	query := `INSERT INTO password_history (user_id, password_hash, pepper_version) VALUES ($1, $2, $3)`
+	if _, err := repo.dbpool.Exec(ctx, query, userID, passwordHash, pepperVersion); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			// foreign_key_violation
+			return fmt.Errorf(errfmt, domain.ErrUserNotFound, pgErr.Error())
+		}
+		return fmt.Errorf(errfmt, domain.ErrGeneralPasswordHistory, err.Error())
+	}
+
+	// entries beyond keep are never checked again, a failed prune is caught up by the next one
+	query = `DELETE FROM password_history WHERE user_id = $1 AND id NOT IN (
+		SELECT id FROM password_history WHERE user_id = $1 ORDER BY id DESC LIMIT $2
+	)`
+	if _, err := repo.dbpool.Exec(ctx, query, userID, keep); err != nil {
+		return fmt.Errorf(errfmt, domain.ErrGeneralPasswordHistory, err.Error())
+	}
+	return nil
+}
+
+func (repo *PgxPasswordHistoryRepository) ListPasswordHashes(ctx context.Context, userID uuid.UUID, limit int) ([]domain.PasswordHistoryEntry, error) {
+	errfmt := "%w: %s"
+	entries := make([]domain.PasswordHistoryEntry, 0)
+	query := `SELECT user_id, password_hash, pepper_version, created_at FROM password_history WHERE user_id = $1 ORDER BY id DESC LIMIT $2`
+	rows, err := repo.dbpool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralPasswordHistory, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry domain.PasswordHistoryEntry
+		if err := rows.Scan(&entry.UserID, &entry.PasswordHash, &entry.PepperVersion, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf(errfmt, domain.ErrGeneralPasswordHistory, err.Error())
+		}
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralPasswordHistory, err.Error())
+	}
+	return entries, nil
+}
@@ -0,0 +1,305 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package refresh
+
+import (
+	context "context"
+
+	domain "github.com/bright-pentium/go-client-practice/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+)
+
+// MockRefreshTokenRepository is an autogenerated mock type for the IRefreshTokenRepository type
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+type MockRefreshTokenRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRefreshTokenRepository) EXPECT() *MockRefreshTokenRepository_Expecter {
+	return &MockRefreshTokenRepository_Expecter{mock: &_m.Mock}
+}
+
+// CreateRefreshToken provides a mock function with given fields: ctx, ID, familyID, userID, tokenHash, authTime, expiresAt
+func (_m *MockRefreshTokenRepository) CreateRefreshToken(ctx context.Context, ID uuid.UUID, familyID uuid.UUID, userID uuid.UUID, tokenHash []byte, authTime time.Time, expiresAt time.Time) (*domain.RefreshToken, error) {
+	ret := _m.Called(ctx, ID, familyID, userID, tokenHash, authTime, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRefreshToken")
+	}
+
+	var r0 *domain.RefreshToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, []byte, time.Time, time.Time) (*domain.RefreshToken, error)); ok {
+		return rf(ctx, ID, familyID, userID, tokenHash, authTime, expiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, []byte, time.Time, time.Time) *domain.RefreshToken); ok {
+		r0 = rf(ctx, ID, familyID, userID, tokenHash, authTime, expiresAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.RefreshToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, []byte, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, ID, familyID, userID, tokenHash, authTime, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRefreshTokenRepository_CreateRefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateRefreshToken'
+type MockRefreshTokenRepository_CreateRefreshToken_Call struct {
+	*mock.Call
+}
+
+// CreateRefreshToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID uuid.UUID
+//   - familyID uuid.UUID
+//   - userID uuid.UUID
+//   - tokenHash []byte
+//   - authTime time.Time
+//   - expiresAt time.Time
+func (_e *MockRefreshTokenRepository_Expecter) CreateRefreshToken(ctx interface{}, ID interface{}, familyID interface{}, userID interface{}, tokenHash interface{}, authTime interface{}, expiresAt interface{}) *MockRefreshTokenRepository_CreateRefreshToken_Call {
+	return &MockRefreshTokenRepository_CreateRefreshToken_Call{Call: _e.mock.On("CreateRefreshToken", ctx, ID, familyID, userID, tokenHash, authTime, expiresAt)}
+}
+
+func (_c *MockRefreshTokenRepository_CreateRefreshToken_Call) Run(run func(ctx context.Context, ID uuid.UUID, familyID uuid.UUID, userID uuid.UUID, tokenHash []byte, authTime time.Time, expiresAt time.Time)) *MockRefreshTokenRepository_CreateRefreshToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(uuid.UUID), args[3].(uuid.UUID), args[4].([]byte), args[5].(time.Time), args[6].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_CreateRefreshToken_Call) Return(_a0 *domain.RefreshToken, _a1 error) *MockRefreshTokenRepository_CreateRefreshToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_CreateRefreshToken_Call) RunAndReturn(run func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, []byte, time.Time, time.Time) (*domain.RefreshToken, error)) *MockRefreshTokenRepository_CreateRefreshToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRefreshTokenByHash provides a mock function with given fields: ctx, tokenHash
+func (_m *MockRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash []byte) (*domain.RefreshToken, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRefreshTokenByHash")
+	}
+
+	var r0 *domain.RefreshToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*domain.RefreshToken, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *domain.RefreshToken); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.RefreshToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRefreshTokenRepository_GetRefreshTokenByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRefreshTokenByHash'
+type MockRefreshTokenRepository_GetRefreshTokenByHash_Call struct {
+	*mock.Call
+}
+
+// GetRefreshTokenByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenHash []byte
+func (_e *MockRefreshTokenRepository_Expecter) GetRefreshTokenByHash(ctx interface{}, tokenHash interface{}) *MockRefreshTokenRepository_GetRefreshTokenByHash_Call {
+	return &MockRefreshTokenRepository_GetRefreshTokenByHash_Call{Call: _e.mock.On("GetRefreshTokenByHash", ctx, tokenHash)}
+}
+
+func (_c *MockRefreshTokenRepository_GetRefreshTokenByHash_Call) Run(run func(ctx context.Context, tokenHash []byte)) *MockRefreshTokenRepository_GetRefreshTokenByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_GetRefreshTokenByHash_Call) Return(_a0 *domain.RefreshToken, _a1 error) *MockRefreshTokenRepository_GetRefreshTokenByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_GetRefreshTokenByHash_Call) RunAndReturn(run func(context.Context, []byte) (*domain.RefreshToken, error)) *MockRefreshTokenRepository_GetRefreshTokenByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkRefreshTokenUsed provides a mock function with given fields: ctx, ID
+func (_m *MockRefreshTokenRepository) MarkRefreshTokenUsed(ctx context.Context, ID uuid.UUID) error {
+	ret := _m.Called(ctx, ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkRefreshTokenUsed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRefreshTokenRepository_MarkRefreshTokenUsed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkRefreshTokenUsed'
+type MockRefreshTokenRepository_MarkRefreshTokenUsed_Call struct {
+	*mock.Call
+}
+
+// MarkRefreshTokenUsed is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID uuid.UUID
+func (_e *MockRefreshTokenRepository_Expecter) MarkRefreshTokenUsed(ctx interface{}, ID interface{}) *MockRefreshTokenRepository_MarkRefreshTokenUsed_Call {
+	return &MockRefreshTokenRepository_MarkRefreshTokenUsed_Call{Call: _e.mock.On("MarkRefreshTokenUsed", ctx, ID)}
+}
+
+func (_c *MockRefreshTokenRepository_MarkRefreshTokenUsed_Call) Run(run func(ctx context.Context, ID uuid.UUID)) *MockRefreshTokenRepository_MarkRefreshTokenUsed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_MarkRefreshTokenUsed_Call) Return(_a0 error) *MockRefreshTokenRepository_MarkRefreshTokenUsed_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_MarkRefreshTokenUsed_Call) RunAndReturn(run func(context.Context, uuid.UUID) error) *MockRefreshTokenRepository_MarkRefreshTokenUsed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeRefreshTokenFamily provides a mock function with given fields: ctx, familyID
+func (_m *MockRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	ret := _m.Called(ctx, familyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeRefreshTokenFamily")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, familyID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRefreshTokenRepository_RevokeRefreshTokenFamily_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeRefreshTokenFamily'
+type MockRefreshTokenRepository_RevokeRefreshTokenFamily_Call struct {
+	*mock.Call
+}
+
+// RevokeRefreshTokenFamily is a helper method to define mock.On call
+//   - ctx context.Context
+//   - familyID uuid.UUID
+func (_e *MockRefreshTokenRepository_Expecter) RevokeRefreshTokenFamily(ctx interface{}, familyID interface{}) *MockRefreshTokenRepository_RevokeRefreshTokenFamily_Call {
+	return &MockRefreshTokenRepository_RevokeRefreshTokenFamily_Call{Call: _e.mock.On("RevokeRefreshTokenFamily", ctx, familyID)}
+}
+
+func (_c *MockRefreshTokenRepository_RevokeRefreshTokenFamily_Call) Run(run func(ctx context.Context, familyID uuid.UUID)) *MockRefreshTokenRepository_RevokeRefreshTokenFamily_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_RevokeRefreshTokenFamily_Call) Return(_a0 error) *MockRefreshTokenRepository_RevokeRefreshTokenFamily_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_RevokeRefreshTokenFamily_Call) RunAndReturn(run func(context.Context, uuid.UUID) error) *MockRefreshTokenRepository_RevokeRefreshTokenFamily_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeRefreshTokensByUser provides a mock function with given fields: ctx, userID
+func (_m *MockRefreshTokenRepository) RevokeRefreshTokensByUser(ctx context.Context, userID uuid.UUID) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeRefreshTokensByUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRefreshTokenRepository_RevokeRefreshTokensByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeRefreshTokensByUser'
+type MockRefreshTokenRepository_RevokeRefreshTokensByUser_Call struct {
+	*mock.Call
+}
+
+// RevokeRefreshTokensByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockRefreshTokenRepository_Expecter) RevokeRefreshTokensByUser(ctx interface{}, userID interface{}) *MockRefreshTokenRepository_RevokeRefreshTokensByUser_Call {
+	return &MockRefreshTokenRepository_RevokeRefreshTokensByUser_Call{Call: _e.mock.On("RevokeRefreshTokensByUser", ctx, userID)}
+}
+
+func (_c *MockRefreshTokenRepository_RevokeRefreshTokensByUser_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockRefreshTokenRepository_RevokeRefreshTokensByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_RevokeRefreshTokensByUser_Call) Return(_a0 error) *MockRefreshTokenRepository_RevokeRefreshTokensByUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_RevokeRefreshTokensByUser_Call) RunAndReturn(run func(context.Context, uuid.UUID) error) *MockRefreshTokenRepository_RevokeRefreshTokensByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRefreshTokenRepository creates a new instance of MockRefreshTokenRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRefreshTokenRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRefreshTokenRepository {
+	mock := &MockRefreshTokenRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,82 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxRefreshTokenRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxRefreshTokenRepository(dbpool *pgxpool.Pool) *PgxRefreshTokenRepository {
+	return &PgxRefreshTokenRepository{
+		dbpool: dbpool,
+	}
+}
+
+func (repo *PgxRefreshTokenRepository) CreateRefreshToken(ctx context.Context, ID uuid.UUID, familyID uuid.UUID, userID uuid.UUID, tokenHash []byte, authTime time.Time, expiresAt time.Time) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	errfmt := "%w: %s"
+	query := `INSERT INTO refresh_tokens (id, family_id, user_id, token_hash, auth_time, expires_at) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, family_id, user_id, token_hash, auth_time, expires_at, used_at, revoked_at, created_at`
+	err := repo.dbpool.QueryRow(ctx, query, ID, familyID, userID, tokenHash, authTime, expiresAt).Scan(
+		&token.ID, &token.FamilyID, &token.UserID, &token.TokenHash, &token.AuthTime, &token.ExpiresAt, &token.UsedAt, &token.RevokedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralRefreshToken, err.Error())
+	}
+	return &token, nil
+}
+
+func (repo *PgxRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash []byte) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	errfmt := "%w: %s"
+	query := `SELECT id, family_id, user_id, token_hash, auth_time, expires_at, used_at, revoked_at, created_at FROM refresh_tokens WHERE token_hash = $1`
+	err := repo.dbpool.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID, &token.FamilyID, &token.UserID, &token.TokenHash, &token.AuthTime, &token.ExpiresAt, &token.UsedAt, &token.RevokedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(errfmt, domain.ErrRefreshTokenNotFound, err.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralRefreshToken, err.Error())
+	}
+	return &token, nil
+}
+
+func (repo *PgxRefreshTokenRepository) MarkRefreshTokenUsed(ctx context.Context, ID uuid.UUID) error {
+	// the used_at guard makes concurrent rotations of the same token race to a single winner
+	query := `UPDATE refresh_tokens SET used_at = now() WHERE id = $1 AND used_at IS NULL`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, ID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralRefreshToken, err.Error())
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return domain.ErrRefreshTokenReused
+	}
+	return nil
+}
+
+func (repo *PgxRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL`
+	if _, err := repo.dbpool.Exec(ctx, query, familyID); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralRefreshToken, err.Error())
+	}
+	return nil
+}
+
+func (repo *PgxRefreshTokenRepository) RevokeRefreshTokensByUser(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`
+	if _, err := repo.dbpool.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralRefreshToken, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package refresh
+
+import (
+	"context"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+)
+
+type IRefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, ID uuid.UUID, familyID uuid.UUID, userID uuid.UUID, tokenHash []byte, authTime time.Time, expiresAt time.Time) (*domain.RefreshToken, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash []byte) (*domain.RefreshToken, error)
+
+	// MarkRefreshTokenUsed returns ErrRefreshTokenReused when the token was already used.
+	MarkRefreshTokenUsed(ctx context.Context, ID uuid.UUID) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeRefreshTokensByUser(ctx context.Context, userID uuid.UUID) error
+}
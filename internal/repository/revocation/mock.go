@@ -0,0 +1,199 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package revocation
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockRevocationRepository is an autogenerated mock type for the IRevocationRepository type
+type MockRevocationRepository struct {
+	mock.Mock
+}
+
+type MockRevocationRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRevocationRepository) EXPECT() *MockRevocationRepository_Expecter {
+	return &MockRevocationRepository_Expecter{mock: &_m.Mock}
+}
+
+// DeleteExpiredRevocations provides a mock function with given fields: ctx
+func (_m *MockRevocationRepository) DeleteExpiredRevocations(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredRevocations")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRevocationRepository_DeleteExpiredRevocations_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpiredRevocations'
+type MockRevocationRepository_DeleteExpiredRevocations_Call struct {
+	*mock.Call
+}
+
+// DeleteExpiredRevocations is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRevocationRepository_Expecter) DeleteExpiredRevocations(ctx interface{}) *MockRevocationRepository_DeleteExpiredRevocations_Call {
+	return &MockRevocationRepository_DeleteExpiredRevocations_Call{Call: _e.mock.On("DeleteExpiredRevocations", ctx)}
+}
+
+func (_c *MockRevocationRepository_DeleteExpiredRevocations_Call) Run(run func(ctx context.Context)) *MockRevocationRepository_DeleteExpiredRevocations_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRevocationRepository_DeleteExpiredRevocations_Call) Return(_a0 int64, _a1 error) *MockRevocationRepository_DeleteExpiredRevocations_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRevocationRepository_DeleteExpiredRevocations_Call) RunAndReturn(run func(context.Context) (int64, error)) *MockRevocationRepository_DeleteExpiredRevocations_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsTokenRevoked provides a mock function with given fields: ctx, jti
+func (_m *MockRevocationRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	ret := _m.Called(ctx, jti)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsTokenRevoked")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, jti)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, jti)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, jti)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockRevocationRepository_IsTokenRevoked_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsTokenRevoked'
+type MockRevocationRepository_IsTokenRevoked_Call struct {
+	*mock.Call
+}
+
+// IsTokenRevoked is a helper method to define mock.On call
+//   - ctx context.Context
+//   - jti string
+func (_e *MockRevocationRepository_Expecter) IsTokenRevoked(ctx interface{}, jti interface{}) *MockRevocationRepository_IsTokenRevoked_Call {
+	return &MockRevocationRepository_IsTokenRevoked_Call{Call: _e.mock.On("IsTokenRevoked", ctx, jti)}
+}
+
+func (_c *MockRevocationRepository_IsTokenRevoked_Call) Run(run func(ctx context.Context, jti string)) *MockRevocationRepository_IsTokenRevoked_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRevocationRepository_IsTokenRevoked_Call) Return(_a0 bool, _a1 error) *MockRevocationRepository_IsTokenRevoked_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockRevocationRepository_IsTokenRevoked_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *MockRevocationRepository_IsTokenRevoked_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeToken provides a mock function with given fields: ctx, jti, expiresAt
+func (_m *MockRevocationRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ret := _m.Called(ctx, jti, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, jti, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockRevocationRepository_RevokeToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeToken'
+type MockRevocationRepository_RevokeToken_Call struct {
+	*mock.Call
+}
+
+// RevokeToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - jti string
+//   - expiresAt time.Time
+func (_e *MockRevocationRepository_Expecter) RevokeToken(ctx interface{}, jti interface{}, expiresAt interface{}) *MockRevocationRepository_RevokeToken_Call {
+	return &MockRevocationRepository_RevokeToken_Call{Call: _e.mock.On("RevokeToken", ctx, jti, expiresAt)}
+}
+
+func (_c *MockRevocationRepository_RevokeToken_Call) Run(run func(ctx context.Context, jti string, expiresAt time.Time)) *MockRevocationRepository_RevokeToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockRevocationRepository_RevokeToken_Call) Return(_a0 error) *MockRevocationRepository_RevokeToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockRevocationRepository_RevokeToken_Call) RunAndReturn(run func(context.Context, string, time.Time) error) *MockRevocationRepository_RevokeToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRevocationRepository creates a new instance of MockRevocationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRevocationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRevocationRepository {
+	mock := &MockRevocationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
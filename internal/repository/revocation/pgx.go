@@ -0,0 +1,46 @@
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxRevocationRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxRevocationRepository(dbpool *pgxpool.Pool) *PgxRevocationRepository {
+	return &PgxRevocationRepository{
+		dbpool: dbpool,
+	}
+}
+
+func (repo *PgxRevocationRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`
+	if _, err := repo.dbpool.Exec(ctx, query, jti, expiresAt); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralRevocation, err.Error())
+	}
+	return nil
+}
+
+func (repo *PgxRevocationRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1 AND expires_at > now())`
+	if err := repo.dbpool.QueryRow(ctx, query, jti).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("%w: %s", domain.ErrGeneralRevocation, err.Error())
+	}
+	return revoked, nil
+}
+
+func (repo *PgxRevocationRepository) DeleteExpiredRevocations(ctx context.Context) (int64, error) {
+	query := `DELETE FROM revoked_tokens WHERE expires_at <= now()`
+	cmdTag, err := repo.dbpool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", domain.ErrGeneralRevocation, err.Error())
+	}
+	return cmdTag.RowsAffected(), nil
+}
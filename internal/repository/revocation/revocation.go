@@ -0,0 +1,12 @@
+package revocation
+
+import (
+	"context"
+	"time"
+)
+
+type IRevocationRepository interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredRevocations(ctx context.Context) (int64, error)
+}
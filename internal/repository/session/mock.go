@@ -0,0 +1,429 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package session
+
+import (
+	context "context"
+
+	domain "github.com/bright-pentium/go-client-practice/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+)
+
+// MockSessionRepository is an autogenerated mock type for the ISessionRepository type
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+type MockSessionRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockSessionRepository) EXPECT() *MockSessionRepository_Expecter {
+	return &MockSessionRepository_Expecter{mock: &_m.Mock}
+}
+
+// CreateSession provides a mock function with given fields: ctx, _a1
+func (_m *MockSessionRepository) CreateSession(ctx context.Context, _a1 *domain.Session) (*domain.Session, error) {
+	ret := _m.Called(ctx, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSession")
+	}
+
+	var r0 *domain.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Session) (*domain.Session, error)); ok {
+		return rf(ctx, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Session) *domain.Session); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.Session) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockSessionRepository_CreateSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateSession'
+type MockSessionRepository_CreateSession_Call struct {
+	*mock.Call
+}
+
+// CreateSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - _a1 *domain.Session
+func (_e *MockSessionRepository_Expecter) CreateSession(ctx interface{}, _a1 interface{}) *MockSessionRepository_CreateSession_Call {
+	return &MockSessionRepository_CreateSession_Call{Call: _e.mock.On("CreateSession", ctx, _a1)}
+}
+
+func (_c *MockSessionRepository_CreateSession_Call) Run(run func(ctx context.Context, _a1 *domain.Session)) *MockSessionRepository_CreateSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.Session))
+	})
+	return _c
+}
+
+func (_c *MockSessionRepository_CreateSession_Call) Return(_a0 *domain.Session, _a1 error) *MockSessionRepository_CreateSession_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockSessionRepository_CreateSession_Call) RunAndReturn(run func(context.Context, *domain.Session) (*domain.Session, error)) *MockSessionRepository_CreateSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpiredSessions provides a mock function with given fields: ctx
+func (_m *MockSessionRepository) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredSessions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockSessionRepository_DeleteExpiredSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpiredSessions'
+type MockSessionRepository_DeleteExpiredSessions_Call struct {
+	*mock.Call
+}
+
+// DeleteExpiredSessions is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockSessionRepository_Expecter) DeleteExpiredSessions(ctx interface{}) *MockSessionRepository_DeleteExpiredSessions_Call {
+	return &MockSessionRepository_DeleteExpiredSessions_Call{Call: _e.mock.On("DeleteExpiredSessions", ctx)}
+}
+
+func (_c *MockSessionRepository_DeleteExpiredSessions_Call) Run(run func(ctx context.Context)) *MockSessionRepository_DeleteExpiredSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockSessionRepository_DeleteExpiredSessions_Call) Return(_a0 int64, _a1 error) *MockSessionRepository_DeleteExpiredSessions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockSessionRepository_DeleteExpiredSessions_Call) RunAndReturn(run func(context.Context) (int64, error)) *MockSessionRepository_DeleteExpiredSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSessionByCookieHash provides a mock function with given fields: ctx, cookieHash
+func (_m *MockSessionRepository) GetSessionByCookieHash(ctx context.Context, cookieHash []byte) (*domain.Session, error) {
+	ret := _m.Called(ctx, cookieHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSessionByCookieHash")
+	}
+
+	var r0 *domain.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*domain.Session, error)); ok {
+		return rf(ctx, cookieHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *domain.Session); ok {
+		r0 = rf(ctx, cookieHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, cookieHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockSessionRepository_GetSessionByCookieHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSessionByCookieHash'
+type MockSessionRepository_GetSessionByCookieHash_Call struct {
+	*mock.Call
+}
+
+// GetSessionByCookieHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - cookieHash []byte
+func (_e *MockSessionRepository_Expecter) GetSessionByCookieHash(ctx interface{}, cookieHash interface{}) *MockSessionRepository_GetSessionByCookieHash_Call {
+	return &MockSessionRepository_GetSessionByCookieHash_Call{Call: _e.mock.On("GetSessionByCookieHash", ctx, cookieHash)}
+}
+
+func (_c *MockSessionRepository_GetSessionByCookieHash_Call) Run(run func(ctx context.Context, cookieHash []byte)) *MockSessionRepository_GetSessionByCookieHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockSessionRepository_GetSessionByCookieHash_Call) Return(_a0 *domain.Session, _a1 error) *MockSessionRepository_GetSessionByCookieHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockSessionRepository_GetSessionByCookieHash_Call) RunAndReturn(run func(context.Context, []byte) (*domain.Session, error)) *MockSessionRepository_GetSessionByCookieHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSessionsByUser provides a mock function with given fields: ctx, userID
+func (_m *MockSessionRepository) ListSessionsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Session, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSessionsByUser")
+	}
+
+	var r0 []domain.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]domain.Session, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []domain.Session); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockSessionRepository_ListSessionsByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSessionsByUser'
+type MockSessionRepository_ListSessionsByUser_Call struct {
+	*mock.Call
+}
+
+// ListSessionsByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockSessionRepository_Expecter) ListSessionsByUser(ctx interface{}, userID interface{}) *MockSessionRepository_ListSessionsByUser_Call {
+	return &MockSessionRepository_ListSessionsByUser_Call{Call: _e.mock.On("ListSessionsByUser", ctx, userID)}
+}
+
+func (_c *MockSessionRepository_ListSessionsByUser_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockSessionRepository_ListSessionsByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockSessionRepository_ListSessionsByUser_Call) Return(_a0 []domain.Session, _a1 error) *MockSessionRepository_ListSessionsByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockSessionRepository_ListSessionsByUser_Call) RunAndReturn(run func(context.Context, uuid.UUID) ([]domain.Session, error)) *MockSessionRepository_ListSessionsByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSession provides a mock function with given fields: ctx, ID, userID
+func (_m *MockSessionRepository) RevokeSession(ctx context.Context, ID uuid.UUID, userID uuid.UUID) error {
+	ret := _m.Called(ctx, ID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r0 = rf(ctx, ID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockSessionRepository_RevokeSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSession'
+type MockSessionRepository_RevokeSession_Call struct {
+	*mock.Call
+}
+
+// RevokeSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockSessionRepository_Expecter) RevokeSession(ctx interface{}, ID interface{}, userID interface{}) *MockSessionRepository_RevokeSession_Call {
+	return &MockSessionRepository_RevokeSession_Call{Call: _e.mock.On("RevokeSession", ctx, ID, userID)}
+}
+
+func (_c *MockSessionRepository_RevokeSession_Call) Run(run func(ctx context.Context, ID uuid.UUID, userID uuid.UUID)) *MockSessionRepository_RevokeSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockSessionRepository_RevokeSession_Call) Return(_a0 error) *MockSessionRepository_RevokeSession_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSessionRepository_RevokeSession_Call) RunAndReturn(run func(context.Context, uuid.UUID, uuid.UUID) error) *MockSessionRepository_RevokeSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSessionsByUser provides a mock function with given fields: ctx, userID
+func (_m *MockSessionRepository) RevokeSessionsByUser(ctx context.Context, userID uuid.UUID) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSessionsByUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockSessionRepository_RevokeSessionsByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSessionsByUser'
+type MockSessionRepository_RevokeSessionsByUser_Call struct {
+	*mock.Call
+}
+
+// RevokeSessionsByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockSessionRepository_Expecter) RevokeSessionsByUser(ctx interface{}, userID interface{}) *MockSessionRepository_RevokeSessionsByUser_Call {
+	return &MockSessionRepository_RevokeSessionsByUser_Call{Call: _e.mock.On("RevokeSessionsByUser", ctx, userID)}
+}
+
+func (_c *MockSessionRepository_RevokeSessionsByUser_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockSessionRepository_RevokeSessionsByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockSessionRepository_RevokeSessionsByUser_Call) Return(_a0 error) *MockSessionRepository_RevokeSessionsByUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSessionRepository_RevokeSessionsByUser_Call) RunAndReturn(run func(context.Context, uuid.UUID) error) *MockSessionRepository_RevokeSessionsByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TouchSession provides a mock function with given fields: ctx, ID, expiresAt
+func (_m *MockSessionRepository) TouchSession(ctx context.Context, ID uuid.UUID, expiresAt time.Time) (*domain.Session, error) {
+	ret := _m.Called(ctx, ID, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchSession")
+	}
+
+	var r0 *domain.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) (*domain.Session, error)); ok {
+		return rf(ctx, ID, expiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) *domain.Session); ok {
+		r0 = rf(ctx, ID, expiresAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, time.Time) error); ok {
+		r1 = rf(ctx, ID, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockSessionRepository_TouchSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchSession'
+type MockSessionRepository_TouchSession_Call struct {
+	*mock.Call
+}
+
+// TouchSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID uuid.UUID
+//   - expiresAt time.Time
+func (_e *MockSessionRepository_Expecter) TouchSession(ctx interface{}, ID interface{}, expiresAt interface{}) *MockSessionRepository_TouchSession_Call {
+	return &MockSessionRepository_TouchSession_Call{Call: _e.mock.On("TouchSession", ctx, ID, expiresAt)}
+}
+
+func (_c *MockSessionRepository_TouchSession_Call) Run(run func(ctx context.Context, ID uuid.UUID, expiresAt time.Time)) *MockSessionRepository_TouchSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockSessionRepository_TouchSession_Call) Return(_a0 *domain.Session, _a1 error) *MockSessionRepository_TouchSession_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockSessionRepository_TouchSession_Call) RunAndReturn(run func(context.Context, uuid.UUID, time.Time) (*domain.Session, error)) *MockSessionRepository_TouchSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockSessionRepository creates a new instance of MockSessionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockSessionRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockSessionRepository {
+	mock := &MockSessionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,135 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxSessionRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxSessionRepository(dbpool *pgxpool.Pool) *PgxSessionRepository {
+	return &PgxSessionRepository{
+		dbpool: dbpool,
+	}
+}
+
+// sessionColumns is the column list every query returns, in the order scanSession reads it.
+const sessionColumns = `id, user_id, client_id, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at, cookie_hash, csrf_hash, amr`
+
+func scanSession(row pgx.Row) (*domain.Session, error) {
+	var session domain.Session
+	if err := row.Scan(&session.ID, &session.UserID, &session.ClientID, &session.UserAgent, &session.IPAddress,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &session.RevokedAt, &session.CookieHash, &session.CSRFHash, &session.AMR,
+	); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (repo *PgxSessionRepository) CreateSession(ctx context.Context, session *domain.Session) (*domain.Session, error) {
+	errfmt := "%w: %s"
+	query := `INSERT INTO sessions (id, user_id, client_id, user_agent, ip_address, expires_at, cookie_hash, csrf_hash, amr) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING ` + sessionColumns
+
+	created, err := scanSession(repo.dbpool.QueryRow(ctx, query,
+		session.ID, session.UserID, session.ClientID, session.UserAgent, session.IPAddress, session.ExpiresAt, session.CookieHash, session.CSRFHash, session.AMR,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			// foreign_key_violation
+			return nil, fmt.Errorf(errfmt, domain.ErrUserNotFound, pgErr.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralSession, err.Error())
+	}
+	return created, nil
+}
+
+func (repo *PgxSessionRepository) ListSessionsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Session, error) {
+	errfmt := "%w: %s"
+	sessions := make([]domain.Session, 0)
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now() ORDER BY last_seen_at DESC`
+	rows, err := repo.dbpool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralSession, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf(errfmt, domain.ErrGeneralSession, err.Error())
+		}
+		sessions = append(sessions, *session)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralSession, err.Error())
+	}
+	return sessions, nil
+}
+
+func (repo *PgxSessionRepository) GetSessionByCookieHash(ctx context.Context, cookieHash []byte) (*domain.Session, error) {
+	errfmt := "%w: %s"
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE cookie_hash = $1`
+
+	session, err := scanSession(repo.dbpool.QueryRow(ctx, query, cookieHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(errfmt, domain.ErrSessionNotFound, err.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralSession, err.Error())
+	}
+	return session, nil
+}
+
+func (repo *PgxSessionRepository) TouchSession(ctx context.Context, ID uuid.UUID, expiresAt time.Time) (*domain.Session, error) {
+	errfmt := "%w: %s"
+	query := `UPDATE sessions SET last_seen_at = now(), expires_at = GREATEST(expires_at, $2) WHERE id = $1 RETURNING ` + sessionColumns
+
+	session, err := scanSession(repo.dbpool.QueryRow(ctx, query, ID, expiresAt))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(errfmt, domain.ErrSessionNotFound, err.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralSession, err.Error())
+	}
+	return session, nil
+}
+
+func (repo *PgxSessionRepository) RevokeSession(ctx context.Context, ID uuid.UUID, userID uuid.UUID) error {
+	query := `UPDATE sessions SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1 AND user_id = $2`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, ID, userID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralSession, err.Error())
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: '%s'", domain.ErrSessionNotFound, ID)
+	}
+	return nil
+}
+
+func (repo *PgxSessionRepository) RevokeSessionsByUser(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`
+	if _, err := repo.dbpool.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralSession, err.Error())
+	}
+	return nil
+}
+
+func (repo *PgxSessionRepository) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	query := `DELETE FROM sessions WHERE expires_at <= now()`
+	cmdTag, err := repo.dbpool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", domain.ErrGeneralSession, err.Error())
+	}
+	return cmdTag.RowsAffected(), nil
+}
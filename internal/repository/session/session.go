@@ -0,0 +1,24 @@
+package session
+
+import (
+	"context"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+)
+
+type ISessionRepository interface {
+	CreateSession(ctx context.Context, session *domain.Session) (*domain.Session, error)
+	// ListSessionsByUser returns the sessions of the user that are neither revoked nor expired, latest first
+	ListSessionsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Session, error)
+	// GetSessionByCookieHash returns the browser session of the cookie, revoked and expired ones included
+	GetSessionByCookieHash(ctx context.Context, cookieHash []byte) (*domain.Session, error)
+
+	// TouchSession records activity on the session and pushes its expiry out to expiresAt if that is later.
+	// Revoked and expired sessions are returned as they are.
+	TouchSession(ctx context.Context, ID uuid.UUID, expiresAt time.Time) (*domain.Session, error)
+	RevokeSession(ctx context.Context, ID uuid.UUID, userID uuid.UUID) error
+	RevokeSessionsByUser(ctx context.Context, userID uuid.UUID) error
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,207 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package signingkey
+
+import (
+	context "context"
+
+	domain "github.com/bright-pentium/go-client-practice/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// MockSigningKeyRepository is an autogenerated mock type for the ISigningKeyRepository type
+type MockSigningKeyRepository struct {
+	mock.Mock
+}
+
+type MockSigningKeyRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockSigningKeyRepository) EXPECT() *MockSigningKeyRepository_Expecter {
+	return &MockSigningKeyRepository_Expecter{mock: &_m.Mock}
+}
+
+// CreateSigningKey provides a mock function with given fields: ctx, kid, algorithm, privateKey, activatesAt
+func (_m *MockSigningKeyRepository) CreateSigningKey(ctx context.Context, kid string, algorithm string, privateKey []byte, activatesAt time.Time) (*domain.SigningKey, error) {
+	ret := _m.Called(ctx, kid, algorithm, privateKey, activatesAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSigningKey")
+	}
+
+	var r0 *domain.SigningKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []byte, time.Time) (*domain.SigningKey, error)); ok {
+		return rf(ctx, kid, algorithm, privateKey, activatesAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []byte, time.Time) *domain.SigningKey); ok {
+		r0 = rf(ctx, kid, algorithm, privateKey, activatesAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SigningKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []byte, time.Time) error); ok {
+		r1 = rf(ctx, kid, algorithm, privateKey, activatesAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockSigningKeyRepository_CreateSigningKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateSigningKey'
+type MockSigningKeyRepository_CreateSigningKey_Call struct {
+	*mock.Call
+}
+
+// CreateSigningKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - kid string
+//   - algorithm string
+//   - privateKey []byte
+//   - activatesAt time.Time
+func (_e *MockSigningKeyRepository_Expecter) CreateSigningKey(ctx interface{}, kid interface{}, algorithm interface{}, privateKey interface{}, activatesAt interface{}) *MockSigningKeyRepository_CreateSigningKey_Call {
+	return &MockSigningKeyRepository_CreateSigningKey_Call{Call: _e.mock.On("CreateSigningKey", ctx, kid, algorithm, privateKey, activatesAt)}
+}
+
+func (_c *MockSigningKeyRepository_CreateSigningKey_Call) Run(run func(ctx context.Context, kid string, algorithm string, privateKey []byte, activatesAt time.Time)) *MockSigningKeyRepository_CreateSigningKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].([]byte), args[4].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockSigningKeyRepository_CreateSigningKey_Call) Return(_a0 *domain.SigningKey, _a1 error) *MockSigningKeyRepository_CreateSigningKey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockSigningKeyRepository_CreateSigningKey_Call) RunAndReturn(run func(context.Context, string, string, []byte, time.Time) (*domain.SigningKey, error)) *MockSigningKeyRepository_CreateSigningKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSigningKeys provides a mock function with given fields: ctx
+func (_m *MockSigningKeyRepository) ListSigningKeys(ctx context.Context) ([]domain.SigningKey, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSigningKeys")
+	}
+
+	var r0 []domain.SigningKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.SigningKey, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.SigningKey); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.SigningKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockSigningKeyRepository_ListSigningKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSigningKeys'
+type MockSigningKeyRepository_ListSigningKeys_Call struct {
+	*mock.Call
+}
+
+// ListSigningKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockSigningKeyRepository_Expecter) ListSigningKeys(ctx interface{}) *MockSigningKeyRepository_ListSigningKeys_Call {
+	return &MockSigningKeyRepository_ListSigningKeys_Call{Call: _e.mock.On("ListSigningKeys", ctx)}
+}
+
+func (_c *MockSigningKeyRepository_ListSigningKeys_Call) Run(run func(ctx context.Context)) *MockSigningKeyRepository_ListSigningKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockSigningKeyRepository_ListSigningKeys_Call) Return(_a0 []domain.SigningKey, _a1 error) *MockSigningKeyRepository_ListSigningKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockSigningKeyRepository_ListSigningKeys_Call) RunAndReturn(run func(context.Context) ([]domain.SigningKey, error)) *MockSigningKeyRepository_ListSigningKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RetireSigningKeys provides a mock function with given fields: ctx, exceptKID, at
+func (_m *MockSigningKeyRepository) RetireSigningKeys(ctx context.Context, exceptKID string, at time.Time) error {
+	ret := _m.Called(ctx, exceptKID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetireSigningKeys")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, exceptKID, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockSigningKeyRepository_RetireSigningKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetireSigningKeys'
+type MockSigningKeyRepository_RetireSigningKeys_Call struct {
+	*mock.Call
+}
+
+// RetireSigningKeys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - exceptKID string
+//   - at time.Time
+func (_e *MockSigningKeyRepository_Expecter) RetireSigningKeys(ctx interface{}, exceptKID interface{}, at interface{}) *MockSigningKeyRepository_RetireSigningKeys_Call {
+	return &MockSigningKeyRepository_RetireSigningKeys_Call{Call: _e.mock.On("RetireSigningKeys", ctx, exceptKID, at)}
+}
+
+func (_c *MockSigningKeyRepository_RetireSigningKeys_Call) Run(run func(ctx context.Context, exceptKID string, at time.Time)) *MockSigningKeyRepository_RetireSigningKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockSigningKeyRepository_RetireSigningKeys_Call) Return(_a0 error) *MockSigningKeyRepository_RetireSigningKeys_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockSigningKeyRepository_RetireSigningKeys_Call) RunAndReturn(run func(context.Context, string, time.Time) error) *MockSigningKeyRepository_RetireSigningKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockSigningKeyRepository creates a new instance of MockSigningKeyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockSigningKeyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockSigningKeyRepository {
+	mock := &MockSigningKeyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,79 @@
+package signingkey
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxSigningKeyRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxSigningKeyRepository(dbpool *pgxpool.Pool) *PgxSigningKeyRepository {
+	return &PgxSigningKeyRepository{
+		dbpool: dbpool,
+	}
+}
+
+func scanSigningKey(row pgx.Row) (*domain.SigningKey, error) {
+	var key domain.SigningKey
+	var der []byte
+	if err := row.Scan(&key.KID, &key.Algorithm, &der, &key.CreatedAt, &key.ActivatesAt, &key.RetiredAt); err != nil {
+		return nil, err
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	key.Key = privateKey
+	return &key, nil
+}
+
+func (repo *PgxSigningKeyRepository) CreateSigningKey(ctx context.Context, kid string, algorithm string, privateKey []byte, activatesAt time.Time) (*domain.SigningKey, error) {
+	errfmt := "%w: %s"
+	query := `INSERT INTO signing_keys (kid, algorithm, private_key, activates_at) VALUES ($1, $2, $3, $4)
+		RETURNING kid, algorithm, private_key, created_at, activates_at, retired_at`
+	key, err := scanSigningKey(repo.dbpool.QueryRow(ctx, query, kid, algorithm, privateKey, activatesAt))
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralSigningKey, err.Error())
+	}
+	return key, nil
+}
+
+func (repo *PgxSigningKeyRepository) ListSigningKeys(ctx context.Context) ([]domain.SigningKey, error) {
+	keys := make([]domain.SigningKey, 0)
+	errfmt := "%w: %s"
+	query := `SELECT kid, algorithm, private_key, created_at, activates_at, retired_at FROM signing_keys ORDER BY activates_at DESC`
+	rows, err := repo.dbpool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralSigningKey, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		key, err := scanSigningKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf(errfmt, domain.ErrGeneralSigningKey, err.Error())
+		}
+		keys = append(keys, *key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralSigningKey, err.Error())
+	}
+	return keys, nil
+}
+
+func (repo *PgxSigningKeyRepository) RetireSigningKeys(ctx context.Context, exceptKID string, at time.Time) error {
+	query := `UPDATE signing_keys SET retired_at = $2 WHERE kid <> $1 AND (retired_at IS NULL OR retired_at > $2)`
+	if _, err := repo.dbpool.Exec(ctx, query, exceptKID, at); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrGeneralSigningKey, err.Error())
+	}
+	return nil
+}
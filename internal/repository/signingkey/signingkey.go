@@ -0,0 +1,17 @@
+package signingkey
+
+import (
+	"context"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+)
+
+type ISigningKeyRepository interface {
+	// CreateSigningKey stores a PKCS#8 DER encoded private key.
+	CreateSigningKey(ctx context.Context, kid string, algorithm string, privateKey []byte, activatesAt time.Time) (*domain.SigningKey, error)
+	ListSigningKeys(ctx context.Context) ([]domain.SigningKey, error)
+
+	// RetireSigningKeys retires every key but kid at the given time, unless it retires earlier already.
+	RetireSigningKeys(ctx context.Context, exceptKID string, at time.Time) error
+}
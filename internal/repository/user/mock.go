@@ -24,9 +24,9 @@ func (_m *MockUserRepository) EXPECT() *MockUserRepository_Expecter {
 	return &MockUserRepository_Expecter{mock: &_m.Mock}
 }
 
-// CreateUser provides a mock function with given fields: ctx, ID, name, account, passwordHash
-func (_m *MockUserRepository) CreateUser(ctx context.Context, ID uuid.UUID, name string, account string, passwordHash []byte) (*domain.User, error) {
-	ret := _m.Called(ctx, ID, name, account, passwordHash)
+// CreateUser provides a mock function with given fields: ctx, ID, name, account, passwordHash, pepperVersion
+func (_m *MockUserRepository) CreateUser(ctx context.Context, ID uuid.UUID, name string, account string, passwordHash []byte, pepperVersion int) (*domain.User, error) {
+	ret := _m.Called(ctx, ID, name, account, passwordHash, pepperVersion)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateUser")
@@ -34,19 +34,19 @@ func (_m *MockUserRepository) CreateUser(ctx context.Context, ID uuid.UUID, name
 
 	var r0 *domain.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, []byte) (*domain.User, error)); ok {
-		return rf(ctx, ID, name, account, passwordHash)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, []byte, int) (*domain.User, error)); ok {
+		return rf(ctx, ID, name, account, passwordHash, pepperVersion)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, []byte) *domain.User); ok {
-		r0 = rf(ctx, ID, name, account, passwordHash)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, string, []byte, int) *domain.User); ok {
+		r0 = rf(ctx, ID, name, account, passwordHash, pepperVersion)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, string, []byte) error); ok {
-		r1 = rf(ctx, ID, name, account, passwordHash)
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, string, []byte, int) error); ok {
+		r1 = rf(ctx, ID, name, account, passwordHash, pepperVersion)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -65,13 +65,14 @@ type MockUserRepository_CreateUser_Call struct {
 //   - name string
 //   - account string
 //   - passwordHash []byte
-func (_e *MockUserRepository_Expecter) CreateUser(ctx interface{}, ID interface{}, name interface{}, account interface{}, passwordHash interface{}) *MockUserRepository_CreateUser_Call {
-	return &MockUserRepository_CreateUser_Call{Call: _e.mock.On("CreateUser", ctx, ID, name, account, passwordHash)}
+//   - pepperVersion int
+func (_e *MockUserRepository_Expecter) CreateUser(ctx interface{}, ID interface{}, name interface{}, account interface{}, passwordHash interface{}, pepperVersion interface{}) *MockUserRepository_CreateUser_Call {
+	return &MockUserRepository_CreateUser_Call{Call: _e.mock.On("CreateUser", ctx, ID, name, account, passwordHash, pepperVersion)}
 }
 
-func (_c *MockUserRepository_CreateUser_Call) Run(run func(ctx context.Context, ID uuid.UUID, name string, account string, passwordHash []byte)) *MockUserRepository_CreateUser_Call {
+func (_c *MockUserRepository_CreateUser_Call) Run(run func(ctx context.Context, ID uuid.UUID, name string, account string, passwordHash []byte, pepperVersion int)) *MockUserRepository_CreateUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string), args[3].(string), args[4].([]byte))
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string), args[3].(string), args[4].([]byte), args[5].(int))
 	})
 	return _c
 }
@@ -81,7 +82,7 @@ func (_c *MockUserRepository_CreateUser_Call) Return(_a0 *domain.User, _a1 error
 	return _c
 }
 
-func (_c *MockUserRepository_CreateUser_Call) RunAndReturn(run func(context.Context, uuid.UUID, string, string, []byte) (*domain.User, error)) *MockUserRepository_CreateUser_Call {
+func (_c *MockUserRepository_CreateUser_Call) RunAndReturn(run func(context.Context, uuid.UUID, string, string, []byte, int) (*domain.User, error)) *MockUserRepository_CreateUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -251,9 +252,9 @@ func (_c *MockUserRepository_GetUserByID_Call) RunAndReturn(run func(context.Con
 	return _c
 }
 
-// UpdateUserByID provides a mock function with given fields: ctx, ID, name, passwordHash
-func (_m *MockUserRepository) UpdateUserByID(ctx context.Context, ID uuid.UUID, name string, passwordHash []byte) (*domain.User, error) {
-	ret := _m.Called(ctx, ID, name, passwordHash)
+// UpdateUserByID provides a mock function with given fields: ctx, ID, name, passwordHash, pepperVersion
+func (_m *MockUserRepository) UpdateUserByID(ctx context.Context, ID uuid.UUID, name string, passwordHash []byte, pepperVersion int) (*domain.User, error) {
+	ret := _m.Called(ctx, ID, name, passwordHash, pepperVersion)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateUserByID")
@@ -261,19 +262,19 @@ func (_m *MockUserRepository) UpdateUserByID(ctx context.Context, ID uuid.UUID,
 
 	var r0 *domain.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, []byte) (*domain.User, error)); ok {
-		return rf(ctx, ID, name, passwordHash)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, []byte, int) (*domain.User, error)); ok {
+		return rf(ctx, ID, name, passwordHash, pepperVersion)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, []byte) *domain.User); ok {
-		r0 = rf(ctx, ID, name, passwordHash)
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string, []byte, int) *domain.User); ok {
+		r0 = rf(ctx, ID, name, passwordHash, pepperVersion)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*domain.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, []byte) error); ok {
-		r1 = rf(ctx, ID, name, passwordHash)
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string, []byte, int) error); ok {
+		r1 = rf(ctx, ID, name, passwordHash, pepperVersion)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -291,13 +292,14 @@ type MockUserRepository_UpdateUserByID_Call struct {
 //   - ID uuid.UUID
 //   - name string
 //   - passwordHash []byte
-func (_e *MockUserRepository_Expecter) UpdateUserByID(ctx interface{}, ID interface{}, name interface{}, passwordHash interface{}) *MockUserRepository_UpdateUserByID_Call {
-	return &MockUserRepository_UpdateUserByID_Call{Call: _e.mock.On("UpdateUserByID", ctx, ID, name, passwordHash)}
+//   - pepperVersion int
+func (_e *MockUserRepository_Expecter) UpdateUserByID(ctx interface{}, ID interface{}, name interface{}, passwordHash interface{}, pepperVersion interface{}) *MockUserRepository_UpdateUserByID_Call {
+	return &MockUserRepository_UpdateUserByID_Call{Call: _e.mock.On("UpdateUserByID", ctx, ID, name, passwordHash, pepperVersion)}
 }
 
-func (_c *MockUserRepository_UpdateUserByID_Call) Run(run func(ctx context.Context, ID uuid.UUID, name string, passwordHash []byte)) *MockUserRepository_UpdateUserByID_Call {
+func (_c *MockUserRepository_UpdateUserByID_Call) Run(run func(ctx context.Context, ID uuid.UUID, name string, passwordHash []byte, pepperVersion int)) *MockUserRepository_UpdateUserByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string), args[3].([]byte))
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string), args[3].([]byte), args[4].(int))
 	})
 	return _c
 }
@@ -307,7 +309,7 @@ func (_c *MockUserRepository_UpdateUserByID_Call) Return(_a0 *domain.User, _a1 e
 	return _c
 }
 
-func (_c *MockUserRepository_UpdateUserByID_Call) RunAndReturn(run func(context.Context, uuid.UUID, string, []byte) (*domain.User, error)) *MockUserRepository_UpdateUserByID_Call {
+func (_c *MockUserRepository_UpdateUserByID_Call) RunAndReturn(run func(context.Context, uuid.UUID, string, []byte, int) (*domain.User, error)) *MockUserRepository_UpdateUserByID_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -23,11 +23,11 @@ func NewPgxUserRepository(dbpool *pgxpool.Pool) *PgxUserRepository {
 	}
 }
 
-func (repo *PgxUserRepository) CreateUser(ctx context.Context, ID uuid.UUID, name string, account string, passwordHash []byte) (*domain.User, error) {
+func (repo *PgxUserRepository) CreateUser(ctx context.Context, ID uuid.UUID, name string, account string, passwordHash []byte, pepperVersion int) (*domain.User, error) {
 	var user domain.User
 	errfmt := "%w: %s"
-	query := `INSERT INTO users (id, name, account, password_hash) VALUES ($1, $2, $3, $4) RETURNING id, name, account, password_hash`
-	err := repo.dbpool.QueryRow(ctx, query, ID, name, account, passwordHash).Scan(&user.ID, &user.Name, &user.Account, &user.PasswordHash)
+	query := `INSERT INTO users (id, name, account, password_hash, pepper_version) VALUES ($1, $2, $3, $4, $5) RETURNING id, name, account, password_hash, pepper_version`
+	err := repo.dbpool.QueryRow(ctx, query, ID, name, account, passwordHash, pepperVersion).Scan(&user.ID, &user.Name, &user.Account, &user.PasswordHash, &user.PepperVersion)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
@@ -48,8 +48,8 @@ func (repo *PgxUserRepository) CreateUser(ctx context.Context, ID uuid.UUID, nam
 func (repo *PgxUserRepository) GetUserByID(ctx context.Context, ID uuid.UUID) (*domain.User, error) {
 	var user domain.User
 	errfmt := "%w: %s"
-	query := `SELECT id, name, account, password_hash FROM users WHERE id = $1`
-	err := repo.dbpool.QueryRow(ctx, query, ID).Scan(&user.ID, &user.Name, &user.Account, &user.PasswordHash)
+	query := `SELECT id, name, account, password_hash, pepper_version FROM users WHERE id = $1`
+	err := repo.dbpool.QueryRow(ctx, query, ID).Scan(&user.ID, &user.Name, &user.Account, &user.PasswordHash, &user.PepperVersion)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf(errfmt, domain.ErrUserNotFound, err.Error())
@@ -62,8 +62,8 @@ func (repo *PgxUserRepository) GetUserByID(ctx context.Context, ID uuid.UUID) (*
 func (repo *PgxUserRepository) GetUserByAccount(ctx context.Context, account string) (*domain.User, error) {
 	var user domain.User
 	errfmt := "%w: %s"
-	query := `SELECT id, name, account, password_hash FROM users WHERE account = $1`
-	err := repo.dbpool.QueryRow(ctx, query, account).Scan(&user.ID, &user.Name, &user.Account, &user.PasswordHash)
+	query := `SELECT id, name, account, password_hash, pepper_version FROM users WHERE account = $1`
+	err := repo.dbpool.QueryRow(ctx, query, account).Scan(&user.ID, &user.Name, &user.Account, &user.PasswordHash, &user.PepperVersion)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf(errfmt, domain.ErrUserNotFound, err)
@@ -78,6 +78,7 @@ func (repo *PgxUserRepository) UpdateUserByID(
 	ID uuid.UUID,
 	name string,
 	passwordHash []byte,
+	pepperVersion int,
 ) (*domain.User, error) {
 	var user domain.User
 	errfmt := "%w: %s"
@@ -95,9 +96,9 @@ func (repo *PgxUserRepository) UpdateUserByID(
 	}
 
 	if passwordHash != nil {
-		updates = append(updates, fmt.Sprintf("password_hash = $%d", argIndex))
-		args = append(args, passwordHash)
-		argIndex++
+		updates = append(updates, fmt.Sprintf("password_hash = $%d, pepper_version = $%d", argIndex, argIndex+1))
+		args = append(args, passwordHash, pepperVersion)
+		argIndex += 2
 	}
 
 	if len(updates) == 0 {
@@ -106,11 +107,11 @@ func (repo *PgxUserRepository) UpdateUserByID(
 	}
 
 	query += strings.Join(updates, ", ")
-	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, name, account, password_hash", argIndex)
+	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, name, account, password_hash, pepper_version", argIndex)
 	args = append(args, ID)
 
 	err := repo.dbpool.QueryRow(ctx, query, args...).Scan(
-		&user.ID, &user.Name, &user.Account, &user.PasswordHash,
+		&user.ID, &user.Name, &user.Account, &user.PasswordHash, &user.PepperVersion,
 	)
 
 	if err != nil {
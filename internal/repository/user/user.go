@@ -8,8 +8,9 @@ import (
 )
 
 type IUserRepository interface {
-	CreateUser(ctx context.Context, ID uuid.UUID, name string, account string, passwordHash []byte) (*domain.User, error)
-	UpdateUserByID(ctx context.Context, ID uuid.UUID, name string, passwordHash []byte) (*domain.User, error)
+	CreateUser(ctx context.Context, ID uuid.UUID, name string, account string, passwordHash []byte, pepperVersion int) (*domain.User, error)
+	// UpdateUserByID leaves the name alone when empty and the password hash when nil, pepperVersion goes with the password hash
+	UpdateUserByID(ctx context.Context, ID uuid.UUID, name string, passwordHash []byte, pepperVersion int) (*domain.User, error)
 	GetUserByID(ctx context.Context, ID uuid.UUID) (*domain.User, error)
 	GetUserByAccount(ctx context.Context, account string) (*domain.User, error)
 	// ListUser(ctx context.Context) ([]domain.User, error)
@@ -0,0 +1,531 @@
+// Code generated by mockery v2.43.2. DO NOT EDIT.
+
+package webauthn
+
+import (
+	context "context"
+
+	domain "github.com/bright-pentium/go-client-practice/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// MockWebAuthnRepository is an autogenerated mock type for the IWebAuthnRepository type
+type MockWebAuthnRepository struct {
+	mock.Mock
+}
+
+type MockWebAuthnRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockWebAuthnRepository) EXPECT() *MockWebAuthnRepository_Expecter {
+	return &MockWebAuthnRepository_Expecter{mock: &_m.Mock}
+}
+
+// ConsumeWebAuthnChallenge provides a mock function with given fields: ctx, challengeHash
+func (_m *MockWebAuthnRepository) ConsumeWebAuthnChallenge(ctx context.Context, challengeHash []byte) (*domain.WebAuthnChallenge, error) {
+	ret := _m.Called(ctx, challengeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeWebAuthnChallenge")
+	}
+
+	var r0 *domain.WebAuthnChallenge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*domain.WebAuthnChallenge, error)); ok {
+		return rf(ctx, challengeHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *domain.WebAuthnChallenge); ok {
+		r0 = rf(ctx, challengeHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WebAuthnChallenge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, challengeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockWebAuthnRepository_ConsumeWebAuthnChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeWebAuthnChallenge'
+type MockWebAuthnRepository_ConsumeWebAuthnChallenge_Call struct {
+	*mock.Call
+}
+
+// ConsumeWebAuthnChallenge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - challengeHash []byte
+func (_e *MockWebAuthnRepository_Expecter) ConsumeWebAuthnChallenge(ctx interface{}, challengeHash interface{}) *MockWebAuthnRepository_ConsumeWebAuthnChallenge_Call {
+	return &MockWebAuthnRepository_ConsumeWebAuthnChallenge_Call{Call: _e.mock.On("ConsumeWebAuthnChallenge", ctx, challengeHash)}
+}
+
+func (_c *MockWebAuthnRepository_ConsumeWebAuthnChallenge_Call) Run(run func(ctx context.Context, challengeHash []byte)) *MockWebAuthnRepository_ConsumeWebAuthnChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_ConsumeWebAuthnChallenge_Call) Return(_a0 *domain.WebAuthnChallenge, _a1 error) *MockWebAuthnRepository_ConsumeWebAuthnChallenge_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_ConsumeWebAuthnChallenge_Call) RunAndReturn(run func(context.Context, []byte) (*domain.WebAuthnChallenge, error)) *MockWebAuthnRepository_ConsumeWebAuthnChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateCredential provides a mock function with given fields: ctx, credential
+func (_m *MockWebAuthnRepository) CreateCredential(ctx context.Context, credential *domain.WebAuthnCredential) (*domain.WebAuthnCredential, error) {
+	ret := _m.Called(ctx, credential)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCredential")
+	}
+
+	var r0 *domain.WebAuthnCredential
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.WebAuthnCredential) (*domain.WebAuthnCredential, error)); ok {
+		return rf(ctx, credential)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.WebAuthnCredential) *domain.WebAuthnCredential); ok {
+		r0 = rf(ctx, credential)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WebAuthnCredential)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *domain.WebAuthnCredential) error); ok {
+		r1 = rf(ctx, credential)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockWebAuthnRepository_CreateCredential_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateCredential'
+type MockWebAuthnRepository_CreateCredential_Call struct {
+	*mock.Call
+}
+
+// CreateCredential is a helper method to define mock.On call
+//   - ctx context.Context
+//   - credential *domain.WebAuthnCredential
+func (_e *MockWebAuthnRepository_Expecter) CreateCredential(ctx interface{}, credential interface{}) *MockWebAuthnRepository_CreateCredential_Call {
+	return &MockWebAuthnRepository_CreateCredential_Call{Call: _e.mock.On("CreateCredential", ctx, credential)}
+}
+
+func (_c *MockWebAuthnRepository_CreateCredential_Call) Run(run func(ctx context.Context, credential *domain.WebAuthnCredential)) *MockWebAuthnRepository_CreateCredential_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.WebAuthnCredential))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_CreateCredential_Call) Return(_a0 *domain.WebAuthnCredential, _a1 error) *MockWebAuthnRepository_CreateCredential_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_CreateCredential_Call) RunAndReturn(run func(context.Context, *domain.WebAuthnCredential) (*domain.WebAuthnCredential, error)) *MockWebAuthnRepository_CreateCredential_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateWebAuthnChallenge provides a mock function with given fields: ctx, challenge
+func (_m *MockWebAuthnRepository) CreateWebAuthnChallenge(ctx context.Context, challenge *domain.WebAuthnChallenge) error {
+	ret := _m.Called(ctx, challenge)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebAuthnChallenge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.WebAuthnChallenge) error); ok {
+		r0 = rf(ctx, challenge)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockWebAuthnRepository_CreateWebAuthnChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWebAuthnChallenge'
+type MockWebAuthnRepository_CreateWebAuthnChallenge_Call struct {
+	*mock.Call
+}
+
+// CreateWebAuthnChallenge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - challenge *domain.WebAuthnChallenge
+func (_e *MockWebAuthnRepository_Expecter) CreateWebAuthnChallenge(ctx interface{}, challenge interface{}) *MockWebAuthnRepository_CreateWebAuthnChallenge_Call {
+	return &MockWebAuthnRepository_CreateWebAuthnChallenge_Call{Call: _e.mock.On("CreateWebAuthnChallenge", ctx, challenge)}
+}
+
+func (_c *MockWebAuthnRepository_CreateWebAuthnChallenge_Call) Run(run func(ctx context.Context, challenge *domain.WebAuthnChallenge)) *MockWebAuthnRepository_CreateWebAuthnChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*domain.WebAuthnChallenge))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_CreateWebAuthnChallenge_Call) Return(_a0 error) *MockWebAuthnRepository_CreateWebAuthnChallenge_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_CreateWebAuthnChallenge_Call) RunAndReturn(run func(context.Context, *domain.WebAuthnChallenge) error) *MockWebAuthnRepository_CreateWebAuthnChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteCredential provides a mock function with given fields: ctx, ID, userID
+func (_m *MockWebAuthnRepository) DeleteCredential(ctx context.Context, ID []byte, userID uuid.UUID) error {
+	ret := _m.Called(ctx, ID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCredential")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, uuid.UUID) error); ok {
+		r0 = rf(ctx, ID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockWebAuthnRepository_DeleteCredential_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteCredential'
+type MockWebAuthnRepository_DeleteCredential_Call struct {
+	*mock.Call
+}
+
+// DeleteCredential is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID []byte
+//   - userID uuid.UUID
+func (_e *MockWebAuthnRepository_Expecter) DeleteCredential(ctx interface{}, ID interface{}, userID interface{}) *MockWebAuthnRepository_DeleteCredential_Call {
+	return &MockWebAuthnRepository_DeleteCredential_Call{Call: _e.mock.On("DeleteCredential", ctx, ID, userID)}
+}
+
+func (_c *MockWebAuthnRepository_DeleteCredential_Call) Run(run func(ctx context.Context, ID []byte, userID uuid.UUID)) *MockWebAuthnRepository_DeleteCredential_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte), args[2].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_DeleteCredential_Call) Return(_a0 error) *MockWebAuthnRepository_DeleteCredential_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_DeleteCredential_Call) RunAndReturn(run func(context.Context, []byte, uuid.UUID) error) *MockWebAuthnRepository_DeleteCredential_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteCredentialsByUser provides a mock function with given fields: ctx, userID
+func (_m *MockWebAuthnRepository) DeleteCredentialsByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCredentialsByUser")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (int64, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) int64); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockWebAuthnRepository_DeleteCredentialsByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteCredentialsByUser'
+type MockWebAuthnRepository_DeleteCredentialsByUser_Call struct {
+	*mock.Call
+}
+
+// DeleteCredentialsByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockWebAuthnRepository_Expecter) DeleteCredentialsByUser(ctx interface{}, userID interface{}) *MockWebAuthnRepository_DeleteCredentialsByUser_Call {
+	return &MockWebAuthnRepository_DeleteCredentialsByUser_Call{Call: _e.mock.On("DeleteCredentialsByUser", ctx, userID)}
+}
+
+func (_c *MockWebAuthnRepository_DeleteCredentialsByUser_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockWebAuthnRepository_DeleteCredentialsByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_DeleteCredentialsByUser_Call) Return(_a0 int64, _a1 error) *MockWebAuthnRepository_DeleteCredentialsByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_DeleteCredentialsByUser_Call) RunAndReturn(run func(context.Context, uuid.UUID) (int64, error)) *MockWebAuthnRepository_DeleteCredentialsByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpiredWebAuthnChallenges provides a mock function with given fields: ctx
+func (_m *MockWebAuthnRepository) DeleteExpiredWebAuthnChallenges(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredWebAuthnChallenges")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockWebAuthnRepository_DeleteExpiredWebAuthnChallenges_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpiredWebAuthnChallenges'
+type MockWebAuthnRepository_DeleteExpiredWebAuthnChallenges_Call struct {
+	*mock.Call
+}
+
+// DeleteExpiredWebAuthnChallenges is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockWebAuthnRepository_Expecter) DeleteExpiredWebAuthnChallenges(ctx interface{}) *MockWebAuthnRepository_DeleteExpiredWebAuthnChallenges_Call {
+	return &MockWebAuthnRepository_DeleteExpiredWebAuthnChallenges_Call{Call: _e.mock.On("DeleteExpiredWebAuthnChallenges", ctx)}
+}
+
+func (_c *MockWebAuthnRepository_DeleteExpiredWebAuthnChallenges_Call) Run(run func(ctx context.Context)) *MockWebAuthnRepository_DeleteExpiredWebAuthnChallenges_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_DeleteExpiredWebAuthnChallenges_Call) Return(_a0 int64, _a1 error) *MockWebAuthnRepository_DeleteExpiredWebAuthnChallenges_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_DeleteExpiredWebAuthnChallenges_Call) RunAndReturn(run func(context.Context) (int64, error)) *MockWebAuthnRepository_DeleteExpiredWebAuthnChallenges_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCredentialByID provides a mock function with given fields: ctx, ID
+func (_m *MockWebAuthnRepository) GetCredentialByID(ctx context.Context, ID []byte) (*domain.WebAuthnCredential, error) {
+	ret := _m.Called(ctx, ID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCredentialByID")
+	}
+
+	var r0 *domain.WebAuthnCredential
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (*domain.WebAuthnCredential, error)); ok {
+		return rf(ctx, ID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) *domain.WebAuthnCredential); ok {
+		r0 = rf(ctx, ID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WebAuthnCredential)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, ID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockWebAuthnRepository_GetCredentialByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCredentialByID'
+type MockWebAuthnRepository_GetCredentialByID_Call struct {
+	*mock.Call
+}
+
+// GetCredentialByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID []byte
+func (_e *MockWebAuthnRepository_Expecter) GetCredentialByID(ctx interface{}, ID interface{}) *MockWebAuthnRepository_GetCredentialByID_Call {
+	return &MockWebAuthnRepository_GetCredentialByID_Call{Call: _e.mock.On("GetCredentialByID", ctx, ID)}
+}
+
+func (_c *MockWebAuthnRepository_GetCredentialByID_Call) Run(run func(ctx context.Context, ID []byte)) *MockWebAuthnRepository_GetCredentialByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_GetCredentialByID_Call) Return(_a0 *domain.WebAuthnCredential, _a1 error) *MockWebAuthnRepository_GetCredentialByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_GetCredentialByID_Call) RunAndReturn(run func(context.Context, []byte) (*domain.WebAuthnCredential, error)) *MockWebAuthnRepository_GetCredentialByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListCredentialsByUser provides a mock function with given fields: ctx, userID
+func (_m *MockWebAuthnRepository) ListCredentialsByUser(ctx context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCredentialsByUser")
+	}
+
+	var r0 []domain.WebAuthnCredential
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]domain.WebAuthnCredential, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []domain.WebAuthnCredential); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.WebAuthnCredential)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockWebAuthnRepository_ListCredentialsByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListCredentialsByUser'
+type MockWebAuthnRepository_ListCredentialsByUser_Call struct {
+	*mock.Call
+}
+
+// ListCredentialsByUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+func (_e *MockWebAuthnRepository_Expecter) ListCredentialsByUser(ctx interface{}, userID interface{}) *MockWebAuthnRepository_ListCredentialsByUser_Call {
+	return &MockWebAuthnRepository_ListCredentialsByUser_Call{Call: _e.mock.On("ListCredentialsByUser", ctx, userID)}
+}
+
+func (_c *MockWebAuthnRepository_ListCredentialsByUser_Call) Run(run func(ctx context.Context, userID uuid.UUID)) *MockWebAuthnRepository_ListCredentialsByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_ListCredentialsByUser_Call) Return(_a0 []domain.WebAuthnCredential, _a1 error) *MockWebAuthnRepository_ListCredentialsByUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_ListCredentialsByUser_Call) RunAndReturn(run func(context.Context, uuid.UUID) ([]domain.WebAuthnCredential, error)) *MockWebAuthnRepository_ListCredentialsByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UseCredential provides a mock function with given fields: ctx, ID, signCount
+func (_m *MockWebAuthnRepository) UseCredential(ctx context.Context, ID []byte, signCount uint32) error {
+	ret := _m.Called(ctx, ID, signCount)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseCredential")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, uint32) error); ok {
+		r0 = rf(ctx, ID, signCount)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockWebAuthnRepository_UseCredential_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseCredential'
+type MockWebAuthnRepository_UseCredential_Call struct {
+	*mock.Call
+}
+
+// UseCredential is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ID []byte
+//   - signCount uint32
+func (_e *MockWebAuthnRepository_Expecter) UseCredential(ctx interface{}, ID interface{}, signCount interface{}) *MockWebAuthnRepository_UseCredential_Call {
+	return &MockWebAuthnRepository_UseCredential_Call{Call: _e.mock.On("UseCredential", ctx, ID, signCount)}
+}
+
+func (_c *MockWebAuthnRepository_UseCredential_Call) Run(run func(ctx context.Context, ID []byte, signCount uint32)) *MockWebAuthnRepository_UseCredential_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte), args[2].(uint32))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_UseCredential_Call) Return(_a0 error) *MockWebAuthnRepository_UseCredential_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockWebAuthnRepository_UseCredential_Call) RunAndReturn(run func(context.Context, []byte, uint32) error) *MockWebAuthnRepository_UseCredential_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockWebAuthnRepository creates a new instance of MockWebAuthnRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockWebAuthnRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockWebAuthnRepository {
+	mock := &MockWebAuthnRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
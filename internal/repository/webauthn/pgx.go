@@ -0,0 +1,175 @@
+package webauthn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgxWebAuthnRepository struct {
+	dbpool *pgxpool.Pool
+}
+
+func NewPgxWebAuthnRepository(dbpool *pgxpool.Pool) *PgxWebAuthnRepository {
+	return &PgxWebAuthnRepository{
+		dbpool: dbpool,
+	}
+}
+
+// credentialColumns is the column list every credential query returns, in the order scanCredential reads it.
+const credentialColumns = `id, user_id, name, public_key, algorithm, sign_count, created_at, last_used_at`
+
+func scanCredential(row pgx.Row) (*domain.WebAuthnCredential, error) {
+	var credential domain.WebAuthnCredential
+	var id []byte
+	var signCount int64
+	if err := row.Scan(&id, &credential.UserID, &credential.Name, &credential.PublicKey, &credential.Algorithm,
+		&signCount, &credential.CreatedAt, &credential.LastUsedAt,
+	); err != nil {
+		return nil, err
+	}
+	credential.ID, credential.SignCount = id, uint32(signCount)
+	return &credential, nil
+}
+
+func (repo *PgxWebAuthnRepository) CreateCredential(ctx context.Context, credential *domain.WebAuthnCredential) (*domain.WebAuthnCredential, error) {
+	errfmt := "%w: %s"
+	query := `INSERT INTO webauthn_credentials (id, user_id, name, public_key, algorithm, sign_count) VALUES ($1, $2, $3, $4, $5, $6) RETURNING ` + credentialColumns
+
+	created, err := scanCredential(repo.dbpool.QueryRow(ctx, query,
+		[]byte(credential.ID), credential.UserID, credential.Name, credential.PublicKey, credential.Algorithm, int64(credential.SignCount),
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				// unique_violation
+				return nil, fmt.Errorf(errfmt, domain.ErrWebAuthnCredentialExists, pgErr.Error())
+			case "23503":
+				// foreign_key_violation
+				return nil, fmt.Errorf(errfmt, domain.ErrUserNotFound, pgErr.Error())
+			}
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralWebAuthn, err.Error())
+	}
+	return created, nil
+}
+
+func (repo *PgxWebAuthnRepository) ListCredentialsByUser(ctx context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+	errfmt := "%w: %s"
+	credentials := make([]domain.WebAuthnCredential, 0)
+	query := `SELECT ` + credentialColumns + ` FROM webauthn_credentials WHERE user_id = $1 ORDER BY created_at`
+	rows, err := repo.dbpool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralWebAuthn, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		credential, err := scanCredential(rows)
+		if err != nil {
+			return nil, fmt.Errorf(errfmt, domain.ErrGeneralWebAuthn, err.Error())
+		}
+		credentials = append(credentials, *credential)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralWebAuthn, err.Error())
+	}
+	return credentials, nil
+}
+
+func (repo *PgxWebAuthnRepository) GetCredentialByID(ctx context.Context, ID []byte) (*domain.WebAuthnCredential, error) {
+	errfmt := "%w: %s"
+	query := `SELECT ` + credentialColumns + ` FROM webauthn_credentials WHERE id = $1`
+
+	credential, err := scanCredential(repo.dbpool.QueryRow(ctx, query, ID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(errfmt, domain.ErrWebAuthnCredentialNotFound, err.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralWebAuthn, err.Error())
+	}
+	return credential, nil
+}
+
+func (repo *PgxWebAuthnRepository) UseCredential(ctx context.Context, ID []byte, signCount uint32) error {
+	errfmt := "%w: %s"
+	// authenticators without a counter always report 0, any other counter must move forward or the
+	// credential may have been cloned
+	query := `UPDATE webauthn_credentials SET sign_count = $2, last_used_at = now()
+		WHERE id = $1 AND (sign_count < $2 OR (sign_count = 0 AND $2 = 0))`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, ID, int64(signCount))
+	if err != nil {
+		return fmt.Errorf(errfmt, domain.ErrGeneralWebAuthn, err.Error())
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf(errfmt, domain.ErrWebAuthnInvalid, "signature counter did not increase")
+	}
+	return nil
+}
+
+func (repo *PgxWebAuthnRepository) DeleteCredential(ctx context.Context, ID []byte, userID uuid.UUID) error {
+	errfmt := "%w: %s"
+	query := `DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, ID, userID)
+	if err != nil {
+		return fmt.Errorf(errfmt, domain.ErrGeneralWebAuthn, err.Error())
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf(errfmt, domain.ErrWebAuthnCredentialNotFound, "no credential of the user with this id")
+	}
+	return nil
+}
+
+func (repo *PgxWebAuthnRepository) DeleteCredentialsByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `DELETE FROM webauthn_credentials WHERE user_id = $1`
+	cmdTag, err := repo.dbpool.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", domain.ErrGeneralWebAuthn, err.Error())
+	}
+	return cmdTag.RowsAffected(), nil
+}
+
+func (repo *PgxWebAuthnRepository) CreateWebAuthnChallenge(ctx context.Context, challenge *domain.WebAuthnChallenge) error {
+	errfmt := "%w: %s"
+	query := `INSERT INTO webauthn_challenges (challenge_hash, user_id, ceremony, expires_at) VALUES ($1, $2, $3, $4)`
+	if _, err := repo.dbpool.Exec(ctx, query, challenge.ChallengeHash, challenge.UserID, challenge.Ceremony, challenge.ExpiresAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			// foreign_key_violation
+			return fmt.Errorf(errfmt, domain.ErrUserNotFound, pgErr.Error())
+		}
+		return fmt.Errorf(errfmt, domain.ErrGeneralWebAuthn, err.Error())
+	}
+	return nil
+}
+
+func (repo *PgxWebAuthnRepository) ConsumeWebAuthnChallenge(ctx context.Context, challengeHash []byte) (*domain.WebAuthnChallenge, error) {
+	errfmt := "%w: %s"
+	var challenge domain.WebAuthnChallenge
+	query := `DELETE FROM webauthn_challenges WHERE challenge_hash = $1 RETURNING challenge_hash, user_id, ceremony, expires_at`
+	err := repo.dbpool.QueryRow(ctx, query, challengeHash).Scan(&challenge.ChallengeHash, &challenge.UserID, &challenge.Ceremony, &challenge.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf(errfmt, domain.ErrWebAuthnChallengeInvalid, err.Error())
+		}
+		return nil, fmt.Errorf(errfmt, domain.ErrGeneralWebAuthn, err.Error())
+	}
+	return &challenge, nil
+}
+
+func (repo *PgxWebAuthnRepository) DeleteExpiredWebAuthnChallenges(ctx context.Context) (int64, error) {
+	query := `DELETE FROM webauthn_challenges WHERE expires_at <= now()`
+	cmdTag, err := repo.dbpool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", domain.ErrGeneralWebAuthn, err.Error())
+	}
+	return cmdTag.RowsAffected(), nil
+}
@@ -0,0 +1,26 @@
+package webauthn
+
+import (
+	"context"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/google/uuid"
+)
+
+type IWebAuthnRepository interface {
+	// CreateCredential stores a credential of the user, ErrWebAuthnCredentialExists is returned when its
+	// ID is registered already, to this user or another one.
+	CreateCredential(ctx context.Context, credential *domain.WebAuthnCredential) (*domain.WebAuthnCredential, error)
+	ListCredentialsByUser(ctx context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error)
+	GetCredentialByID(ctx context.Context, ID []byte) (*domain.WebAuthnCredential, error)
+	// UseCredential records a login with the credential. The signature counter must increase unless the
+	// authenticator does not keep one, ErrWebAuthnInvalid is returned for a counter that went backwards.
+	UseCredential(ctx context.Context, ID []byte, signCount uint32) error
+	DeleteCredential(ctx context.Context, ID []byte, userID uuid.UUID) error
+	DeleteCredentialsByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	CreateWebAuthnChallenge(ctx context.Context, challenge *domain.WebAuthnChallenge) error
+	// ConsumeWebAuthnChallenge removes the challenge and returns it, so it answers a single ceremony
+	ConsumeWebAuthnChallenge(ctx context.Context, challengeHash []byte) (*domain.WebAuthnChallenge, error)
+	DeleteExpiredWebAuthnChallenges(ctx context.Context) (int64, error)
+}
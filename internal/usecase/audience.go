@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+)
+
+// ResolveAudience returns the aud of a token issued to the client. Without a request the token
+// is for this server (defaultAudience); otherwise every requested resource (RFC 8707) must be
+// this server or one of the audiences registered for the client, or ErrInvalidTarget is returned.
+func ResolveAudience(client *domain.Client, requested []string, defaultAudience string) ([]string, error) {
+	if len(requested) == 0 {
+		return []string{defaultAudience}, nil
+	}
+
+	audience := make([]string, 0, len(requested))
+	seen := make(map[string]struct{}, len(requested))
+	for _, req := range requested {
+		if req != defaultAudience && !containsString(client.Audiences, req) {
+			return nil, fmt.Errorf("%w: '%s' is not allowed for the client", domain.ErrInvalidTarget, req)
+		}
+		if _, dup := seen[req]; dup {
+			continue
+		}
+		seen[req] = struct{}{}
+		audience = append(audience, req)
+	}
+	return audience, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveAudience(t *testing.T) {
+	const self = "https://auth.example.com"
+	client := &domain.Client{Audiences: []string{"https://api.example.com", "billing"}}
+
+	t.Run("empty request is for this server", func(t *testing.T) {
+		audience, err := usecase.ResolveAudience(client, nil, self)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{self}, audience)
+	})
+
+	t.Run("registered audiences and this server", func(t *testing.T) {
+		audience, err := usecase.ResolveAudience(client, []string{"https://api.example.com", self, "https://api.example.com"}, self)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"https://api.example.com", self}, audience)
+	})
+
+	t.Run("unregistered audience", func(t *testing.T) {
+		audience, err := usecase.ResolveAudience(client, []string{"https://api.example.com", "https://other.example.com"}, self)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidTarget)
+		assert.Nil(t, audience)
+	})
+
+	t.Run("client without audiences may only ask for this server", func(t *testing.T) {
+		_, err := usecase.ResolveAudience(&domain.Client{}, []string{"billing"}, self)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidTarget)
+	})
+}
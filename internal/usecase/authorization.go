@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/repository/authorization"
+	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/google/uuid"
+)
+
+const ResponseTypeCode = "code"
+
+// AuthorizationUseCase implements the authorization code grant with PKCE (RFC 6749 section 4.1, RFC 7636).
+type AuthorizationUseCase struct {
+	repo       authorization.IAuthorizationCodeRepository
+	clientRepo clientRepo.IClientRepository
+	userRepo   userRepo.IUserRepository
+	ttl        time.Duration
+}
+
+func NewAuthorizationUseCase(repo authorization.IAuthorizationCodeRepository, clientRepo clientRepo.IClientRepository, userRepo userRepo.IUserRepository, ttl time.Duration) *AuthorizationUseCase {
+	return &AuthorizationUseCase{repo: repo, clientRepo: clientRepo, userRepo: userRepo, ttl: ttl}
+}
+
+// ValidateAuthorizationRequest returns the client and the scope the user is asked to consent to.
+// ErrClientNotFound and ErrInvalidRedirectURI must be shown to the user; every other
+// error is reported to the client through its redirect uri.
+func (u *AuthorizationUseCase) ValidateAuthorizationRequest(ctx context.Context, req *domain.AuthorizationRequest) (*domain.Client, []domain.Permission, error) {
+	client, err := u.clientRepo.GetClientByID(ctx, req.ClientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !registeredRedirectURI(client, req.RedirectURI) {
+		return nil, nil, fmt.Errorf("%w: '%s' is not registered", domain.ErrInvalidRedirectURI, req.RedirectURI)
+	}
+
+	if req.ResponseType != ResponseTypeCode {
+		return nil, nil, fmt.Errorf("%w: '%s'", domain.ErrUnsupportedResponseType, req.ResponseType)
+	}
+	if req.CodeChallengeMethod != domain.CodeChallengeS256 {
+		return nil, nil, fmt.Errorf("%w: code_challenge_method must be %s", domain.ErrInvalidAuthorizationRequest, domain.CodeChallengeS256)
+	}
+	if !validCodeChallenge(req.CodeChallenge) {
+		return nil, nil, fmt.Errorf("%w: malformed code_challenge", domain.ErrInvalidAuthorizationRequest)
+	}
+
+	scope, err := NarrowScope(client.Scope, req.Scope)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, scope, nil
+}
+
+// IssueAuthorizationCode records the consent of the user, who logged in at authTime. approved narrows
+// the requested scope, an empty approval grants everything that was requested.
+func (u *AuthorizationUseCase) IssueAuthorizationCode(ctx context.Context, userID uuid.UUID, authTime time.Time, req *domain.AuthorizationRequest, approved []domain.Permission) (string, error) {
+	_, requested, err := u.ValidateAuthorizationRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	scope, err := NarrowScope(requested, approved)
+	if err != nil {
+		return "", err
+	}
+
+	code, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = u.repo.CreateAuthorizationCode(ctx, &domain.AuthorizationCode{
+		CodeHash:      hashToken(code),
+		ClientID:      req.ClientID,
+		UserID:        userID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         scope,
+		CodeChallenge: req.CodeChallenge,
+		Nonce:         req.Nonce,
+		AuthTime:      authTime,
+		ExpiresAt:     time.Now().Add(u.ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// ExchangeAuthorizationCode consumes the code and returns the user who consented together with the stored grant.
+// The code is burnt even when the exchange fails, so a leaked code cannot be retried.
+func (u *AuthorizationUseCase) ExchangeAuthorizationCode(ctx context.Context, clientID uuid.UUID, code string, redirectURI string, codeVerifier string) (*domain.User, *domain.AuthorizationCode, error) {
+	stored, err := u.repo.ConsumeAuthorizationCode(ctx, hashToken(code))
+	if err != nil {
+		if errors.Is(err, domain.ErrAuthorizationCodeNotFound) {
+			return nil, nil, fmt.Errorf("%w: %s", domain.ErrAuthorizationCodeInvalid, err.Error())
+		}
+		return nil, nil, err
+	}
+
+	switch {
+	case !time.Now().Before(stored.ExpiresAt):
+		return nil, nil, fmt.Errorf("%w: expired", domain.ErrAuthorizationCodeInvalid)
+	case stored.ClientID != clientID:
+		return nil, nil, fmt.Errorf("%w: issued to another client", domain.ErrAuthorizationCodeInvalid)
+	case stored.RedirectURI != redirectURI:
+		return nil, nil, fmt.Errorf("%w: redirect_uri mismatch", domain.ErrAuthorizationCodeInvalid)
+	case !verifyCodeChallenge(stored.CodeChallenge, codeVerifier):
+		return nil, nil, fmt.Errorf("%w: code_verifier mismatch", domain.ErrAuthorizationCodeInvalid)
+	}
+
+	user, err := u.userRepo.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, nil, fmt.Errorf("%w: %s", domain.ErrAuthorizationCodeInvalid, err.Error())
+		}
+		return nil, nil, err
+	}
+	return user, stored, nil
+}
+
+// registeredRedirectURI compares by simple string equality (RFC 6749 section 3.1.2.3).
+func registeredRedirectURI(client *domain.Client, redirectURI string) bool {
+	for _, registered := range client.RedirectURIs {
+		if registered == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+// validCodeChallenge accepts the base64url encoding of a SHA-256 digest.
+func validCodeChallenge(challenge string) bool {
+	digest, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(digest) == sha256.Size
+}
+
+// verifyCodeChallenge checks the verifier against the S256 challenge (RFC 7636 section 4.6).
+func verifyCodeChallenge(challenge string, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for _, c := range verifier {
+		if !isUnreserved(c) {
+			return false
+		}
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+func isUnreserved(c rune) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,230 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	authorizationRepo "github.com/bright-pentium/go-client-practice/internal/repository/authorization"
+	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 7636 appendix B
+const (
+	testCodeVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testCodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func testAuthorizationRequest(client *domain.Client) *domain.AuthorizationRequest {
+	return &domain.AuthorizationRequest{
+		ResponseType:        usecase.ResponseTypeCode,
+		ClientID:            client.ID,
+		RedirectURI:         client.RedirectURIs[0],
+		Scope:               []domain.Permission{domain.PermCreateResource},
+		State:               "xyz",
+		CodeChallenge:       testCodeChallenge,
+		CodeChallengeMethod: domain.CodeChallengeS256,
+	}
+}
+
+func TestValidateAuthorizationRequest(t *testing.T) {
+	ctx := context.Background()
+	client := &domain.Client{
+		ID:           uuid.New(),
+		Scope:        []domain.Permission{domain.PermCreateResource},
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}
+
+	cases := []struct {
+		name   string
+		modify func(req *domain.AuthorizationRequest)
+		err    error
+	}{
+		{"unregistered redirect uri", func(req *domain.AuthorizationRequest) { req.RedirectURI = "https://evil.example.com/callback" }, domain.ErrInvalidRedirectURI},
+		{"redirect uri is matched exactly", func(req *domain.AuthorizationRequest) { req.RedirectURI += "/" }, domain.ErrInvalidRedirectURI},
+		{"implicit flow", func(req *domain.AuthorizationRequest) { req.ResponseType = "token" }, domain.ErrUnsupportedResponseType},
+		{"missing pkce", func(req *domain.AuthorizationRequest) { req.CodeChallenge, req.CodeChallengeMethod = "", "" }, domain.ErrInvalidAuthorizationRequest},
+		{"plain pkce", func(req *domain.AuthorizationRequest) { req.CodeChallengeMethod = "plain" }, domain.ErrInvalidAuthorizationRequest},
+		{"malformed challenge", func(req *domain.AuthorizationRequest) { req.CodeChallenge = "short" }, domain.ErrInvalidAuthorizationRequest},
+		{"scope beyond the client", func(req *domain.AuthorizationRequest) { req.Scope = []domain.Permission{domain.PermAll} }, domain.ErrInvalidScope},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClientRepo := new(clientRepo.MockClientRepository)
+			uc := usecase.NewAuthorizationUseCase(nil, mockClientRepo, nil, time.Minute)
+			mockClientRepo.On("GetClientByID", ctx, client.ID).Return(client, nil)
+
+			req := testAuthorizationRequest(client)
+			tc.modify(req)
+			_, _, err := uc.ValidateAuthorizationRequest(ctx, req)
+
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	t.Run("valid request", func(t *testing.T) {
+		mockClientRepo := new(clientRepo.MockClientRepository)
+		uc := usecase.NewAuthorizationUseCase(nil, mockClientRepo, nil, time.Minute)
+		mockClientRepo.On("GetClientByID", ctx, client.ID).Return(client, nil)
+
+		got, scope, err := uc.ValidateAuthorizationRequest(ctx, testAuthorizationRequest(client))
+
+		require.NoError(t, err)
+		assert.Equal(t, client, got)
+		assert.Equal(t, []domain.Permission{domain.PermCreateResource}, scope)
+	})
+
+	t.Run("unknown client", func(t *testing.T) {
+		mockClientRepo := new(clientRepo.MockClientRepository)
+		uc := usecase.NewAuthorizationUseCase(nil, mockClientRepo, nil, time.Minute)
+		mockClientRepo.On("GetClientByID", ctx, client.ID).Return(nil, domain.ErrClientNotFound)
+
+		_, _, err := uc.ValidateAuthorizationRequest(ctx, testAuthorizationRequest(client))
+
+		assert.ErrorIs(t, err, domain.ErrClientNotFound)
+	})
+}
+
+func TestIssueAuthorizationCode(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	client := &domain.Client{
+		ID:           uuid.New(),
+		Scope:        []domain.Permission{domain.PermAll},
+		RedirectURIs: []string{"https://app.example.com/callback"},
+	}
+
+	t.Run("stores the hash of the code with the approved scope", func(t *testing.T) {
+		mockRepo := new(authorizationRepo.MockAuthorizationCodeRepository)
+		mockClientRepo := new(clientRepo.MockClientRepository)
+		uc := usecase.NewAuthorizationUseCase(mockRepo, mockClientRepo, nil, time.Minute)
+
+		req := testAuthorizationRequest(client)
+		req.Scope = []domain.Permission{domain.PermCreateResource, domain.PermProfile}
+		req.Nonce = "n-0S6_WzA2Mj"
+		authTime := time.Now().Add(-time.Hour)
+
+		var captured *domain.AuthorizationCode
+		mockClientRepo.On("GetClientByID", ctx, client.ID).Return(client, nil)
+		mockRepo.On("CreateAuthorizationCode", ctx, mock.AnythingOfType("*domain.AuthorizationCode")).
+			Run(func(args mock.Arguments) {
+				captured = args.Get(1).(*domain.AuthorizationCode)
+			}).
+			Return(&domain.AuthorizationCode{}, nil)
+
+		code, err := uc.IssueAuthorizationCode(ctx, userID, authTime, req, []domain.Permission{domain.PermProfile})
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, code)
+		assert.Equal(t, sha256Of(code), captured.CodeHash)
+		assert.Equal(t, client.ID, captured.ClientID)
+		assert.Equal(t, userID, captured.UserID)
+		assert.Equal(t, req.RedirectURI, captured.RedirectURI)
+		assert.Equal(t, []domain.Permission{domain.PermProfile}, captured.Scope)
+		assert.Equal(t, testCodeChallenge, captured.CodeChallenge)
+		assert.Equal(t, "n-0S6_WzA2Mj", captured.Nonce)
+		assert.Equal(t, authTime, captured.AuthTime)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), captured.ExpiresAt, time.Second)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("approval cannot exceed the request", func(t *testing.T) {
+		mockRepo := new(authorizationRepo.MockAuthorizationCodeRepository)
+		mockClientRepo := new(clientRepo.MockClientRepository)
+		uc := usecase.NewAuthorizationUseCase(mockRepo, mockClientRepo, nil, time.Minute)
+		mockClientRepo.On("GetClientByID", ctx, client.ID).Return(client, nil)
+
+		_, err := uc.IssueAuthorizationCode(ctx, userID, time.Now(), testAuthorizationRequest(client), []domain.Permission{domain.PermIntrospect})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidScope)
+		mockRepo.AssertNotCalled(t, "CreateAuthorizationCode", mock.Anything, mock.Anything)
+	})
+}
+
+func TestExchangeAuthorizationCode(t *testing.T) {
+	ctx := context.Background()
+	code := "issued-code"
+	clientID := uuid.New()
+	user := &domain.User{ID: uuid.New(), Name: "Alice"}
+	redirectURI := "https://app.example.com/callback"
+
+	stored := func() *domain.AuthorizationCode {
+		return &domain.AuthorizationCode{
+			ClientID:      clientID,
+			UserID:        user.ID,
+			RedirectURI:   redirectURI,
+			Scope:         []domain.Permission{domain.PermCreateResource},
+			CodeChallenge: testCodeChallenge,
+			ExpiresAt:     time.Now().Add(time.Minute),
+		}
+	}
+
+	t.Run("successful exchange", func(t *testing.T) {
+		mockRepo := new(authorizationRepo.MockAuthorizationCodeRepository)
+		mockUserRepo := new(userRepo.MockUserRepository)
+		uc := usecase.NewAuthorizationUseCase(mockRepo, nil, mockUserRepo, time.Minute)
+
+		grant := stored()
+		mockRepo.On("ConsumeAuthorizationCode", ctx, sha256Of(code)).Return(grant, nil)
+		mockUserRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+
+		gotUser, gotGrant, err := uc.ExchangeAuthorizationCode(ctx, clientID, code, redirectURI, testCodeVerifier)
+
+		require.NoError(t, err)
+		assert.Equal(t, user, gotUser)
+		assert.Equal(t, grant, gotGrant)
+	})
+
+	cases := []struct {
+		name        string
+		modify      func(grant *domain.AuthorizationCode)
+		clientID    uuid.UUID
+		redirectURI string
+		verifier    string
+	}{
+		{"wrong verifier", func(*domain.AuthorizationCode) {}, clientID, redirectURI, "x" + testCodeVerifier[1:]},
+		{"short verifier", func(grant *domain.AuthorizationCode) {
+			sum := sha256.Sum256([]byte("short"))
+			grant.CodeChallenge = base64.RawURLEncoding.EncodeToString(sum[:])
+		}, clientID, redirectURI, "short"},
+		{"other client", func(*domain.AuthorizationCode) {}, uuid.New(), redirectURI, testCodeVerifier},
+		{"other redirect uri", func(*domain.AuthorizationCode) {}, clientID, redirectURI + "/other", testCodeVerifier},
+		{"expired", func(grant *domain.AuthorizationCode) { grant.ExpiresAt = time.Now().Add(-time.Second) }, clientID, redirectURI, testCodeVerifier},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(authorizationRepo.MockAuthorizationCodeRepository)
+			uc := usecase.NewAuthorizationUseCase(mockRepo, nil, nil, time.Minute)
+
+			grant := stored()
+			tc.modify(grant)
+			mockRepo.On("ConsumeAuthorizationCode", ctx, sha256Of(code)).Return(grant, nil)
+
+			gotUser, gotGrant, err := uc.ExchangeAuthorizationCode(ctx, tc.clientID, code, tc.redirectURI, tc.verifier)
+
+			assert.ErrorIs(t, err, domain.ErrAuthorizationCodeInvalid)
+			assert.Nil(t, gotUser)
+			assert.Nil(t, gotGrant)
+		})
+	}
+
+	t.Run("used or unknown code", func(t *testing.T) {
+		mockRepo := new(authorizationRepo.MockAuthorizationCodeRepository)
+		uc := usecase.NewAuthorizationUseCase(mockRepo, nil, nil, time.Minute)
+
+		mockRepo.On("ConsumeAuthorizationCode", ctx, sha256Of(code)).Return(nil, domain.ErrAuthorizationCodeNotFound)
+
+		_, _, err := uc.ExchangeAuthorizationCode(ctx, clientID, code, redirectURI, testCodeVerifier)
+
+		assert.ErrorIs(t, err, domain.ErrAuthorizationCodeInvalid)
+	})
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+)
+
+// StaticClaimProvider adds the same claims to every token, such as the tenant or environment of the deployment.
+type StaticClaimProvider struct {
+	claims map[string]string
+}
+
+func NewStaticClaimProvider(claims map[string]string) *StaticClaimProvider {
+	return &StaticClaimProvider{claims: claims}
+}
+
+func (p *StaticClaimProvider) Name() string {
+	return "static"
+}
+
+func (p *StaticClaimProvider) Claims(ctx context.Context, req *domain.ClaimRequest) (map[string]interface{}, error) {
+	claims := make(map[string]interface{}, len(p.claims))
+	for name, value := range p.claims {
+		claims[name] = value
+	}
+	return claims, nil
+}
+
+// ClientOwnerClaimProvider names the user who registered the client on client tokens, so resource
+// servers can tell who is behind a machine token.
+type ClientOwnerClaimProvider struct {
+	userRepo userRepo.IUserRepository
+}
+
+func NewClientOwnerClaimProvider(userRepo userRepo.IUserRepository) *ClientOwnerClaimProvider {
+	return &ClientOwnerClaimProvider{userRepo: userRepo}
+}
+
+func (p *ClientOwnerClaimProvider) Name() string {
+	return "owner"
+}
+
+func (p *ClientOwnerClaimProvider) Claims(ctx context.Context, req *domain.ClaimRequest) (map[string]interface{}, error) {
+	if req.Client == nil || req.Claims.Type != domain.ClientType {
+		return nil, nil
+	}
+	owner, err := p.userRepo.GetUserByID(ctx, req.Client.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return map[string]interface{}{"owner": owner.ID.String(), "owner_name": owner.Name}, nil
+}
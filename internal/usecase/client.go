@@ -2,61 +2,231 @@ package usecase
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/bright-pentium/go-client-practice/internal/domain"
 	repo "github.com/bright-pentium/go-client-practice/internal/repository/client"
 	"github.com/google/uuid"
 	"github.com/sethvargo/go-password/password"
-	"golang.org/x/crypto/bcrypt"
-)
-
-const (
-	ClientPepper string = "3tw0d2o"
 )
 
 type ClientUseCase struct {
-	repo repo.IClientRepository
+	repo     repo.IClientRepository
+	hasher   *PepperedHasher
+	throttle *LoginThrottleUseCase
 }
 
-func NewClientUseCase(repo repo.IClientRepository) *ClientUseCase {
-	return &ClientUseCase{repo: repo}
+func NewClientUseCase(repo repo.IClientRepository, hasher *PepperedHasher, throttle *LoginThrottleUseCase) *ClientUseCase {
+	return &ClientUseCase{repo: repo, hasher: hasher, throttle: throttle}
 }
 
 func (u *ClientUseCase) ListClientsByUser(ctx context.Context, userID uuid.UUID) ([]domain.Client, error) {
 	return u.repo.ListClientsByUser(ctx, userID)
 }
 
-func (u *ClientUseCase) CreateClient(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission) (*domain.Client, string, error) {
-	randomStrings, err := password.Generate(32, 10, 0, false, true)
+// CreateClient registers the client with a generated secret, which is returned once in plain text.
+// Clients authenticating with a TLS client certificate or private_key_jwt get no secret and an empty string is returned.
+// ID, UserID, Scope, RedirectURIs, Audiences, the authentication method, the claim providers and the access token format are taken from the given client.
+func (u *ClientUseCase) CreateClient(ctx context.Context, client *domain.Client) (*domain.Client, string, error) {
+	// the admin APIs are for administrators themselves, never for a client acting on its own
+	if slices.Contains(client.Scope, domain.PermAdmin) {
+		return nil, "", fmt.Errorf("%w: the admin permission cannot be granted to a client", domain.ErrInvalidClientData)
+	}
+	for _, redirectURI := range client.RedirectURIs {
+		if err := validateRedirectURI(redirectURI); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := validateAuthMethod(client); err != nil {
+		return nil, "", err
+	}
+	switch client.AccessTokenFormat {
+	case "", domain.TokenFormatJWT, domain.TokenFormatOpaque:
+	default:
+		return nil, "", fmt.Errorf("%w: unsupported access token format '%s'", domain.ErrInvalidClientData, client.AccessTokenFormat)
+	}
+
+	registered := &domain.Client{
+		ID:           client.ID,
+		UserID:       client.UserID,
+		Scope:        client.Scope,
+		RedirectURIs: client.RedirectURIs,
+		Audiences:    client.Audiences,
+		DPoPRequired: client.DPoPRequired,
+		AuthMethod:   client.AuthMethod,
+
+		ClaimProviders:    client.ClaimProviders,
+		AccessTokenFormat: client.AccessTokenFormat,
+	}
+	switch client.AuthMethod {
+	case "":
+		registered.AuthMethod = domain.AuthMethodClientSecretBasic
+	case domain.AuthMethodTLSClientAuth:
+		registered.TLSSubjectDN, registered.TLSClientCA = client.TLSSubjectDN, client.TLSClientCA
+	case domain.AuthMethodSelfSignedTLSClientAuth:
+		registered.TLSCertificateThumbprint = client.TLSCertificateThumbprint
+	}
+	if registered.AccessTokenFormat == "" {
+		registered.AccessTokenFormat = domain.TokenFormatJWT
+	}
+
+	var randomStrings string
+	if registered.UsesSecret() {
+		var err error
+		if randomStrings, err = password.Generate(32, 10, 0, false, true); err != nil {
+			return nil, "", err
+		}
+		if registered.SecretHash, registered.PepperVersion, err = u.hasher.Hash(randomStrings); err != nil {
+			return nil, "", fmt.Errorf("%w: %s", domain.ErrClientHashFail, err)
+		}
+	}
+
+	created, err := u.repo.CreateClient(ctx, registered)
 	if err != nil {
 		return nil, "", err
 	}
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(ClientPepper+randomStrings), bcrypt.DefaultCost)
+	return created, randomStrings, nil
+}
+
+// ClientLogin checks the secret of the client, ip is where the login comes from. While the client or
+// the ip is locked out the secret is not even checked and a LoginThrottledError is returned.
+func (u *ClientUseCase) ClientLogin(ctx context.Context, ID uuid.UUID, secret string, ip string) (*domain.Client, error) {
+	keys := loginKeys(clientLoginKey(ID), ip)
+	retryAfter, err := u.throttle.Check(ctx, keys...)
 	if err != nil {
-		return nil, "", fmt.Errorf("%w: %s", domain.ErrClientHashFail, err)
+		return nil, err
+	}
+	if retryAfter > 0 {
+		return nil, &domain.LoginThrottledError{RetryAfter: retryAfter, Err: domain.ErrClientLoginFail}
 	}
 
-	client, err := u.repo.CreateClient(ctx, ID, userID, scope, passwordHash)
+	client, err := u.repo.GetClientByID(ctx, ID)
 	if err != nil {
-		return nil, "", err
+		if errors.Is(err, domain.ErrClientNotFound) {
+			return nil, u.throttle.failed(ctx, domain.ErrClientLoginFail, keys...)
+		}
+		return nil, err
 	}
-	return client, randomStrings, nil
+	if !client.UsesSecret() {
+		return nil, u.throttle.failed(ctx, domain.ErrClientLoginFail, keys...)
+	}
+	ok, rehash := u.hasher.Verify(client.SecretHash, client.PepperVersion, secret)
+	if !ok {
+		return nil, u.throttle.failed(ctx, domain.ErrClientLoginFail, keys...)
+	}
+	if err := u.throttle.Reset(ctx, clientLoginKey(ID)); err != nil {
+		return nil, err
+	}
+
+	// the secret is only ever known here, move the hash to the current algorithm and pepper
+	if rehash {
+		if err := u.rehash(ctx, client, secret); err != nil {
+			log.Printf("secret rehash of client '%s' failed: %v", client.ID, err)
+		}
+	}
+	return client, nil
 }
 
-func (u *ClientUseCase) ClientLogin(ctx context.Context, ID uuid.UUID, secret string) (*domain.Client, error) {
+func (u *ClientUseCase) rehash(ctx context.Context, client *domain.Client, secret string) error {
+	secretHash, pepperVersion, err := u.hasher.Hash(secret)
+	if err != nil {
+		return err
+	}
+	if _, err := u.repo.UpdateClientByIDandUser(ctx, client.ID, client.UserID, nil, secretHash, pepperVersion); err != nil {
+		return err
+	}
+	client.SecretHash, client.PepperVersion = secretHash, pepperVersion
+	return nil
+}
+
+// TLSClientLogin authenticates a client by the TLS client certificate chain it presented, leaf first (RFC 8705 section 2).
+// A tls_client_auth client needs a chain to its registered CA with the registered subject DN, in the RFC 4514 form
+// x509 formats it in. A self_signed_tls_client_auth client needs the very certificate it registered.
+func (u *ClientUseCase) TLSClientLogin(ctx context.Context, ID uuid.UUID, chain []*x509.Certificate) (*domain.Client, error) {
+	if len(chain) == 0 {
+		return nil, domain.ErrClientLoginFail
+	}
 	client, err := u.repo.GetClientByID(ctx, ID)
 	if err != nil {
+		if errors.Is(err, domain.ErrClientNotFound) {
+			return nil, domain.ErrClientLoginFail
+		}
 		return nil, err
 	}
-	if bcrypt.CompareHashAndPassword(client.SecretHash, []byte(ClientPepper+secret)) != nil {
+
+	leaf := chain[0]
+	switch client.AuthMethod {
+	case domain.AuthMethodTLSClientAuth:
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(client.TLSClientCA)) {
+			return nil, domain.ErrClientLoginFail
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range chain[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := leaf.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		if err != nil || leaf.Subject.String() != client.TLSSubjectDN {
+			return nil, domain.ErrClientLoginFail
+		}
+	case domain.AuthMethodSelfSignedTLSClientAuth:
+		now := time.Now()
+		thumbprint := domain.CertificateThumbprint(leaf)
+		if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) ||
+			subtle.ConstantTimeCompare([]byte(thumbprint), []byte(client.TLSCertificateThumbprint)) != 1 {
+			return nil, domain.ErrClientLoginFail
+		}
+	default:
 		return nil, domain.ErrClientLoginFail
 	}
 	return client, nil
 }
 
+// validateAuthMethod checks that a client authenticating with a TLS client certificate registered what identifies it.
+// The keys of a private_key_jwt client are registered apart from the client.
+func validateAuthMethod(client *domain.Client) error {
+	switch client.AuthMethod {
+	case "", domain.AuthMethodClientSecretBasic, domain.AuthMethodPrivateKeyJWT:
+	case domain.AuthMethodTLSClientAuth:
+		if client.TLSSubjectDN == "" || !x509.NewCertPool().AppendCertsFromPEM([]byte(client.TLSClientCA)) {
+			return fmt.Errorf("%w: tls_client_auth needs a subject DN and a PEM encoded CA", domain.ErrInvalidClientData)
+		}
+	case domain.AuthMethodSelfSignedTLSClientAuth:
+		thumbprint, err := base64.RawURLEncoding.DecodeString(client.TLSCertificateThumbprint)
+		if err != nil || len(thumbprint) != sha256.Size {
+			return fmt.Errorf("%w: self_signed_tls_client_auth needs the x5t#S256 of the certificate", domain.ErrInvalidClientData)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported token endpoint auth method '%s'", domain.ErrInvalidClientData, client.AuthMethod)
+	}
+	return nil
+}
+
+// validateRedirectURI enforces RFC 6749 section 3.1.2: an absolute URI without a fragment.
+func validateRedirectURI(redirectURI string) error {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil || !parsed.IsAbs() || parsed.Host == "" || parsed.Fragment != "" || strings.Contains(redirectURI, "#") {
+		return fmt.Errorf("%w: '%s'", domain.ErrInvalidRedirectURI, redirectURI)
+	}
+	return nil
+}
+
 func (u *ClientUseCase) UpdateClientScope(ctx context.Context, ID uuid.UUID, userID uuid.UUID, scope []domain.Permission) (*domain.Client, error) {
-	return u.repo.UpdateClientByIDandUser(ctx, ID, userID, scope, nil)
+	return u.repo.UpdateClientByIDandUser(ctx, ID, userID, scope, nil, 0)
 }
 
 func (u *ClientUseCase) DeleteClientByIDandUser(ctx context.Context, ID uuid.UUID, userID uuid.UUID) error {
@@ -2,8 +2,17 @@ package usecase_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
+	"math/big"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bright-pentium/go-client-practice/internal/domain"
 	mockRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
@@ -11,12 +20,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
 func TestListClientsByUser(t *testing.T) {
 	mockRepo := new(mockRepo.MockClientRepository)
-	uc := usecase.NewClientUseCase(mockRepo)
+	uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
 
 	userID := uuid.New()
 	expectedClients := []domain.Client{{ID: uuid.New(), UserID: userID}}
@@ -33,35 +43,67 @@ func TestListClientsByUser(t *testing.T) {
 
 func TestCreateClient(t *testing.T) {
 	mockRepo := new(mockRepo.MockClientRepository)
-	uc := usecase.NewClientUseCase(mockRepo)
+	uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
 
 	clientID := uuid.New()
 	userID := uuid.New()
 	scope := []domain.Permission{"read", "write"}
+	redirectURIs := []string{"https://app.example.com/callback"}
+	audiences := []string{"https://api.example.com"}
 
-	var capturedHash []byte
-	expectedClient := &domain.Client{ID: clientID, UserID: userID, Scope: scope}
+	var captured *domain.Client
+	expectedClient := &domain.Client{ID: clientID, UserID: userID, Scope: scope, RedirectURIs: redirectURIs, Audiences: audiences}
 
 	mockRepo.
-		On("CreateClient", mock.Anything, clientID, userID, scope, mock.AnythingOfType("[]uint8")).
+		On("CreateClient", mock.Anything, mock.AnythingOfType("*domain.Client")).
 		Run(func(args mock.Arguments) {
-			capturedHash = args.Get(4).([]byte)
+			captured = args.Get(1).(*domain.Client)
 		}).
 		Return(expectedClient, nil)
 
 	ctx := context.Background()
-	client, secret, err := uc.CreateClient(ctx, clientID, userID, scope)
+	client, secret, err := uc.CreateClient(ctx, &domain.Client{ID: clientID, UserID: userID, Scope: scope, RedirectURIs: redirectURIs, Audiences: audiences})
 
 	assert.NoError(t, err)
 	assert.NotEmpty(t, secret)
 	assert.Equal(t, expectedClient, client)
-	assert.NotNil(t, capturedHash)
+	assert.Equal(t, clientID, captured.ID)
+	assert.Equal(t, userID, captured.UserID)
+	assert.Equal(t, scope, captured.Scope)
+	assert.Equal(t, redirectURIs, captured.RedirectURIs)
+	assert.Equal(t, audiences, captured.Audiences)
+	assert.NotNil(t, captured.SecretHash)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCreateClientInvalidRedirectURI(t *testing.T) {
+	mockRepo := new(mockRepo.MockClientRepository)
+	uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
+
+	for _, redirectURI := range []string{"/callback", "app.example.com/callback", "https://app.example.com/callback#frag", "::"} {
+		client, secret, err := uc.CreateClient(context.Background(), &domain.Client{ID: uuid.New(), RedirectURIs: []string{redirectURI}})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidRedirectURI, redirectURI)
+		assert.Nil(t, client)
+		assert.Empty(t, secret)
+	}
+	mockRepo.AssertNotCalled(t, "CreateClient", mock.Anything, mock.Anything)
+}
+
+func TestCreateClientAdminScope(t *testing.T) {
+	mockRepo := new(mockRepo.MockClientRepository)
+	uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
+
+	client, _, err := uc.CreateClient(context.Background(), &domain.Client{ID: uuid.New(), Scope: []domain.Permission{domain.PermAdmin}})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidClientData)
+	assert.Nil(t, client)
+	mockRepo.AssertNotCalled(t, "CreateClient", mock.Anything, mock.Anything)
+}
+
 func TestClientLoginSuccess(t *testing.T) {
 	mockRepo := new(mockRepo.MockClientRepository)
-	uc := usecase.NewClientUseCase(mockRepo)
+	uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
 
 	clientID := uuid.New()
 	secret := "supersecret"
@@ -70,18 +112,21 @@ func TestClientLoginSuccess(t *testing.T) {
 	expectedClient := &domain.Client{ID: clientID, SecretHash: hash}
 
 	mockRepo.On("GetClientByID", mock.Anything, clientID).Return(expectedClient, nil)
+	// the bcrypt hash is upgraded to Argon2id
+	mockRepo.On("UpdateClientByIDandUser", mock.Anything, clientID, uuid.Nil, []domain.Permission(nil), mock.AnythingOfType("[]uint8"), 0).Return(expectedClient, nil)
 
 	ctx := context.Background()
-	client, err := uc.ClientLogin(ctx, clientID, secret)
+	client, err := uc.ClientLogin(ctx, clientID, secret, "")
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedClient, client)
+	assert.True(t, strings.HasPrefix(string(client.SecretHash), "$argon2id$"))
 	mockRepo.AssertExpectations(t)
 }
 
 func TestClientLoginFailure(t *testing.T) {
 	mockRepo := new(mockRepo.MockClientRepository)
-	uc := usecase.NewClientUseCase(mockRepo)
+	uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
 
 	clientID := uuid.New()
 	incorrectSecret := "wrong"
@@ -93,7 +138,7 @@ func TestClientLoginFailure(t *testing.T) {
 	mockRepo.On("GetClientByID", mock.Anything, clientID).Return(expectedClient, nil)
 
 	ctx := context.Background()
-	client, err := uc.ClientLogin(ctx, clientID, incorrectSecret)
+	client, err := uc.ClientLogin(ctx, clientID, incorrectSecret, "")
 
 	assert.ErrorIs(t, err, domain.ErrClientLoginFail)
 	assert.Nil(t, client)
@@ -102,21 +147,176 @@ func TestClientLoginFailure(t *testing.T) {
 
 func TestClientLoginRepoError(t *testing.T) {
 	mockRepo := new(mockRepo.MockClientRepository)
-	uc := usecase.NewClientUseCase(mockRepo)
+	uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
 
 	clientID := uuid.New()
 
 	mockRepo.On("GetClientByID", mock.Anything, clientID).Return(nil, errors.New("db error"))
 
 	ctx := context.Background()
-	client, err := uc.ClientLogin(ctx, clientID, "whatever")
+	client, err := uc.ClientLogin(ctx, clientID, "whatever", "")
 
 	assert.Error(t, err)
 	assert.Nil(t, client)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestClientLoginNotFound(t *testing.T) {
+	mockRepo := new(mockRepo.MockClientRepository)
+	uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
+
+	clientID := uuid.New()
+
+	mockRepo.On("GetClientByID", mock.Anything, clientID).Return(nil, domain.ErrClientNotFound)
+
+	ctx := context.Background()
+	client, err := uc.ClientLogin(ctx, clientID, "whatever", "")
+
+	assert.ErrorIs(t, err, domain.ErrClientLoginFail)
+	assert.Nil(t, client)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestClientLoginTLSClient(t *testing.T) {
+	mockRepo := new(mockRepo.MockClientRepository)
+	uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
+
+	clientID := uuid.New()
+	hash, _ := bcryptGenerateWithPepper("leftover")
+	mockRepo.On("GetClientByID", mock.Anything, clientID).Return(&domain.Client{ID: clientID, SecretHash: hash, AuthMethod: domain.AuthMethodTLSClientAuth}, nil)
+
+	client, err := uc.ClientLogin(context.Background(), clientID, "leftover", "")
+
+	assert.ErrorIs(t, err, domain.ErrClientLoginFail)
+	assert.Nil(t, client)
+}
+
+func TestCreateTLSClient(t *testing.T) {
+	ca, _ := newTestCertificate(t, "Test CA", nil, nil)
+
+	t.Run("no secret is issued", func(t *testing.T) {
+		mockRepo := new(mockRepo.MockClientRepository)
+		uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
+		var captured *domain.Client
+		mockRepo.
+			On("CreateClient", mock.Anything, mock.AnythingOfType("*domain.Client")).
+			Run(func(args mock.Arguments) { captured = args.Get(1).(*domain.Client) }).
+			Return(&domain.Client{}, nil)
+
+		_, secret, err := uc.CreateClient(context.Background(), &domain.Client{
+			ID:           uuid.New(),
+			AuthMethod:   domain.AuthMethodTLSClientAuth,
+			TLSSubjectDN: "CN=service",
+			TLSClientCA:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw})),
+		})
+
+		require.NoError(t, err)
+		assert.Empty(t, secret)
+		assert.Nil(t, captured.SecretHash)
+		assert.Equal(t, "CN=service", captured.TLSSubjectDN)
+	})
+
+	cases := []struct {
+		name   string
+		client domain.Client
+	}{
+		{"unknown method", domain.Client{AuthMethod: "client_secret_jwt"}},
+		{"missing subject DN", domain.Client{AuthMethod: domain.AuthMethodTLSClientAuth, TLSClientCA: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))}},
+		{"CA is not PEM", domain.Client{AuthMethod: domain.AuthMethodTLSClientAuth, TLSSubjectDN: "CN=service", TLSClientCA: "garbage"}},
+		{"thumbprint is not SHA-256", domain.Client{AuthMethod: domain.AuthMethodSelfSignedTLSClientAuth, TLSCertificateThumbprint: "c2hvcnQ"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(mockRepo.MockClientRepository)
+			uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
+
+			_, _, err := uc.CreateClient(context.Background(), &tc.client)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidClientData)
+			mockRepo.AssertNotCalled(t, "CreateClient", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTLSClientLogin(t *testing.T) {
+	ctx := context.Background()
+	ca, caKey := newTestCertificate(t, "Test CA", nil, nil)
+	leaf, _ := newTestCertificate(t, "service", ca, caKey)
+	otherCA, otherKey := newTestCertificate(t, "Other CA", nil, nil)
+	foreign, _ := newTestCertificate(t, "service", otherCA, otherKey)
+	selfSigned, _ := newTestCertificate(t, "device", nil, nil)
+
+	caClient := &domain.Client{
+		ID:           uuid.New(),
+		AuthMethod:   domain.AuthMethodTLSClientAuth,
+		TLSSubjectDN: "CN=service",
+		TLSClientCA:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw})),
+	}
+	selfSignedClient := &domain.Client{
+		ID:                       uuid.New(),
+		AuthMethod:               domain.AuthMethodSelfSignedTLSClientAuth,
+		TLSCertificateThumbprint: domain.CertificateThumbprint(selfSigned),
+	}
+	secretClient := &domain.Client{ID: uuid.New(), AuthMethod: domain.AuthMethodClientSecretBasic}
+
+	cases := []struct {
+		name   string
+		client *domain.Client
+		chain  []*x509.Certificate
+		ok     bool
+	}{
+		{"certificate issued by the registered CA", caClient, []*x509.Certificate{leaf}, true},
+		{"certificate of another CA", caClient, []*x509.Certificate{foreign}, false},
+		{"other subject DN", &domain.Client{ID: caClient.ID, AuthMethod: caClient.AuthMethod, TLSSubjectDN: "CN=other", TLSClientCA: caClient.TLSClientCA}, []*x509.Certificate{leaf}, false},
+		{"registered self-signed certificate", selfSignedClient, []*x509.Certificate{selfSigned}, true},
+		{"other self-signed certificate", selfSignedClient, []*x509.Certificate{leaf}, false},
+		{"secret client", secretClient, []*x509.Certificate{leaf}, false},
+		{"no certificate", caClient, nil, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(mockRepo.MockClientRepository)
+			uc := usecase.NewClientUseCase(mockRepo, testPepperedHasher(), testThrottle())
+			mockRepo.On("GetClientByID", mock.Anything, tc.client.ID).Return(tc.client, nil)
+
+			client, err := uc.TLSClientLogin(ctx, tc.client.ID, tc.chain)
+
+			if tc.ok {
+				require.NoError(t, err)
+				assert.Equal(t, tc.client, client)
+			} else {
+				assert.ErrorIs(t, err, domain.ErrClientLoginFail)
+				assert.Nil(t, client)
+			}
+		})
+	}
+}
+
+// newTestCertificate issues a client certificate for the common name, self-signed when parent is nil.
+func newTestCertificate(t *testing.T, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
 // Helper function to generate bcrypt hash with pepper
 func bcryptGenerateWithPepper(secret string) ([]byte, error) {
-	return bcrypt.GenerateFromPassword([]byte(usecase.ClientPepper+secret), bcrypt.MinCost)
+	return bcrypt.GenerateFromPassword([]byte(testPepper+secret), bcrypt.MinCost)
 }
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	"github.com/bright-pentium/go-client-practice/internal/repository/clientkey"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// ClientAssertionAlgorithms are the signing algorithms accepted for client assertions, asymmetric only:
+// a shared key would be a client secret again.
+var ClientAssertionAlgorithms = []string{"ES256", "RS256", "PS256", "EdDSA"}
+
+// maxAssertionLifetime bounds how far in the future a client assertion may expire, it is also the
+// longest a jti has to be remembered against replay.
+const maxAssertionLifetime = 5 * time.Minute
+
+// ClientKeyUseCase manages the public keys of private_key_jwt clients and authenticates them by
+// JWT assertion (RFC 7523 section 2.2). The jti replay cache is per process like the DPoP one.
+type ClientKeyUseCase struct {
+	repo       clientkey.IClientKeyRepository
+	clientRepo clientRepo.IClientRepository
+	seen       *ttlCache[string, struct{}]
+}
+
+func NewClientKeyUseCase(repo clientkey.IClientKeyRepository, clientRepo clientRepo.IClientRepository) *ClientKeyUseCase {
+	return &ClientKeyUseCase{repo: repo, clientRepo: clientRepo, seen: newTTLCache[string, struct{}]()}
+}
+
+// ListClientKeys returns the keys of a client of the user.
+func (u *ClientKeyUseCase) ListClientKeys(ctx context.Context, clientID uuid.UUID, userID uuid.UUID) ([]domain.ClientKey, error) {
+	if err := u.checkOwner(ctx, clientID, userID); err != nil {
+		return nil, err
+	}
+	return u.repo.ListClientKeys(ctx, clientID)
+}
+
+// AddClientKey registers a public key for a client of the user, see ValidateClientJWK.
+func (u *ClientKeyUseCase) AddClientKey(ctx context.Context, clientID uuid.UUID, userID uuid.UUID, jwk domain.JWK) (*domain.ClientKey, error) {
+	jwk, err := ValidateClientJWK(jwk)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.checkOwner(ctx, clientID, userID); err != nil {
+		return nil, err
+	}
+	return u.repo.CreateClientKey(ctx, &domain.ClientKey{ClientID: clientID, Kid: jwk.Kid, JWK: jwk})
+}
+
+func (u *ClientKeyUseCase) DeleteClientKey(ctx context.Context, clientID uuid.UUID, userID uuid.UUID, kid string) error {
+	if err := u.checkOwner(ctx, clientID, userID); err != nil {
+		return err
+	}
+	return u.repo.DeleteClientKey(ctx, clientID, kid)
+}
+
+// checkOwner hides the clients of other users behind ErrClientNotFound.
+func (u *ClientKeyUseCase) checkOwner(ctx context.Context, clientID uuid.UUID, userID uuid.UUID) error {
+	client, err := u.clientRepo.GetClientByID(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	if client.UserID != userID {
+		return fmt.Errorf("%w: '%s'", domain.ErrClientNotFound, clientID)
+	}
+	return nil
+}
+
+// ValidateClientJWK checks that jwk is a supported public signing key and returns it with its kid,
+// which defaults to the RFC 7638 thumbprint.
+func ValidateClientJWK(jwk domain.JWK) (domain.JWK, error) {
+	if _, err := jwk.PublicKey(); err != nil {
+		return jwk, err
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return jwk, fmt.Errorf("%w: use must be sig", domain.ErrInvalidJWK)
+	}
+	if jwk.Alg != "" && !slices.Contains(ClientAssertionAlgorithms, jwk.Alg) {
+		return jwk, fmt.Errorf("%w: alg %s", domain.ErrUnsupportedKey, jwk.Alg)
+	}
+	if jwk.Kid == "" {
+		thumbprint, err := jwk.Thumbprint()
+		if err != nil {
+			return jwk, err
+		}
+		jwk.Kid = thumbprint
+	}
+	return jwk, nil
+}
+
+// ClientAssertionLogin authenticates a private_key_jwt client by a JWT it signed with one of its keys.
+// iss and sub must be the client id, aud must include audience, the token endpoint, and the assertion
+// must be short-lived and carry a jti that was not seen before.
+func (u *ClientKeyUseCase) ClientAssertionLogin(ctx context.Context, assertion string, audience string) (*domain.Client, error) {
+	var client *domain.Client
+	var lookupErr error
+	claims := new(jwt.RegisteredClaims)
+	parser := jwt.NewParser(jwt.WithValidMethods(ClientAssertionAlgorithms))
+	_, err := parser.ParseWithClaims(assertion, claims, func(token *jwt.Token) (interface{}, error) {
+		// the claims are decoded but not trusted yet, they only tell whose keys to verify with
+		clientID, err := uuid.Parse(claims.Issuer)
+		if err != nil || claims.Subject != claims.Issuer {
+			return nil, errors.New("iss and sub must be the client id")
+		}
+		if client, err = u.clientRepo.GetClientByID(ctx, clientID); err != nil {
+			if !errors.Is(err, domain.ErrClientNotFound) {
+				lookupErr = err
+			}
+			return nil, err
+		}
+		if client.AuthMethod != domain.AuthMethodPrivateKeyJWT {
+			return nil, errors.New("client does not authenticate with private_key_jwt")
+		}
+		keys, err := u.repo.ListClientKeys(ctx, clientID)
+		if err != nil {
+			lookupErr = err
+			return nil, err
+		}
+		key, err := assertionKey(keys, token)
+		if err != nil {
+			return nil, err
+		}
+		return key.PublicKey()
+	})
+	if lookupErr != nil {
+		return nil, lookupErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidClientAssertion, err.Error())
+	}
+
+	switch {
+	case claims.ExpiresAt == nil || claims.ExpiresAt.After(time.Now().Add(maxAssertionLifetime)):
+		return nil, fmt.Errorf("%w: exp must be within %s", domain.ErrInvalidClientAssertion, maxAssertionLifetime)
+	case !claims.VerifyAudience(audience, true):
+		return nil, fmt.Errorf("%w: aud must include %s", domain.ErrInvalidClientAssertion, audience)
+	case claims.ID == "":
+		return nil, fmt.Errorf("%w: missing jti", domain.ErrInvalidClientAssertion)
+	}
+	if !u.seen.Add(client.ID.String()+"."+claims.ID, struct{}{}, claims.ExpiresAt.Time) {
+		return nil, fmt.Errorf("%w: jti was already used", domain.ErrInvalidClientAssertion)
+	}
+	return client, nil
+}
+
+// Run drops expired assertions from the replay cache every interval until ctx is done.
+func (u *ClientKeyUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			u.seen.Purge()
+		}
+	}
+}
+
+// assertionKey picks the key named by the kid header, which may be left out when the client has a single key.
+func assertionKey(keys []domain.ClientKey, token *jwt.Token) (*domain.JWK, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" && len(keys) == 1 {
+		kid = keys[0].Kid
+	}
+	for _, key := range keys {
+		if key.Kid != kid {
+			continue
+		}
+		if key.JWK.Alg != "" && key.JWK.Alg != token.Method.Alg() {
+			return nil, fmt.Errorf("key %s is registered for %s", kid, key.JWK.Alg)
+		}
+		return &key.JWK, nil
+	}
+	return nil, fmt.Errorf("no registered key with kid '%s'", kid)
+}
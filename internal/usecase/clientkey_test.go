@@ -0,0 +1,228 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	clientKeyRepo "github.com/bright-pentium/go-client-practice/internal/repository/clientkey"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testTokenEndpoint = "https://server.example.com/oauth/token"
+
+func TestAddClientKey(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	client := &domain.Client{ID: uuid.New(), UserID: userID}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwk, err := domain.NewJWK(&key.PublicKey)
+	require.NoError(t, err)
+
+	setup := func() (*usecase.ClientKeyUseCase, *clientKeyRepo.MockClientKeyRepository, *clientRepo.MockClientRepository) {
+		mockKeyRepo := new(clientKeyRepo.MockClientKeyRepository)
+		mockClientRepo := new(clientRepo.MockClientRepository)
+		mockClientRepo.On("GetClientByID", ctx, client.ID).Return(client, nil)
+		return usecase.NewClientKeyUseCase(mockKeyRepo, mockClientRepo), mockKeyRepo, mockClientRepo
+	}
+
+	t.Run("kid defaults to the thumbprint", func(t *testing.T) {
+		uc, mockKeyRepo, _ := setup()
+		thumbprint, err := jwk.Thumbprint()
+		require.NoError(t, err)
+		var captured *domain.ClientKey
+		mockKeyRepo.
+			On("CreateClientKey", ctx, mock.AnythingOfType("*domain.ClientKey")).
+			Run(func(args mock.Arguments) { captured = args.Get(1).(*domain.ClientKey) }).
+			Return(&domain.ClientKey{}, nil)
+
+		_, err = uc.AddClientKey(ctx, client.ID, userID, jwk)
+
+		require.NoError(t, err)
+		assert.Equal(t, client.ID, captured.ClientID)
+		assert.Equal(t, thumbprint, captured.Kid)
+		assert.Equal(t, thumbprint, captured.JWK.Kid)
+	})
+
+	t.Run("client of another user", func(t *testing.T) {
+		uc, mockKeyRepo, _ := setup()
+
+		_, err := uc.AddClientKey(ctx, client.ID, uuid.New(), jwk)
+
+		assert.ErrorIs(t, err, domain.ErrClientNotFound)
+		mockKeyRepo.AssertNotCalled(t, "CreateClientKey", mock.Anything, mock.Anything)
+	})
+
+	cases := []struct {
+		name string
+		jwk  domain.JWK
+	}{
+		{"symmetric key", domain.JWK{Kty: "oct"}},
+		{"malformed EC key", domain.JWK{Kty: "EC", Crv: "P-256", X: "AAAA", Y: "AAAA"}},
+		{"encryption key", domain.JWK{Kty: jwk.Kty, Crv: jwk.Crv, X: jwk.X, Y: jwk.Y, Use: "enc"}},
+		{"symmetric alg", domain.JWK{Kty: jwk.Kty, Crv: jwk.Crv, X: jwk.X, Y: jwk.Y, Alg: "HS256"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, mockKeyRepo, _ := setup()
+
+			_, err := uc.AddClientKey(ctx, client.ID, userID, tc.jwk)
+
+			assert.True(t, errors.Is(err, domain.ErrInvalidJWK) || errors.Is(err, domain.ErrUnsupportedKey), err)
+			mockKeyRepo.AssertNotCalled(t, "CreateClientKey", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestClientAssertionLogin(t *testing.T) {
+	ctx := context.Background()
+	client := &domain.Client{ID: uuid.New(), AuthMethod: domain.AuthMethodPrivateKeyJWT}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwk, err := domain.NewJWK(&key.PublicKey)
+	require.NoError(t, err)
+	jwk.Kid = "key-1"
+	keys := []domain.ClientKey{{ClientID: client.ID, Kid: jwk.Kid, JWK: jwk}}
+
+	setup := func() *usecase.ClientKeyUseCase {
+		mockKeyRepo := new(clientKeyRepo.MockClientKeyRepository)
+		mockClientRepo := new(clientRepo.MockClientRepository)
+		mockClientRepo.On("GetClientByID", ctx, client.ID).Return(client, nil)
+		mockKeyRepo.On("ListClientKeys", ctx, client.ID).Return(keys, nil)
+		return usecase.NewClientKeyUseCase(mockKeyRepo, mockClientRepo)
+	}
+	newClaims := func() jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Issuer:    client.ID.String(),
+			Subject:   client.ID.String(),
+			Audience:  jwt.ClaimStrings{testTokenEndpoint},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			ID:        uuid.NewString(),
+		}
+	}
+	sign := func(claims jwt.RegisteredClaims, kid string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	t.Run("valid assertion", func(t *testing.T) {
+		uc := setup()
+
+		authenticated, err := uc.ClientAssertionLogin(ctx, sign(newClaims(), "key-1"), testTokenEndpoint)
+
+		require.NoError(t, err)
+		assert.Equal(t, client, authenticated)
+	})
+
+	t.Run("kid may be left out with a single key", func(t *testing.T) {
+		uc := setup()
+
+		_, err := uc.ClientAssertionLogin(ctx, sign(newClaims(), ""), testTokenEndpoint)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("replayed assertion", func(t *testing.T) {
+		uc := setup()
+		assertion := sign(newClaims(), "key-1")
+
+		_, err := uc.ClientAssertionLogin(ctx, assertion, testTokenEndpoint)
+		require.NoError(t, err)
+		_, err = uc.ClientAssertionLogin(ctx, assertion, testTokenEndpoint)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidClientAssertion)
+	})
+
+	cases := []struct {
+		name   string
+		modify func(claims *jwt.RegisteredClaims)
+		kid    string
+	}{
+		{"other audience", func(claims *jwt.RegisteredClaims) { claims.Audience = jwt.ClaimStrings{"https://other.example.com"} }, "key-1"},
+		{"expired", func(claims *jwt.RegisteredClaims) {
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		}, "key-1"},
+		{"long-lived", func(claims *jwt.RegisteredClaims) { claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour)) }, "key-1"},
+		{"no exp", func(claims *jwt.RegisteredClaims) { claims.ExpiresAt = nil }, "key-1"},
+		{"no jti", func(claims *jwt.RegisteredClaims) { claims.ID = "" }, "key-1"},
+		{"sub is not iss", func(claims *jwt.RegisteredClaims) { claims.Subject = uuid.NewString() }, "key-1"},
+		{"unknown kid", func(*jwt.RegisteredClaims) {}, "key-2"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc := setup()
+			claims := newClaims()
+			tc.modify(&claims)
+
+			authenticated, err := uc.ClientAssertionLogin(ctx, sign(claims, tc.kid), testTokenEndpoint)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidClientAssertion)
+			assert.Nil(t, authenticated)
+		})
+	}
+
+	t.Run("signed by another key", func(t *testing.T) {
+		uc := setup()
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, newClaims())
+		signed, err := token.SignedString(other)
+		require.NoError(t, err)
+
+		_, err = uc.ClientAssertionLogin(ctx, signed, testTokenEndpoint)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidClientAssertion)
+	})
+
+	t.Run("symmetric algorithms are refused", func(t *testing.T) {
+		uc := setup()
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims()).SignedString([]byte("secret"))
+		require.NoError(t, err)
+
+		_, err = uc.ClientAssertionLogin(ctx, signed, testTokenEndpoint)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidClientAssertion)
+	})
+
+	t.Run("client without private_key_jwt", func(t *testing.T) {
+		mockKeyRepo := new(clientKeyRepo.MockClientKeyRepository)
+		mockClientRepo := new(clientRepo.MockClientRepository)
+		mockClientRepo.On("GetClientByID", ctx, client.ID).Return(&domain.Client{ID: client.ID, AuthMethod: domain.AuthMethodClientSecretBasic}, nil)
+		uc := usecase.NewClientKeyUseCase(mockKeyRepo, mockClientRepo)
+
+		_, err := uc.ClientAssertionLogin(ctx, sign(newClaims(), "key-1"), testTokenEndpoint)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidClientAssertion)
+		mockKeyRepo.AssertNotCalled(t, "ListClientKeys", mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository error is not an invalid assertion", func(t *testing.T) {
+		mockKeyRepo := new(clientKeyRepo.MockClientKeyRepository)
+		mockClientRepo := new(clientRepo.MockClientRepository)
+		mockClientRepo.On("GetClientByID", ctx, client.ID).Return(client, nil)
+		mockKeyRepo.On("ListClientKeys", ctx, client.ID).Return(nil, domain.ErrGeneralClientKey)
+		uc := usecase.NewClientKeyUseCase(mockKeyRepo, mockClientRepo)
+
+		_, err := uc.ClientAssertionLogin(ctx, sign(newClaims(), "key-1"), testTokenEndpoint)
+
+		assert.ErrorIs(t, err, domain.ErrGeneralClientKey)
+		assert.NotErrorIs(t, err, domain.ErrInvalidClientAssertion)
+	})
+}
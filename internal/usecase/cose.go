@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// COSE algorithms of WebAuthn credentials (RFC 9053), in order of preference.
+const (
+	coseES256 = -7
+	coseEdDSA = -8
+	coseRS256 = -257
+)
+
+var coseAlgorithms = []int{coseES256, coseEdDSA, coseRS256}
+
+var errCBOR = errors.New("malformed cbor")
+
+// cborMaxDepth bounds the nesting of decoded items, attestation objects go three levels deep.
+const cborMaxDepth = 8
+
+// decodeCBOR decodes the first item of data (RFC 8949) and returns the bytes after it. Only the
+// deterministic subset authenticators produce is supported (CTAP2 canonical CBOR): integers decode to
+// int64, byte strings to []byte, text to string, arrays to []interface{} and maps to
+// map[interface{}]interface{} keyed by int64 or string. Tags are dropped, floats and indefinite
+// lengths are refused.
+func decodeCBOR(data []byte) (interface{}, []byte, error) {
+	return decodeCBORItem(data, 0)
+}
+
+func decodeCBORItem(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > cborMaxDepth {
+		return nil, nil, fmt.Errorf("%w: nested too deep", errCBOR)
+	}
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("%w: unexpected end", errCBOR)
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22, 23:
+			return nil, data, nil
+		default:
+			return nil, nil, fmt.Errorf("%w: unsupported simple value or float", errCBOR)
+		}
+	}
+
+	var arg uint64
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(data) < size {
+			return nil, nil, fmt.Errorf("%w: unexpected end", errCBOR)
+		}
+		for _, b := range data[:size] {
+			arg = arg<<8 | uint64(b)
+		}
+		data = data[size:]
+	default:
+		return nil, nil, fmt.Errorf("%w: indefinite or reserved length", errCBOR)
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, nil, fmt.Errorf("%w: integer overflow", errCBOR)
+		}
+		return int64(arg), data, nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, nil, fmt.Errorf("%w: integer overflow", errCBOR)
+		}
+		return -1 - int64(arg), data, nil
+	case 2, 3:
+		if arg > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("%w: unexpected end", errCBOR)
+		}
+		if major == 2 {
+			return append([]byte(nil), data[:arg]...), data[arg:], nil
+		}
+		return string(data[:arg]), data[arg:], nil
+	case 4:
+		// every item takes at least a byte, a longer array cannot fit
+		if arg > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("%w: unexpected end", errCBOR)
+		}
+		items := make([]interface{}, arg)
+		for i := range items {
+			var err error
+			if items[i], data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+		}
+		return items, data, nil
+	case 5:
+		if arg > uint64(len(data))/2 {
+			return nil, nil, fmt.Errorf("%w: unexpected end", errCBOR)
+		}
+		items := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, rest, err := decodeCBORItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("%w: map key must be an integer or text", errCBOR)
+			}
+			if _, duplicate := items[key]; duplicate {
+				return nil, nil, fmt.Errorf("%w: duplicate map key", errCBOR)
+			}
+			if items[key], data, err = decodeCBORItem(rest, depth+1); err != nil {
+				return nil, nil, err
+			}
+		}
+		return items, data, nil
+	default:
+		// tag, its content is what matters
+		return decodeCBORItem(data, depth+1)
+	}
+}
+
+// Flags of the authenticator data (WebAuthn Level 2 section 6.1).
+const (
+	authDataUserPresent      = 0x01
+	authDataUserVerified     = 0x04
+	authDataAttestedCredData = 0x40
+	authDataExtensions       = 0x80
+)
+
+// authenticatorData is the data an authenticator signs, with the credential it attests at registration.
+type authenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+	// CredentialID and PublicKey, the raw COSE key, are only set at registration
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("authenticator data is too short")
+	}
+	parsed := &authenticatorData{RPIDHash: data[:32], Flags: data[32], SignCount: binary.BigEndian.Uint32(data[33:37])}
+	rest := data[37:]
+
+	if parsed.Flags&authDataAttestedCredData != 0 {
+		// aaguid, then the length of the credential ID
+		if len(rest) < 18 {
+			return nil, errors.New("attested credential data is too short")
+		}
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength > len(rest) || idLength > 1023 {
+			return nil, errors.New("credential id does not fit")
+		}
+		parsed.CredentialID, rest = rest[:idLength], rest[idLength:]
+
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("credential public key: %w", err)
+		}
+		parsed.PublicKey, rest = rest[:len(rest)-len(after)], after
+	}
+	if parsed.Flags&authDataExtensions != 0 {
+		var err error
+		if _, rest, err = decodeCBOR(rest); err != nil {
+			return nil, fmt.Errorf("extensions: %w", err)
+		}
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("trailing bytes after authenticator data")
+	}
+	return parsed, nil
+}
+
+// parseCOSEKey returns the public key of a COSE_Key (RFC 9052 section 7) and its algorithm, which must be
+// one of coseAlgorithms.
+func parseCOSEKey(raw []byte) (crypto.PublicKey, int, error) {
+	decoded, rest, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+	key, ok := decoded.(map[interface{}]interface{})
+	if !ok || len(rest) != 0 {
+		return nil, 0, errors.New("cose key must be a single map")
+	}
+	kty, _ := key[int64(1)].(int64)
+	alg, _ := key[int64(3)].(int64)
+
+	switch {
+	case kty == 2 && alg == coseES256:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		y, _ := key[int64(-3)].([]byte)
+		if crv != 1 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, errors.New("es256 key must be a P-256 point")
+		}
+		point := append(append([]byte{4}, x...), y...)
+		// ecdh rejects points off the curve, which ecdsa.PublicKey would take as is
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, 0, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, coseES256, nil
+	case kty == 1 && alg == coseEdDSA:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		if crv != 6 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, errors.New("eddsa key must be an Ed25519 key")
+		}
+		return ed25519.PublicKey(x), coseEdDSA, nil
+	case kty == 3 && alg == coseRS256:
+		n, _ := key[int64(-1)].([]byte)
+		e, _ := key[int64(-2)].([]byte)
+		modulus := new(big.Int).SetBytes(n)
+		exponent := new(big.Int).SetBytes(e)
+		if modulus.BitLen() < 2048 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > math.MaxInt32 || exponent.Bit(0) == 0 {
+			return nil, 0, errors.New("rs256 key must have a modulus of at least 2048 bits and an odd exponent")
+		}
+		return &rsa.PublicKey{N: modulus, E: int(exponent.Int64())}, coseRS256, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported cose key type %d with algorithm %d", kty, alg)
+	}
+}
+
+// verifyCOSESignature checks the signature of msg by the COSE key, ES256 signatures are ASN.1 DER encoded.
+func verifyCOSESignature(raw []byte, msg []byte, sig []byte) error {
+	key, _, err := parseCOSEKey(raw)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(msg)
+	switch pub := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+			return errors.New("es256 signature does not verify")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, msg, sig) {
+			return errors.New("eddsa signature does not verify")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
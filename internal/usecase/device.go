@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/repository/device"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/google/uuid"
+)
+
+// userCodeAlphabet has no vowels, so no words are spelled, and no look-alike characters (RFC 8628 section 6.1).
+const (
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+)
+
+// slowDownStep is how much the interval grows on every slow_down (RFC 8628 section 3.5).
+const slowDownStep = 5
+
+// DeviceAuthorizationUseCase implements the device authorization grant (RFC 8628).
+type DeviceAuthorizationUseCase struct {
+	repo     device.IDeviceAuthorizationRepository
+	userRepo userRepo.IUserRepository
+	ttl      time.Duration
+	interval int
+}
+
+func NewDeviceAuthorizationUseCase(repo device.IDeviceAuthorizationRepository, userRepo userRepo.IUserRepository, ttl time.Duration, interval int) *DeviceAuthorizationUseCase {
+	return &DeviceAuthorizationUseCase{repo: repo, userRepo: userRepo, ttl: ttl, interval: interval}
+}
+
+// RequestDeviceAuthorization starts a device authorization for the client and returns it with the device code,
+// only the hash of which is stored. The requested scope is narrowed to what the client may hold.
+func (u *DeviceAuthorizationUseCase) RequestDeviceAuthorization(ctx context.Context, client *domain.Client, requested []domain.Permission) (*domain.DeviceAuthorization, string, error) {
+	scope, err := NarrowScope(client.Scope, requested)
+	if err != nil {
+		return nil, "", err
+	}
+
+	deviceCode, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	userCode, err := newUserCode()
+	if err != nil {
+		return nil, "", err
+	}
+
+	created, err := u.repo.CreateDeviceAuthorization(ctx, &domain.DeviceAuthorization{
+		DeviceCodeHash: hashToken(deviceCode),
+		UserCode:       userCode,
+		ClientID:       client.ID,
+		Scope:          scope,
+		Status:         domain.DevicePending,
+		Interval:       u.interval,
+		ExpiresAt:      time.Now().Add(u.ttl),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return created, deviceCode, nil
+}
+
+// GetPendingDeviceAuthorization returns the request the user is asked to decide on.
+func (u *DeviceAuthorizationUseCase) GetPendingDeviceAuthorization(ctx context.Context, userCode string) (*domain.DeviceAuthorization, error) {
+	authorization, err := u.repo.GetDeviceAuthorizationByUserCode(ctx, NormalizeUserCode(userCode))
+	if err != nil {
+		if errors.Is(err, domain.ErrDeviceAuthorizationNotFound) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrUserCodeInvalid, err.Error())
+		}
+		return nil, err
+	}
+	if authorization.Status != domain.DevicePending || !time.Now().Before(authorization.ExpiresAt) {
+		return nil, fmt.Errorf("%w: no longer pending", domain.ErrUserCodeInvalid)
+	}
+	return authorization, nil
+}
+
+// ApproveDeviceAuthorization records the approval of the user, who logged in at authTime. approved narrows
+// the requested scope, an empty approval grants everything that was requested.
+func (u *DeviceAuthorizationUseCase) ApproveDeviceAuthorization(ctx context.Context, userCode string, userID uuid.UUID, authTime time.Time, approved []domain.Permission) error {
+	authorization, err := u.GetPendingDeviceAuthorization(ctx, userCode)
+	if err != nil {
+		return err
+	}
+	scope, err := NarrowScope(authorization.Scope, approved)
+	if err != nil {
+		return err
+	}
+
+	authorization.Status = domain.DeviceApproved
+	authorization.UserID = &userID
+	authorization.Scope = scope
+	authorization.AuthTime = &authTime
+	return u.decide(ctx, authorization)
+}
+
+func (u *DeviceAuthorizationUseCase) DenyDeviceAuthorization(ctx context.Context, userCode string, userID uuid.UUID) error {
+	authorization, err := u.GetPendingDeviceAuthorization(ctx, userCode)
+	if err != nil {
+		return err
+	}
+
+	authorization.Status = domain.DeviceDenied
+	authorization.UserID = &userID
+	return u.decide(ctx, authorization)
+}
+
+func (u *DeviceAuthorizationUseCase) decide(ctx context.Context, authorization *domain.DeviceAuthorization) error {
+	if _, err := u.repo.DecideDeviceAuthorization(ctx, authorization); err != nil {
+		if errors.Is(err, domain.ErrDeviceAuthorizationNotFound) {
+			return fmt.Errorf("%w: %s", domain.ErrUserCodeInvalid, err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// PollDeviceAuthorization answers a token request of the device. Until the user decides it returns
+// ErrAuthorizationPending, or ErrSlowDown with a longer interval when polled too often. Once approved,
+// the request is consumed and the user is returned together with it.
+func (u *DeviceAuthorizationUseCase) PollDeviceAuthorization(ctx context.Context, clientID uuid.UUID, deviceCode string) (*domain.User, *domain.DeviceAuthorization, error) {
+	deviceCodeHash := hashToken(deviceCode)
+	authorization, err := u.repo.GetDeviceAuthorizationByDeviceCode(ctx, deviceCodeHash)
+	if err != nil {
+		if errors.Is(err, domain.ErrDeviceAuthorizationNotFound) {
+			return nil, nil, fmt.Errorf("%w: %s", domain.ErrDeviceCodeInvalid, err.Error())
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	switch {
+	case authorization.ClientID != clientID:
+		return nil, nil, fmt.Errorf("%w: issued to another client", domain.ErrDeviceCodeInvalid)
+	case authorization.Status == domain.DeviceConsumed:
+		return nil, nil, fmt.Errorf("%w: already redeemed", domain.ErrDeviceCodeInvalid)
+	case !now.Before(authorization.ExpiresAt):
+		return nil, nil, domain.ErrDeviceCodeExpired
+	}
+
+	interval := authorization.Interval
+	tooFast := authorization.LastPolledAt != nil && now.Sub(*authorization.LastPolledAt) < time.Duration(interval)*time.Second
+	if tooFast {
+		interval += slowDownStep
+	}
+	if err := u.repo.RecordDevicePoll(ctx, deviceCodeHash, interval); err != nil {
+		return nil, nil, err
+	}
+	if tooFast {
+		return nil, nil, fmt.Errorf("%w: interval is now %d seconds", domain.ErrSlowDown, interval)
+	}
+
+	switch authorization.Status {
+	case domain.DevicePending:
+		return nil, nil, domain.ErrAuthorizationPending
+	case domain.DeviceDenied:
+		return nil, nil, domain.ErrDeviceAccessDenied
+	}
+
+	consumed, err := u.repo.ConsumeDeviceAuthorization(ctx, deviceCodeHash)
+	if err != nil {
+		if errors.Is(err, domain.ErrDeviceAuthorizationNotFound) {
+			return nil, nil, fmt.Errorf("%w: already redeemed", domain.ErrDeviceCodeInvalid)
+		}
+		return nil, nil, err
+	}
+	user, err := u.userRepo.GetUserByID(ctx, *consumed.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, nil, fmt.Errorf("%w: %s", domain.ErrDeviceCodeInvalid, err.Error())
+		}
+		return nil, nil, err
+	}
+	return user, consumed, nil
+}
+
+// Run deletes expired device authorizations every interval until ctx is done.
+func (u *DeviceAuthorizationUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := u.repo.DeleteExpiredDeviceAuthorizations(ctx); err != nil {
+				log.Printf("device authorization purge failed: %v", err)
+			}
+		}
+	}
+}
+
+// NormalizeUserCode drops separators and case, so the user may type the code however it was shown.
+func NormalizeUserCode(userCode string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(userCode))
+}
+
+// FormatUserCode splits a user code in two halves for display, e.g. WDJB-MJHT.
+func FormatUserCode(userCode string) string {
+	half := len(userCode) / 2
+	return userCode[:half] + "-" + userCode[half:]
+}
+
+func newUserCode() (string, error) {
+	code := make([]byte, 0, userCodeLength)
+	buf := make([]byte, 16)
+	for len(code) < userCodeLength {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			// rejection sampling keeps every character equally likely
+			if int(b) < 256-256%len(userCodeAlphabet) && len(code) < userCodeLength {
+				code = append(code, userCodeAlphabet[int(b)%len(userCodeAlphabet)])
+			}
+		}
+	}
+	return string(code), nil
+}
@@ -0,0 +1,263 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	deviceRepo "github.com/bright-pentium/go-client-practice/internal/repository/device"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestDeviceAuthorization(t *testing.T) {
+	ctx := context.Background()
+	client := &domain.Client{ID: uuid.New(), Scope: []domain.Permission{domain.PermCreateResource, domain.PermOpenID}}
+
+	t.Run("stores the hash of the device code", func(t *testing.T) {
+		mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+		uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, 10*time.Minute, 5)
+
+		var captured *domain.DeviceAuthorization
+		mockRepo.On("CreateDeviceAuthorization", ctx, mock.AnythingOfType("*domain.DeviceAuthorization")).
+			Run(func(args mock.Arguments) {
+				captured = args.Get(1).(*domain.DeviceAuthorization)
+			}).
+			Return(&domain.DeviceAuthorization{}, nil)
+
+		_, deviceCode, err := uc.RequestDeviceAuthorization(ctx, client, []domain.Permission{domain.PermCreateResource})
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, deviceCode)
+		assert.Equal(t, sha256Of(deviceCode), captured.DeviceCodeHash)
+		assert.Regexp(t, "^[BCDFGHJKLMNPQRSTVWXZ]{8}$", captured.UserCode)
+		assert.Equal(t, client.ID, captured.ClientID)
+		assert.Equal(t, []domain.Permission{domain.PermCreateResource}, captured.Scope)
+		assert.Equal(t, domain.DevicePending, captured.Status)
+		assert.Equal(t, 5, captured.Interval)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), captured.ExpiresAt, time.Second)
+	})
+
+	t.Run("scope beyond the client", func(t *testing.T) {
+		mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+		uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, 10*time.Minute, 5)
+
+		_, _, err := uc.RequestDeviceAuthorization(ctx, client, []domain.Permission{domain.PermAll})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidScope)
+		mockRepo.AssertNotCalled(t, "CreateDeviceAuthorization", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDecideDeviceAuthorization(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	pending := func() *domain.DeviceAuthorization {
+		return &domain.DeviceAuthorization{
+			UserCode:  "WDJBMJHT",
+			ClientID:  uuid.New(),
+			Scope:     []domain.Permission{domain.PermCreateResource, domain.PermOpenID},
+			Status:    domain.DevicePending,
+			ExpiresAt: time.Now().Add(time.Minute),
+		}
+	}
+
+	t.Run("approval narrows the requested scope", func(t *testing.T) {
+		mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+		uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, time.Minute, 5)
+		authTime := time.Now().Add(-time.Hour)
+
+		var captured *domain.DeviceAuthorization
+		mockRepo.On("GetDeviceAuthorizationByUserCode", ctx, "WDJBMJHT").Return(pending(), nil)
+		mockRepo.On("DecideDeviceAuthorization", ctx, mock.AnythingOfType("*domain.DeviceAuthorization")).
+			Run(func(args mock.Arguments) {
+				captured = args.Get(1).(*domain.DeviceAuthorization)
+			}).
+			Return(&domain.DeviceAuthorization{}, nil)
+
+		err := uc.ApproveDeviceAuthorization(ctx, "wdjb-mjht", userID, authTime, []domain.Permission{domain.PermOpenID})
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.DeviceApproved, captured.Status)
+		assert.Equal(t, &userID, captured.UserID)
+		assert.Equal(t, []domain.Permission{domain.PermOpenID}, captured.Scope)
+		assert.Equal(t, &authTime, captured.AuthTime)
+	})
+
+	t.Run("approval cannot exceed the request", func(t *testing.T) {
+		mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+		uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, time.Minute, 5)
+		mockRepo.On("GetDeviceAuthorizationByUserCode", ctx, "WDJBMJHT").Return(pending(), nil)
+
+		err := uc.ApproveDeviceAuthorization(ctx, "WDJB-MJHT", userID, time.Now(), []domain.Permission{domain.PermIntrospect})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidScope)
+		mockRepo.AssertNotCalled(t, "DecideDeviceAuthorization", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+		uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, time.Minute, 5)
+
+		mockRepo.On("GetDeviceAuthorizationByUserCode", ctx, "WDJBMJHT").Return(pending(), nil)
+		mockRepo.On("DecideDeviceAuthorization", ctx, mock.MatchedBy(func(a *domain.DeviceAuthorization) bool {
+			return a.Status == domain.DeviceDenied
+		})).Return(&domain.DeviceAuthorization{}, nil)
+
+		require.NoError(t, uc.DenyDeviceAuthorization(ctx, "WDJB-MJHT", userID))
+		mockRepo.AssertExpectations(t)
+	})
+
+	cases := []struct {
+		name   string
+		modify func(a *domain.DeviceAuthorization)
+	}{
+		{"expired", func(a *domain.DeviceAuthorization) { a.ExpiresAt = time.Now().Add(-time.Second) }},
+		{"already decided", func(a *domain.DeviceAuthorization) { a.Status = domain.DeviceDenied }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+			uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, time.Minute, 5)
+			authorization := pending()
+			tc.modify(authorization)
+			mockRepo.On("GetDeviceAuthorizationByUserCode", ctx, "WDJBMJHT").Return(authorization, nil)
+
+			_, err := uc.GetPendingDeviceAuthorization(ctx, "WDJB-MJHT")
+
+			assert.ErrorIs(t, err, domain.ErrUserCodeInvalid)
+		})
+	}
+
+	t.Run("unknown user code", func(t *testing.T) {
+		mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+		uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, time.Minute, 5)
+		mockRepo.On("GetDeviceAuthorizationByUserCode", ctx, "BBBBBBBB").Return(nil, domain.ErrDeviceAuthorizationNotFound)
+
+		_, err := uc.GetPendingDeviceAuthorization(ctx, "bbbb-bbbb")
+
+		assert.ErrorIs(t, err, domain.ErrUserCodeInvalid)
+	})
+}
+
+func TestPollDeviceAuthorization(t *testing.T) {
+	ctx := context.Background()
+	deviceCode := "device-code"
+	clientID := uuid.New()
+	user := &domain.User{ID: uuid.New(), Name: "Alice"}
+
+	stored := func(status domain.DeviceAuthorizationStatus) *domain.DeviceAuthorization {
+		return &domain.DeviceAuthorization{
+			DeviceCodeHash: sha256Of(deviceCode),
+			ClientID:       clientID,
+			Scope:          []domain.Permission{domain.PermCreateResource},
+			Status:         status,
+			UserID:         &user.ID,
+			Interval:       5,
+			ExpiresAt:      time.Now().Add(time.Minute),
+		}
+	}
+
+	t.Run("approved request is consumed", func(t *testing.T) {
+		mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+		mockUserRepo := new(userRepo.MockUserRepository)
+		uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, mockUserRepo, time.Minute, 5)
+
+		approved := stored(domain.DeviceApproved)
+		mockRepo.On("GetDeviceAuthorizationByDeviceCode", ctx, sha256Of(deviceCode)).Return(approved, nil)
+		mockRepo.On("RecordDevicePoll", ctx, sha256Of(deviceCode), 5).Return(nil)
+		mockRepo.On("ConsumeDeviceAuthorization", ctx, sha256Of(deviceCode)).Return(approved, nil)
+		mockUserRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+
+		gotUser, gotGrant, err := uc.PollDeviceAuthorization(ctx, clientID, deviceCode)
+
+		require.NoError(t, err)
+		assert.Equal(t, user, gotUser)
+		assert.Equal(t, approved, gotGrant)
+	})
+
+	cases := []struct {
+		name   string
+		status domain.DeviceAuthorizationStatus
+		err    error
+	}{
+		{"pending", domain.DevicePending, domain.ErrAuthorizationPending},
+		{"denied", domain.DeviceDenied, domain.ErrDeviceAccessDenied},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+			uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, time.Minute, 5)
+			mockRepo.On("GetDeviceAuthorizationByDeviceCode", ctx, sha256Of(deviceCode)).Return(stored(tc.status), nil)
+			mockRepo.On("RecordDevicePoll", ctx, sha256Of(deviceCode), 5).Return(nil)
+
+			_, _, err := uc.PollDeviceAuthorization(ctx, clientID, deviceCode)
+
+			assert.ErrorIs(t, err, tc.err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("polling too fast slows the device down", func(t *testing.T) {
+		mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+		uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, time.Minute, 5)
+		authorization := stored(domain.DevicePending)
+		lastPolled := time.Now().Add(-2 * time.Second)
+		authorization.LastPolledAt = &lastPolled
+
+		mockRepo.On("GetDeviceAuthorizationByDeviceCode", ctx, sha256Of(deviceCode)).Return(authorization, nil)
+		mockRepo.On("RecordDevicePoll", ctx, sha256Of(deviceCode), 10).Return(nil)
+
+		_, _, err := uc.PollDeviceAuthorization(ctx, clientID, deviceCode)
+
+		assert.ErrorIs(t, err, domain.ErrSlowDown)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+		uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, time.Minute, 5)
+		authorization := stored(domain.DevicePending)
+		authorization.ExpiresAt = time.Now().Add(-time.Second)
+		mockRepo.On("GetDeviceAuthorizationByDeviceCode", ctx, sha256Of(deviceCode)).Return(authorization, nil)
+
+		_, _, err := uc.PollDeviceAuthorization(ctx, clientID, deviceCode)
+
+		assert.ErrorIs(t, err, domain.ErrDeviceCodeExpired)
+		mockRepo.AssertNotCalled(t, "RecordDevicePoll", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("other client", func(t *testing.T) {
+		mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+		uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, time.Minute, 5)
+		mockRepo.On("GetDeviceAuthorizationByDeviceCode", ctx, sha256Of(deviceCode)).Return(stored(domain.DeviceApproved), nil)
+
+		_, _, err := uc.PollDeviceAuthorization(ctx, uuid.New(), deviceCode)
+
+		assert.ErrorIs(t, err, domain.ErrDeviceCodeInvalid)
+	})
+
+	t.Run("concurrent redemption", func(t *testing.T) {
+		mockRepo := new(deviceRepo.MockDeviceAuthorizationRepository)
+		uc := usecase.NewDeviceAuthorizationUseCase(mockRepo, nil, time.Minute, 5)
+		mockRepo.On("GetDeviceAuthorizationByDeviceCode", ctx, sha256Of(deviceCode)).Return(stored(domain.DeviceApproved), nil)
+		mockRepo.On("RecordDevicePoll", ctx, sha256Of(deviceCode), 5).Return(nil)
+		mockRepo.On("ConsumeDeviceAuthorization", ctx, sha256Of(deviceCode)).Return(nil, domain.ErrDeviceAuthorizationNotFound)
+
+		_, _, err := uc.PollDeviceAuthorization(ctx, clientID, deviceCode)
+
+		assert.ErrorIs(t, err, domain.ErrDeviceCodeInvalid)
+	})
+}
+
+func TestUserCodeFormatting(t *testing.T) {
+	assert.Equal(t, "WDJB-MJHT", usecase.FormatUserCode("WDJBMJHT"))
+	assert.Equal(t, "WDJBMJHT", usecase.NormalizeUserCode(" wdjb-mjht"))
+	assert.Equal(t, "WDJBMJHT", usecase.NormalizeUserCode(strings.ToLower(usecase.FormatUserCode("WDJBMJHT"))))
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// DPoPAlgorithms are the proof signing algorithms accepted, asymmetric only as RFC 9449 requires.
+var DPoPAlgorithms = []string{"ES256", "RS256", "PS256", "EdDSA"}
+
+// DPoPUseCase verifies DPoP proofs (RFC 9449). Seen proofs are remembered until they go stale,
+// the replay cache is per process like the revocation cache.
+type DPoPUseCase struct {
+	seen   *ttlCache[string, struct{}]
+	window time.Duration
+}
+
+// NewDPoPUseCase accepts proofs issued up to window before or after now.
+func NewDPoPUseCase(window time.Duration) *DPoPUseCase {
+	return &DPoPUseCase{seen: newTTLCache[string, struct{}](), window: window}
+}
+
+// VerifyProof checks a DPoP proof for a request of method to uri and returns the thumbprint of its key.
+// accessToken is the token presented with the proof on protected resources, empty at the token endpoint.
+func (u *DPoPUseCase) VerifyProof(proof string, method string, uri string, accessToken string) (string, error) {
+	var jkt string
+	claims := new(domain.DPoPProof)
+	parser := jwt.NewParser(jwt.WithValidMethods(DPoPAlgorithms))
+	_, err := parser.ParseWithClaims(proof, claims, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != domain.DPoPProofType {
+			return nil, fmt.Errorf("typ must be %s", domain.DPoPProofType)
+		}
+		jwk, err := proofKey(token.Header["jwk"])
+		if err != nil {
+			return nil, err
+		}
+		if jkt, err = jwk.Thumbprint(); err != nil {
+			return nil, err
+		}
+		return jwk.PublicKey()
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", domain.ErrInvalidDPoPProof, err.Error())
+	}
+
+	now := time.Now()
+	switch {
+	case claims.ID == "":
+		return "", fmt.Errorf("%w: missing jti", domain.ErrInvalidDPoPProof)
+	case claims.HTTPMethod != method:
+		return "", fmt.Errorf("%w: htm does not match the request", domain.ErrInvalidDPoPProof)
+	case !sameHTU(claims.HTTPURI, uri):
+		return "", fmt.Errorf("%w: htu does not match the request", domain.ErrInvalidDPoPProof)
+	case claims.IssuedAt == nil || claims.IssuedAt.Before(now.Add(-u.window)) || claims.IssuedAt.After(now.Add(u.window)):
+		return "", fmt.Errorf("%w: iat is not recent", domain.ErrInvalidDPoPProof)
+	}
+	if accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		if claims.AccessTokenHash != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			return "", fmt.Errorf("%w: ath does not match the access token", domain.ErrInvalidDPoPProof)
+		}
+	}
+
+	// a proof older than the window is rejected above, so it only has to be remembered that long
+	if !u.seen.Add(jkt+"."+claims.ID, struct{}{}, claims.IssuedAt.Add(u.window)) {
+		return "", domain.ErrDPoPProofReplayed
+	}
+	return jkt, nil
+}
+
+// Run drops stale proofs from the replay cache every interval until ctx is done.
+func (u *DPoPUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			u.seen.Purge()
+		}
+	}
+}
+
+// proofKey reads the jwk header, which must be a public key.
+func proofKey(header interface{}) (*domain.JWK, error) {
+	if header == nil {
+		return nil, fmt.Errorf("missing jwk header")
+	}
+	raw, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	var members map[string]interface{}
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, fmt.Errorf("jwk header is not an object")
+	}
+	if _, private := members["d"]; private {
+		return nil, fmt.Errorf("jwk header carries a private key")
+	}
+	jwk := new(domain.JWK)
+	if err := json.Unmarshal(raw, jwk); err != nil {
+		return nil, err
+	}
+	return jwk, nil
+}
+
+// sameHTU compares URIs without query and fragment (RFC 9449 section 4.3).
+func sameHTU(htu string, uri string) bool {
+	a, errA := url.Parse(htu)
+	b, errB := url.Parse(uri)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host) && a.EscapedPath() == b.EscapedPath()
+}
@@ -0,0 +1,140 @@
+package usecase_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHTU = "https://server.example.com/resource"
+
+// signProof signs a DPoP proof with the key and puts the public part in the jwk header.
+func signProof(t *testing.T, key *ecdsa.PrivateKey, proof domain.DPoPProof, header map[string]interface{}) string {
+	jwk, err := domain.NewJWK(&key.PublicKey)
+	require.NoError(t, err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, &proof)
+	token.Header["typ"] = domain.DPoPProofType
+	token.Header["jwk"] = jwk
+	for name, value := range header {
+		token.Header[name] = value
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestVerifyDPoPProof(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwk, err := domain.NewJWK(&key.PublicKey)
+	require.NoError(t, err)
+	thumbprint, err := jwk.Thumbprint()
+	require.NoError(t, err)
+
+	accessToken := "access-token"
+	newProof := func() domain.DPoPProof {
+		return domain.DPoPProof{
+			ID:              uuid.NewString(),
+			HTTPMethod:      "GET",
+			HTTPURI:         testHTU,
+			IssuedAt:        jwt.NewNumericDate(time.Now()),
+			AccessTokenHash: base64.RawURLEncoding.EncodeToString(sha256Of(accessToken)),
+		}
+	}
+
+	t.Run("valid proof returns the key thumbprint", func(t *testing.T) {
+		uc := usecase.NewDPoPUseCase(time.Minute)
+
+		jkt, err := uc.VerifyProof(signProof(t, key, newProof(), nil), "GET", testHTU+"?page=2", accessToken)
+
+		require.NoError(t, err)
+		assert.Equal(t, thumbprint, jkt)
+	})
+
+	t.Run("token endpoint proofs carry no ath", func(t *testing.T) {
+		uc := usecase.NewDPoPUseCase(time.Minute)
+		proof := newProof()
+		proof.AccessTokenHash = ""
+
+		_, err := uc.VerifyProof(signProof(t, key, proof, nil), "GET", testHTU, "")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("replayed proof", func(t *testing.T) {
+		uc := usecase.NewDPoPUseCase(time.Minute)
+		proof := signProof(t, key, newProof(), nil)
+
+		_, err := uc.VerifyProof(proof, "GET", testHTU, accessToken)
+		require.NoError(t, err)
+		_, err = uc.VerifyProof(proof, "GET", testHTU, accessToken)
+
+		assert.ErrorIs(t, err, domain.ErrDPoPProofReplayed)
+	})
+
+	cases := []struct {
+		name   string
+		modify func(proof *domain.DPoPProof)
+		header map[string]interface{}
+	}{
+		{"other method", func(proof *domain.DPoPProof) { proof.HTTPMethod = "POST" }, nil},
+		{"other uri", func(proof *domain.DPoPProof) { proof.HTTPURI = "https://server.example.com/other" }, nil},
+		{"stale", func(proof *domain.DPoPProof) { proof.IssuedAt = jwt.NewNumericDate(time.Now().Add(-2 * time.Minute)) }, nil},
+		{"issued in the future", func(proof *domain.DPoPProof) { proof.IssuedAt = jwt.NewNumericDate(time.Now().Add(2 * time.Minute)) }, nil},
+		{"missing jti", func(proof *domain.DPoPProof) { proof.ID = "" }, nil},
+		{"ath of another token", func(proof *domain.DPoPProof) {
+			proof.AccessTokenHash = base64.RawURLEncoding.EncodeToString(sha256Of("other-token"))
+		}, nil},
+		{"wrong typ", func(*domain.DPoPProof) {}, map[string]interface{}{"typ": "JWT"}},
+		{"missing jwk", func(*domain.DPoPProof) {}, map[string]interface{}{"jwk": nil}},
+		{"private key in jwk", func(*domain.DPoPProof) {}, map[string]interface{}{
+			"jwk": map[string]string{"kty": "EC", "crv": "P-256", "x": jwk.X, "y": jwk.Y, "d": "c2VjcmV0"},
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc := usecase.NewDPoPUseCase(time.Minute)
+			proof := newProof()
+			tc.modify(&proof)
+
+			_, err := uc.VerifyProof(signProof(t, key, proof, tc.header), "GET", testHTU, accessToken)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidDPoPProof)
+		})
+	}
+
+	t.Run("symmetric algorithms are refused", func(t *testing.T) {
+		uc := usecase.NewDPoPUseCase(time.Minute)
+		proof := newProof()
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, &proof)
+		token.Header["typ"] = domain.DPoPProofType
+		token.Header["jwk"] = jwk
+		signed, err := token.SignedString([]byte("secret"))
+		require.NoError(t, err)
+
+		_, err = uc.VerifyProof(signed, "GET", testHTU, accessToken)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidDPoPProof)
+	})
+
+	t.Run("signed by another key than the jwk", func(t *testing.T) {
+		uc := usecase.NewDPoPUseCase(time.Minute)
+		other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		_, err = uc.VerifyProof(signProof(t, other, newProof(), map[string]interface{}{"jwk": jwk}), "GET", testHTU, accessToken)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidDPoPProof)
+	})
+}
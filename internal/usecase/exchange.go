@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/google/uuid"
+)
+
+// TokenExchangeUseCase implements the checks of the token exchange grant (RFC 8693).
+type TokenExchangeUseCase struct {
+	verifier   *TokenVerifier
+	userRepo   userRepo.IUserRepository
+	clientRepo clientRepo.IClientRepository
+}
+
+func NewTokenExchangeUseCase(verifier *TokenVerifier, userRepo userRepo.IUserRepository, clientRepo clientRepo.IClientRepository) *TokenExchangeUseCase {
+	return &TokenExchangeUseCase{verifier: verifier, userRepo: userRepo, clientRepo: clientRepo}
+}
+
+// Exchange validates the subject token and narrows its scope. The actor is the subject of the
+// actor token, or the calling client when there is none, and becomes the head of the act chain.
+// Sender-constrained tokens are only exchanged when the request proves possession of their key.
+func (u *TokenExchangeUseCase) Exchange(ctx context.Context, caller *domain.Client, req *domain.TokenExchangeRequest) (*domain.TokenExchange, error) {
+	subject, err := u.verify(ctx, req.SubjectToken, req.SubjectTokenType)
+	if err != nil {
+		return nil, fmt.Errorf("subject_token: %w", err)
+	}
+	if err := checkPossession(subject, req); err != nil {
+		return nil, fmt.Errorf("subject_token: %w", err)
+	}
+
+	actor := &domain.Actor{Subject: caller.ID.String()}
+	if req.ActorToken != "" {
+		actorClaims, err := u.verify(ctx, req.ActorToken, req.ActorTokenType)
+		if err != nil {
+			return nil, fmt.Errorf("actor_token: %w", err)
+		}
+		if err := checkPossession(actorClaims, req); err != nil {
+			return nil, fmt.Errorf("actor_token: %w", err)
+		}
+		actor.Subject = actorClaims.Subject
+	} else if req.ActorTokenType != "" {
+		return nil, fmt.Errorf("%w: actor_token_type without actor_token", domain.ErrInvalidExchangeToken)
+	}
+	actor.Actor = subject.Act
+
+	scope, err := NarrowScope(domain.ParseScope(subject.Scope), req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	exchange := &domain.TokenExchange{Subject: subject, Scope: scope, Actor: actor}
+	if err := u.loadSubject(ctx, exchange); err != nil {
+		return nil, err
+	}
+	return exchange, nil
+}
+
+// verify accepts only live access tokens we issued, an ID token proves nothing about authorization.
+func (u *TokenExchangeUseCase) verify(ctx context.Context, token string, tokenType string) (*domain.JwtClaims, error) {
+	if tokenType != domain.TokenTypeAccessToken && tokenType != domain.TokenTypeJWT {
+		return nil, fmt.Errorf("%w: unsupported token type '%s'", domain.ErrInvalidExchangeToken, tokenType)
+	}
+	claims, err := u.verifier.Verify(ctx, token)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAccessToken) || errors.Is(err, domain.ErrTokenRevoked) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidExchangeToken, err.Error())
+		}
+		return nil, err
+	}
+	return claims, nil
+}
+
+// checkPossession rejects a DPoP-bound token (RFC 9449 section 6) unless the request carries a DPoP
+// proof of the same key, and a certificate-bound one (RFC 8705 section 3) unless the request comes
+// over the same TLS client certificate. A stolen token must not be laundered into an unbound one.
+func checkPossession(claims *domain.JwtClaims, req *domain.TokenExchangeRequest) error {
+	if claims.Cnf == nil {
+		return nil
+	}
+	if claims.Cnf.JKT != "" && claims.Cnf.JKT != req.DPoPKeyThumbprint {
+		return fmt.Errorf("%w: the token is bound to a DPoP key the request does not prove", domain.ErrInvalidExchangeToken)
+	}
+	if claims.Cnf.X5tS256 != "" && claims.Cnf.X5tS256 != req.CertificateThumbprint {
+		return fmt.Errorf("%w: the token is bound to another client certificate", domain.ErrInvalidExchangeToken)
+	}
+	return nil
+}
+
+// loadSubject resolves the user or client behind the subject token, which must still exist.
+func (u *TokenExchangeUseCase) loadSubject(ctx context.Context, exchange *domain.TokenExchange) error {
+	subjectID, err := uuid.Parse(exchange.Subject.Subject)
+	if err != nil {
+		return fmt.Errorf("%w: malformed subject", domain.ErrInvalidExchangeToken)
+	}
+
+	if exchange.Subject.Type == domain.UserType {
+		exchange.User, err = u.userRepo.GetUserByID(ctx, subjectID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return fmt.Errorf("%w: %s", domain.ErrInvalidExchangeToken, err.Error())
+		}
+		return err
+	}
+	exchange.Client, err = u.clientRepo.GetClientByID(ctx, subjectID)
+	if errors.Is(err, domain.ErrClientNotFound) {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidExchangeToken, err.Error())
+	}
+	return err
+}
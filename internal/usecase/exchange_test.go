@@ -0,0 +1,267 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	opaqueRepo "github.com/bright-pentium/go-client-practice/internal/repository/opaque"
+	revocationRepo "github.com/bright-pentium/go-client-practice/internal/repository/revocation"
+	sessionRepo "github.com/bright-pentium/go-client-practice/internal/repository/session"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenExchange(t *testing.T) {
+	ctx := context.Background()
+	keys := usecase.NewKeySet(usecase.NewHMACSigningKey("secret"))
+	gateway := &domain.Client{ID: uuid.New()}
+	user := &domain.User{ID: uuid.New(), Name: "Alice"}
+
+	setup := func() (*usecase.TokenExchangeUseCase, *revocationRepo.MockRevocationRepository, *userRepo.MockUserRepository, *clientRepo.MockClientRepository) {
+		mockRevocations := new(revocationRepo.MockRevocationRepository)
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockClientRepo := new(clientRepo.MockClientRepository)
+		revocations := usecase.NewRevocationUseCase(mockRevocations, time.Minute)
+		// anything that is not a JWT is looked up as an opaque token
+		mockOpaque := new(opaqueRepo.MockOpaqueTokenRepository)
+		mockOpaque.On("GetOpaqueTokenByHash", ctx, mock.Anything).Return(nil, domain.ErrOpaqueTokenNotFound)
+		verifier := usecase.NewTokenVerifier(keys, usecase.NewOpaqueTokenUseCase(mockOpaque, time.Minute), revocations, usecase.NewSessionUseCase(new(sessionRepo.MockSessionRepository), nil, time.Minute))
+		return usecase.NewTokenExchangeUseCase(verifier, mockUserRepo, mockClientRepo), mockRevocations, mockUserRepo, mockClientRepo
+	}
+	sign := func(claims domain.JwtClaims) string {
+		token, err := keys.Sign(claims)
+		require.NoError(t, err)
+		return token
+	}
+	userToken := func() domain.JwtClaims {
+		claims := testClaims()
+		claims.Subject = user.ID.String()
+		claims.Scope = domain.FormatScope([]domain.Permission{domain.PermCreateResource, domain.PermOpenID})
+		claims.ID = "subject-jti"
+		return claims
+	}
+	request := func(subject string, scope ...domain.Permission) *domain.TokenExchangeRequest {
+		return &domain.TokenExchangeRequest{SubjectToken: subject, SubjectTokenType: domain.TokenTypeAccessToken, Scope: scope}
+	}
+
+	t.Run("downscopes with the calling client as actor", func(t *testing.T) {
+		uc, mockRevocations, mockUserRepo, _ := setup()
+		mockRevocations.On("IsTokenRevoked", ctx, "subject-jti").Return(false, nil)
+		mockUserRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+
+		exchange, err := uc.Exchange(ctx, gateway, request(sign(userToken()), domain.PermCreateResource))
+
+		require.NoError(t, err)
+		assert.Equal(t, user, exchange.User)
+		assert.Nil(t, exchange.Client)
+		assert.Equal(t, user.ID.String(), exchange.Subject.Subject)
+		assert.Equal(t, []domain.Permission{domain.PermCreateResource}, exchange.Scope)
+		assert.Equal(t, &domain.Actor{Subject: gateway.ID.String()}, exchange.Actor)
+	})
+
+	t.Run("keeps the subject scope when none is requested", func(t *testing.T) {
+		uc, mockRevocations, mockUserRepo, _ := setup()
+		mockRevocations.On("IsTokenRevoked", ctx, "subject-jti").Return(false, nil)
+		mockUserRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+
+		exchange, err := uc.Exchange(ctx, gateway, request(sign(userToken())))
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.Permission{domain.PermCreateResource, domain.PermOpenID}, exchange.Scope)
+	})
+
+	t.Run("scope cannot be widened", func(t *testing.T) {
+		uc, mockRevocations, _, _ := setup()
+		mockRevocations.On("IsTokenRevoked", ctx, "subject-jti").Return(false, nil)
+
+		_, err := uc.Exchange(ctx, gateway, request(sign(userToken()), domain.PermIntrospect))
+
+		assert.ErrorIs(t, err, domain.ErrInvalidScope)
+	})
+
+	t.Run("actor token extends the delegation chain", func(t *testing.T) {
+		uc, mockRevocations, _, mockClientRepo := setup()
+		service := &domain.Client{ID: uuid.New()}
+		subject := testClaims()
+		subject.Type = domain.ClientType
+		subject.Subject = service.ID.String()
+		subject.Scope = string(domain.PermCreateResource)
+		subject.ID = "subject-jti"
+		subject.Act = &domain.Actor{Subject: "first-hop"}
+		actor := testClaims()
+		actor.Subject = "second-hop"
+		actor.ID = "actor-jti"
+
+		mockRevocations.On("IsTokenRevoked", ctx, mock.AnythingOfType("string")).Return(false, nil)
+		mockClientRepo.On("GetClientByID", ctx, service.ID).Return(service, nil)
+
+		req := request(sign(subject))
+		req.ActorToken, req.ActorTokenType = sign(actor), domain.TokenTypeJWT
+		exchange, err := uc.Exchange(ctx, gateway, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, service, exchange.Client)
+		assert.Equal(t, &domain.Actor{Subject: "second-hop", Actor: &domain.Actor{Subject: "first-hop"}}, exchange.Actor)
+	})
+
+	t.Run("DPoP-bound subject token exchanged by the key holder", func(t *testing.T) {
+		uc, mockRevocations, mockUserRepo, _ := setup()
+		mockRevocations.On("IsTokenRevoked", ctx, "subject-jti").Return(false, nil)
+		mockUserRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		subject := userToken()
+		subject.Cnf = &domain.Confirmation{JKT: "proof-key"}
+
+		req := request(sign(subject))
+		req.DPoPKeyThumbprint = "proof-key"
+		exchange, err := uc.Exchange(ctx, gateway, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, subject.Cnf, exchange.Subject.Cnf)
+	})
+
+	t.Run("certificate-bound subject token exchanged over its certificate", func(t *testing.T) {
+		uc, mockRevocations, mockUserRepo, _ := setup()
+		mockRevocations.On("IsTokenRevoked", ctx, "subject-jti").Return(false, nil)
+		mockUserRepo.On("GetUserByID", ctx, user.ID).Return(user, nil)
+		subject := userToken()
+		subject.Cnf = &domain.Confirmation{X5tS256: "client-certificate"}
+
+		req := request(sign(subject))
+		req.CertificateThumbprint = "client-certificate"
+		exchange, err := uc.Exchange(ctx, gateway, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, subject.Cnf, exchange.Subject.Cnf)
+	})
+
+	certificateCases := []struct {
+		name   string
+		bound  func(subject *domain.JwtClaims, actor *domain.JwtClaims)
+		holder string
+	}{
+		{"certificate-bound subject token without a certificate", func(subject *domain.JwtClaims, _ *domain.JwtClaims) {
+			subject.Cnf = &domain.Confirmation{X5tS256: "client-certificate"}
+		}, ""},
+		{"certificate-bound subject token over another certificate", func(subject *domain.JwtClaims, _ *domain.JwtClaims) {
+			subject.Cnf = &domain.Confirmation{X5tS256: "client-certificate"}
+		}, "other-certificate"},
+		{"certificate-bound actor token without a certificate", func(_ *domain.JwtClaims, actor *domain.JwtClaims) {
+			actor.Cnf = &domain.Confirmation{X5tS256: "client-certificate"}
+		}, ""},
+	}
+	for _, tc := range certificateCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, mockRevocations, _, _ := setup()
+			mockRevocations.On("IsTokenRevoked", ctx, mock.AnythingOfType("string")).Return(false, nil)
+			subject, actor := userToken(), testClaims()
+			actor.ID = "actor-jti"
+			tc.bound(&subject, &actor)
+
+			req := request(sign(subject))
+			req.ActorToken, req.ActorTokenType = sign(actor), domain.TokenTypeAccessToken
+			req.CertificateThumbprint = tc.holder
+			exchange, err := uc.Exchange(ctx, gateway, req)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidExchangeToken)
+			assert.Nil(t, exchange)
+		})
+	}
+
+	dpopCases := []struct {
+		name   string
+		bound  func(subject *domain.JwtClaims, actor *domain.JwtClaims)
+		holder string
+	}{
+		{"DPoP-bound subject token without a proof", func(subject *domain.JwtClaims, _ *domain.JwtClaims) {
+			subject.Cnf = &domain.Confirmation{JKT: "proof-key"}
+		}, ""},
+		{"DPoP-bound subject token with a proof of another key", func(subject *domain.JwtClaims, _ *domain.JwtClaims) {
+			subject.Cnf = &domain.Confirmation{JKT: "proof-key"}
+		}, "other-key"},
+		{"DPoP-bound actor token without a proof", func(_ *domain.JwtClaims, actor *domain.JwtClaims) {
+			actor.Cnf = &domain.Confirmation{JKT: "proof-key"}
+		}, ""},
+	}
+	for _, tc := range dpopCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, mockRevocations, _, _ := setup()
+			mockRevocations.On("IsTokenRevoked", ctx, mock.AnythingOfType("string")).Return(false, nil)
+			subject, actor := userToken(), testClaims()
+			actor.ID = "actor-jti"
+			tc.bound(&subject, &actor)
+
+			req := request(sign(subject))
+			req.ActorToken, req.ActorTokenType = sign(actor), domain.TokenTypeAccessToken
+			req.DPoPKeyThumbprint = tc.holder
+			exchange, err := uc.Exchange(ctx, gateway, req)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidExchangeToken)
+			assert.Nil(t, exchange)
+		})
+	}
+
+	cases := []struct {
+		name   string
+		modify func(req *domain.TokenExchangeRequest)
+	}{
+		{"unsupported subject token type", func(req *domain.TokenExchangeRequest) {
+			req.SubjectTokenType = "urn:ietf:params:oauth:token-type:refresh_token"
+		}},
+		{"forged subject token", func(req *domain.TokenExchangeRequest) { req.SubjectToken += "x" }},
+		{"invalid actor token", func(req *domain.TokenExchangeRequest) {
+			req.ActorToken, req.ActorTokenType = "garbage", domain.TokenTypeAccessToken
+		}},
+		{"actor token type without actor token", func(req *domain.TokenExchangeRequest) {
+			req.ActorTokenType = domain.TokenTypeAccessToken
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, mockRevocations, _, _ := setup()
+			mockRevocations.On("IsTokenRevoked", ctx, "subject-jti").Return(false, nil)
+
+			req := request(sign(userToken()))
+			tc.modify(req)
+			exchange, err := uc.Exchange(ctx, gateway, req)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidExchangeToken)
+			assert.Nil(t, exchange)
+		})
+	}
+
+	t.Run("id token is not exchangeable", func(t *testing.T) {
+		uc, _, _, _ := setup()
+		claims := userToken()
+		claims.Type = domain.IDTokenType
+
+		_, err := uc.Exchange(ctx, gateway, request(sign(claims)))
+
+		assert.ErrorIs(t, err, domain.ErrInvalidExchangeToken)
+	})
+
+	t.Run("revoked subject token", func(t *testing.T) {
+		uc, mockRevocations, _, _ := setup()
+		mockRevocations.On("IsTokenRevoked", ctx, "subject-jti").Return(true, nil)
+
+		_, err := uc.Exchange(ctx, gateway, request(sign(userToken())))
+
+		assert.ErrorIs(t, err, domain.ErrInvalidExchangeToken)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		uc, mockRevocations, mockUserRepo, _ := setup()
+		mockRevocations.On("IsTokenRevoked", ctx, "subject-jti").Return(false, nil)
+		mockUserRepo.On("GetUserByID", ctx, user.ID).Return(nil, domain.ErrUserNotFound)
+
+		_, err := uc.Exchange(ctx, gateway, request(sign(userToken())))
+
+		assert.ErrorIs(t, err, domain.ErrInvalidExchangeToken)
+	})
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/google/uuid"
+)
+
+// IntrospectionUseCase answers whether an access token is still good, applying
+// the same checks as the authentication middleware plus the existence of its subject.
+type IntrospectionUseCase struct {
+	verifier   *TokenVerifier
+	userRepo   userRepo.IUserRepository
+	clientRepo clientRepo.IClientRepository
+}
+
+func NewIntrospectionUseCase(verifier *TokenVerifier, userRepo userRepo.IUserRepository, clientRepo clientRepo.IClientRepository) *IntrospectionUseCase {
+	return &IntrospectionUseCase{verifier: verifier, userRepo: userRepo, clientRepo: clientRepo}
+}
+
+// Introspect never reports why a token is inactive; errors are returned only
+// when the answer cannot be determined.
+func (u *IntrospectionUseCase) Introspect(ctx context.Context, token string) (*domain.Introspection, error) {
+	inactive := &domain.Introspection{Active: false}
+
+	claims, err := u.verifier.Verify(ctx, token)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAccessToken) || errors.Is(err, domain.ErrTokenRevoked) {
+			return inactive, nil
+		}
+		return nil, err
+	}
+
+	exists, err := u.subjectExists(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return inactive, nil
+	}
+
+	result := &domain.Introspection{
+		Active:    true,
+		Scope:     claims.Scope,
+		TokenType: "Bearer",
+		Sub:       claims.Subject,
+		Iss:       claims.Issuer,
+		Aud:       claims.Audience,
+		Jti:       claims.ID,
+		Type:      claims.Type,
+		Act:       claims.Act,
+		Cnf:       claims.Cnf,
+	}
+	// RFC 9449 section 6.2: a bound token is reported with its confirmation
+	if claims.Cnf != nil && claims.Cnf.JKT != "" {
+		result.TokenType = "DPoP"
+	}
+	if claims.Type == domain.ClientType {
+		result.ClientID = claims.Subject
+	} else if claims.AuthorizedParty != "" {
+		result.ClientID = claims.AuthorizedParty
+	}
+	if claims.ExpiresAt != nil {
+		result.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		result.Iat = claims.IssuedAt.Unix()
+	}
+	return result, nil
+}
+
+// subjectExists reports whether the user or client the token was issued to has not been deleted since.
+func (u *IntrospectionUseCase) subjectExists(ctx context.Context, claims *domain.JwtClaims) (bool, error) {
+	subject, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return false, nil
+	}
+
+	switch claims.Type {
+	case domain.UserType:
+		_, err = u.userRepo.GetUserByID(ctx, subject)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return false, nil
+		}
+	case domain.ClientType:
+		_, err = u.clientRepo.GetClientByID(ctx, subject)
+		if errors.Is(err, domain.ErrClientNotFound) {
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
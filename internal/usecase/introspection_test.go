@@ -0,0 +1,208 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	opaqueRepo "github.com/bright-pentium/go-client-practice/internal/repository/opaque"
+	revocationRepo "github.com/bright-pentium/go-client-practice/internal/repository/revocation"
+	sessionRepo "github.com/bright-pentium/go-client-practice/internal/repository/session"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntrospect(t *testing.T) {
+	ctx := context.Background()
+	keys := usecase.NewKeySet(usecase.NewHMACSigningKey("secret"))
+
+	setup := func() (*usecase.IntrospectionUseCase, *revocationRepo.MockRevocationRepository, *userRepo.MockUserRepository, *clientRepo.MockClientRepository) {
+		mockRevocations := new(revocationRepo.MockRevocationRepository)
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockClientRepo := new(clientRepo.MockClientRepository)
+		revocations := usecase.NewRevocationUseCase(mockRevocations, time.Minute)
+		// anything that is not a JWT is looked up as an opaque token
+		mockOpaque := new(opaqueRepo.MockOpaqueTokenRepository)
+		mockOpaque.On("GetOpaqueTokenByHash", ctx, mock.Anything).Return(nil, domain.ErrOpaqueTokenNotFound)
+		verifier := usecase.NewTokenVerifier(keys, usecase.NewOpaqueTokenUseCase(mockOpaque, time.Minute), revocations, usecase.NewSessionUseCase(new(sessionRepo.MockSessionRepository), nil, time.Minute))
+		return usecase.NewIntrospectionUseCase(verifier, mockUserRepo, mockClientRepo), mockRevocations, mockUserRepo, mockClientRepo
+	}
+	sign := func(claims domain.JwtClaims) string {
+		token, err := keys.Sign(claims)
+		require.NoError(t, err)
+		return token
+	}
+	clientClaims := func(clientID uuid.UUID) domain.JwtClaims {
+		claims := testClaims()
+		claims.Type = domain.ClientType
+		claims.Scope = string(domain.PermCreateResource)
+		claims.Subject = clientID.String()
+		claims.Issuer = "ClientApp"
+		claims.Audience = jwt.ClaimStrings{"https://api.example.com"}
+		claims.ID = "jti-1"
+		return claims
+	}
+
+	t.Run("active client token", func(t *testing.T) {
+		uc, mockRevocations, _, mockClientRepo := setup()
+		clientID := uuid.New()
+		claims := clientClaims(clientID)
+
+		mockRevocations.On("IsTokenRevoked", ctx, "jti-1").Return(false, nil)
+		mockClientRepo.On("GetClientByID", ctx, clientID).Return(&domain.Client{ID: clientID}, nil)
+
+		result, err := uc.Introspect(ctx, sign(claims))
+
+		require.NoError(t, err)
+		assert.Equal(t, &domain.Introspection{
+			Active:    true,
+			Scope:     string(domain.PermCreateResource),
+			ClientID:  clientID.String(),
+			TokenType: "Bearer",
+			Exp:       claims.ExpiresAt.Unix(),
+			Iat:       claims.IssuedAt.Unix(),
+			Sub:       clientID.String(),
+			Iss:       "ClientApp",
+			Aud:       []string{"https://api.example.com"},
+			Jti:       "jti-1",
+			Type:      domain.ClientType,
+		}, result)
+	})
+
+	t.Run("active user token has no client_id", func(t *testing.T) {
+		uc, _, mockUserRepo, _ := setup()
+		userID := uuid.New()
+		claims := testClaims()
+		claims.Subject = userID.String()
+
+		mockUserRepo.On("GetUserByID", ctx, userID).Return(&domain.User{ID: userID}, nil)
+
+		result, err := uc.Introspect(ctx, sign(claims))
+
+		require.NoError(t, err)
+		assert.True(t, result.Active)
+		assert.Empty(t, result.ClientID)
+		assert.Equal(t, userID.String(), result.Sub)
+	})
+
+	t.Run("delegated user token reports the client", func(t *testing.T) {
+		uc, _, mockUserRepo, _ := setup()
+		userID, clientID := uuid.New(), uuid.New()
+		claims := testClaims()
+		claims.Subject = userID.String()
+		claims.AuthorizedParty = clientID.String()
+
+		mockUserRepo.On("GetUserByID", ctx, userID).Return(&domain.User{ID: userID}, nil)
+
+		result, err := uc.Introspect(ctx, sign(claims))
+
+		require.NoError(t, err)
+		assert.True(t, result.Active)
+		assert.Equal(t, clientID.String(), result.ClientID)
+		assert.Equal(t, userID.String(), result.Sub)
+	})
+
+	t.Run("dpop bound token", func(t *testing.T) {
+		uc, mockRevocations, _, mockClientRepo := setup()
+		clientID := uuid.New()
+		claims := clientClaims(clientID)
+		claims.Cnf = &domain.Confirmation{JKT: "0ZcOCORZNYy-DWpqq30jZyJGHTN0d2HglBV3uiguA4I"}
+
+		mockRevocations.On("IsTokenRevoked", ctx, "jti-1").Return(false, nil)
+		mockClientRepo.On("GetClientByID", ctx, clientID).Return(&domain.Client{ID: clientID}, nil)
+
+		result, err := uc.Introspect(ctx, sign(claims))
+
+		require.NoError(t, err)
+		assert.Equal(t, "DPoP", result.TokenType)
+		assert.Equal(t, claims.Cnf, result.Cnf)
+	})
+
+	t.Run("certificate bound token", func(t *testing.T) {
+		uc, mockRevocations, _, mockClientRepo := setup()
+		clientID := uuid.New()
+		claims := clientClaims(clientID)
+		claims.Cnf = &domain.Confirmation{X5tS256: "bwcK0esc3ACC3DB2Y5_lESsXE8o9ltc05O89jdN-dg2"}
+
+		mockRevocations.On("IsTokenRevoked", ctx, "jti-1").Return(false, nil)
+		mockClientRepo.On("GetClientByID", ctx, clientID).Return(&domain.Client{ID: clientID}, nil)
+
+		result, err := uc.Introspect(ctx, sign(claims))
+
+		require.NoError(t, err)
+		assert.NotEqual(t, "DPoP", result.TokenType)
+		assert.Equal(t, claims.Cnf, result.Cnf)
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		uc, mockRevocations, _, _ := setup()
+
+		mockRevocations.On("IsTokenRevoked", ctx, "jti-1").Return(true, nil)
+
+		result, err := uc.Introspect(ctx, sign(clientClaims(uuid.New())))
+
+		require.NoError(t, err)
+		assert.Equal(t, &domain.Introspection{Active: false}, result)
+	})
+
+	t.Run("deleted client", func(t *testing.T) {
+		uc, mockRevocations, _, mockClientRepo := setup()
+		clientID := uuid.New()
+
+		mockRevocations.On("IsTokenRevoked", ctx, "jti-1").Return(false, nil)
+		mockClientRepo.On("GetClientByID", ctx, clientID).Return(nil, domain.ErrClientNotFound)
+
+		result, err := uc.Introspect(ctx, sign(clientClaims(clientID)))
+
+		require.NoError(t, err)
+		assert.False(t, result.Active)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		uc, _, mockUserRepo, _ := setup()
+		userID := uuid.New()
+		claims := testClaims()
+		claims.Subject = userID.String()
+
+		mockUserRepo.On("GetUserByID", ctx, userID).Return(nil, domain.ErrUserNotFound)
+
+		result, err := uc.Introspect(ctx, sign(claims))
+
+		require.NoError(t, err)
+		assert.False(t, result.Active)
+	})
+
+	t.Run("expired or foreign token", func(t *testing.T) {
+		uc, _, _, _ := setup()
+		expired := testClaims()
+		expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		foreign, err := usecase.NewKeySet(usecase.NewHMACSigningKey("other")).Sign(testClaims())
+		require.NoError(t, err)
+
+		for _, token := range []string{sign(expired), foreign, "not-a-jwt"} {
+			result, err := uc.Introspect(ctx, token)
+			require.NoError(t, err)
+			assert.False(t, result.Active)
+		}
+	})
+
+	t.Run("repository failure is an error", func(t *testing.T) {
+		uc, mockRevocations, _, mockClientRepo := setup()
+		clientID := uuid.New()
+
+		mockRevocations.On("IsTokenRevoked", ctx, "jti-1").Return(false, nil)
+		mockClientRepo.On("GetClientByID", ctx, clientID).Return(nil, domain.ErrGeneralClient)
+
+		result, err := uc.Introspect(ctx, sign(clientClaims(clientID)))
+
+		assert.ErrorIs(t, err, domain.ErrGeneralClient)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+)
+
+// ClaimProvider adds custom claims to access tokens. Claim names are relative, the token issuer puts
+// them under its namespace. A nil map adds nothing.
+type ClaimProvider interface {
+	Name() string
+	Claims(ctx context.Context, req *domain.ClaimRequest) (map[string]interface{}, error)
+}
+
+// TokenIssuer signs access tokens once the claim providers enriched them. Tokens issued to a client run
+// the providers the client is configured with, first-party user tokens the default ones. Clients
+// configured for opaque tokens get a reference to the stored claims instead of a JWT.
+type TokenIssuer struct {
+	keys      *KeySet
+	opaque    *OpaqueTokenUseCase
+	namespace string
+	defaults  []string
+	providers map[string]ClaimProvider
+}
+
+func NewTokenIssuer(keys *KeySet, opaque *OpaqueTokenUseCase, namespace string, defaults []string) *TokenIssuer {
+	return &TokenIssuer{keys: keys, opaque: opaque, namespace: namespace, defaults: defaults, providers: make(map[string]ClaimProvider)}
+}
+
+// Register adds a provider under its name, replacing any with the same name.
+func (i *TokenIssuer) Register(provider ClaimProvider) {
+	i.providers[provider.Name()] = provider
+}
+
+// CheckProviders returns ErrUnknownClaimProvider for the first name that is not registered.
+func (i *TokenIssuer) CheckProviders(names []string) error {
+	for _, name := range names {
+		if _, ok := i.providers[name]; !ok {
+			return fmt.Errorf("%w: '%s'", domain.ErrUnknownClaimProvider, name)
+		}
+	}
+	return nil
+}
+
+// Issue adds the custom claims of the providers for req to claims and signs or stores them. A provider setting
+// a reserved claim fails the issuance rather than changing what the token means.
+func (i *TokenIssuer) Issue(ctx context.Context, req *domain.ClaimRequest, claims domain.JwtClaims) (string, error) {
+	req.Claims = claims
+	names := i.defaults
+	if req.Client != nil {
+		names = req.Client.ClaimProviders
+	}
+
+	custom := make(map[string]interface{}, len(claims.Custom))
+	for name, value := range claims.Custom {
+		custom[name] = value
+	}
+	for _, name := range names {
+		provider, ok := i.providers[name]
+		if !ok {
+			// checked when the client was registered, the provider was dropped from the configuration since
+			log.Printf("claim provider %s is not registered", name)
+			continue
+		}
+		added, err := provider.Claims(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		for claim, value := range added {
+			claim = i.namespace + claim
+			if _, reserved := domain.ReservedClaims[claim]; reserved {
+				return "", fmt.Errorf("%w: '%s' set by %s", domain.ErrReservedClaim, claim, name)
+			}
+			custom[claim] = value
+		}
+	}
+	if len(custom) > 0 {
+		claims.Custom = custom
+	}
+	if req.Client != nil && req.Client.AccessTokenFormat == domain.TokenFormatOpaque {
+		return i.opaque.IssueOpaqueToken(ctx, claims)
+	}
+	return i.keys.Sign(claims)
+}
@@ -0,0 +1,186 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	opaqueRepo "github.com/bright-pentium/go-client-practice/internal/repository/opaque"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testNamespace = "https://server.example.com/claims/"
+
+// fakeClaimProvider returns fixed claims and records the requests it was called with.
+type fakeClaimProvider struct {
+	name     string
+	claims   map[string]interface{}
+	err      error
+	requests []*domain.ClaimRequest
+}
+
+func (p *fakeClaimProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeClaimProvider) Claims(ctx context.Context, req *domain.ClaimRequest) (map[string]interface{}, error) {
+	p.requests = append(p.requests, req)
+	return p.claims, p.err
+}
+
+func TestIssue(t *testing.T) {
+	ctx := context.Background()
+	keys := usecase.NewKeySet(usecase.NewHMACSigningKey("secret"))
+
+	setup := func(namespace string, defaults []string) (*usecase.TokenIssuer, *fakeClaimProvider, *fakeClaimProvider) {
+		tenant := &fakeClaimProvider{name: "tenant", claims: map[string]interface{}{"tenant": "acme"}}
+		plan := &fakeClaimProvider{name: "plan", claims: map[string]interface{}{"plan": "gold"}}
+		issuer := usecase.NewTokenIssuer(keys, nil, namespace, defaults)
+		issuer.Register(tenant)
+		issuer.Register(plan)
+		return issuer, tenant, plan
+	}
+
+	t.Run("user tokens get the default providers", func(t *testing.T) {
+		issuer, tenant, plan := setup(testNamespace, []string{"tenant"})
+		user := &domain.User{ID: uuid.New()}
+
+		token, err := issuer.Issue(ctx, &domain.ClaimRequest{User: user, GrantType: "password"}, testClaims())
+
+		require.NoError(t, err)
+		claims, err := keys.Parse(token)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{testNamespace + "tenant": "acme"}, claims.Custom)
+		assert.Equal(t, "subject", claims.Subject)
+		require.Len(t, tenant.requests, 1)
+		assert.Equal(t, user, tenant.requests[0].User)
+		assert.Empty(t, plan.requests)
+	})
+
+	t.Run("client tokens get the providers of the client", func(t *testing.T) {
+		issuer, tenant, plan := setup(testNamespace, []string{"tenant"})
+		client := &domain.Client{ID: uuid.New(), ClaimProviders: []string{"plan"}}
+
+		token, err := issuer.Issue(ctx, &domain.ClaimRequest{Client: client, GrantType: "client_credentials"}, testClaims())
+
+		require.NoError(t, err)
+		claims, err := keys.Parse(token)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{testNamespace + "plan": "gold"}, claims.Custom)
+		assert.Empty(t, tenant.requests)
+		assert.Equal(t, testClaims().Subject, plan.requests[0].Claims.Subject)
+	})
+
+	t.Run("no providers add no claims", func(t *testing.T) {
+		issuer, _, _ := setup(testNamespace, nil)
+
+		token, err := issuer.Issue(ctx, &domain.ClaimRequest{}, testClaims())
+
+		require.NoError(t, err)
+		claims, err := keys.Parse(token)
+		require.NoError(t, err)
+		assert.Nil(t, claims.Custom)
+	})
+
+	t.Run("unregistered providers are skipped", func(t *testing.T) {
+		issuer, _, _ := setup(testNamespace, []string{"removed", "tenant"})
+
+		token, err := issuer.Issue(ctx, &domain.ClaimRequest{}, testClaims())
+
+		require.NoError(t, err)
+		claims, err := keys.Parse(token)
+		require.NoError(t, err)
+		assert.Contains(t, claims.Custom, testNamespace+"tenant")
+	})
+
+	t.Run("reserved claims cannot be overridden", func(t *testing.T) {
+		issuer, _, _ := setup("", []string{"evil"})
+		issuer.Register(&fakeClaimProvider{name: "evil", claims: map[string]interface{}{"scope": "*"}})
+
+		token, err := issuer.Issue(ctx, &domain.ClaimRequest{}, testClaims())
+
+		assert.ErrorIs(t, err, domain.ErrReservedClaim)
+		assert.Empty(t, token)
+	})
+
+	t.Run("provider error fails the issuance", func(t *testing.T) {
+		issuer, _, _ := setup(testNamespace, []string{"broken"})
+		providerErr := errors.New("provider down")
+		issuer.Register(&fakeClaimProvider{name: "broken", err: providerErr})
+
+		_, err := issuer.Issue(ctx, &domain.ClaimRequest{}, testClaims())
+
+		assert.ErrorIs(t, err, providerErr)
+	})
+
+	t.Run("opaque clients get a stored reference", func(t *testing.T) {
+		mockOpaque := new(opaqueRepo.MockOpaqueTokenRepository)
+		issuer := usecase.NewTokenIssuer(keys, usecase.NewOpaqueTokenUseCase(mockOpaque, time.Minute), testNamespace, nil)
+		issuer.Register(&fakeClaimProvider{name: "tenant", claims: map[string]interface{}{"tenant": "acme"}})
+		client := &domain.Client{ID: uuid.New(), ClaimProviders: []string{"tenant"}, AccessTokenFormat: domain.TokenFormatOpaque}
+		var captured *domain.OpaqueToken
+		mockOpaque.
+			On("CreateOpaqueToken", ctx, mock.AnythingOfType("*domain.OpaqueToken")).
+			Run(func(args mock.Arguments) { captured = args.Get(1).(*domain.OpaqueToken) }).
+			Return(&domain.OpaqueToken{}, nil)
+
+		token, err := issuer.Issue(ctx, &domain.ClaimRequest{Client: client}, testClaims())
+
+		require.NoError(t, err)
+		assert.False(t, usecase.IsJWT(token))
+		assert.Equal(t, "acme", captured.Claims.Custom[testNamespace+"tenant"])
+	})
+
+	t.Run("check providers", func(t *testing.T) {
+		issuer, _, _ := setup(testNamespace, nil)
+
+		assert.NoError(t, issuer.CheckProviders([]string{"tenant", "plan"}))
+		assert.ErrorIs(t, issuer.CheckProviders([]string{"tenant", "other"}), domain.ErrUnknownClaimProvider)
+	})
+}
+
+func TestClientOwnerClaimProvider(t *testing.T) {
+	ctx := context.Background()
+	owner := &domain.User{ID: uuid.New(), Name: "alice"}
+	client := &domain.Client{ID: uuid.New(), UserID: owner.ID}
+
+	t.Run("client token", func(t *testing.T) {
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockUserRepo.On("GetUserByID", ctx, owner.ID).Return(owner, nil)
+		provider := usecase.NewClientOwnerClaimProvider(mockUserRepo)
+
+		claims, err := provider.Claims(ctx, &domain.ClaimRequest{Client: client, Claims: domain.JwtClaims{Type: domain.ClientType}})
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"owner": owner.ID.String(), "owner_name": owner.Name}, claims)
+	})
+
+	t.Run("delegated token", func(t *testing.T) {
+		mockUserRepo := new(userRepo.MockUserRepository)
+		provider := usecase.NewClientOwnerClaimProvider(mockUserRepo)
+
+		claims, err := provider.Claims(ctx, &domain.ClaimRequest{Client: client, Claims: domain.JwtClaims{Type: domain.UserType}})
+
+		require.NoError(t, err)
+		assert.Nil(t, claims)
+		mockUserRepo.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deleted owner", func(t *testing.T) {
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockUserRepo.On("GetUserByID", ctx, owner.ID).Return(nil, domain.ErrUserNotFound)
+		provider := usecase.NewClientOwnerClaimProvider(mockUserRepo)
+
+		claims, err := provider.Claims(ctx, &domain.ClaimRequest{Client: client, Claims: domain.JwtClaims{Type: domain.ClientType}})
+
+		require.NoError(t, err)
+		assert.Nil(t, claims)
+	})
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"crypto/x509"
+	"log"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/repository/signingkey"
+)
+
+// KeyRingUseCase keeps the KeySet in sync with the rotated keys stored in the
+// database, next to the keys configured statically from PEM files or SECRET_KEY.
+type KeyRingUseCase struct {
+	repo   signingkey.ISigningKeyRepository
+	keys   *KeySet
+	static []domain.SigningKey
+
+	// algorithm of generated keys
+	algorithm string
+	// how long a new key is only published before it starts signing
+	activationDelay time.Duration
+	// how long a retired key keeps verifying, the lifetime of the tokens it signed
+	tokenTTL time.Duration
+}
+
+func NewKeyRingUseCase(repo signingkey.ISigningKeyRepository, keys *KeySet, static []domain.SigningKey, algorithm string, activationDelay time.Duration, tokenTTL time.Duration) *KeyRingUseCase {
+	return &KeyRingUseCase{
+		repo:            repo,
+		keys:            keys,
+		static:          static,
+		algorithm:       algorithm,
+		activationDelay: activationDelay,
+		tokenTTL:        tokenTTL,
+	}
+}
+
+// ListKeys returns the rotated keys, newest first.
+func (u *KeyRingUseCase) ListKeys(ctx context.Context) ([]domain.SigningKey, error) {
+	return u.repo.ListSigningKeys(ctx)
+}
+
+// Rotate generates a new key in the next state. Once it activates, every other
+// key retires and keeps verifying for the lifetime of the tokens it signed.
+func (u *KeyRingUseCase) Rotate(ctx context.Context) (*domain.SigningKey, error) {
+	return u.rotate(ctx, time.Now().Add(u.activationDelay))
+}
+
+// EnsureActiveKey creates an immediately active key when nothing can sign,
+// which is the case on a fresh database without static keys.
+func (u *KeyRingUseCase) EnsureActiveKey(ctx context.Context) error {
+	if err := u.Sync(ctx); err != nil {
+		return err
+	}
+	if _, err := u.keys.SigningKey(time.Now()); err == nil {
+		return nil
+	}
+	_, err := u.rotate(ctx, time.Now())
+	return err
+}
+
+func (u *KeyRingUseCase) rotate(ctx context.Context, activatesAt time.Time) (*domain.SigningKey, error) {
+	generated, err := GenerateSigningKey(u.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(generated.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := u.repo.CreateSigningKey(ctx, generated.KID, generated.Algorithm, der, activatesAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.RetireSigningKeys(ctx, key.KID, activatesAt); err != nil {
+		return nil, err
+	}
+	// static keys are configured, they cannot be retired and are outranked by activation time instead
+	return key, u.Sync(ctx)
+}
+
+// Sync reloads the rotated keys and drops the retired ones whose tokens have all expired.
+func (u *KeyRingUseCase) Sync(ctx context.Context) error {
+	stored, err := u.repo.ListSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	keys := make([]domain.SigningKey, 0, len(stored)+len(u.static))
+	for _, key := range stored {
+		if key.RetiredAt != nil && now.After(key.RetiredAt.Add(u.tokenTTL)) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	u.keys.Replace(append(keys, u.static...))
+	return nil
+}
+
+// Run syncs periodically so rotations made by other replicas are picked up.
+func (u *KeyRingUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := u.Sync(ctx); err != nil {
+				log.Printf("key ring sync failed: %v", err)
+			}
+		}
+	}
+}
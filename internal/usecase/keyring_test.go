@@ -0,0 +1,151 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	signingKeyRepo "github.com/bright-pentium/go-client-practice/internal/repository/signingkey"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper function to build a stored key the way the repository returns it
+func storedKey(t *testing.T, activatesAt time.Time, retiredAt *time.Time) domain.SigningKey {
+	key, err := usecase.GenerateSigningKey(domain.AlgES256)
+	require.NoError(t, err)
+	key.ActivatesAt = activatesAt
+	key.RetiredAt = retiredAt
+	return *key
+}
+
+func TestKeyRingRotate(t *testing.T) {
+	mockRepo := new(signingKeyRepo.MockSigningKeyRepository)
+	keys := usecase.NewKeySet()
+	uc := usecase.NewKeyRingUseCase(mockRepo, keys, nil, domain.AlgES256, 10*time.Minute, 15*time.Minute)
+	ctx := context.Background()
+
+	active := storedKey(t, time.Now().Add(-time.Hour), nil)
+	var next domain.SigningKey
+	mockRepo.On("CreateSigningKey", ctx, mock.AnythingOfType("string"), domain.AlgES256, mock.AnythingOfType("[]uint8"), mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) {
+			privateKey, err := x509.ParsePKCS8PrivateKey(args.Get(3).([]byte))
+			require.NoError(t, err)
+			next = domain.SigningKey{KID: args.String(1), Algorithm: args.String(2), Key: privateKey, ActivatesAt: args.Get(4).(time.Time)}
+		}).
+		Return(func(context.Context, string, string, []byte, time.Time) *domain.SigningKey { return &next }, nil)
+	mockRepo.On("RetireSigningKeys", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) {
+			assert.Equal(t, next.KID, args.String(1))
+			assert.Equal(t, next.ActivatesAt, args.Get(2).(time.Time))
+			at := args.Get(2).(time.Time)
+			active.RetiredAt = &at
+		}).
+		Return(nil)
+	mockRepo.On("ListSigningKeys", ctx).
+		Return(func(context.Context) []domain.SigningKey { return []domain.SigningKey{next, active} }, nil)
+
+	key, err := uc.Rotate(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.KeyNext, key.StateAt(time.Now()))
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), key.ActivatesAt, time.Minute)
+
+	// the next key is published right away but the old key keeps signing until it activates
+	kids := []string{}
+	for _, jwk := range keys.JWKS().Keys {
+		kids = append(kids, jwk.Kid)
+	}
+	assert.ElementsMatch(t, []string{next.KID, active.KID}, kids)
+	signing, err := keys.SigningKey(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, active.KID, signing.KID)
+
+	// after activation the new key signs and the retired one still verifies
+	signing, err = keys.SigningKey(time.Now().Add(11 * time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, next.KID, signing.KID)
+	assert.Equal(t, domain.KeyRetired, active.StateAt(time.Now().Add(11*time.Minute)))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestKeyRingSyncDropsExpiredRetiredKeys(t *testing.T) {
+	mockRepo := new(signingKeyRepo.MockSigningKeyRepository)
+	static := usecase.NewHMACSigningKey("legacy")
+	keys := usecase.NewKeySet()
+	uc := usecase.NewKeyRingUseCase(mockRepo, keys, []domain.SigningKey{static}, domain.AlgES256, 0, 15*time.Minute)
+	ctx := context.Background()
+
+	recentlyRetiredAt := time.Now().Add(-5 * time.Minute)
+	longRetiredAt := time.Now().Add(-time.Hour)
+	current := storedKey(t, recentlyRetiredAt, nil)
+	recentlyRetired := storedKey(t, time.Now().Add(-2*time.Hour), &recentlyRetiredAt)
+	longRetired := storedKey(t, time.Now().Add(-3*time.Hour), &longRetiredAt)
+	mockRepo.On("ListSigningKeys", ctx).Return([]domain.SigningKey{current, recentlyRetired, longRetired}, nil)
+
+	require.NoError(t, uc.Sync(ctx))
+
+	sign := func(key domain.SigningKey) string {
+		token := jwt.NewWithClaims(key.Method(), testClaims())
+		token.Header["kid"] = key.KID
+		signed, err := token.SignedString(key.Key)
+		require.NoError(t, err)
+		return signed
+	}
+	_, err := jwt.ParseWithClaims(sign(recentlyRetired), new(domain.JwtClaims), keys.Keyfunc)
+	assert.NoError(t, err)
+	_, err = jwt.ParseWithClaims(sign(longRetired), new(domain.JwtClaims), keys.Keyfunc)
+	assert.ErrorIs(t, err, domain.ErrSigningKeyNotFound)
+
+	// the rotated key outranks the static one
+	signing, err := keys.SigningKey(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, current.KID, signing.KID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestKeyRingEnsureActiveKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("static key is enough", func(t *testing.T) {
+		mockRepo := new(signingKeyRepo.MockSigningKeyRepository)
+		keys := usecase.NewKeySet()
+		uc := usecase.NewKeyRingUseCase(mockRepo, keys, []domain.SigningKey{usecase.NewHMACSigningKey("legacy")}, domain.AlgES256, time.Minute, time.Minute)
+		mockRepo.On("ListSigningKeys", ctx).Return([]domain.SigningKey{}, nil)
+
+		assert.NoError(t, uc.EnsureActiveKey(ctx))
+		mockRepo.AssertNotCalled(t, "CreateSigningKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fresh database without static keys", func(t *testing.T) {
+		mockRepo := new(signingKeyRepo.MockSigningKeyRepository)
+		keys := usecase.NewKeySet()
+		uc := usecase.NewKeyRingUseCase(mockRepo, keys, nil, domain.AlgEdDSA, time.Hour, time.Minute)
+
+		var created []domain.SigningKey
+		mockRepo.On("CreateSigningKey", ctx, mock.AnythingOfType("string"), domain.AlgEdDSA, mock.AnythingOfType("[]uint8"), mock.AnythingOfType("time.Time")).
+			Return(func(_ context.Context, kid string, alg string, der []byte, activatesAt time.Time) *domain.SigningKey {
+				privateKey, err := x509.ParsePKCS8PrivateKey(der)
+				require.NoError(t, err)
+				created = append(created, domain.SigningKey{KID: kid, Algorithm: alg, Key: privateKey, ActivatesAt: activatesAt})
+				return &created[0]
+			}, nil)
+		mockRepo.On("RetireSigningKeys", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil)
+		mockRepo.On("ListSigningKeys", ctx).Return(func(context.Context) []domain.SigningKey { return created }, nil)
+
+		require.NoError(t, uc.EnsureActiveKey(ctx))
+
+		// activation is immediate despite the configured delay
+		signed, err := keys.Sign(testClaims())
+		require.NoError(t, err)
+		token, err := jwt.ParseWithClaims(signed, new(domain.JwtClaims), keys.Keyfunc)
+		require.NoError(t, err)
+		assert.Equal(t, created[0].KID, token.Header["kid"])
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// KeySet signs tokens with its most recently activated key and verifies tokens signed by any of its keys.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []domain.SigningKey
+}
+
+func NewKeySet(keys ...domain.SigningKey) *KeySet {
+	return &KeySet{keys: keys}
+}
+
+// Replace swaps the whole set, e.g. after the key ring was rotated.
+func (s *KeySet) Replace(keys []domain.SigningKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+// NewHMACSigningKey wraps the legacy shared secret.
+func NewHMACSigningKey(secret string) domain.SigningKey {
+	return domain.SigningKey{KID: LegacyHMACKeyID, Algorithm: domain.AlgHS256, Key: []byte(secret)}
+}
+
+// LegacyHMACKeyID is stamped on HS256 tokens. Tokens minted before kids were
+// introduced carry none and are matched to the HS256 key as well.
+const LegacyHMACKeyID = "hs256"
+
+// ParseSigningKeyPEM reads a PKCS#8, PKCS#1 or SEC 1 private key. The algorithm
+// follows from the key type and the kid is the RFC 7638 thumbprint of the public key.
+func ParseSigningKeyPEM(data []byte) (*domain.SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block found", domain.ErrUnsupportedKey)
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnsupportedKey, err)
+	}
+	return newAsymmetricSigningKey(key)
+}
+
+// GenerateSigningKey creates a fresh key for one of the asymmetric algorithms.
+func GenerateSigningKey(alg string) (*domain.SigningKey, error) {
+	var key interface{}
+	var err error
+	switch alg {
+	case domain.AlgRS256:
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case domain.AlgES256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case domain.AlgEdDSA:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: cannot generate %s keys", domain.ErrUnsupportedKey, alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newAsymmetricSigningKey(key)
+}
+
+func newAsymmetricSigningKey(key interface{}) (*domain.SigningKey, error) {
+	var alg string
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("%w: RSA keys must be at least 2048 bits", domain.ErrUnsupportedKey)
+		}
+		alg = domain.AlgRS256
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: ES256 needs a P-256 key", domain.ErrUnsupportedKey)
+		}
+		alg = domain.AlgES256
+	case ed25519.PrivateKey:
+		alg = domain.AlgEdDSA
+	default:
+		return nil, fmt.Errorf("%w: %T", domain.ErrUnsupportedKey, key)
+	}
+
+	jwk, err := domain.NewJWK(key.(crypto.Signer).Public())
+	if err != nil {
+		return nil, err
+	}
+	kid, err := jwk.Thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.SigningKey{KID: kid, Algorithm: alg, Key: key}, nil
+}
+
+// Sign signs the claims with the current signing key and stamps its kid in the header.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key, err := s.SigningKey(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.Method(), claims)
+	token.Header["kid"] = key.KID
+	return token.SignedString(key.Key)
+}
+
+// SigningKey returns the active key that was activated last. Keys activated at
+// the same time, like statically configured ones, are preferred in set order.
+func (s *KeySet) SigningKey(now time.Time) (*domain.SigningKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var current *domain.SigningKey
+	for i, key := range s.keys {
+		if key.StateAt(now) != domain.KeyActive {
+			continue
+		}
+		if current == nil || key.ActivatesAt.After(current.ActivatesAt) {
+			current = &s.keys[i]
+		}
+	}
+	if current == nil {
+		return nil, fmt.Errorf("%w: no active key", domain.ErrSigningKeyNotFound)
+	}
+	key := *current
+	return &key, nil
+}
+
+// Keyfunc resolves the verification key of a token by kid. The token's alg must
+// match the key's algorithm, so a public key can never be abused as an HMAC secret.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, key := range s.keys {
+		if key.Algorithm != token.Method.Alg() {
+			continue
+		}
+		if key.KID == kid || (kid == "" && key.Symmetric()) {
+			return key.VerificationKey(), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: kid '%s' alg '%s'", domain.ErrSigningKeyNotFound, kid, token.Method.Alg())
+}
+
+// Parse verifies a token signed by one of our keys and returns its claims.
+func (s *KeySet) Parse(tokenString string) (*domain.JwtClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, new(domain.JwtClaims), s.Keyfunc)
+	if err != nil {
+		return nil, err
+	}
+	return token.Claims.(*domain.JwtClaims), nil
+}
+
+// JWKS publishes the public half of every asymmetric key.
+func (s *KeySet) JWKS() domain.JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	set := domain.JWKS{Keys: make([]domain.JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		if key.Symmetric() {
+			continue
+		}
+		jwk, err := domain.NewJWK(key.VerificationKey())
+		if err != nil {
+			// only keys accepted by newAsymmetricSigningKey end up here
+			continue
+		}
+		jwk.Kid, jwk.Use, jwk.Alg = key.KID, "sig", key.Algorithm
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,206 @@
+package usecase_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper function to PEM encode a freshly generated private key
+func pkcs8PEM(t *testing.T, key interface{}) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func testClaims() domain.JwtClaims {
+	now := time.Now()
+	return domain.JwtClaims{
+		Scope: string(domain.PermAll),
+		Type:  domain.UserType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Subject:   "subject",
+		},
+	}
+}
+
+func TestParseSigningKeyPEM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name string
+		pem  []byte
+		alg  string
+	}{
+		{"RSA PKCS#8", pkcs8PEM(t, rsaKey), domain.AlgRS256},
+		{"RSA PKCS#1", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), domain.AlgRS256},
+		{"EC P-256", pkcs8PEM(t, ecKey), domain.AlgES256},
+		{"Ed25519", pkcs8PEM(t, edKey), domain.AlgEdDSA},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := usecase.ParseSigningKeyPEM(tc.pem)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.alg, key.Algorithm)
+			assert.NotEmpty(t, key.KID)
+		})
+	}
+
+	t.Run("P-384 is rejected", func(t *testing.T) {
+		p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		_, err = usecase.ParseSigningKeyPEM(pkcs8PEM(t, p384))
+
+		assert.ErrorIs(t, err, domain.ErrUnsupportedKey)
+	})
+
+	t.Run("not PEM", func(t *testing.T) {
+		_, err := usecase.ParseSigningKeyPEM([]byte("garbage"))
+
+		assert.ErrorIs(t, err, domain.ErrUnsupportedKey)
+	})
+}
+
+func TestGenerateSigningKey(t *testing.T) {
+	for _, alg := range []string{domain.AlgRS256, domain.AlgES256, domain.AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			key, err := usecase.GenerateSigningKey(alg)
+			require.NoError(t, err)
+			assert.Equal(t, alg, key.Algorithm)
+
+			// the generated key is published, unlike the HS256 secret
+			jwks := usecase.NewKeySet(*key).JWKS()
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, key.KID, jwks.Keys[0].Kid)
+		})
+	}
+
+	t.Run("HS256 is not generated", func(t *testing.T) {
+		_, err := usecase.GenerateSigningKey(domain.AlgHS256)
+
+		assert.ErrorIs(t, err, domain.ErrUnsupportedKey)
+	})
+}
+
+func TestKeySetSignAndVerify(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	for _, raw := range []interface{}{ecKey, edKey} {
+		key, err := usecase.ParseSigningKeyPEM(pkcs8PEM(t, raw))
+		require.NoError(t, err)
+		keys := usecase.NewKeySet(*key, usecase.NewHMACSigningKey("legacy"))
+
+		t.Run(key.Algorithm, func(t *testing.T) {
+			signed, err := keys.Sign(testClaims())
+			require.NoError(t, err)
+
+			token, err := jwt.ParseWithClaims(signed, new(domain.JwtClaims), keys.Keyfunc)
+			require.NoError(t, err)
+			assert.Equal(t, key.KID, token.Header["kid"])
+			assert.Equal(t, "subject", token.Claims.(*domain.JwtClaims).Subject)
+
+			// a resource server holding only the published JWKS can verify too
+			jwks := keys.JWKS()
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, key.KID, jwks.Keys[0].Kid)
+			pub, err := jwks.Keys[0].PublicKey()
+			require.NoError(t, err)
+			_, err = jwt.ParseWithClaims(signed, new(domain.JwtClaims), func(*jwt.Token) (interface{}, error) { return pub, nil })
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestKeySetLegacyHS256(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	key, err := usecase.ParseSigningKeyPEM(pkcs8PEM(t, ecKey))
+	require.NoError(t, err)
+
+	// tokens minted before the switch carry no kid
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims()).SignedString([]byte("legacy"))
+	require.NoError(t, err)
+
+	keys := usecase.NewKeySet(*key, usecase.NewHMACSigningKey("legacy"))
+	_, err = jwt.ParseWithClaims(legacy, new(domain.JwtClaims), keys.Keyfunc)
+	assert.NoError(t, err)
+
+	onlyLegacy := usecase.NewKeySet(usecase.NewHMACSigningKey("legacy"))
+	signed, err := onlyLegacy.Sign(testClaims())
+	require.NoError(t, err)
+	token, err := jwt.ParseWithClaims(signed, new(domain.JwtClaims), onlyLegacy.Keyfunc)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AlgHS256, token.Method.Alg())
+	assert.Equal(t, usecase.LegacyHMACKeyID, token.Header["kid"])
+	assert.Empty(t, onlyLegacy.JWKS().Keys)
+}
+
+func TestKeySetRejectsUnknownKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := usecase.ParseSigningKeyPEM(pkcs8PEM(t, rsaKey))
+	require.NoError(t, err)
+	keys := usecase.NewKeySet(*key)
+
+	t.Run("unknown kid", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, testClaims())
+		token.Header["kid"] = "unknown"
+		signed, err := token.SignedString(other)
+		require.NoError(t, err)
+
+		_, err = jwt.ParseWithClaims(signed, new(domain.JwtClaims), keys.Keyfunc)
+
+		assert.ErrorIs(t, err, domain.ErrSigningKeyNotFound)
+	})
+
+	t.Run("HS256 with the public key as secret", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+		token.Header["kid"] = key.KID
+		signed, err := token.SignedString(x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey))
+		require.NoError(t, err)
+
+		_, err = jwt.ParseWithClaims(signed, new(domain.JwtClaims), keys.Keyfunc)
+
+		assert.ErrorIs(t, err, domain.ErrSigningKeyNotFound)
+	})
+}
+
+func TestJWKThumbprint(t *testing.T) {
+	// example from RFC 7638 section 3.1
+	jwk := domain.JWK{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+
+	thumbprint, err := jwk.Thumbprint()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+}
@@ -0,0 +1,353 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/repository/mfa"
+	"github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/google/uuid"
+)
+
+// TOTP parameters, the defaults of RFC 6238 that every authenticator app supports.
+const (
+	totpDigits = 6
+	totpPeriod = 30 * time.Second
+	// totpSkew is how many steps a code may be off, for clocks that drift
+	totpSkew = 1
+	// totpSecretSize is the size of the shared key, the 160 bits RFC 4226 recommends for HMAC-SHA1
+	totpSecretSize = 20
+
+	recoveryCodeCount = 10
+)
+
+// MFAUseCase is the second factor of user logins: TOTP codes of an authenticator app, single-use
+// recovery codes when the app is lost, or a registered WebAuthn credential. A login whose password was
+// verified becomes a challenge that is answered with one of them, failed answers are throttled like
+// failed passwords.
+type MFAUseCase struct {
+	repo         mfa.IMFARepository
+	userRepo     user.IUserRepository
+	throttle     *LoginThrottleUseCase
+	webauthn     *WebAuthnUseCase
+	issuer       string
+	challengeTTL time.Duration
+}
+
+func NewMFAUseCase(repo mfa.IMFARepository, userRepo user.IUserRepository, throttle *LoginThrottleUseCase, webauthn *WebAuthnUseCase, issuer string, challengeTTL time.Duration) *MFAUseCase {
+	return &MFAUseCase{repo: repo, userRepo: userRepo, throttle: throttle, webauthn: webauthn, issuer: issuer, challengeTTL: challengeTTL}
+}
+
+// mfaLoginKey is the key failed answers to the challenges of a user are counted under.
+func mfaLoginKey(userID uuid.UUID) string { return "mfa:" + userID.String() }
+
+// EnrollTOTP generates a new TOTP secret of the user and returns it base32 encoded together with its
+// otpauth URI, which authenticator apps read from a QR code. The factor is only used once confirmed.
+func (u *MFAUseCase) EnrollTOTP(ctx context.Context, user *domain.User) (string, string, error) {
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	if _, err := u.repo.CreateTOTP(ctx, user.ID, secret); err != nil {
+		return "", "", err
+	}
+
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+	query := url.Values{}
+	query.Set("secret", encoded)
+	query.Set("issuer", u.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+	// authenticator apps do not all read + as a space, which the Key Uri Format spells %20
+	uri := url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + u.issuer + ":" + user.Account, RawQuery: strings.ReplaceAll(query.Encode(), "+", "%20")}
+	return encoded, uri.String(), nil
+}
+
+// ConfirmTOTP turns on the factor of the user with a first code of the app, proving the secret was
+// stored. It returns the recovery codes, they are shown this once and only their hashes are kept.
+func (u *MFAUseCase) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	totp, err := u.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if totp.ConfirmedAt != nil {
+		return nil, domain.ErrMFAAlreadyEnrolled
+	}
+	step, ok := matchTOTP(totp, code, time.Now())
+	if !ok {
+		return nil, domain.ErrMFACodeInvalid
+	}
+	if err := u.repo.UseTOTPStep(ctx, userID, step); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([][]byte, recoveryCodeCount)
+	for i := range codes {
+		if codes[i], err = newRecoveryCode(); err != nil {
+			return nil, err
+		}
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	if err := u.repo.ConfirmTOTP(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// StartChallenge returns the token of a challenge for the second factor of the login together with the
+// methods it can be answered with, or "" when the user has no confirmed factor and the password is
+// enough. mode is the login mode the user asked for.
+func (u *MFAUseCase) StartChallenge(ctx context.Context, user *domain.User, mode string, authTime time.Time) (string, []string, error) {
+	methods, err := u.methods(ctx, user.ID)
+	if err != nil || len(methods) == 0 {
+		return "", nil, err
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := u.repo.CreateMFAChallenge(ctx, &domain.MFAChallenge{
+		ChallengeHash: hashToken(token),
+		UserID:        user.ID,
+		Mode:          mode,
+		AuthTime:      authTime,
+		ExpiresAt:     time.Now().Add(u.challengeTTL),
+	}); err != nil {
+		return "", nil, err
+	}
+	return token, methods, nil
+}
+
+// methods returns the second factors the user has: a confirmed TOTP factor, whose recovery codes come
+// with it, and registered WebAuthn credentials.
+func (u *MFAUseCase) methods(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var methods []string
+	totp, err := u.repo.GetTOTP(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrMFANotEnrolled) {
+		return nil, err
+	}
+	if err == nil && totp.ConfirmedAt != nil {
+		methods = append(methods, domain.MFAMethodTOTP)
+	}
+
+	hasCredentials, err := u.webauthn.HasCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if hasCredentials {
+		methods = append(methods, domain.MFAMethodWebAuthn)
+	}
+	return methods, nil
+}
+
+// VerifyChallenge answers the challenge of the token with a TOTP or recovery code, ip is where the
+// answer comes from. The challenge is answered once, and while the user or the ip is locked out the code
+// is not even checked and a LoginThrottledError is returned.
+func (u *MFAUseCase) VerifyChallenge(ctx context.Context, token string, code string, ip string) (*domain.User, *domain.MFAChallenge, error) {
+	return u.answerChallenge(ctx, token, ip, domain.ErrMFACodeInvalid, func(userID uuid.UUID) error {
+		return u.verifyCode(ctx, userID, code)
+	})
+}
+
+// BeginWebAuthnChallenge returns the options of navigator.credentials.get() that answer the challenge of
+// the token, only the credentials of its user are allowed.
+func (u *MFAUseCase) BeginWebAuthnChallenge(ctx context.Context, token string) (*domain.WebAuthnRequestOptions, error) {
+	challenge, err := u.repo.GetMFAChallengeByHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if !challenge.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrMFAChallengeInvalid
+	}
+	return u.webauthn.BeginLogin(ctx, &challenge.UserID)
+}
+
+// VerifyWebAuthnChallenge answers the challenge of the token with an assertion of a credential of its
+// user, throttled like VerifyChallenge.
+func (u *MFAUseCase) VerifyWebAuthnChallenge(ctx context.Context, token string, assertion *domain.WebAuthnAssertion, ip string) (*domain.User, *domain.MFAChallenge, error) {
+	return u.answerChallenge(ctx, token, ip, domain.ErrWebAuthnInvalid, func(userID uuid.UUID) error {
+		_, err := u.webauthn.VerifyAssertion(ctx, &userID, assertion)
+		return err
+	})
+}
+
+// answerChallenge runs verify for the user of the challenge unless the user or the ip is locked out,
+// failures wrapping invalid are counted.
+func (u *MFAUseCase) answerChallenge(ctx context.Context, token string, ip string, invalid error, verify func(userID uuid.UUID) error) (*domain.User, *domain.MFAChallenge, error) {
+	challenge, err := u.repo.GetMFAChallengeByHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !challenge.ExpiresAt.After(time.Now()) {
+		return nil, nil, domain.ErrMFAChallengeInvalid
+	}
+
+	keys := loginKeys(mfaLoginKey(challenge.UserID), ip)
+	retryAfter, err := u.throttle.Check(ctx, keys...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if retryAfter > 0 {
+		return nil, nil, &domain.LoginThrottledError{RetryAfter: retryAfter, Err: invalid}
+	}
+
+	if err := verify(challenge.UserID); err != nil {
+		if errors.Is(err, invalid) {
+			return nil, nil, u.throttle.failed(ctx, err, keys...)
+		}
+		return nil, nil, err
+	}
+	if err := u.repo.DeleteMFAChallenge(ctx, challenge.ChallengeHash); err != nil {
+		return nil, nil, err
+	}
+	if err := u.throttle.Reset(ctx, mfaLoginKey(challenge.UserID)); err != nil {
+		return nil, nil, err
+	}
+
+	user, err := u.userRepo.GetUserByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, challenge, nil
+}
+
+// verifyCode accepts a TOTP code of the confirmed factor, or else spends a recovery code.
+func (u *MFAUseCase) verifyCode(ctx context.Context, userID uuid.UUID, code string) error {
+	code = strings.TrimSpace(code)
+	if !isTOTPCode(code) {
+		return u.repo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+	}
+
+	totp, err := u.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) {
+			return domain.ErrMFACodeInvalid
+		}
+		return err
+	}
+	if totp.ConfirmedAt == nil {
+		return domain.ErrMFACodeInvalid
+	}
+	step, ok := matchTOTP(totp, code, time.Now())
+	if !ok {
+		return domain.ErrMFACodeInvalid
+	}
+	// the step is recorded atomically, a code intercepted or replayed within its window is refused
+	return u.repo.UseTOTPStep(ctx, userID, step)
+}
+
+// ResetMFA removes the factor, the recovery codes and the WebAuthn credentials of the user, who signs in
+// with the password only until enrolling again. ErrMFANotEnrolled is returned when there was none of them.
+func (u *MFAUseCase) ResetMFA(ctx context.Context, userID uuid.UUID) error {
+	deleted, err := u.webauthn.DeleteCredentials(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := u.repo.DeleteMFA(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) && deleted > 0 {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Run periodically deletes the challenges that expired unanswered.
+func (u *MFAUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := u.repo.DeleteExpiredMFAChallenges(ctx); err != nil {
+				log.Printf("mfa challenge purge failed: %v", err)
+			}
+		}
+	}
+}
+
+// GenerateTOTP returns the code of the secret at the given time (RFC 6238).
+func GenerateTOTP(secret []byte, at time.Time) string {
+	return hotp(secret, at.Unix()/int64(totpPeriod.Seconds()))
+}
+
+// hotp is the HMAC-SHA1 one-time password of the counter with dynamic truncation (RFC 4226 section 5.3).
+func hotp(secret []byte, counter int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulo := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		modulo *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%modulo)
+}
+
+// matchTOTP returns the time step the code belongs to, steps not after the last used one never match.
+func matchTOTP(totp *domain.TOTP, code string, now time.Time) (int64, bool) {
+	current := now.Unix() / int64(totpPeriod.Seconds())
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= totp.LastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hotp(totp.Secret, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totpDigits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecoveryCode returns 80 bits of randomness as four dash-separated groups, easy to write down.
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	encoded := strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+	return encoded[0:4] + "-" + encoded[4:8] + "-" + encoded[8:12] + "-" + encoded[12:16], nil
+}
+
+// hashRecoveryCode hashes the code as typed by the user, case and separators aside.
+func hashRecoveryCode(code string) []byte {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+	return hashToken(normalized)
+}
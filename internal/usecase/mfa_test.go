@@ -0,0 +1,289 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	attemptRepo "github.com/bright-pentium/go-client-practice/internal/repository/loginattempt"
+	mfaRepo "github.com/bright-pentium/go-client-practice/internal/repository/mfa"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	webauthnRepo "github.com/bright-pentium/go-client-practice/internal/repository/webauthn"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testTOTPSecret = []byte("12345678901234567890")
+
+func TestGenerateTOTP(t *testing.T) {
+	// RFC 6238 appendix B, SHA1 vectors truncated to 6 digits
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for at, code := range vectors {
+		assert.Equal(t, code, usecase.GenerateTOTP(testTOTPSecret, time.Unix(at, 0)), at)
+	}
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	ctx := context.Background()
+	user := &domain.User{ID: uuid.New(), Account: "alice"}
+	mockMFARepo := new(mfaRepo.MockMFARepository)
+	mockMFARepo.On("CreateTOTP", mock.Anything, user.ID, mock.AnythingOfType("[]uint8")).Return(&domain.TOTP{UserID: user.ID}, nil)
+	useCase := usecase.NewMFAUseCase(mockMFARepo, nil, testThrottle(), testWebAuthn(), "ClientApp", time.Minute)
+
+	secret, uri, err := useCase.EnrollTOTP(ctx, user)
+
+	require.NoError(t, err)
+	stored := mockMFARepo.Calls[0].Arguments.Get(2).([]byte)
+	assert.Len(t, stored, 20)
+	assert.Equal(t, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(stored), secret)
+
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/ClientApp:alice", parsed.Path)
+	assert.Equal(t, url.Values{
+		"secret": {secret}, "issuer": {"ClientApp"}, "algorithm": {"SHA1"}, "digits": {"6"}, "period": {"30"},
+	}, parsed.Query())
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("first code confirms and returns recovery codes", func(t *testing.T) {
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, userID).Return(&domain.TOTP{UserID: userID, Secret: testTOTPSecret}, nil)
+		mockMFARepo.On("UseTOTPStep", mock.Anything, userID, mock.AnythingOfType("int64")).Return(nil)
+		mockMFARepo.On("ConfirmTOTP", mock.Anything, userID, mock.AnythingOfType("[][]uint8")).Return(nil)
+		useCase := usecase.NewMFAUseCase(mockMFARepo, nil, testThrottle(), testWebAuthn(), "ClientApp", time.Minute)
+
+		codes, err := useCase.ConfirmTOTP(ctx, userID, usecase.GenerateTOTP(testTOTPSecret, time.Now()))
+
+		require.NoError(t, err)
+		assert.Len(t, codes, 10)
+		hashes := mockMFARepo.Calls[2].Arguments.Get(2).([][]byte)
+		assert.Len(t, hashes, 10)
+		for i, code := range codes {
+			assert.Regexp(t, `^[a-z2-7]{4}(-[a-z2-7]{4}){3}$`, code)
+			assert.NotContains(t, string(hashes[i]), code, "only hashes are stored")
+		}
+		mockMFARepo.AssertExpectations(t)
+	})
+
+	t.Run("wrong code is refused", func(t *testing.T) {
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, userID).Return(&domain.TOTP{UserID: userID, Secret: testTOTPSecret}, nil)
+		useCase := usecase.NewMFAUseCase(mockMFARepo, nil, testThrottle(), testWebAuthn(), "ClientApp", time.Minute)
+
+		codes, err := useCase.ConfirmTOTP(ctx, userID, usecase.GenerateTOTP(testTOTPSecret, time.Now().Add(-time.Hour)))
+
+		assert.Nil(t, codes)
+		assert.ErrorIs(t, err, domain.ErrMFACodeInvalid)
+		mockMFARepo.AssertNotCalled(t, "ConfirmTOTP", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("confirmed factor is not confirmed again", func(t *testing.T) {
+		confirmedAt := time.Now()
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, userID).Return(&domain.TOTP{UserID: userID, Secret: testTOTPSecret, ConfirmedAt: &confirmedAt}, nil)
+		useCase := usecase.NewMFAUseCase(mockMFARepo, nil, testThrottle(), testWebAuthn(), "ClientApp", time.Minute)
+
+		_, err := useCase.ConfirmTOTP(ctx, userID, usecase.GenerateTOTP(testTOTPSecret, time.Now()))
+
+		assert.ErrorIs(t, err, domain.ErrMFAAlreadyEnrolled)
+	})
+}
+
+func TestStartMFAChallenge(t *testing.T) {
+	ctx := context.Background()
+	user := &domain.User{ID: uuid.New(), Account: "alice"}
+	authTime := time.Now()
+
+	t.Run("not enrolled needs no challenge", func(t *testing.T) {
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, user.ID).Return(nil, domain.ErrMFANotEnrolled)
+		useCase := usecase.NewMFAUseCase(mockMFARepo, nil, testThrottle(), testWebAuthn(), "ClientApp", time.Minute)
+
+		token, methods, err := useCase.StartChallenge(ctx, user, "token", authTime)
+
+		require.NoError(t, err)
+		assert.Empty(t, token)
+		assert.Empty(t, methods)
+	})
+
+	t.Run("unconfirmed factor needs no challenge", func(t *testing.T) {
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, user.ID).Return(&domain.TOTP{UserID: user.ID, Secret: testTOTPSecret}, nil)
+		useCase := usecase.NewMFAUseCase(mockMFARepo, nil, testThrottle(), testWebAuthn(), "ClientApp", time.Minute)
+
+		token, methods, err := useCase.StartChallenge(ctx, user, "token", authTime)
+
+		require.NoError(t, err)
+		assert.Empty(t, token)
+		assert.Empty(t, methods)
+		mockMFARepo.AssertNotCalled(t, "CreateMFAChallenge", mock.Anything, mock.Anything)
+	})
+
+	t.Run("confirmed factor starts a challenge", func(t *testing.T) {
+		confirmedAt := time.Now()
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, user.ID).Return(&domain.TOTP{UserID: user.ID, Secret: testTOTPSecret, ConfirmedAt: &confirmedAt}, nil)
+		mockMFARepo.On("CreateMFAChallenge", mock.Anything, mock.MatchedBy(func(challenge *domain.MFAChallenge) bool {
+			return challenge.UserID == user.ID && challenge.Mode == "cookie" && challenge.AuthTime.Equal(authTime) &&
+				challenge.ExpiresAt.After(time.Now()) && len(challenge.ChallengeHash) == 32
+		})).Return(nil)
+		useCase := usecase.NewMFAUseCase(mockMFARepo, nil, testThrottle(), testWebAuthn(), "ClientApp", time.Minute)
+
+		token, methods, err := useCase.StartChallenge(ctx, user, "cookie", authTime)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, []string{domain.MFAMethodTOTP}, methods)
+		mockMFARepo.AssertExpectations(t)
+	})
+
+	t.Run("webauthn credential starts a challenge", func(t *testing.T) {
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockWebAuthnRepo := new(webauthnRepo.MockWebAuthnRepository)
+		mockMFARepo.On("GetTOTP", mock.Anything, user.ID).Return(nil, domain.ErrMFANotEnrolled)
+		mockMFARepo.On("CreateMFAChallenge", mock.Anything, mock.Anything).Return(nil)
+		mockWebAuthnRepo.On("ListCredentialsByUser", mock.Anything, user.ID).Return([]domain.WebAuthnCredential{{ID: []byte("credential"), UserID: user.ID}}, nil)
+		passkeys := usecase.NewWebAuthnUseCase(mockWebAuthnRepo, nil, testThrottle(), testRelyingParty, []string{testOrigin}, time.Minute)
+		useCase := usecase.NewMFAUseCase(mockMFARepo, nil, testThrottle(), passkeys, "ClientApp", time.Minute)
+
+		token, methods, err := useCase.StartChallenge(ctx, user, "token", authTime)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, []string{domain.MFAMethodWebAuthn}, methods)
+	})
+}
+
+func TestVerifyMFAChallenge(t *testing.T) {
+	ctx := context.Background()
+	confirmedAt := time.Now()
+	user := &domain.User{ID: uuid.New(), Account: "alice"}
+	challenge := &domain.MFAChallenge{ChallengeHash: []byte("hash"), UserID: user.ID, Mode: "token", AuthTime: time.Now(), ExpiresAt: time.Now().Add(time.Minute)}
+	totp := &domain.TOTP{UserID: user.ID, Secret: testTOTPSecret, ConfirmedAt: &confirmedAt}
+
+	newUseCase := func() (*usecase.MFAUseCase, *mfaRepo.MockMFARepository) {
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockMFARepo.On("GetMFAChallengeByHash", mock.Anything, mock.Anything).Return(challenge, nil)
+		mockMFARepo.On("GetTOTP", mock.Anything, user.ID).Return(totp, nil)
+		mockMFARepo.On("DeleteMFAChallenge", mock.Anything, challenge.ChallengeHash).Return(nil)
+		mockUserRepo.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+		return usecase.NewMFAUseCase(mockMFARepo, mockUserRepo, testThrottle(), testWebAuthn(), "ClientApp", time.Minute), mockMFARepo
+	}
+
+	t.Run("totp code answers the challenge", func(t *testing.T) {
+		useCase, mockMFARepo := newUseCase()
+		mockMFARepo.On("UseTOTPStep", mock.Anything, user.ID, mock.AnythingOfType("int64")).Return(nil)
+
+		got, answered, err := useCase.VerifyChallenge(ctx, "token", usecase.GenerateTOTP(testTOTPSecret, time.Now()), "203.0.113.7")
+
+		require.NoError(t, err)
+		assert.Equal(t, user, got)
+		assert.Equal(t, challenge, answered)
+		mockMFARepo.AssertExpectations(t)
+	})
+
+	t.Run("replayed totp code is refused", func(t *testing.T) {
+		useCase, mockMFARepo := newUseCase()
+		mockMFARepo.On("UseTOTPStep", mock.Anything, user.ID, mock.AnythingOfType("int64")).Return(domain.ErrMFACodeInvalid)
+
+		_, _, err := useCase.VerifyChallenge(ctx, "token", usecase.GenerateTOTP(testTOTPSecret, time.Now()), "203.0.113.7")
+
+		assert.ErrorIs(t, err, domain.ErrMFACodeInvalid)
+		mockMFARepo.AssertNotCalled(t, "DeleteMFAChallenge", mock.Anything, mock.Anything)
+	})
+
+	t.Run("recovery code is matched by its hash", func(t *testing.T) {
+		useCase, mockMFARepo := newUseCase()
+		var hashes [][]byte
+		mockMFARepo.On("UseRecoveryCode", mock.Anything, user.ID, mock.AnythingOfType("[]uint8")).Run(func(args mock.Arguments) {
+			hashes = append(hashes, args.Get(2).([]byte))
+		}).Return(nil)
+
+		_, _, err := useCase.VerifyChallenge(ctx, "token", "abcd-efgh-ijkl-mnop", "")
+		require.NoError(t, err)
+		_, _, err = useCase.VerifyChallenge(ctx, "token", " ABCD EFGH IJKL MNOP ", "")
+		require.NoError(t, err)
+
+		require.Len(t, hashes, 2)
+		assert.Equal(t, hashes[0], hashes[1], "case and separators do not matter")
+		assert.NotContains(t, string(hashes[0]), "abcd")
+	})
+
+	t.Run("used recovery code counts as a failure", func(t *testing.T) {
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+		mockMFARepo.On("GetMFAChallengeByHash", mock.Anything, mock.Anything).Return(challenge, nil)
+		mockMFARepo.On("UseRecoveryCode", mock.Anything, user.ID, mock.Anything).Return(domain.ErrMFACodeInvalid)
+		mockAttemptRepo.On("ListLoginAttempts", mock.Anything, mock.Anything).Return([]domain.LoginAttempt{}, nil)
+		mockAttemptRepo.On("RecordLoginFailure", mock.Anything, "mfa:"+user.ID.String(), mock.Anything).Return(&domain.LoginAttempt{Failures: 1}, nil)
+		mockAttemptRepo.On("RecordLoginFailure", mock.Anything, "ip:203.0.113.7", mock.Anything).Return(&domain.LoginAttempt{Failures: 1}, nil)
+		useCase := usecase.NewMFAUseCase(mockMFARepo, nil, usecase.NewLoginThrottleUseCase(mockAttemptRepo, testThrottlePolicy), testWebAuthn(), "ClientApp", time.Minute)
+
+		_, _, err := useCase.VerifyChallenge(ctx, "token", "abcd-efgh-ijkl-mnop", "203.0.113.7")
+
+		assert.ErrorIs(t, err, domain.ErrMFACodeInvalid)
+		mockAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("locked out answers are not checked", func(t *testing.T) {
+		until := time.Now().Add(time.Minute)
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+		mockMFARepo.On("GetMFAChallengeByHash", mock.Anything, mock.Anything).Return(challenge, nil)
+		mockAttemptRepo.On("ListLoginAttempts", mock.Anything, []string{"mfa:" + user.ID.String()}).
+			Return([]domain.LoginAttempt{{Key: "mfa:" + user.ID.String(), Failures: 3, LockedUntil: &until}}, nil)
+		useCase := usecase.NewMFAUseCase(mockMFARepo, nil, usecase.NewLoginThrottleUseCase(mockAttemptRepo, testThrottlePolicy), testWebAuthn(), "ClientApp", time.Minute)
+
+		_, _, err := useCase.VerifyChallenge(ctx, "token", usecase.GenerateTOTP(testTOTPSecret, time.Now()), "")
+
+		var throttled *domain.LoginThrottledError
+		require.ErrorAs(t, err, &throttled)
+		assert.ErrorIs(t, err, domain.ErrMFACodeInvalid)
+		mockMFARepo.AssertNotCalled(t, "GetTOTP", mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired challenge is refused", func(t *testing.T) {
+		expired := *challenge
+		expired.ExpiresAt = time.Now().Add(-time.Second)
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockMFARepo.On("GetMFAChallengeByHash", mock.Anything, mock.Anything).Return(&expired, nil)
+		useCase := usecase.NewMFAUseCase(mockMFARepo, nil, testThrottle(), testWebAuthn(), "ClientApp", time.Minute)
+
+		_, _, err := useCase.VerifyChallenge(ctx, "token", usecase.GenerateTOTP(testTOTPSecret, time.Now()), "")
+
+		assert.ErrorIs(t, err, domain.ErrMFAChallengeInvalid)
+	})
+
+	t.Run("challenge is answered once", func(t *testing.T) {
+		mockMFARepo := new(mfaRepo.MockMFARepository)
+		mockMFARepo.On("GetMFAChallengeByHash", mock.Anything, mock.Anything).Return(challenge, nil)
+		mockMFARepo.On("UseRecoveryCode", mock.Anything, user.ID, mock.Anything).Return(nil)
+		mockMFARepo.On("DeleteMFAChallenge", mock.Anything, challenge.ChallengeHash).Return(domain.ErrMFAChallengeInvalid)
+		useCase := usecase.NewMFAUseCase(mockMFARepo, nil, testThrottle(), testWebAuthn(), "ClientApp", time.Minute)
+
+		_, _, err := useCase.VerifyChallenge(ctx, "token", strings.Repeat("a", 16), "")
+
+		assert.ErrorIs(t, err, domain.ErrMFAChallengeInvalid)
+	})
+}
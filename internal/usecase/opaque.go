@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/repository/opaque"
+)
+
+// OpaqueTokenUseCase issues opaque access tokens and resolves them back to their claims. Resolved
+// tokens are cached for cacheTTL, which bounds how long a revocation made by another replica takes
+// to be seen here; revocations made by this one take effect at once.
+type OpaqueTokenUseCase struct {
+	repo     opaque.IOpaqueTokenRepository
+	cache    *ttlCache[string, *domain.JwtClaims]
+	cacheTTL time.Duration
+}
+
+func NewOpaqueTokenUseCase(repo opaque.IOpaqueTokenRepository, cacheTTL time.Duration) *OpaqueTokenUseCase {
+	return &OpaqueTokenUseCase{repo: repo, cache: newTTLCache[string, *domain.JwtClaims](), cacheTTL: cacheTTL}
+}
+
+// IssueOpaqueToken stores the claims under the hash of a new random token and returns the token.
+func (u *OpaqueTokenUseCase) IssueOpaqueToken(ctx context.Context, claims domain.JwtClaims) (string, error) {
+	if claims.ExpiresAt == nil {
+		return "", fmt.Errorf("%w: opaque tokens must expire", domain.ErrGeneralOpaqueToken)
+	}
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = u.repo.CreateOpaqueToken(ctx, &domain.OpaqueToken{
+		TokenHash: hashToken(token),
+		Subject:   claims.Subject,
+		Type:      claims.Type,
+		Scope:     claims.Scope,
+		Claims:    claims,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ResolveOpaqueToken returns the claims of a live opaque token, ErrInvalidAccessToken when it is
+// unknown, expired or revoked.
+func (u *OpaqueTokenUseCase) ResolveOpaqueToken(ctx context.Context, token string) (*domain.JwtClaims, error) {
+	key := string(hashToken(token))
+	if claims, ok := u.cache.Get(key); ok {
+		return claims, nil
+	}
+
+	stored, err := u.repo.GetOpaqueTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrOpaqueTokenNotFound) {
+			return nil, fmt.Errorf("%w: unknown token", domain.ErrInvalidAccessToken)
+		}
+		return nil, err
+	}
+	if stored.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidAccessToken, domain.ErrTokenRevoked.Error())
+	}
+	if !time.Now().Before(stored.ExpiresAt) {
+		return nil, fmt.Errorf("%w: token is expired", domain.ErrInvalidAccessToken)
+	}
+
+	expiresAt := time.Now().Add(u.cacheTTL)
+	if stored.ExpiresAt.Before(expiresAt) {
+		expiresAt = stored.ExpiresAt
+	}
+	u.cache.Set(key, &stored.Claims, expiresAt)
+	return &stored.Claims, nil
+}
+
+// RevokeOpaqueToken revokes the token, unknown tokens are ignored.
+func (u *OpaqueTokenUseCase) RevokeOpaqueToken(ctx context.Context, token string) error {
+	if err := u.repo.RevokeOpaqueToken(ctx, hashToken(token)); err != nil {
+		return err
+	}
+	u.cache.Delete(string(hashToken(token)))
+	return nil
+}
+
+// Run periodically deletes expired tokens and drops them from the cache.
+func (u *OpaqueTokenUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			u.cache.Purge()
+			if _, err := u.repo.DeleteExpiredOpaqueTokens(ctx); err != nil {
+				log.Printf("opaque token purge failed: %v", err)
+			}
+		}
+	}
+}
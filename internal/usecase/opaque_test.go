@@ -0,0 +1,189 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	opaqueRepo "github.com/bright-pentium/go-client-practice/internal/repository/opaque"
+	revocationRepo "github.com/bright-pentium/go-client-practice/internal/repository/revocation"
+	sessionRepo "github.com/bright-pentium/go-client-practice/internal/repository/session"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpaqueToken(t *testing.T) {
+	ctx := context.Background()
+	hash := func(token string) []byte {
+		sum := sha256.Sum256([]byte(token))
+		return sum[:]
+	}
+	stored := func(token string, claims domain.JwtClaims) *domain.OpaqueToken {
+		return &domain.OpaqueToken{TokenHash: hash(token), Subject: claims.Subject, Type: claims.Type, Scope: claims.Scope, Claims: claims, ExpiresAt: claims.ExpiresAt.Time}
+	}
+
+	t.Run("only the hash is stored", func(t *testing.T) {
+		mockRepo := new(opaqueRepo.MockOpaqueTokenRepository)
+		uc := usecase.NewOpaqueTokenUseCase(mockRepo, time.Minute)
+		var captured *domain.OpaqueToken
+		mockRepo.
+			On("CreateOpaqueToken", ctx, mock.AnythingOfType("*domain.OpaqueToken")).
+			Run(func(args mock.Arguments) { captured = args.Get(1).(*domain.OpaqueToken) }).
+			Return(&domain.OpaqueToken{}, nil)
+
+		token, err := uc.IssueOpaqueToken(ctx, testClaims())
+
+		require.NoError(t, err)
+		assert.False(t, usecase.IsJWT(token))
+		assert.Equal(t, hash(token), captured.TokenHash)
+		assert.Equal(t, "subject", captured.Subject)
+		assert.Equal(t, domain.UserType, captured.Type)
+		assert.Equal(t, string(domain.PermAll), captured.Scope)
+	})
+
+	t.Run("resolved tokens are cached", func(t *testing.T) {
+		mockRepo := new(opaqueRepo.MockOpaqueTokenRepository)
+		uc := usecase.NewOpaqueTokenUseCase(mockRepo, time.Minute)
+		mockRepo.On("GetOpaqueTokenByHash", ctx, hash("token")).Return(stored("token", testClaims()), nil).Once()
+
+		for i := 0; i < 2; i++ {
+			claims, err := uc.ResolveOpaqueToken(ctx, "token")
+			require.NoError(t, err)
+			assert.Equal(t, "subject", claims.Subject)
+		}
+		mockRepo.AssertNumberOfCalls(t, "GetOpaqueTokenByHash", 1)
+	})
+
+	t.Run("revocation drops the cached token", func(t *testing.T) {
+		mockRepo := new(opaqueRepo.MockOpaqueTokenRepository)
+		uc := usecase.NewOpaqueTokenUseCase(mockRepo, time.Minute)
+		mockRepo.On("GetOpaqueTokenByHash", ctx, hash("token")).Return(stored("token", testClaims()), nil).Once()
+		mockRepo.On("RevokeOpaqueToken", ctx, hash("token")).Return(nil)
+		revoked := stored("token", testClaims())
+		now := time.Now()
+		revoked.RevokedAt = &now
+		mockRepo.On("GetOpaqueTokenByHash", ctx, hash("token")).Return(revoked, nil).Once()
+
+		_, err := uc.ResolveOpaqueToken(ctx, "token")
+		require.NoError(t, err)
+		require.NoError(t, uc.RevokeOpaqueToken(ctx, "token"))
+		_, err = uc.ResolveOpaqueToken(ctx, "token")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidAccessToken)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		mockRepo := new(opaqueRepo.MockOpaqueTokenRepository)
+		uc := usecase.NewOpaqueTokenUseCase(mockRepo, time.Minute)
+		mockRepo.On("GetOpaqueTokenByHash", ctx, hash("token")).Return(nil, domain.ErrOpaqueTokenNotFound)
+
+		_, err := uc.ResolveOpaqueToken(ctx, "token")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidAccessToken)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		mockRepo := new(opaqueRepo.MockOpaqueTokenRepository)
+		uc := usecase.NewOpaqueTokenUseCase(mockRepo, time.Minute)
+		expired := stored("token", testClaims())
+		expired.ExpiresAt = time.Now().Add(-time.Second)
+		mockRepo.On("GetOpaqueTokenByHash", ctx, hash("token")).Return(expired, nil)
+
+		_, err := uc.ResolveOpaqueToken(ctx, "token")
+
+		assert.ErrorIs(t, err, domain.ErrInvalidAccessToken)
+	})
+
+	t.Run("repository error is not an invalid token", func(t *testing.T) {
+		mockRepo := new(opaqueRepo.MockOpaqueTokenRepository)
+		uc := usecase.NewOpaqueTokenUseCase(mockRepo, time.Minute)
+		mockRepo.On("GetOpaqueTokenByHash", ctx, hash("token")).Return(nil, domain.ErrGeneralOpaqueToken)
+
+		_, err := uc.ResolveOpaqueToken(ctx, "token")
+
+		assert.ErrorIs(t, err, domain.ErrGeneralOpaqueToken)
+		assert.NotErrorIs(t, err, domain.ErrInvalidAccessToken)
+	})
+}
+
+func TestTokenVerifier(t *testing.T) {
+	ctx := context.Background()
+	keys := usecase.NewKeySet(usecase.NewHMACSigningKey("secret"))
+
+	setup := func() (*usecase.TokenVerifier, *opaqueRepo.MockOpaqueTokenRepository, *revocationRepo.MockRevocationRepository) {
+		mockOpaque := new(opaqueRepo.MockOpaqueTokenRepository)
+		mockRevocations := new(revocationRepo.MockRevocationRepository)
+		opaque := usecase.NewOpaqueTokenUseCase(mockOpaque, time.Minute)
+		revocations := usecase.NewRevocationUseCase(mockRevocations, time.Minute)
+		return usecase.NewTokenVerifier(keys, opaque, revocations, usecase.NewSessionUseCase(new(sessionRepo.MockSessionRepository), nil, time.Minute)), mockOpaque, mockRevocations
+	}
+
+	t.Run("jwt", func(t *testing.T) {
+		verifier, _, mockRevocations := setup()
+		claims := testClaims()
+		claims.ID = "jti-1"
+		token, err := keys.Sign(claims)
+		require.NoError(t, err)
+		mockRevocations.On("IsTokenRevoked", ctx, "jti-1").Return(false, nil)
+
+		verified, err := verifier.Verify(ctx, token)
+
+		require.NoError(t, err)
+		assert.Equal(t, "subject", verified.Subject)
+	})
+
+	t.Run("revoked jwt", func(t *testing.T) {
+		verifier, _, mockRevocations := setup()
+		claims := testClaims()
+		claims.ID = "jti-1"
+		token, err := keys.Sign(claims)
+		require.NoError(t, err)
+		mockRevocations.On("IsTokenRevoked", ctx, "jti-1").Return(true, nil)
+
+		_, err = verifier.Verify(ctx, token)
+
+		assert.ErrorIs(t, err, domain.ErrTokenRevoked)
+	})
+
+	t.Run("id token", func(t *testing.T) {
+		verifier, _, mockRevocations := setup()
+		claims := testClaims()
+		claims.Type = domain.IDTokenType
+		token, err := keys.Sign(claims)
+		require.NoError(t, err)
+
+		_, err = verifier.Verify(ctx, token)
+
+		assert.ErrorIs(t, err, domain.ErrInvalidAccessToken)
+		mockRevocations.AssertNotCalled(t, "IsTokenRevoked", mock.Anything, mock.Anything)
+	})
+
+	t.Run("opaque token resolves to the same claims", func(t *testing.T) {
+		verifier, mockOpaque, _ := setup()
+		claims := testClaims()
+		claims.Type = domain.ClientType
+		claims.Subject = uuid.NewString()
+		mockOpaque.On("GetOpaqueTokenByHash", ctx, mock.Anything).Return(&domain.OpaqueToken{Claims: claims, ExpiresAt: claims.ExpiresAt.Time}, nil)
+
+		verified, err := verifier.Verify(ctx, "opaque-token")
+
+		require.NoError(t, err)
+		assert.Equal(t, claims.Subject, verified.Subject)
+		assert.Equal(t, domain.ClientType, verified.Type)
+	})
+
+	t.Run("revoking an opaque token needs no denylist", func(t *testing.T) {
+		verifier, mockOpaque, mockRevocations := setup()
+		mockOpaque.On("RevokeOpaqueToken", ctx, mock.Anything).Return(nil)
+
+		err := verifier.Revoke(ctx, "opaque-token", &domain.JwtClaims{})
+
+		require.NoError(t, err)
+		mockRevocations.AssertNotCalled(t, "RevokeToken", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
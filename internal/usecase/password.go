@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher hashes passwords and client secrets into self-describing strings, so hashes made
+// with an earlier algorithm or earlier parameters keep verifying after the configuration changes.
+type PasswordHasher interface {
+	Hash(password string) ([]byte, error)
+	// Verify reports whether password matches hash and whether hash should be replaced by a new
+	// Hash of password because it was made with another algorithm or other parameters.
+	Verify(hash []byte, password string) (ok bool, rehash bool)
+	// Recognizes reports whether hash is in the format of this hasher.
+	Recognizes(hash []byte) bool
+}
+
+// Argon2idParams are the cost parameters of Argon2id (RFC 9106), Memory is in KiB.
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// Argon2idHasher stores hashes in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>, salt and key in unpadded base64.
+type Argon2idHasher struct {
+	params Argon2idParams
+}
+
+func NewArgon2idHasher(params Argon2idParams) *Argon2idHasher {
+	if params.SaltLength == 0 {
+		params.SaltLength = 16
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = 32
+	}
+	return &Argon2idHasher{params: params}
+}
+
+const argon2idPrefix = "$argon2id$"
+
+func (h *Argon2idHasher) Hash(password string) ([]byte, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return []byte(fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	)), nil
+}
+
+func (h *Argon2idHasher) Verify(hash []byte, password string) (bool, bool) {
+	params, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		return false, false
+	}
+	computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return false, false
+	}
+	return true, params != h.params
+}
+
+func (h *Argon2idHasher) Recognizes(hash []byte) bool {
+	return bytes.HasPrefix(hash, []byte(argon2idPrefix))
+}
+
+func parseArgon2id(hash []byte) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("malformed argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version '%s'", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id key: %w", err)
+	}
+	params.SaltLength, params.KeyLength = uint32(len(salt)), uint32(len(key))
+	return params, salt, key, nil
+}
+
+// BcryptHasher is the hasher of the hashes stored before Argon2id, it only considers the first
+// 72 bytes of a password and refuses longer ones.
+type BcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(password), h.cost)
+}
+
+func (h *BcryptHasher) Verify(hash []byte, password string) (bool, bool) {
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil {
+		return false, false
+	}
+	cost, err := bcrypt.Cost(hash)
+	return true, err != nil || cost != h.cost
+}
+
+func (h *BcryptHasher) Recognizes(hash []byte) bool {
+	// $2a$, $2b$ and $2y$ are all accepted by the bcrypt package
+	return len(hash) > 4 && hash[0] == '$' && hash[1] == '2' && hash[3] == '$'
+}
+
+// FallbackHasher hashes with the current hasher and still verifies the hashes of the legacy ones,
+// which it asks to have replaced.
+type FallbackHasher struct {
+	current PasswordHasher
+	legacy  []PasswordHasher
+}
+
+func NewFallbackHasher(current PasswordHasher, legacy ...PasswordHasher) *FallbackHasher {
+	return &FallbackHasher{current: current, legacy: legacy}
+}
+
+func (h *FallbackHasher) Hash(password string) ([]byte, error) {
+	return h.current.Hash(password)
+}
+
+func (h *FallbackHasher) Verify(hash []byte, password string) (bool, bool) {
+	if h.current.Recognizes(hash) {
+		return h.current.Verify(hash, password)
+	}
+	for _, legacy := range h.legacy {
+		if legacy.Recognizes(hash) {
+			ok, _ := legacy.Verify(hash, password)
+			return ok, ok
+		}
+	}
+	return false, false
+}
+
+func (h *FallbackHasher) Recognizes(hash []byte) bool {
+	if h.current.Recognizes(hash) {
+		return true
+	}
+	for _, legacy := range h.legacy {
+		if legacy.Recognizes(hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// PepperedHasher prepends a versioned pepper to what it hands to the hasher. New hashes take the
+// current, highest, version; older versions only verify hashes made before a rotation and ask for
+// them to be replaced, so a leaked pepper can be retired once no stored hash uses it anymore.
+type PepperedHasher struct {
+	hasher  PasswordHasher
+	peppers map[int]string
+	current int
+}
+
+func NewPepperedHasher(hasher PasswordHasher, peppers map[int]string) *PepperedHasher {
+	current := 0
+	for version := range peppers {
+		if version > current {
+			current = version
+		}
+	}
+	return &PepperedHasher{hasher: hasher, peppers: peppers, current: current}
+}
+
+// Hash returns the hash of password under the current pepper and the version of that pepper.
+func (h *PepperedHasher) Hash(password string) ([]byte, int, error) {
+	hash, err := h.hasher.Hash(h.peppers[h.current] + password)
+	if err != nil {
+		return nil, 0, err
+	}
+	return hash, h.current, nil
+}
+
+// Verify checks password against a hash made with the pepper of the given version, hashes of
+// retired versions never match. rehash is also reported for hashes under an older pepper.
+func (h *PepperedHasher) Verify(hash []byte, version int, password string) (bool, bool) {
+	pepper, ok := h.peppers[version]
+	if !ok {
+		return false, false
+	}
+	ok, rehash := h.hasher.Verify(hash, pepper+password)
+	return ok, ok && (rehash || version != h.current)
+}
@@ -0,0 +1,161 @@
+package usecase_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2id keeps the tests fast, production parameters come from the config.
+var testArgon2id = usecase.Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1}
+
+// testPepper is version 0, the pepper that was hardcoded before peppers were configurable.
+const testPepper = "3tw0d2o"
+
+func testHasher() *usecase.FallbackHasher {
+	return usecase.NewFallbackHasher(usecase.NewArgon2idHasher(testArgon2id), usecase.NewBcryptHasher(bcrypt.MinCost))
+}
+
+func testPepperedHasher() *usecase.PepperedHasher {
+	return usecase.NewPepperedHasher(testHasher(), map[int]string{0: testPepper})
+}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := usecase.NewArgon2idHasher(testArgon2id)
+
+	t.Run("phc format", func(t *testing.T) {
+		hash, err := hasher.Hash("password")
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(hash), "$argon2id$v=19$m=64,t=1,p=1$"))
+		assert.Len(t, strings.Split(string(hash), "$"), 6)
+		assert.True(t, hasher.Recognizes(hash))
+	})
+
+	t.Run("salted", func(t *testing.T) {
+		first, err := hasher.Hash("password")
+		require.NoError(t, err)
+		second, err := hasher.Hash("password")
+		require.NoError(t, err)
+
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		hash, err := hasher.Hash("password")
+		require.NoError(t, err)
+
+		ok, rehash := hasher.Verify(hash, "password")
+		assert.True(t, ok)
+		assert.False(t, rehash)
+
+		ok, _ = hasher.Verify(hash, "wrong")
+		assert.False(t, ok)
+	})
+
+	t.Run("no truncation", func(t *testing.T) {
+		long := strings.Repeat("a", 100)
+		hash, err := hasher.Hash(long)
+		require.NoError(t, err)
+
+		ok, _ := hasher.Verify(hash, long[:72])
+		assert.False(t, ok)
+	})
+
+	t.Run("other parameters ask for a rehash", func(t *testing.T) {
+		old := usecase.NewArgon2idHasher(usecase.Argon2idParams{Memory: 32, Iterations: 1, Parallelism: 1})
+		hash, err := old.Hash("password")
+		require.NoError(t, err)
+
+		ok, rehash := hasher.Verify(hash, "password")
+		assert.True(t, ok)
+		assert.True(t, rehash)
+	})
+
+	t.Run("malformed hash", func(t *testing.T) {
+		for _, hash := range []string{"$argon2id$", "$argon2id$v=19$m=64,t=1,p=1$!!$!!", "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5"} {
+			ok, _ := hasher.Verify([]byte(hash), "password")
+			assert.False(t, ok, hash)
+		}
+	})
+}
+
+func TestFallbackHasher(t *testing.T) {
+	hasher := testHasher()
+
+	t.Run("hashes with the current hasher", func(t *testing.T) {
+		hash, err := hasher.Hash("password")
+
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(hash), "$argon2id$"))
+	})
+
+	t.Run("legacy bcrypt hash verifies and asks for a rehash", func(t *testing.T) {
+		hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+		require.NoError(t, err)
+
+		ok, rehash := hasher.Verify(hash, "password")
+		assert.True(t, ok)
+		assert.True(t, rehash)
+
+		ok, rehash = hasher.Verify(hash, "wrong")
+		assert.False(t, ok)
+		assert.False(t, rehash)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		ok, _ := hasher.Verify([]byte("plain"), "plain")
+
+		assert.False(t, ok)
+		assert.False(t, hasher.Recognizes([]byte("plain")))
+	})
+}
+
+func TestPepperedHasher(t *testing.T) {
+	rotated := usecase.NewPepperedHasher(testHasher(), map[int]string{0: testPepper, 1: "new-pepper"})
+
+	t.Run("hashes under the highest version", func(t *testing.T) {
+		hash, version, err := rotated.Hash("password")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, version)
+		ok, rehash := testHasher().Verify(hash, "new-pepper"+"password")
+		assert.True(t, ok)
+		assert.False(t, rehash)
+	})
+
+	t.Run("older version verifies and asks for a rehash", func(t *testing.T) {
+		hash, version, err := testPepperedHasher().Hash("password")
+		require.NoError(t, err)
+		require.Equal(t, 0, version)
+
+		ok, rehash := rotated.Verify(hash, version, "password")
+		assert.True(t, ok)
+		assert.True(t, rehash)
+
+		ok, rehash = rotated.Verify(hash, version, "wrong")
+		assert.False(t, ok)
+		assert.False(t, rehash)
+	})
+
+	t.Run("pepper is checked against the recorded version", func(t *testing.T) {
+		hash, _, err := testPepperedHasher().Hash("password")
+		require.NoError(t, err)
+
+		ok, _ := rotated.Verify(hash, 1, "password")
+		assert.False(t, ok)
+	})
+
+	t.Run("retired version no longer verifies", func(t *testing.T) {
+		hash, version, err := testPepperedHasher().Hash("password")
+		require.NoError(t, err)
+		retired := usecase.NewPepperedHasher(testHasher(), map[int]string{1: "new-pepper"})
+
+		ok, _ := retired.Verify(hash, version, "password")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/repository/breach"
+	"github.com/bright-pentium/go-client-practice/internal/repository/passwordhistory"
+)
+
+// PasswordPolicyUseCase checks the passwords administrators set against the password policy, the
+// previous passwords of the user and a corpus of breached passwords.
+type PasswordPolicyUseCase struct {
+	policy      domain.PasswordPolicy
+	historyRepo passwordhistory.IPasswordHistoryRepository
+	breachRepo  breach.IBreachedPasswordRepository
+	hasher      *PepperedHasher
+}
+
+// NewPasswordPolicyUseCase builds the policy, historyRepo may be nil when policy.History is 0 and
+// breachRepo is nil when no breached password corpus is configured.
+func NewPasswordPolicyUseCase(policy domain.PasswordPolicy, historyRepo passwordhistory.IPasswordHistoryRepository, breachRepo breach.IBreachedPasswordRepository, hasher *PepperedHasher) *PasswordPolicyUseCase {
+	return &PasswordPolicyUseCase{policy: policy, historyRepo: historyRepo, breachRepo: breachRepo, hasher: hasher}
+}
+
+// Check returns a PasswordPolicyError listing every rule password breaks as the password of user.
+// The password of a user not created yet, without a PasswordHash, is not checked against a history.
+func (u *PasswordPolicyUseCase) Check(ctx context.Context, user *domain.User, password string) error {
+	var violations []string
+	if utf8.RuneCountInString(password) < u.policy.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters long", u.policy.MinLength))
+	}
+	if characterClasses(password) < u.policy.MinCharacterClasses {
+		violations = append(violations, fmt.Sprintf("must contain at least %d of lowercase letters, uppercase letters, digits and symbols", u.policy.MinCharacterClasses))
+	}
+	if containsName(password, user.Account) {
+		violations = append(violations, "must not contain the account name")
+	}
+	if containsName(password, user.Name) {
+		violations = append(violations, "must not contain the user name")
+	}
+
+	if user.PasswordHash != nil && u.policy.History > 0 {
+		reused, err := u.reused(ctx, user, password)
+		if err != nil {
+			return err
+		}
+		if reused {
+			violations = append(violations, fmt.Sprintf("must not be one of the last %d passwords", u.policy.History))
+		}
+	}
+
+	if u.breachRepo != nil {
+		count, err := u.breachRepo.CountBreaches(ctx, sha1.Sum([]byte(password)))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			violations = append(violations, "appears in known data breaches")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &domain.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// reused checks password against the current password of the user and the previous ones kept.
+func (u *PasswordPolicyUseCase) reused(ctx context.Context, user *domain.User, password string) (bool, error) {
+	if ok, _ := u.hasher.Verify(user.PasswordHash, user.PepperVersion, password); ok {
+		return true, nil
+	}
+	if u.policy.History == 1 {
+		return false, nil
+	}
+	entries, err := u.historyRepo.ListPasswordHashes(ctx, user.ID, u.policy.History-1)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if ok, _ := u.hasher.Verify(entry.PasswordHash, entry.PepperVersion, password); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Record keeps the password hash user had before a password change in the history.
+func (u *PasswordPolicyUseCase) Record(ctx context.Context, user *domain.User) error {
+	if u.policy.History <= 1 || user.PasswordHash == nil {
+		return nil
+	}
+	return u.historyRepo.AddPasswordHash(ctx, user.ID, user.PasswordHash, user.PepperVersion, u.policy.History-1)
+}
+
+// containsName matches case-insensitively, names shorter than 3 characters would reject too much to be matched.
+func containsName(password string, name string) bool {
+	if utf8.RuneCountInString(name) < 3 {
+		return false
+	}
+	return strings.Contains(strings.ToLower(password), strings.ToLower(name))
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
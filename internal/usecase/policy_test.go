@@ -0,0 +1,214 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/sha1"
+	"testing"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	breachRepo "github.com/bright-pentium/go-client-practice/internal/repository/breach"
+	historyRepo "github.com/bright-pentium/go-client-practice/internal/repository/passwordhistory"
+	refreshRepo "github.com/bright-pentium/go-client-practice/internal/repository/refresh"
+	sessionRepo "github.com/bright-pentium/go-client-practice/internal/repository/session"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = domain.PasswordPolicy{MinLength: 12, MinCharacterClasses: 3}
+
+// testPasswordPolicy checks length and character classes only, without history or breach screening.
+func testPasswordPolicy() *usecase.PasswordPolicyUseCase {
+	return usecase.NewPasswordPolicyUseCase(testPolicy, nil, nil, testPepperedHasher())
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+	user := &domain.User{Name: "Alice Liddell", Account: "alice"}
+
+	t.Run("every violation is reported", func(t *testing.T) {
+		mockBreachRepo := new(breachRepo.MockBreachedPasswordRepository)
+		mockBreachRepo.On("CountBreaches", mock.Anything, sha1.Sum([]byte("alice"))).Return(3, nil)
+		policy := usecase.NewPasswordPolicyUseCase(testPolicy, nil, mockBreachRepo, testPepperedHasher())
+
+		err := policy.Check(ctx, user, "alice")
+
+		var policyErr *domain.PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+		assert.ErrorIs(t, err, domain.ErrPasswordPolicy)
+		assert.Equal(t, []string{
+			"must be at least 12 characters long",
+			"must contain at least 3 of lowercase letters, uppercase letters, digits and symbols",
+			"must not contain the account name",
+			"appears in known data breaches",
+		}, policyErr.Violations)
+		mockBreachRepo.AssertExpectations(t)
+	})
+
+	t.Run("names are matched case-insensitively", func(t *testing.T) {
+		err := testPasswordPolicy().Check(ctx, user, "9-ALICE LIDDELL-x")
+
+		var policyErr *domain.PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, []string{"must not contain the account name", "must not contain the user name"}, policyErr.Violations)
+	})
+
+	t.Run("length counts characters", func(t *testing.T) {
+		err := testPasswordPolicy().Check(ctx, user, "Ünïcödé-pä1")
+
+		var policyErr *domain.PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, []string{"must be at least 12 characters long"}, policyErr.Violations)
+	})
+
+	t.Run("strong password passes", func(t *testing.T) {
+		mockBreachRepo := new(breachRepo.MockBreachedPasswordRepository)
+		mockBreachRepo.On("CountBreaches", mock.Anything, mock.Anything).Return(0, nil)
+		policy := usecase.NewPasswordPolicyUseCase(testPolicy, nil, mockBreachRepo, testPepperedHasher())
+
+		assert.NoError(t, policy.Check(ctx, user, "Correct-horse-7"))
+		mockBreachRepo.AssertExpectations(t)
+	})
+
+	t.Run("corpus failure is not a violation", func(t *testing.T) {
+		mockBreachRepo := new(breachRepo.MockBreachedPasswordRepository)
+		mockBreachRepo.On("CountBreaches", mock.Anything, mock.Anything).Return(0, domain.ErrGeneralBreachedPassword)
+		policy := usecase.NewPasswordPolicyUseCase(testPolicy, nil, mockBreachRepo, testPepperedHasher())
+
+		err := policy.Check(ctx, user, "Correct-horse-7")
+
+		assert.ErrorIs(t, err, domain.ErrGeneralBreachedPassword)
+		assert.NotErrorIs(t, err, domain.ErrPasswordPolicy)
+	})
+}
+
+func TestPasswordPolicyHistory(t *testing.T) {
+	ctx := context.Background()
+	hasher := testPepperedHasher()
+	history := domain.PasswordPolicy{MinLength: 12, MinCharacterClasses: 3, History: 3}
+
+	currentHash, currentVersion, err := hasher.Hash("Current-pass-1")
+	require.NoError(t, err)
+	previousHash, previousVersion, err := hasher.Hash("Previous-pass-1")
+	require.NoError(t, err)
+	user := &domain.User{ID: uuid.New(), Name: "Alice", Account: "alice", PasswordHash: currentHash, PepperVersion: currentVersion}
+
+	newPolicy := func() (*usecase.PasswordPolicyUseCase, *historyRepo.MockPasswordHistoryRepository) {
+		mockHistoryRepo := new(historyRepo.MockPasswordHistoryRepository)
+		mockHistoryRepo.On("ListPasswordHashes", mock.Anything, user.ID, 2).
+			Return([]domain.PasswordHistoryEntry{{UserID: user.ID, PasswordHash: previousHash, PepperVersion: previousVersion}}, nil)
+		return usecase.NewPasswordPolicyUseCase(history, mockHistoryRepo, nil, hasher), mockHistoryRepo
+	}
+
+	t.Run("current password cannot be reused", func(t *testing.T) {
+		policy, mockHistoryRepo := newPolicy()
+
+		err := policy.Check(ctx, user, "Current-pass-1")
+
+		var policyErr *domain.PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+		assert.Equal(t, []string{"must not be one of the last 3 passwords"}, policyErr.Violations)
+		mockHistoryRepo.AssertNotCalled(t, "ListPasswordHashes", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("previous password cannot be reused", func(t *testing.T) {
+		policy, mockHistoryRepo := newPolicy()
+
+		err := policy.Check(ctx, user, "Previous-pass-1")
+
+		assert.ErrorIs(t, err, domain.ErrPasswordPolicy)
+		mockHistoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("new password passes", func(t *testing.T) {
+		policy, mockHistoryRepo := newPolicy()
+
+		assert.NoError(t, policy.Check(ctx, user, "Brand-new-pass-1"))
+		mockHistoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("new user has no history", func(t *testing.T) {
+		policy, mockHistoryRepo := newPolicy()
+
+		assert.NoError(t, policy.Check(ctx, &domain.User{Name: "Bob", Account: "bob"}, "Current-pass-1"))
+		mockHistoryRepo.AssertNotCalled(t, "ListPasswordHashes", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("record keeps the previous passwords", func(t *testing.T) {
+		policy, mockHistoryRepo := newPolicy()
+		mockHistoryRepo.On("AddPasswordHash", mock.Anything, user.ID, currentHash, currentVersion, 2).Return(nil)
+
+		assert.NoError(t, policy.Record(ctx, user))
+		mockHistoryRepo.AssertCalled(t, "AddPasswordHash", mock.Anything, user.ID, currentHash, currentVersion, 2)
+	})
+
+	t.Run("current password only needs no history", func(t *testing.T) {
+		policy := usecase.NewPasswordPolicyUseCase(domain.PasswordPolicy{History: 1}, nil, nil, hasher)
+
+		assert.ErrorIs(t, policy.Check(ctx, user, "Current-pass-1"), domain.ErrPasswordPolicy)
+		assert.NoError(t, policy.Check(ctx, user, "Brand-new-pass-1"))
+		assert.NoError(t, policy.Record(ctx, user))
+	})
+}
+
+func TestSysUserPasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("create rejects a weak password", func(t *testing.T) {
+		mockUserRepo := new(userRepo.MockUserRepository)
+		useCase := usecase.NewSysUserUseCase(mockUserRepo, nil, nil, testPepperedHasher(), testPasswordPolicy(), testThrottle())
+
+		user, err := useCase.CreateUser(ctx, "Foo", "foo", "a")
+
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, domain.ErrPasswordPolicy)
+		mockUserRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update checks the new name", func(t *testing.T) {
+		id := uuid.New()
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockUserRepo.On("GetUserByID", mock.Anything, id).Return(&domain.User{ID: id, Name: "Old Name", Account: "olduser"}, nil)
+		useCase := usecase.NewSysUserUseCase(mockUserRepo, nil, nil, testPepperedHasher(), testPasswordPolicy(), testThrottle())
+
+		user, err := useCase.UpdateUserByID(ctx, id, "Rosalind", "1-rosalind-X")
+
+		var policyErr *domain.PasswordPolicyError
+		require.ErrorAs(t, err, &policyErr)
+		assert.Nil(t, user)
+		assert.Equal(t, []string{"must not contain the user name"}, policyErr.Violations)
+		mockUserRepo.AssertNotCalled(t, "UpdateUserByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update records the replaced password", func(t *testing.T) {
+		hasher := testPepperedHasher()
+		oldHash, oldVersion, err := hasher.Hash("Current-pass-1")
+		require.NoError(t, err)
+		id := uuid.New()
+		current := &domain.User{ID: id, Name: "Alice", Account: "alice", PasswordHash: oldHash, PepperVersion: oldVersion}
+
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockHistoryRepo := new(historyRepo.MockPasswordHistoryRepository)
+		mockRefreshRepo := new(refreshRepo.MockRefreshTokenRepository)
+		mockSessionRepo := new(sessionRepo.MockSessionRepository)
+		mockUserRepo.On("GetUserByID", mock.Anything, id).Return(current, nil)
+		mockHistoryRepo.On("ListPasswordHashes", mock.Anything, id, 4).Return([]domain.PasswordHistoryEntry{}, nil)
+		mockUserRepo.On("UpdateUserByID", mock.Anything, id, "", mock.AnythingOfType("[]uint8"), 0).Return(current, nil)
+		mockHistoryRepo.On("AddPasswordHash", mock.Anything, id, oldHash, oldVersion, 4).Return(nil)
+		mockRefreshRepo.On("RevokeRefreshTokensByUser", mock.Anything, id).Return(nil)
+		mockSessionRepo.On("RevokeSessionsByUser", mock.Anything, id).Return(nil)
+		policy := usecase.NewPasswordPolicyUseCase(domain.PasswordPolicy{MinLength: 12, MinCharacterClasses: 3, History: 5}, mockHistoryRepo, nil, hasher)
+		useCase := usecase.NewSysUserUseCase(mockUserRepo, mockRefreshRepo, mockSessionRepo, hasher, policy, testThrottle())
+
+		_, err = useCase.UpdateUserByID(ctx, id, "", "Brand-new-pass-1")
+
+		assert.NoError(t, err)
+		mockUserRepo.AssertExpectations(t)
+		mockHistoryRepo.AssertExpectations(t)
+		mockRefreshRepo.AssertExpectations(t)
+		mockSessionRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/repository/refresh"
+	"github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/google/uuid"
+)
+
+type RefreshTokenUseCase struct {
+	repo     refresh.IRefreshTokenRepository
+	userRepo user.IUserRepository
+	ttl      time.Duration
+}
+
+func NewRefreshTokenUseCase(repo refresh.IRefreshTokenRepository, userRepo user.IUserRepository, ttl time.Duration) *RefreshTokenUseCase {
+	return &RefreshTokenUseCase{repo: repo, userRepo: userRepo, ttl: ttl}
+}
+
+// IssueRefreshToken starts a new token family for a user who authenticated at authTime,
+// the family is the session of the login.
+func (u *RefreshTokenUseCase) IssueRefreshToken(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID, authTime time.Time) (string, error) {
+	_, token, err := u.issue(ctx, sessionID, userID, authTime)
+	return token, err
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one of the same family, returned
+// both as stored and in plain text. Presenting a token that was already rotated revokes the whole family.
+func (u *RefreshTokenUseCase) RotateRefreshToken(ctx context.Context, token string) (*domain.User, *domain.RefreshToken, string, error) {
+	current, err := u.repo.GetRefreshTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+			return nil, nil, "", domain.ErrRefreshTokenInvalid
+		}
+		return nil, nil, "", err
+	}
+
+	if current.RevokedAt != nil || time.Now().After(current.ExpiresAt) {
+		return nil, nil, "", domain.ErrRefreshTokenInvalid
+	}
+
+	if current.UsedAt == nil {
+		err = u.repo.MarkRefreshTokenUsed(ctx, current.ID)
+	} else {
+		err = domain.ErrRefreshTokenReused
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			if err := u.repo.RevokeRefreshTokenFamily(ctx, current.FamilyID); err != nil {
+				return nil, nil, "", err
+			}
+		}
+		return nil, nil, "", err
+	}
+
+	user, err := u.userRepo.GetUserByID(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, nil, "", domain.ErrRefreshTokenInvalid
+		}
+		return nil, nil, "", err
+	}
+
+	stored, next, err := u.issue(ctx, current.FamilyID, current.UserID, current.AuthTime)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return user, stored, next, nil
+}
+
+// RevokeRefreshToken revokes the family of the token. Unknown tokens are ignored,
+// as required for the revocation endpoint (RFC 7009 section 2.2).
+func (u *RefreshTokenUseCase) RevokeRefreshToken(ctx context.Context, token string) error {
+	current, err := u.repo.GetRefreshTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+			return nil
+		}
+		return err
+	}
+	return u.repo.RevokeRefreshTokenFamily(ctx, current.FamilyID)
+}
+
+func (u *RefreshTokenUseCase) issue(ctx context.Context, familyID uuid.UUID, userID uuid.UUID, authTime time.Time) (*domain.RefreshToken, string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	stored, err := u.repo.CreateRefreshToken(ctx, uuid.New(), familyID, userID, hashToken(token), authTime, time.Now().Add(u.ttl))
+	if err != nil {
+		return nil, "", err
+	}
+	return stored, token, nil
+}
+
+// newOpaqueToken returns 256 bits of randomness encoded for use in URLs and headers.
+func newOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken is how opaque tokens are stored, so a database leak does not leak usable tokens.
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
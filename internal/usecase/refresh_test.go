@@ -0,0 +1,177 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	refreshRepo "github.com/bright-pentium/go-client-practice/internal/repository/refresh"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func sha256Of(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+func TestIssueRefreshToken(t *testing.T) {
+	mockRepo := new(refreshRepo.MockRefreshTokenRepository)
+	uc := usecase.NewRefreshTokenUseCase(mockRepo, nil, time.Hour)
+
+	sessionID := uuid.New()
+	userID := uuid.New()
+	authTime := time.Now()
+	var capturedHash []byte
+	mockRepo.On("CreateRefreshToken", mock.Anything, mock.Anything, sessionID, userID, mock.AnythingOfType("[]uint8"), authTime, mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) {
+			capturedHash = args.Get(4).([]byte)
+		}).
+		Return(&domain.RefreshToken{}, nil)
+
+	token, err := uc.IssueRefreshToken(context.Background(), sessionID, userID, authTime)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, sha256Of(token), capturedHash) // only the hash is persisted
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	token := "presented-token"
+
+	t.Run("successful rotation keeps the family and auth time", func(t *testing.T) {
+		mockRepo := new(refreshRepo.MockRefreshTokenRepository)
+		mockUserRepo := new(userRepo.MockUserRepository)
+		uc := usecase.NewRefreshTokenUseCase(mockRepo, mockUserRepo, time.Hour)
+
+		current := &domain.RefreshToken{ID: uuid.New(), FamilyID: uuid.New(), UserID: uuid.New(), AuthTime: time.Now().Add(-time.Hour), ExpiresAt: time.Now().Add(time.Hour)}
+		expectedUser := &domain.User{ID: current.UserID, Name: "Alice"}
+		expectedStored := &domain.RefreshToken{FamilyID: current.FamilyID, AuthTime: current.AuthTime}
+
+		mockRepo.On("GetRefreshTokenByHash", ctx, sha256Of(token)).Return(current, nil)
+		mockRepo.On("MarkRefreshTokenUsed", ctx, current.ID).Return(nil)
+		mockUserRepo.On("GetUserByID", ctx, current.UserID).Return(expectedUser, nil)
+		mockRepo.On("CreateRefreshToken", ctx, mock.Anything, current.FamilyID, current.UserID, mock.AnythingOfType("[]uint8"), current.AuthTime, mock.AnythingOfType("time.Time")).
+			Return(expectedStored, nil)
+
+		user, stored, next, err := uc.RotateRefreshToken(ctx, token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedUser, user)
+		assert.Equal(t, expectedStored, stored)
+		assert.NotEmpty(t, next)
+		assert.NotEqual(t, token, next)
+		mockRepo.AssertExpectations(t)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		mockRepo := new(refreshRepo.MockRefreshTokenRepository)
+		uc := usecase.NewRefreshTokenUseCase(mockRepo, nil, time.Hour)
+
+		mockRepo.On("GetRefreshTokenByHash", ctx, sha256Of(token)).Return(nil, domain.ErrRefreshTokenNotFound)
+
+		user, stored, next, err := uc.RotateRefreshToken(ctx, token)
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenInvalid)
+		assert.Nil(t, user)
+		assert.Nil(t, stored)
+		assert.Empty(t, next)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		mockRepo := new(refreshRepo.MockRefreshTokenRepository)
+		uc := usecase.NewRefreshTokenUseCase(mockRepo, nil, time.Hour)
+
+		current := &domain.RefreshToken{ID: uuid.New(), FamilyID: uuid.New(), ExpiresAt: time.Now().Add(-time.Minute)}
+		mockRepo.On("GetRefreshTokenByHash", ctx, sha256Of(token)).Return(current, nil)
+
+		_, _, _, err := uc.RotateRefreshToken(ctx, token)
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenInvalid)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		mockRepo := new(refreshRepo.MockRefreshTokenRepository)
+		uc := usecase.NewRefreshTokenUseCase(mockRepo, nil, time.Hour)
+
+		revokedAt := time.Now()
+		current := &domain.RefreshToken{ID: uuid.New(), FamilyID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+		mockRepo.On("GetRefreshTokenByHash", ctx, sha256Of(token)).Return(current, nil)
+
+		_, _, _, err := uc.RotateRefreshToken(ctx, token)
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenInvalid)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reused token revokes the family", func(t *testing.T) {
+		mockRepo := new(refreshRepo.MockRefreshTokenRepository)
+		uc := usecase.NewRefreshTokenUseCase(mockRepo, nil, time.Hour)
+
+		usedAt := time.Now().Add(-time.Minute)
+		current := &domain.RefreshToken{ID: uuid.New(), FamilyID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}
+		mockRepo.On("GetRefreshTokenByHash", ctx, sha256Of(token)).Return(current, nil)
+		mockRepo.On("RevokeRefreshTokenFamily", ctx, current.FamilyID).Return(nil)
+
+		_, _, _, err := uc.RotateRefreshToken(ctx, token)
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("concurrent rotation loses the race", func(t *testing.T) {
+		mockRepo := new(refreshRepo.MockRefreshTokenRepository)
+		uc := usecase.NewRefreshTokenUseCase(mockRepo, nil, time.Hour)
+
+		current := &domain.RefreshToken{ID: uuid.New(), FamilyID: uuid.New(), ExpiresAt: time.Now().Add(time.Hour)}
+		mockRepo.On("GetRefreshTokenByHash", ctx, sha256Of(token)).Return(current, nil)
+		mockRepo.On("MarkRefreshTokenUsed", ctx, current.ID).Return(domain.ErrRefreshTokenReused)
+		mockRepo.On("RevokeRefreshTokenFamily", ctx, current.FamilyID).Return(nil)
+
+		_, _, _, err := uc.RotateRefreshToken(ctx, token)
+
+		assert.ErrorIs(t, err, domain.ErrRefreshTokenReused)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	token := "presented-token"
+
+	t.Run("revokes the family", func(t *testing.T) {
+		mockRepo := new(refreshRepo.MockRefreshTokenRepository)
+		uc := usecase.NewRefreshTokenUseCase(mockRepo, nil, time.Hour)
+
+		current := &domain.RefreshToken{ID: uuid.New(), FamilyID: uuid.New()}
+		mockRepo.On("GetRefreshTokenByHash", ctx, sha256Of(token)).Return(current, nil)
+		mockRepo.On("RevokeRefreshTokenFamily", ctx, current.FamilyID).Return(nil)
+
+		err := uc.RevokeRefreshToken(ctx, token)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown token is ignored", func(t *testing.T) {
+		mockRepo := new(refreshRepo.MockRefreshTokenRepository)
+		uc := usecase.NewRefreshTokenUseCase(mockRepo, nil, time.Hour)
+
+		mockRepo.On("GetRefreshTokenByHash", ctx, sha256Of(token)).Return(nil, domain.ErrRefreshTokenNotFound)
+
+		err := uc.RevokeRefreshToken(ctx, token)
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "RevokeRefreshTokenFamily", mock.Anything, mock.Anything)
+	})
+}
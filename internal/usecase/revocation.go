@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/repository/revocation"
+)
+
+// RevocationUseCase is the jti denylist. Revoked entries are cached until the
+// token expires; lookups that found nothing are cached for negativeTTL, which
+// bounds how long a revocation made by another replica takes to be seen here.
+type RevocationUseCase struct {
+	repo        revocation.IRevocationRepository
+	cache       *ttlCache[string, bool]
+	negativeTTL time.Duration
+}
+
+func NewRevocationUseCase(repo revocation.IRevocationRepository, negativeTTL time.Duration) *RevocationUseCase {
+	return &RevocationUseCase{repo: repo, cache: newTTLCache[string, bool](), negativeTTL: negativeTTL}
+}
+
+// RevokeToken denylists the jti until expiresAt, after which the token is rejected for being expired anyway.
+func (u *RevocationUseCase) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" || !time.Now().Before(expiresAt) {
+		return nil
+	}
+	if err := u.repo.RevokeToken(ctx, jti, expiresAt); err != nil {
+		return err
+	}
+	u.cache.Set(jti, true, expiresAt)
+	return nil
+}
+
+// IsRevoked reports whether the token was revoked. Tokens minted before jti
+// was introduced cannot be revoked.
+func (u *RevocationUseCase) IsRevoked(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+	if revoked, ok := u.cache.Get(jti); ok {
+		return revoked, nil
+	}
+
+	revoked, err := u.repo.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+	if revoked {
+		u.cache.Set(jti, true, expiresAt)
+	} else {
+		u.cache.Set(jti, false, time.Now().Add(u.negativeTTL))
+	}
+	return revoked, nil
+}
+
+// Check is the error-returning form of IsRevoked used by the middleware.
+func (u *RevocationUseCase) Check(ctx context.Context, claims *domain.JwtClaims) error {
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	revoked, err := u.IsRevoked(ctx, claims.ID, expiresAt)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return domain.ErrTokenRevoked
+	}
+	return nil
+}
+
+// Run periodically deletes denylist entries whose tokens have expired.
+func (u *RevocationUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			u.cache.Purge()
+			if _, err := u.repo.DeleteExpiredRevocations(ctx); err != nil {
+				log.Printf("revocation purge failed: %v", err)
+			}
+		}
+	}
+}
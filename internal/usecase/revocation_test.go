@@ -0,0 +1,133 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	revocationRepo "github.com/bright-pentium/go-client-practice/internal/repository/revocation"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRevokeToken(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("revoked jti is served from cache", func(t *testing.T) {
+		mockRepo := new(revocationRepo.MockRevocationRepository)
+		uc := usecase.NewRevocationUseCase(mockRepo, time.Minute)
+
+		expiresAt := time.Now().Add(time.Hour)
+		mockRepo.On("RevokeToken", ctx, "jti-1", expiresAt).Return(nil)
+
+		assert.NoError(t, uc.RevokeToken(ctx, "jti-1", expiresAt))
+		revoked, err := uc.IsRevoked(ctx, "jti-1", expiresAt)
+
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+		mockRepo.AssertNotCalled(t, "IsTokenRevoked", mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired token or missing jti is a no-op", func(t *testing.T) {
+		mockRepo := new(revocationRepo.MockRevocationRepository)
+		uc := usecase.NewRevocationUseCase(mockRepo, time.Minute)
+
+		assert.NoError(t, uc.RevokeToken(ctx, "jti-1", time.Now().Add(-time.Second)))
+		assert.NoError(t, uc.RevokeToken(ctx, "", time.Now().Add(time.Hour)))
+		mockRepo.AssertNotCalled(t, "RevokeToken", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(revocationRepo.MockRevocationRepository)
+		uc := usecase.NewRevocationUseCase(mockRepo, time.Minute)
+
+		expiresAt := time.Now().Add(time.Hour)
+		mockRepo.On("RevokeToken", ctx, "jti-1", expiresAt).Return(domain.ErrGeneralRevocation)
+
+		err := uc.RevokeToken(ctx, "jti-1", expiresAt)
+
+		assert.ErrorIs(t, err, domain.ErrGeneralRevocation)
+	})
+}
+
+func TestIsRevoked(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	t.Run("revocation by another replica is read from the repository", func(t *testing.T) {
+		mockRepo := new(revocationRepo.MockRevocationRepository)
+		uc := usecase.NewRevocationUseCase(mockRepo, time.Minute)
+
+		mockRepo.On("IsTokenRevoked", ctx, "jti-1").Return(true, nil).Once()
+
+		first, err := uc.IsRevoked(ctx, "jti-1", expiresAt)
+		assert.NoError(t, err)
+		second, err := uc.IsRevoked(ctx, "jti-1", expiresAt)
+		assert.NoError(t, err)
+
+		assert.True(t, first)
+		assert.True(t, second)
+		mockRepo.AssertNumberOfCalls(t, "IsTokenRevoked", 1)
+	})
+
+	t.Run("negative lookups are cached for the negative ttl", func(t *testing.T) {
+		mockRepo := new(revocationRepo.MockRevocationRepository)
+		uc := usecase.NewRevocationUseCase(mockRepo, time.Minute)
+
+		mockRepo.On("IsTokenRevoked", ctx, "jti-1").Return(false, nil)
+
+		for i := 0; i < 3; i++ {
+			revoked, err := uc.IsRevoked(ctx, "jti-1", expiresAt)
+			assert.NoError(t, err)
+			assert.False(t, revoked)
+		}
+		mockRepo.AssertNumberOfCalls(t, "IsTokenRevoked", 1)
+	})
+
+	t.Run("zero negative ttl always asks the repository", func(t *testing.T) {
+		mockRepo := new(revocationRepo.MockRevocationRepository)
+		uc := usecase.NewRevocationUseCase(mockRepo, 0)
+
+		mockRepo.On("IsTokenRevoked", ctx, "jti-1").Return(false, nil)
+
+		_, _ = uc.IsRevoked(ctx, "jti-1", expiresAt)
+		_, _ = uc.IsRevoked(ctx, "jti-1", expiresAt)
+
+		mockRepo.AssertNumberOfCalls(t, "IsTokenRevoked", 2)
+	})
+
+	t.Run("repository errors are not cached", func(t *testing.T) {
+		mockRepo := new(revocationRepo.MockRevocationRepository)
+		uc := usecase.NewRevocationUseCase(mockRepo, time.Minute)
+
+		mockRepo.On("IsTokenRevoked", ctx, "jti-1").Return(false, errors.New("db down")).Once()
+		mockRepo.On("IsTokenRevoked", ctx, "jti-1").Return(true, nil).Once()
+
+		_, err := uc.IsRevoked(ctx, "jti-1", expiresAt)
+		assert.Error(t, err)
+		revoked, err := uc.IsRevoked(ctx, "jti-1", expiresAt)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+}
+
+func TestRevocationCheck(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(revocationRepo.MockRevocationRepository)
+	uc := usecase.NewRevocationUseCase(mockRepo, time.Minute)
+
+	mockRepo.On("IsTokenRevoked", ctx, "revoked").Return(true, nil)
+	mockRepo.On("IsTokenRevoked", ctx, "active").Return(false, nil)
+
+	claims := func(jti string) *domain.JwtClaims {
+		return &domain.JwtClaims{RegisteredClaims: jwt.RegisteredClaims{ID: jti, ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}}
+	}
+
+	assert.ErrorIs(t, uc.Check(ctx, claims("revoked")), domain.ErrTokenRevoked)
+	assert.NoError(t, uc.Check(ctx, claims("active")))
+	assert.NoError(t, uc.Check(ctx, claims(""))) // tokens without jti predate revocation
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+)
+
+// NarrowScope returns the subset of granted permissions that was requested.
+// An empty request keeps the whole granted scope. Every requested permission
+// must be known and covered by the granted scope, otherwise ErrInvalidScope is returned.
+func NarrowScope(granted []domain.Permission, requested []domain.Permission) ([]domain.Permission, error) {
+	if len(requested) == 0 {
+		return granted, nil
+	}
+
+	narrowed := make([]domain.Permission, 0, len(requested))
+	seen := make(map[domain.Permission]struct{}, len(requested))
+	for _, req := range requested {
+		if _, ok := domain.ValidPermissions[req]; !ok {
+			return nil, fmt.Errorf("%w: unknown permission '%s'", domain.ErrInvalidScope, req)
+		}
+		if !grants(granted, req) {
+			return nil, fmt.Errorf("%w: '%s' is not granted", domain.ErrInvalidScope, req)
+		}
+		if _, dup := seen[req]; dup {
+			continue
+		}
+		seen[req] = struct{}{}
+		narrowed = append(narrowed, req)
+	}
+	return narrowed, nil
+}
+
+func grants(granted []domain.Permission, required domain.Permission) bool {
+	for _, perm := range granted {
+		if perm.Grants(required) {
+			return true
+		}
+	}
+	return false
+}
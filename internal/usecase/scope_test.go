@@ -0,0 +1,71 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNarrowScope(t *testing.T) {
+	t.Run("empty request keeps granted scope", func(t *testing.T) {
+		granted := []domain.Permission{domain.PermCreateResource}
+
+		scope, err := usecase.NarrowScope(granted, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, granted, scope)
+	})
+
+	t.Run("wildcard grants a single permission", func(t *testing.T) {
+		scope, err := usecase.NarrowScope([]domain.Permission{domain.PermAll}, []domain.Permission{domain.PermCreateResource})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Permission{domain.PermCreateResource}, scope)
+	})
+
+	t.Run("wildcard does not grant a privileged permission", func(t *testing.T) {
+		scope, err := usecase.NarrowScope([]domain.Permission{domain.PermAll}, []domain.Permission{domain.PermAdmin})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidScope)
+		assert.Nil(t, scope)
+	})
+
+	t.Run("wildcard does not grant introspection", func(t *testing.T) {
+		scope, err := usecase.NarrowScope([]domain.Permission{domain.PermAll}, []domain.Permission{domain.PermIntrospect})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidScope)
+		assert.Nil(t, scope)
+	})
+
+	t.Run("wildcard does not grant token exchange", func(t *testing.T) {
+		scope, err := usecase.NarrowScope([]domain.Permission{domain.PermAll}, []domain.Permission{domain.PermExchange})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidScope)
+		assert.Nil(t, scope)
+	})
+
+	t.Run("duplicates are collapsed", func(t *testing.T) {
+		requested := []domain.Permission{domain.PermCreateResource, domain.PermCreateResource}
+
+		scope, err := usecase.NarrowScope([]domain.Permission{domain.PermCreateResource}, requested)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Permission{domain.PermCreateResource}, scope)
+	})
+
+	t.Run("wildcard cannot be requested without holding it", func(t *testing.T) {
+		scope, err := usecase.NarrowScope([]domain.Permission{domain.PermCreateResource}, []domain.Permission{domain.PermAll})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidScope)
+		assert.Nil(t, scope)
+	})
+
+	t.Run("unknown permission", func(t *testing.T) {
+		scope, err := usecase.NarrowScope([]domain.Permission{domain.PermAll}, []domain.Permission{"resource:delete"})
+
+		assert.ErrorIs(t, err, domain.ErrInvalidScope)
+		assert.Nil(t, scope)
+	})
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/repository/refresh"
+	"github.com/bright-pentium/go-client-practice/internal/repository/session"
+	"github.com/google/uuid"
+)
+
+// SessionUseCase tracks where users are signed in and signs them out remotely. Whether a session is
+// live is cached for cacheTTL like the jti denylist, which bounds both how long a sign-out made by
+// another replica takes to be seen here and how often last seen is written.
+type SessionUseCase struct {
+	repo        session.ISessionRepository
+	refreshRepo refresh.IRefreshTokenRepository
+	cache       *ttlCache[uuid.UUID, bool]
+	cacheTTL    time.Duration
+}
+
+func NewSessionUseCase(repo session.ISessionRepository, refreshRepo refresh.IRefreshTokenRepository, cacheTTL time.Duration) *SessionUseCase {
+	return &SessionUseCase{repo: repo, refreshRepo: refreshRepo, cache: newTTLCache[uuid.UUID, bool](), cacheTTL: cacheTTL}
+}
+
+// StartSession records a new session of the user, ClientID is set when a client acts for the user.
+// UserID, ClientID, UserAgent, IPAddress, ExpiresAt and AMR are taken from the given session.
+func (u *SessionUseCase) StartSession(ctx context.Context, session *domain.Session) (*domain.Session, error) {
+	return u.start(ctx, session, nil, nil)
+}
+
+// StartCookieSession records a browser session of the web console like StartSession and returns the
+// values of its cookie and of its CSRF token, only their hashes are stored.
+func (u *SessionUseCase) StartCookieSession(ctx context.Context, session *domain.Session) (*domain.Session, string, string, error) {
+	cookie, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", "", err
+	}
+	csrf, err := newOpaqueToken()
+	if err != nil {
+		return nil, "", "", err
+	}
+	created, err := u.start(ctx, session, hashToken(cookie), hashToken(csrf))
+	if err != nil {
+		return nil, "", "", err
+	}
+	return created, cookie, csrf, nil
+}
+
+func (u *SessionUseCase) start(ctx context.Context, session *domain.Session, cookieHash []byte, csrfHash []byte) (*domain.Session, error) {
+	return u.repo.CreateSession(ctx, &domain.Session{
+		ID:         uuid.New(),
+		UserID:     session.UserID,
+		ClientID:   session.ClientID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		ExpiresAt:  session.ExpiresAt,
+		CookieHash: cookieHash,
+		CSRFHash:   csrfHash,
+		AMR:        session.AMR,
+	})
+}
+
+// ResolveCookie returns the live browser session of the cookie. State-changing requests must also
+// present the CSRF token handed out at login (synchronizer token pattern), safe ones pass checkCSRF false.
+func (u *SessionUseCase) ResolveCookie(ctx context.Context, cookie string, csrf string, checkCSRF bool) (*domain.Session, error) {
+	session, err := u.repo.GetSessionByCookieHash(ctx, hashToken(cookie))
+	if err != nil {
+		return nil, err
+	}
+	if err := u.Check(ctx, session.ID.String()); err != nil {
+		return nil, err
+	}
+	if checkCSRF && subtle.ConstantTimeCompare(hashToken(csrf), session.CSRFHash) != 1 {
+		return nil, domain.ErrCSRFTokenInvalid
+	}
+	return session, nil
+}
+
+// ListSessions returns the live sessions of the user, the one named by current is flagged.
+func (u *SessionUseCase) ListSessions(ctx context.Context, userID uuid.UUID, current string) ([]domain.Session, error) {
+	sessions, err := u.repo.ListSessionsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID.String() == current
+	}
+	return sessions, nil
+}
+
+// ExtendSession records the activity of a refresh token rotation, the session lives at least until expiresAt.
+// It returns nil for token families from before sessions were tracked, their tokens carry no sid.
+func (u *SessionUseCase) ExtendSession(ctx context.Context, ID uuid.UUID, expiresAt time.Time) (*domain.Session, error) {
+	session, err := u.repo.TouchSession(ctx, ID, expiresAt)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return nil, nil
+	}
+	return session, err
+}
+
+// RevokeSession signs the user out of one of their sessions, its refresh tokens included.
+func (u *SessionUseCase) RevokeSession(ctx context.Context, userID uuid.UUID, ID uuid.UUID) error {
+	if err := u.repo.RevokeSession(ctx, ID, userID); err != nil {
+		return err
+	}
+	u.cache.Set(ID, false, time.Now().Add(u.cacheTTL))
+	return u.refreshRepo.RevokeRefreshTokenFamily(ctx, ID)
+}
+
+// RevokeAllSessions signs the user out everywhere.
+func (u *SessionUseCase) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	sessions, err := u.repo.ListSessionsByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := u.repo.RevokeSessionsByUser(ctx, userID); err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		u.cache.Set(session.ID, false, time.Now().Add(u.cacheTTL))
+	}
+	return u.refreshRepo.RevokeRefreshTokensByUser(ctx, userID)
+}
+
+// Check returns ErrTokenRevoked when the session a token names was signed out, expired or is unknown.
+// Tokens without a sid are not tied to a session.
+func (u *SessionUseCase) Check(ctx context.Context, sid string) error {
+	if sid == "" {
+		return nil
+	}
+	ID, err := uuid.Parse(sid)
+	if err != nil {
+		return fmt.Errorf("%w: malformed sid", domain.ErrTokenRevoked)
+	}
+
+	live, ok := u.cache.Get(ID)
+	if !ok {
+		session, err := u.repo.TouchSession(ctx, ID, time.Time{})
+		if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+			return err
+		}
+		live = err == nil && session.RevokedAt == nil && time.Now().Before(session.ExpiresAt)
+		u.cache.Set(ID, live, time.Now().Add(u.cacheTTL))
+	}
+	if !live {
+		return fmt.Errorf("%w: session '%s' was signed out", domain.ErrTokenRevoked, sid)
+	}
+	return nil
+}
+
+// Run periodically deletes expired sessions.
+func (u *SessionUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			u.cache.Purge()
+			if _, err := u.repo.DeleteExpiredSessions(ctx); err != nil {
+				log.Printf("session purge failed: %v", err)
+			}
+		}
+	}
+}
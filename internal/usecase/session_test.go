@@ -0,0 +1,282 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	opaqueRepo "github.com/bright-pentium/go-client-practice/internal/repository/opaque"
+	refreshRepo "github.com/bright-pentium/go-client-practice/internal/repository/refresh"
+	revocationRepo "github.com/bright-pentium/go-client-practice/internal/repository/revocation"
+	sessionRepo "github.com/bright-pentium/go-client-practice/internal/repository/session"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	setup := func() (*usecase.SessionUseCase, *sessionRepo.MockSessionRepository, *refreshRepo.MockRefreshTokenRepository) {
+		mockRepo := new(sessionRepo.MockSessionRepository)
+		mockRefreshRepo := new(refreshRepo.MockRefreshTokenRepository)
+		return usecase.NewSessionUseCase(mockRepo, mockRefreshRepo, time.Minute), mockRepo, mockRefreshRepo
+	}
+	live := func(ID uuid.UUID) *domain.Session {
+		return &domain.Session{ID: ID, UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}
+	}
+
+	t.Run("start generates the id", func(t *testing.T) {
+		uc, mockRepo, _ := setup()
+		var captured *domain.Session
+		mockRepo.
+			On("CreateSession", ctx, mock.AnythingOfType("*domain.Session")).
+			Run(func(args mock.Arguments) { captured = args.Get(1).(*domain.Session) }).
+			Return(&domain.Session{}, nil)
+
+		_, err := uc.StartSession(ctx, &domain.Session{ID: uuid.Nil, UserID: userID, UserAgent: "curl/8.0", IPAddress: "10.0.0.1"})
+
+		require.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, captured.ID)
+		assert.Equal(t, userID, captured.UserID)
+		assert.Equal(t, "curl/8.0", captured.UserAgent)
+		assert.Equal(t, "10.0.0.1", captured.IPAddress)
+	})
+
+	t.Run("list flags the current session", func(t *testing.T) {
+		uc, mockRepo, _ := setup()
+		current, other := uuid.New(), uuid.New()
+		mockRepo.On("ListSessionsByUser", ctx, userID).Return([]domain.Session{*live(other), *live(current)}, nil)
+
+		sessions, err := uc.ListSessions(ctx, userID, current.String())
+
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		assert.False(t, sessions[0].Current)
+		assert.True(t, sessions[1].Current)
+	})
+
+	t.Run("live session is checked once per cache ttl", func(t *testing.T) {
+		uc, mockRepo, _ := setup()
+		ID := uuid.New()
+		mockRepo.On("TouchSession", ctx, ID, time.Time{}).Return(live(ID), nil).Once()
+
+		for i := 0; i < 2; i++ {
+			assert.NoError(t, uc.Check(ctx, ID.String()))
+		}
+		mockRepo.AssertNumberOfCalls(t, "TouchSession", 1)
+	})
+
+	t.Run("tokens without sid are not tied to a session", func(t *testing.T) {
+		uc, mockRepo, _ := setup()
+
+		assert.NoError(t, uc.Check(ctx, ""))
+		mockRepo.AssertNotCalled(t, "TouchSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	cases := []struct {
+		name    string
+		session func(ID uuid.UUID) *domain.Session
+		err     error
+	}{
+		{"revoked session", func(ID uuid.UUID) *domain.Session {
+			session := live(ID)
+			now := time.Now()
+			session.RevokedAt = &now
+			return session
+		}, nil},
+		{"expired session", func(ID uuid.UUID) *domain.Session {
+			session := live(ID)
+			session.ExpiresAt = time.Now().Add(-time.Second)
+			return session
+		}, nil},
+		{"unknown session", func(ID uuid.UUID) *domain.Session { return nil }, domain.ErrSessionNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc, mockRepo, _ := setup()
+			ID := uuid.New()
+			mockRepo.On("TouchSession", ctx, ID, time.Time{}).Return(tc.session(ID), tc.err)
+
+			err := uc.Check(ctx, ID.String())
+
+			assert.ErrorIs(t, err, domain.ErrTokenRevoked)
+		})
+	}
+
+	t.Run("repository error is not a sign out", func(t *testing.T) {
+		uc, mockRepo, _ := setup()
+		ID := uuid.New()
+		mockRepo.On("TouchSession", ctx, ID, time.Time{}).Return(nil, domain.ErrGeneralSession)
+
+		err := uc.Check(ctx, ID.String())
+
+		assert.ErrorIs(t, err, domain.ErrGeneralSession)
+		assert.NotErrorIs(t, err, domain.ErrTokenRevoked)
+	})
+
+	t.Run("revoke signs out the session and its refresh tokens", func(t *testing.T) {
+		uc, mockRepo, mockRefreshRepo := setup()
+		ID := uuid.New()
+		mockRepo.On("TouchSession", ctx, ID, time.Time{}).Return(live(ID), nil).Once()
+		mockRepo.On("RevokeSession", ctx, ID, userID).Return(nil)
+		mockRefreshRepo.On("RevokeRefreshTokenFamily", ctx, ID).Return(nil)
+
+		require.NoError(t, uc.Check(ctx, ID.String()))
+		require.NoError(t, uc.RevokeSession(ctx, userID, ID))
+
+		assert.ErrorIs(t, uc.Check(ctx, ID.String()), domain.ErrTokenRevoked)
+		mockRefreshRepo.AssertCalled(t, "RevokeRefreshTokenFamily", ctx, ID)
+	})
+
+	t.Run("revoke someone else's session", func(t *testing.T) {
+		uc, mockRepo, mockRefreshRepo := setup()
+		ID := uuid.New()
+		mockRepo.On("RevokeSession", ctx, ID, userID).Return(domain.ErrSessionNotFound)
+
+		err := uc.RevokeSession(ctx, userID, ID)
+
+		assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+		mockRefreshRepo.AssertNotCalled(t, "RevokeRefreshTokenFamily", mock.Anything, mock.Anything)
+	})
+
+	t.Run("revoke all", func(t *testing.T) {
+		uc, mockRepo, mockRefreshRepo := setup()
+		first, second := uuid.New(), uuid.New()
+		mockRepo.On("ListSessionsByUser", ctx, userID).Return([]domain.Session{*live(first), *live(second)}, nil)
+		mockRepo.On("RevokeSessionsByUser", ctx, userID).Return(nil)
+		mockRefreshRepo.On("RevokeRefreshTokensByUser", ctx, userID).Return(nil)
+
+		require.NoError(t, uc.RevokeAllSessions(ctx, userID))
+
+		assert.ErrorIs(t, uc.Check(ctx, first.String()), domain.ErrTokenRevoked)
+		assert.ErrorIs(t, uc.Check(ctx, second.String()), domain.ErrTokenRevoked)
+		mockRepo.AssertNotCalled(t, "TouchSession", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("refresh of a family from before sessions", func(t *testing.T) {
+		uc, mockRepo, _ := setup()
+		ID := uuid.New()
+		expiresAt := time.Now().Add(time.Hour)
+		mockRepo.On("TouchSession", ctx, ID, expiresAt).Return(nil, domain.ErrSessionNotFound)
+
+		session, err := uc.ExtendSession(ctx, ID, expiresAt)
+
+		require.NoError(t, err)
+		assert.Nil(t, session)
+	})
+}
+
+func TestCookieSession(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	hash := func(value string) []byte {
+		sum := sha256.Sum256([]byte(value))
+		return sum[:]
+	}
+
+	setup := func() (*usecase.SessionUseCase, *sessionRepo.MockSessionRepository) {
+		mockRepo := new(sessionRepo.MockSessionRepository)
+		return usecase.NewSessionUseCase(mockRepo, nil, time.Minute), mockRepo
+	}
+	stored := func(cookie string, csrf string) *domain.Session {
+		return &domain.Session{ID: uuid.New(), UserID: userID, ExpiresAt: time.Now().Add(time.Hour), CookieHash: hash(cookie), CSRFHash: hash(csrf)}
+	}
+
+	t.Run("only the hashes are stored", func(t *testing.T) {
+		uc, mockRepo := setup()
+		var captured *domain.Session
+		mockRepo.
+			On("CreateSession", ctx, mock.AnythingOfType("*domain.Session")).
+			Run(func(args mock.Arguments) { captured = args.Get(1).(*domain.Session) }).
+			Return(&domain.Session{}, nil)
+
+		_, cookie, csrf, err := uc.StartCookieSession(ctx, &domain.Session{UserID: userID})
+
+		require.NoError(t, err)
+		assert.NotEqual(t, cookie, csrf)
+		assert.Equal(t, hash(cookie), captured.CookieHash)
+		assert.Equal(t, hash(csrf), captured.CSRFHash)
+	})
+
+	t.Run("safe request needs no csrf token", func(t *testing.T) {
+		uc, mockRepo := setup()
+		session := stored("cookie", "csrf")
+		mockRepo.On("GetSessionByCookieHash", ctx, hash("cookie")).Return(session, nil)
+		mockRepo.On("TouchSession", ctx, session.ID, time.Time{}).Return(session, nil)
+
+		resolved, err := uc.ResolveCookie(ctx, "cookie", "", false)
+
+		require.NoError(t, err)
+		assert.Equal(t, userID, resolved.UserID)
+	})
+
+	t.Run("state-changing request", func(t *testing.T) {
+		uc, mockRepo := setup()
+		session := stored("cookie", "csrf")
+		mockRepo.On("GetSessionByCookieHash", ctx, hash("cookie")).Return(session, nil)
+		mockRepo.On("TouchSession", ctx, session.ID, time.Time{}).Return(session, nil)
+
+		_, err := uc.ResolveCookie(ctx, "cookie", "csrf", true)
+		require.NoError(t, err)
+
+		for _, csrf := range []string{"", "other"} {
+			_, err = uc.ResolveCookie(ctx, "cookie", csrf, true)
+			assert.ErrorIs(t, err, domain.ErrCSRFTokenInvalid)
+		}
+	})
+
+	t.Run("signed out session", func(t *testing.T) {
+		uc, mockRepo := setup()
+		session := stored("cookie", "csrf")
+		now := time.Now()
+		session.RevokedAt = &now
+		mockRepo.On("GetSessionByCookieHash", ctx, hash("cookie")).Return(session, nil)
+		mockRepo.On("TouchSession", ctx, session.ID, time.Time{}).Return(session, nil)
+
+		_, err := uc.ResolveCookie(ctx, "cookie", "csrf", true)
+
+		assert.ErrorIs(t, err, domain.ErrTokenRevoked)
+	})
+
+	t.Run("unknown cookie", func(t *testing.T) {
+		uc, mockRepo := setup()
+		mockRepo.On("GetSessionByCookieHash", ctx, hash("cookie")).Return(nil, domain.ErrSessionNotFound)
+
+		_, err := uc.ResolveCookie(ctx, "cookie", "", false)
+
+		assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+	})
+}
+
+func TestTokenVerifierSession(t *testing.T) {
+	ctx := context.Background()
+	keys := usecase.NewKeySet(usecase.NewHMACSigningKey("secret"))
+	mockRevocations := new(revocationRepo.MockRevocationRepository)
+	mockSessions := new(sessionRepo.MockSessionRepository)
+	verifier := usecase.NewTokenVerifier(
+		keys,
+		usecase.NewOpaqueTokenUseCase(new(opaqueRepo.MockOpaqueTokenRepository), time.Minute),
+		usecase.NewRevocationUseCase(mockRevocations, time.Minute),
+		usecase.NewSessionUseCase(mockSessions, nil, time.Minute),
+	)
+	ID := uuid.New()
+	now := time.Now()
+	mockRevocations.On("IsTokenRevoked", ctx, "jti-1").Return(false, nil)
+	mockSessions.On("TouchSession", ctx, ID, time.Time{}).Return(&domain.Session{ID: ID, ExpiresAt: now.Add(time.Hour), RevokedAt: &now}, nil)
+
+	claims := testClaims()
+	claims.ID = "jti-1"
+	claims.SessionID = ID.String()
+	token, err := keys.Sign(claims)
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(ctx, token)
+
+	assert.ErrorIs(t, err, domain.ErrTokenRevoked)
+}
@@ -5,25 +5,34 @@ import (
 	"fmt"
 
 	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/repository/refresh"
+	"github.com/bright-pentium/go-client-practice/internal/repository/session"
 	"github.com/bright-pentium/go-client-practice/internal/repository/user"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type SysUserUseCase struct {
-	repo user.IUserRepository
+	repo        user.IUserRepository
+	refreshRepo refresh.IRefreshTokenRepository
+	sessionRepo session.ISessionRepository
+	hasher      *PepperedHasher
+	policy      *PasswordPolicyUseCase
+	throttle    *LoginThrottleUseCase
 }
 
-func NewSysUserUseCase(repo user.IUserRepository) *SysUserUseCase {
-	return &SysUserUseCase{repo: repo}
+func NewSysUserUseCase(repo user.IUserRepository, refreshRepo refresh.IRefreshTokenRepository, sessionRepo session.ISessionRepository, hasher *PepperedHasher, policy *PasswordPolicyUseCase, throttle *LoginThrottleUseCase) *SysUserUseCase {
+	return &SysUserUseCase{repo: repo, refreshRepo: refreshRepo, sessionRepo: sessionRepo, hasher: hasher, policy: policy, throttle: throttle}
 }
 
 func (u *SysUserUseCase) CreateUser(ctx context.Context, name string, account string, password string) (*domain.User, error) {
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(UserPepper+password), bcrypt.DefaultCost)
+	if err := u.policy.Check(ctx, &domain.User{Name: name, Account: account}, password); err != nil {
+		return nil, err
+	}
+	passwordHash, pepperVersion, err := u.hasher.Hash(password)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", domain.ErrUserHashFail, err)
 	}
-	return u.repo.CreateUser(ctx, uuid.New(), name, account, passwordHash)
+	return u.repo.CreateUser(ctx, uuid.New(), name, account, passwordHash, pepperVersion)
 }
 
 func (u *SysUserUseCase) GetUserByID(ctx context.Context, ID uuid.UUID) (*domain.User, error) {
@@ -32,16 +41,59 @@ func (u *SysUserUseCase) GetUserByID(ctx context.Context, ID uuid.UUID) (*domain
 
 func (u *SysUserUseCase) UpdateUserByID(ctx context.Context, ID uuid.UUID, name string, password string) (*domain.User, error) {
 	var passwordHash []byte
+	var pepperVersion int
+	var current *domain.User
 	var err error
 	if password != "" {
-		passwordHash, err = bcrypt.GenerateFromPassword([]byte(UserPepper+password), bcrypt.DefaultCost)
+		if current, err = u.repo.GetUserByID(ctx, ID); err != nil {
+			return nil, err
+		}
+		// the password is checked against the name the user will have
+		candidate := *current
+		if name != "" {
+			candidate.Name = name
+		}
+		if err := u.policy.Check(ctx, &candidate, password); err != nil {
+			return nil, err
+		}
+		passwordHash, pepperVersion, err = u.hasher.Hash(password)
 		if err != nil {
 			return nil, fmt.Errorf("%w: %s", domain.ErrUserHashFail, err)
 		}
 	}
-	return u.repo.UpdateUserByID(ctx, ID, name, passwordHash)
+	user, err := u.repo.UpdateUserByID(ctx, ID, name, passwordHash, pepperVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if passwordHash != nil {
+		if err := u.policy.Record(ctx, current); err != nil {
+			return nil, err
+		}
+		// a new password ends every session opened with the old one
+		if err := u.refreshRepo.RevokeRefreshTokensByUser(ctx, ID); err != nil {
+			return nil, err
+		}
+		if err := u.sessionRepo.RevokeSessionsByUser(ctx, ID); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// UnlockUser forgets the failed logins of the user, which lifts a lockout of the account. A lockout of
+// the source IP the failures came from stays in place.
+func (u *SysUserUseCase) UnlockUser(ctx context.Context, ID uuid.UUID) error {
+	user, err := u.repo.GetUserByID(ctx, ID)
+	if err != nil {
+		return err
+	}
+	return u.throttle.Reset(ctx, userLoginKey(user.Account))
 }
 
 func (u *SysUserUseCase) DeleteUserByID(ctx context.Context, ID uuid.UUID) error {
+	if err := u.refreshRepo.RevokeRefreshTokensByUser(ctx, ID); err != nil {
+		return err
+	}
 	return u.repo.DeleteUserByID(ctx, ID)
 }
@@ -2,9 +2,12 @@ package usecase_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/bright-pentium/go-client-practice/internal/domain"
+	refreshRepo "github.com/bright-pentium/go-client-practice/internal/repository/refresh"
+	sessionRepo "github.com/bright-pentium/go-client-practice/internal/repository/session"
 	mockRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
 	"github.com/bright-pentium/go-client-practice/internal/usecase"
 	"github.com/google/uuid"
@@ -12,18 +15,16 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-const UserPepper = "3tw0d2o" // Ensure this matches the actual constant
-
 func TestCreateUserSuccess(t *testing.T) {
 	mockRepo := new(mockRepo.MockUserRepository)
-	useCase := usecase.NewSysUserUseCase(mockRepo)
+	useCase := usecase.NewSysUserUseCase(mockRepo, nil, nil, testPepperedHasher(), testPasswordPolicy(), testThrottle())
 
 	name := "Test User"
 	account := "testuser"
-	password := "password123"
+	password := "Correct-horse-7"
 	var capturedHash []byte
 
-	mockRepo.On("CreateUser", mock.Anything, mock.Anything, name, account, mock.AnythingOfType("[]uint8")).
+	mockRepo.On("CreateUser", mock.Anything, mock.Anything, name, account, mock.AnythingOfType("[]uint8"), 0).
 		Run(func(args mock.Arguments) {
 			capturedHash = args.Get(4).([]byte)
 		}).
@@ -40,7 +41,7 @@ func TestCreateUserSuccess(t *testing.T) {
 
 func TestGetUserByID(t *testing.T) {
 	mockRepo := new(mockRepo.MockUserRepository)
-	useCase := usecase.NewSysUserUseCase(mockRepo)
+	useCase := usecase.NewSysUserUseCase(mockRepo, nil, nil, testPepperedHasher(), testPasswordPolicy(), testThrottle())
 
 	id := uuid.New()
 	expectedUser := &domain.User{ID: id, Name: "Alice"}
@@ -57,18 +58,23 @@ func TestGetUserByID(t *testing.T) {
 
 func TestUpdateUserByIDSuccess(t *testing.T) {
 	mockRepo := new(mockRepo.MockUserRepository)
-	useCase := usecase.NewSysUserUseCase(mockRepo)
+	mockRefreshRepo := new(refreshRepo.MockRefreshTokenRepository)
+	mockSessionRepo := new(sessionRepo.MockSessionRepository)
+	useCase := usecase.NewSysUserUseCase(mockRepo, mockRefreshRepo, mockSessionRepo, testPepperedHasher(), testPasswordPolicy(), testThrottle())
 
 	id := uuid.New()
 	name := "Updated Name"
-	password := "newpassword"
+	password := "Battery-staple-9"
 	var capturedHash []byte
 
-	mockRepo.On("UpdateUserByID", mock.Anything, id, name, mock.AnythingOfType("[]uint8")).
+	mockRepo.On("GetUserByID", mock.Anything, id).Return(&domain.User{ID: id, Name: "Old Name", Account: "olduser"}, nil)
+	mockRepo.On("UpdateUserByID", mock.Anything, id, name, mock.AnythingOfType("[]uint8"), 0).
 		Run(func(args mock.Arguments) {
 			capturedHash = args.Get(3).([]byte)
 		}).
 		Return(&domain.User{ID: id, Name: name}, nil)
+	mockRefreshRepo.On("RevokeRefreshTokensByUser", mock.Anything, id).Return(nil)
+	mockSessionRepo.On("RevokeSessionsByUser", mock.Anything, id).Return(nil)
 
 	ctx := context.Background()
 	user, err := useCase.UpdateUserByID(ctx, id, name, password)
@@ -77,13 +83,36 @@ func TestUpdateUserByIDSuccess(t *testing.T) {
 	assert.NotNil(t, user)
 	assert.NotNil(t, capturedHash)
 	mockRepo.AssertExpectations(t)
+	mockRefreshRepo.AssertExpectations(t)
+	mockSessionRepo.AssertExpectations(t)
+}
+
+func TestUpdateUserByIDNameOnlyKeepsRefreshTokens(t *testing.T) {
+	mockRepo := new(mockRepo.MockUserRepository)
+	mockRefreshRepo := new(refreshRepo.MockRefreshTokenRepository)
+	useCase := usecase.NewSysUserUseCase(mockRepo, mockRefreshRepo, nil, testPepperedHasher(), testPasswordPolicy(), testThrottle())
+
+	id := uuid.New()
+	name := "Updated Name"
+
+	mockRepo.On("UpdateUserByID", mock.Anything, id, name, []byte(nil), 0).Return(&domain.User{ID: id, Name: name}, nil)
+
+	ctx := context.Background()
+	user, err := useCase.UpdateUserByID(ctx, id, name, "")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, user)
+	mockRepo.AssertExpectations(t)
+	mockRefreshRepo.AssertNotCalled(t, "RevokeRefreshTokensByUser", mock.Anything, mock.Anything)
 }
 
 func TestDeleteUserByID(t *testing.T) {
 	mockRepo := new(mockRepo.MockUserRepository)
-	useCase := usecase.NewSysUserUseCase(mockRepo)
+	mockRefreshRepo := new(refreshRepo.MockRefreshTokenRepository)
+	useCase := usecase.NewSysUserUseCase(mockRepo, mockRefreshRepo, nil, testPepperedHasher(), testPasswordPolicy(), testThrottle())
 
 	id := uuid.New()
+	mockRefreshRepo.On("RevokeRefreshTokensByUser", mock.Anything, id).Return(nil)
 	mockRepo.On("DeleteUserByID", mock.Anything, id).Return(nil)
 
 	ctx := context.Background()
@@ -91,17 +120,30 @@ func TestDeleteUserByID(t *testing.T) {
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockRefreshRepo.AssertExpectations(t)
 }
 
 func TestCreateUserHashFail(t *testing.T) {
-	useCase := usecase.NewSysUserUseCase(nil)
-
-	// Override bcrypt to fail intentionally via an invalid cost (not directly mockable)
-	longPassword := string(make([]byte, 1<<20)) // huge password to likely trigger bcrypt error
+	useCase := usecase.NewSysUserUseCase(nil, nil, nil, usecase.NewPepperedHasher(failingHasher{}, map[int]string{0: testPepper}), testPasswordPolicy(), testThrottle())
 	ctx := context.Background()
 
-	user, err := useCase.CreateUser(ctx, "Foo", "bar", longPassword)
+	user, err := useCase.CreateUser(ctx, "Foo", "bar", "Correct-horse-7")
 
 	assert.Nil(t, user)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrUserHashFail)
+}
+
+// failingHasher stands in for a hasher that cannot hash, like bcrypt refusing a password over 72 bytes.
+type failingHasher struct{}
+
+func (failingHasher) Hash(password string) ([]byte, error) {
+	return nil, errors.New("cannot hash")
+}
+
+func (failingHasher) Verify(hash []byte, password string) (bool, bool) {
+	return false, false
+}
+
+func (failingHasher) Recognizes(hash []byte) bool {
+	return false
 }
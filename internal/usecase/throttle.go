@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	repo "github.com/bright-pentium/go-client-practice/internal/repository/loginattempt"
+	"github.com/google/uuid"
+)
+
+// LoginThrottleUseCase slows brute force down: failed logins are counted per account or client and per
+// source IP, past a threshold each failure locks the key out for twice as long as the previous one.
+type LoginThrottleUseCase struct {
+	repo   repo.ILoginAttemptRepository
+	policy domain.LoginThrottlePolicy
+}
+
+func NewLoginThrottleUseCase(repo repo.ILoginAttemptRepository, policy domain.LoginThrottlePolicy) *LoginThrottleUseCase {
+	return &LoginThrottleUseCase{repo: repo, policy: policy}
+}
+
+// Keys of the login attempts, accounts are counted whether they exist or not so a lockout does not
+// tell them apart.
+func userLoginKey(account string) string { return "user:" + account }
+
+func clientLoginKey(ID uuid.UUID) string { return "client:" + ID.String() }
+
+func sourceLoginKey(ip string) string { return "ip:" + ip }
+
+// loginKeys are the keys a login of subject from ip is counted under, an unknown ip is not counted.
+func loginKeys(subject string, ip string) []string {
+	if ip == "" {
+		return []string{subject}
+	}
+	return []string{subject, sourceLoginKey(ip)}
+}
+
+// Check returns how long logins under the keys remain locked out, 0 when they are not.
+func (u *LoginThrottleUseCase) Check(ctx context.Context, keys ...string) (time.Duration, error) {
+	attempts, err := u.repo.ListLoginAttempts(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+	var retryAfter time.Duration
+	now := time.Now()
+	for _, attempt := range attempts {
+		if attempt.LockedUntil != nil && attempt.LockedUntil.Sub(now) > retryAfter {
+			retryAfter = attempt.LockedUntil.Sub(now)
+		}
+	}
+	return retryAfter, nil
+}
+
+// Fail counts a failed login under the keys and locks out those past their threshold.
+func (u *LoginThrottleUseCase) Fail(ctx context.Context, keys ...string) error {
+	forgetBefore := time.Now().Add(-u.policy.FailureWindow)
+	for _, key := range keys {
+		attempt, err := u.repo.RecordLoginFailure(ctx, key, forgetBefore)
+		if err != nil {
+			return err
+		}
+		threshold := u.policy.MaxFailures
+		if strings.HasPrefix(key, sourceLoginKey("")) {
+			threshold = u.policy.MaxFailuresPerIP
+		}
+		if threshold <= 0 || attempt.Failures < threshold {
+			continue
+		}
+		if err := u.repo.LockLogin(ctx, key, time.Now().Add(u.lockout(attempt.Failures-threshold))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// failed counts a failed login under the keys and returns loginErr, the failure the login is reported as.
+func (u *LoginThrottleUseCase) failed(ctx context.Context, loginErr error, keys ...string) error {
+	if err := u.Fail(ctx, keys...); err != nil {
+		return err
+	}
+	return loginErr
+}
+
+// lockout doubles with every failure past the threshold, up to MaxLockout.
+func (u *LoginThrottleUseCase) lockout(excess int) time.Duration {
+	lockout := u.policy.Lockout
+	for i := 0; i < excess && lockout < u.policy.MaxLockout; i++ {
+		lockout *= 2
+	}
+	return min(lockout, u.policy.MaxLockout)
+}
+
+// Reset forgets the failures under key, after a successful login or when an administrator unlocks it.
+func (u *LoginThrottleUseCase) Reset(ctx context.Context, key string) error {
+	return u.repo.ResetLoginAttempts(ctx, key)
+}
+
+// Run periodically deletes the login attempts that are forgotten.
+func (u *LoginThrottleUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := u.repo.DeleteLoginAttempts(ctx, time.Now().Add(-u.policy.FailureWindow)); err != nil {
+				log.Printf("login attempt purge failed: %v", err)
+			}
+		}
+	}
+}
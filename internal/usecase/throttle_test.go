@@ -0,0 +1,227 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	clientRepo "github.com/bright-pentium/go-client-practice/internal/repository/client"
+	attemptRepo "github.com/bright-pentium/go-client-practice/internal/repository/loginattempt"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testThrottlePolicy = domain.LoginThrottlePolicy{
+	MaxFailures:      3,
+	MaxFailuresPerIP: 10,
+	Lockout:          30 * time.Second,
+	MaxLockout:       5 * time.Minute,
+	FailureWindow:    15 * time.Minute,
+}
+
+// testThrottle never locks out, for tests not about brute force protection.
+func testThrottle() *usecase.LoginThrottleUseCase {
+	mockRepo := new(attemptRepo.MockLoginAttemptRepository)
+	mockRepo.On("ListLoginAttempts", mock.Anything, mock.Anything).Return([]domain.LoginAttempt{}, nil).Maybe()
+	mockRepo.On("RecordLoginFailure", mock.Anything, mock.Anything, mock.Anything).Return(&domain.LoginAttempt{Failures: 1}, nil).Maybe()
+	mockRepo.On("ResetLoginAttempts", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return usecase.NewLoginThrottleUseCase(mockRepo, testThrottlePolicy)
+}
+
+// lockedFor matches a lockout ending about d from now.
+func lockedFor(d time.Duration) interface{} {
+	return mock.MatchedBy(func(until time.Time) bool {
+		remaining := time.Until(until)
+		return remaining > d-5*time.Second && remaining <= d
+	})
+}
+
+func TestLoginThrottleCheck(t *testing.T) {
+	ctx := context.Background()
+	soon, later, past := time.Now().Add(time.Minute), time.Now().Add(time.Hour), time.Now().Add(-time.Minute)
+	mockRepo := new(attemptRepo.MockLoginAttemptRepository)
+	mockRepo.On("ListLoginAttempts", mock.Anything, []string{"user:alice", "ip:203.0.113.7"}).Return([]domain.LoginAttempt{
+		{Key: "user:alice", Failures: 3, LockedUntil: &soon},
+		{Key: "ip:203.0.113.7", Failures: 12, LockedUntil: &later},
+	}, nil)
+	mockRepo.On("ListLoginAttempts", mock.Anything, []string{"user:bob"}).Return([]domain.LoginAttempt{
+		{Key: "user:bob", Failures: 4, LockedUntil: &past},
+	}, nil)
+	throttle := usecase.NewLoginThrottleUseCase(mockRepo, testThrottlePolicy)
+
+	retryAfter, err := throttle.Check(ctx, "user:alice", "ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour.Seconds(), retryAfter.Seconds(), 5)
+
+	retryAfter, err = throttle.Check(ctx, "user:bob")
+	require.NoError(t, err)
+	assert.Zero(t, retryAfter)
+}
+
+func TestLoginThrottleFail(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		name     string
+		key      string
+		failures int
+		lockout  time.Duration
+	}{
+		{"below the threshold", "user:alice", 2, 0},
+		{"at the threshold", "user:alice", 3, 30 * time.Second},
+		{"doubles past the threshold", "user:alice", 5, 2 * time.Minute},
+		{"capped", "user:alice", 40, 5 * time.Minute},
+		{"ip below its threshold", "ip:203.0.113.7", 9, 0},
+		{"ip at its threshold", "ip:203.0.113.7", 10, 30 * time.Second},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockRepo := new(attemptRepo.MockLoginAttemptRepository)
+			mockRepo.On("RecordLoginFailure", mock.Anything, tc.key, mock.MatchedBy(func(forgetBefore time.Time) bool {
+				return time.Since(forgetBefore) >= testThrottlePolicy.FailureWindow
+			})).Return(&domain.LoginAttempt{Key: tc.key, Failures: tc.failures}, nil)
+			if tc.lockout > 0 {
+				mockRepo.On("LockLogin", mock.Anything, tc.key, lockedFor(tc.lockout)).Return(nil)
+			}
+			throttle := usecase.NewLoginThrottleUseCase(mockRepo, testThrottlePolicy)
+
+			require.NoError(t, throttle.Fail(ctx, tc.key))
+
+			mockRepo.AssertExpectations(t)
+			if tc.lockout == 0 {
+				mockRepo.AssertNotCalled(t, "LockLogin", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestLoginUserThrottled(t *testing.T) {
+	ctx := context.Background()
+	hash, version, err := testPepperedHasher().Hash("password")
+	require.NoError(t, err)
+	user := &domain.User{ID: uuid.New(), Account: "alice", PasswordHash: hash, PepperVersion: version}
+	keys := []string{"user:alice", "ip:203.0.113.7"}
+
+	t.Run("locked out login is not tried", func(t *testing.T) {
+		until := time.Now().Add(time.Minute)
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+		mockAttemptRepo.On("ListLoginAttempts", mock.Anything, keys).Return([]domain.LoginAttempt{{Key: "user:alice", Failures: 3, LockedUntil: &until}}, nil)
+		useCase := usecase.NewUserUseCase(mockUserRepo, testPepperedHasher(), usecase.NewLoginThrottleUseCase(mockAttemptRepo, testThrottlePolicy))
+
+		logged, err := useCase.LoginUser(ctx, "alice", "password", "203.0.113.7")
+
+		assert.Nil(t, logged)
+		assert.ErrorIs(t, err, domain.ErrUserLoginFail)
+		var throttled *domain.LoginThrottledError
+		require.ErrorAs(t, err, &throttled)
+		assert.InDelta(t, time.Minute.Seconds(), throttled.RetryAfter.Seconds(), 5)
+		mockUserRepo.AssertNotCalled(t, "GetUserByAccount", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failure is counted per account and ip", func(t *testing.T) {
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockUserRepo.On("GetUserByAccount", mock.Anything, "alice").Return(user, nil)
+		mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+		mockAttemptRepo.On("ListLoginAttempts", mock.Anything, keys).Return([]domain.LoginAttempt{}, nil)
+		mockAttemptRepo.On("RecordLoginFailure", mock.Anything, "user:alice", mock.Anything).Return(&domain.LoginAttempt{Failures: 1}, nil)
+		mockAttemptRepo.On("RecordLoginFailure", mock.Anything, "ip:203.0.113.7", mock.Anything).Return(&domain.LoginAttempt{Failures: 1}, nil)
+		useCase := usecase.NewUserUseCase(mockUserRepo, testPepperedHasher(), usecase.NewLoginThrottleUseCase(mockAttemptRepo, testThrottlePolicy))
+
+		_, err := useCase.LoginUser(ctx, "alice", "wrong", "203.0.113.7")
+
+		assert.ErrorIs(t, err, domain.ErrUserLoginFail)
+		assert.NotErrorIs(t, err, domain.ErrLoginThrottled)
+		mockAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown account is counted the same", func(t *testing.T) {
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockUserRepo.On("GetUserByAccount", mock.Anything, "nobody").Return(nil, domain.ErrUserNotFound)
+		mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+		mockAttemptRepo.On("ListLoginAttempts", mock.Anything, []string{"user:nobody"}).Return([]domain.LoginAttempt{}, nil)
+		mockAttemptRepo.On("RecordLoginFailure", mock.Anything, "user:nobody", mock.Anything).Return(&domain.LoginAttempt{Failures: 3}, nil)
+		mockAttemptRepo.On("LockLogin", mock.Anything, "user:nobody", lockedFor(30*time.Second)).Return(nil)
+		useCase := usecase.NewUserUseCase(mockUserRepo, testPepperedHasher(), usecase.NewLoginThrottleUseCase(mockAttemptRepo, testThrottlePolicy))
+
+		_, err := useCase.LoginUser(ctx, "nobody", "password", "")
+
+		assert.ErrorIs(t, err, domain.ErrUserLoginFail)
+		mockAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("success forgets the failures of the account only", func(t *testing.T) {
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockUserRepo.On("GetUserByAccount", mock.Anything, "alice").Return(user, nil)
+		mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+		mockAttemptRepo.On("ListLoginAttempts", mock.Anything, keys).Return([]domain.LoginAttempt{}, nil)
+		mockAttemptRepo.On("ResetLoginAttempts", mock.Anything, "user:alice").Return(nil)
+		useCase := usecase.NewUserUseCase(mockUserRepo, testPepperedHasher(), usecase.NewLoginThrottleUseCase(mockAttemptRepo, testThrottlePolicy))
+
+		logged, err := useCase.LoginUser(ctx, "alice", "password", "203.0.113.7")
+
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, logged.ID)
+		mockAttemptRepo.AssertExpectations(t)
+		mockAttemptRepo.AssertNotCalled(t, "ResetLoginAttempts", mock.Anything, "ip:203.0.113.7")
+	})
+
+	t.Run("attempt store failure fails the login", func(t *testing.T) {
+		mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+		mockAttemptRepo.On("ListLoginAttempts", mock.Anything, mock.Anything).Return(nil, domain.ErrGeneralLoginAttempt)
+		useCase := usecase.NewUserUseCase(nil, testPepperedHasher(), usecase.NewLoginThrottleUseCase(mockAttemptRepo, testThrottlePolicy))
+
+		_, err := useCase.LoginUser(ctx, "alice", "password", "")
+
+		assert.ErrorIs(t, err, domain.ErrGeneralLoginAttempt)
+		assert.NotErrorIs(t, err, domain.ErrUserLoginFail)
+	})
+}
+
+func TestClientLoginThrottled(t *testing.T) {
+	clientID := uuid.New()
+	until := time.Now().Add(time.Minute)
+	mockClientRepo := new(clientRepo.MockClientRepository)
+	mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+	mockAttemptRepo.On("ListLoginAttempts", mock.Anything, []string{"client:" + clientID.String(), "ip:203.0.113.7"}).
+		Return([]domain.LoginAttempt{{Key: "ip:203.0.113.7", Failures: 10, LockedUntil: &until}}, nil)
+	useCase := usecase.NewClientUseCase(mockClientRepo, testPepperedHasher(), usecase.NewLoginThrottleUseCase(mockAttemptRepo, testThrottlePolicy))
+
+	client, err := useCase.ClientLogin(context.Background(), clientID, "secret", "203.0.113.7")
+
+	assert.Nil(t, client)
+	assert.ErrorIs(t, err, domain.ErrClientLoginFail)
+	assert.ErrorIs(t, err, domain.ErrLoginThrottled)
+	mockClientRepo.AssertNotCalled(t, "GetClientByID", mock.Anything, mock.Anything)
+}
+
+func TestUnlockUser(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	t.Run("forgets the failures of the account", func(t *testing.T) {
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockUserRepo.On("GetUserByID", mock.Anything, id).Return(&domain.User{ID: id, Account: "alice"}, nil)
+		mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+		mockAttemptRepo.On("ResetLoginAttempts", mock.Anything, "user:alice").Return(nil)
+		useCase := usecase.NewSysUserUseCase(mockUserRepo, nil, nil, testPepperedHasher(), testPasswordPolicy(), usecase.NewLoginThrottleUseCase(mockAttemptRepo, testThrottlePolicy))
+
+		assert.NoError(t, useCase.UnlockUser(ctx, id))
+		mockAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockUserRepo.On("GetUserByID", mock.Anything, id).Return(nil, domain.ErrUserNotFound)
+		useCase := usecase.NewSysUserUseCase(mockUserRepo, nil, nil, testPepperedHasher(), testPasswordPolicy(), testThrottle())
+
+		err := useCase.UnlockUser(ctx, id)
+
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+}
@@ -3,36 +3,73 @@ package usecase
 import (
 	"context"
 	"errors"
+	"log"
 
 	"github.com/bright-pentium/go-client-practice/internal/domain"
 	repo "github.com/bright-pentium/go-client-practice/internal/repository/user"
-	"golang.org/x/crypto/bcrypt"
-)
-
-const (
-	UserPepper string = "3tw0d2o"
+	"github.com/google/uuid"
 )
 
 type UserUseCase struct {
-	repo repo.IUserRepository
+	repo     repo.IUserRepository
+	hasher   *PepperedHasher
+	throttle *LoginThrottleUseCase
 }
 
-func NewUserUseCase(repo repo.IUserRepository) *UserUseCase {
-	return &UserUseCase{repo: repo}
+func NewUserUseCase(repo repo.IUserRepository, hasher *PepperedHasher, throttle *LoginThrottleUseCase) *UserUseCase {
+	return &UserUseCase{repo: repo, hasher: hasher, throttle: throttle}
 }
 
-func (u *UserUseCase) LoginUser(ctx context.Context, account string, password string) (*domain.User, error) {
+func (u *UserUseCase) GetUserByID(ctx context.Context, ID uuid.UUID) (*domain.User, error) {
+	return u.repo.GetUserByID(ctx, ID)
+}
+
+// LoginUser checks the password of the account, ip is where the login comes from. While the account or
+// the ip is locked out the password is not even checked and a LoginThrottledError is returned.
+func (u *UserUseCase) LoginUser(ctx context.Context, account string, password string, ip string) (*domain.User, error) {
+	keys := loginKeys(userLoginKey(account), ip)
+	retryAfter, err := u.throttle.Check(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter > 0 {
+		return nil, &domain.LoginThrottledError{RetryAfter: retryAfter, Err: domain.ErrUserLoginFail}
+	}
+
 	user, err := u.repo.GetUserByAccount(ctx, account)
 	if err != nil {
 		if errors.Is(err, domain.ErrUserNotFound) {
-			return nil, domain.ErrUserLoginFail
+			return nil, u.throttle.failed(ctx, domain.ErrUserLoginFail, keys...)
 		} else {
 			return nil, err
 		}
 	}
 
-	if bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(UserPepper+password)) != nil {
-		return nil, domain.ErrUserLoginFail
+	ok, rehash := u.hasher.Verify(user.PasswordHash, user.PepperVersion, password)
+	if !ok {
+		return nil, u.throttle.failed(ctx, domain.ErrUserLoginFail, keys...)
+	}
+	if err := u.throttle.Reset(ctx, userLoginKey(account)); err != nil {
+		return nil, err
+	}
+
+	// the password is only ever known here, upgrade hashes of an earlier algorithm, earlier parameters or an earlier pepper
+	if rehash {
+		if err := u.rehash(ctx, user, password); err != nil {
+			log.Printf("password rehash of user '%s' failed: %v", user.ID, err)
+		}
 	}
 	return user, nil
 }
+
+func (u *UserUseCase) rehash(ctx context.Context, user *domain.User, password string) error {
+	passwordHash, pepperVersion, err := u.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	if _, err := u.repo.UpdateUserByID(ctx, user.ID, "", passwordHash, pepperVersion); err != nil {
+		return err
+	}
+	user.PasswordHash, user.PepperVersion = passwordHash, pepperVersion
+	return nil
+}
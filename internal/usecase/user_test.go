@@ -3,6 +3,7 @@ package usecase_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/bright-pentium/go-client-practice/internal/domain"
@@ -12,18 +13,20 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // Helper function to hash passwords for testing
 func hashPassword(password string) []byte {
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(usecase.UserPepper+password), bcrypt.DefaultCost)
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(testPepper+password), bcrypt.DefaultCost)
 	return hashedPassword
 }
 
 func TestLoginUser(t *testing.T) {
 	mockRepo := new(userRepo.MockUserRepository)
-	userUseCase := usecase.NewUserUseCase(mockRepo)
+	userUseCase := usecase.NewUserUseCase(mockRepo, testPepperedHasher(), testThrottle())
 	ctx := context.Background()
 
 	// --- Test Case 1: Successful Login ---
@@ -41,10 +44,13 @@ func TestLoginUser(t *testing.T) {
 
 		// Set up the mock expectation: when GetUserByAccount is called, return expectedUser
 		mockRepo.On("GetUserByAccount", ctx, account).Return(expectedUser, nil).Once()
+		// the bcrypt hash is upgraded to Argon2id
+		mockRepo.On("UpdateUserByID", ctx, expectedUser.ID, "", mock.AnythingOfType("[]uint8"), 0).Return(expectedUser, nil).Once()
 
-		user, err := userUseCase.LoginUser(ctx, account, password)
+		user, err := userUseCase.LoginUser(ctx, account, password, "")
 
 		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(user.PasswordHash), "$argon2id$"))
 		assert.Equal(t, expectedUser.ID, user.ID)
 		assert.Equal(t, expectedUser.Account, user.Account)
 		assert.Equal(t, expectedUser.Name, user.Name)
@@ -59,7 +65,7 @@ func TestLoginUser(t *testing.T) {
 		// Set up the mock expectation: GetUserByAccount returns ErrUserNotFound
 		mockRepo.On("GetUserByAccount", ctx, account).Return((*domain.User)(nil), domain.ErrUserNotFound).Once()
 
-		user, err := userUseCase.LoginUser(ctx, account, password)
+		user, err := userUseCase.LoginUser(ctx, account, password, "")
 
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, domain.ErrUserLoginFail)) // Expect ErrUserLoginFail due to not found
@@ -84,7 +90,7 @@ func TestLoginUser(t *testing.T) {
 		// Set up the mock expectation: GetUserByAccount returns the user
 		mockRepo.On("GetUserByAccount", ctx, account).Return(existingUser, nil).Once()
 
-		user, err := userUseCase.LoginUser(ctx, account, incorrectPassword)
+		user, err := userUseCase.LoginUser(ctx, account, incorrectPassword, "")
 
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, domain.ErrUserLoginFail)) // Expect ErrUserLoginFail due to wrong password
@@ -101,7 +107,7 @@ func TestLoginUser(t *testing.T) {
 		// Set up the mock expectation: GetUserByAccount returns a generic error
 		mockRepo.On("GetUserByAccount", ctx, account).Return((*domain.User)(nil), repoError).Once()
 
-		user, err := userUseCase.LoginUser(ctx, account, password)
+		user, err := userUseCase.LoginUser(ctx, account, password, "")
 
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, repoError))                // Expect the original repository error to be propagated
@@ -126,7 +132,7 @@ func TestLoginUser(t *testing.T) {
 
 		mockRepo.On("GetUserByAccount", ctx, account).Return(existingUser, nil).Once()
 
-		user, err := userUseCase.LoginUser(ctx, account, password)
+		user, err := userUseCase.LoginUser(ctx, account, password, "")
 
 		assert.Error(t, err)
 		assert.True(t, errors.Is(err, domain.ErrUserLoginFail)) // bcrypt.CompareHashAndPassword will return error
@@ -134,3 +140,95 @@ func TestLoginUser(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestLoginUserRehash(t *testing.T) {
+	ctx := context.Background()
+	password := "correctpassword"
+	argon2idHash := func(params usecase.Argon2idParams) []byte {
+		hash, err := usecase.NewArgon2idHasher(params).Hash(testPepper + password)
+		require.NoError(t, err)
+		return hash
+	}
+
+	t.Run("current hash is kept", func(t *testing.T) {
+		mockRepo := new(userRepo.MockUserRepository)
+		user := &domain.User{ID: uuid.New(), Account: "alice", PasswordHash: argon2idHash(testArgon2id)}
+		mockRepo.On("GetUserByAccount", ctx, "alice").Return(user, nil)
+
+		_, err := usecase.NewUserUseCase(mockRepo, testPepperedHasher(), testThrottle()).LoginUser(ctx, "alice", password, "")
+
+		require.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "UpdateUserByID", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("hash with old parameters is upgraded", func(t *testing.T) {
+		mockRepo := new(userRepo.MockUserRepository)
+		user := &domain.User{ID: uuid.New(), Account: "alice", PasswordHash: argon2idHash(usecase.Argon2idParams{Memory: 32, Iterations: 1, Parallelism: 1})}
+		var upgraded []byte
+		mockRepo.On("GetUserByAccount", ctx, "alice").Return(user, nil)
+		mockRepo.
+			On("UpdateUserByID", ctx, user.ID, "", mock.AnythingOfType("[]uint8"), 0).
+			Run(func(args mock.Arguments) { upgraded = args.Get(3).([]byte) }).
+			Return(user, nil)
+
+		_, err := usecase.NewUserUseCase(mockRepo, testPepperedHasher(), testThrottle()).LoginUser(ctx, "alice", password, "")
+
+		require.NoError(t, err)
+		ok, rehash := testHasher().Verify(upgraded, testPepper+password)
+		assert.True(t, ok)
+		assert.False(t, rehash)
+	})
+
+	t.Run("failed upgrade does not fail the login", func(t *testing.T) {
+		mockRepo := new(userRepo.MockUserRepository)
+		user := &domain.User{ID: uuid.New(), Account: "alice", PasswordHash: hashPassword(password)}
+		legacy := user.PasswordHash
+		mockRepo.On("GetUserByAccount", ctx, "alice").Return(user, nil)
+		mockRepo.On("UpdateUserByID", ctx, user.ID, "", mock.AnythingOfType("[]uint8"), 0).Return(nil, domain.ErrGeneralUser)
+
+		logged, err := usecase.NewUserUseCase(mockRepo, testPepperedHasher(), testThrottle()).LoginUser(ctx, "alice", password, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, legacy, logged.PasswordHash)
+	})
+}
+
+func TestLoginUserPepperRotation(t *testing.T) {
+	ctx := context.Background()
+	password := "correctpassword"
+	rotated := usecase.NewPepperedHasher(testHasher(), map[int]string{0: testPepper, 1: "new-pepper"})
+	hash, version, err := testPepperedHasher().Hash(password)
+	require.NoError(t, err)
+
+	mockRepo := new(userRepo.MockUserRepository)
+	user := &domain.User{ID: uuid.New(), Account: "alice", PasswordHash: hash, PepperVersion: version}
+	mockRepo.On("GetUserByAccount", ctx, "alice").Return(user, nil)
+	mockRepo.On("UpdateUserByID", ctx, user.ID, "", mock.AnythingOfType("[]uint8"), 1).Return(user, nil)
+
+	logged, err := usecase.NewUserUseCase(mockRepo, rotated, testThrottle()).LoginUser(ctx, "alice", password, "")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, logged.PepperVersion)
+	ok, rehash := rotated.Verify(logged.PasswordHash, logged.PepperVersion, password)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+}
+
+func TestUserUseCaseGetUserByID(t *testing.T) {
+	mockRepo := new(userRepo.MockUserRepository)
+	userUseCase := usecase.NewUserUseCase(mockRepo, testPepperedHasher(), testThrottle())
+	ctx := context.Background()
+
+	expectedUser := &domain.User{ID: uuid.New(), Name: "Test User", Account: "test@example.com"}
+	missingID := uuid.New()
+	mockRepo.On("GetUserByID", ctx, expectedUser.ID).Return(expectedUser, nil)
+	mockRepo.On("GetUserByID", ctx, missingID).Return(nil, domain.ErrUserNotFound)
+
+	user, err := userUseCase.GetUserByID(ctx, expectedUser.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedUser, user)
+
+	user, err = userUseCase.GetUserByID(ctx, missingID)
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	assert.Nil(t, user)
+}
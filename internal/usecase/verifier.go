@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+)
+
+// TokenVerifier resolves a presented access token to its claims whatever its format: JWTs are verified
+// against the key set and the jti denylist, opaque tokens are looked up. Either way the session the
+// token was issued under must not have been signed out.
+type TokenVerifier struct {
+	keys        *KeySet
+	opaque      *OpaqueTokenUseCase
+	revocations *RevocationUseCase
+	sessions    *SessionUseCase
+}
+
+func NewTokenVerifier(keys *KeySet, opaque *OpaqueTokenUseCase, revocations *RevocationUseCase, sessions *SessionUseCase) *TokenVerifier {
+	return &TokenVerifier{keys: keys, opaque: opaque, revocations: revocations, sessions: sessions}
+}
+
+// IsJWT tells a compact JWS apart from an opaque token, which is unpadded base64url and has no dots.
+func IsJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Verify returns the claims of a live access token. It returns ErrInvalidAccessToken for ID tokens and for
+// tokens that are malformed, expired, unknown or revoked opaque tokens, and ErrTokenRevoked for denylisted
+// JWTs and tokens of a signed out session.
+func (v *TokenVerifier) Verify(ctx context.Context, token string) (*domain.JwtClaims, error) {
+	claims, err := v.resolve(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.sessions.Check(ctx, claims.SessionID); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (v *TokenVerifier) resolve(ctx context.Context, token string) (*domain.JwtClaims, error) {
+	if !IsJWT(token) {
+		return v.opaque.ResolveOpaqueToken(ctx, token)
+	}
+	claims, err := v.keys.Parse(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidAccessToken, err.Error())
+	}
+	if claims.Type != domain.UserType && claims.Type != domain.ClientType {
+		// an ID token proves nothing about authorization
+		return nil, fmt.Errorf("%w: not an access token", domain.ErrInvalidAccessToken)
+	}
+	if err := v.revocations.Check(ctx, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Revoke revokes a token Verify accepted, an opaque one at once and a JWT by denylisting its jti.
+func (v *TokenVerifier) Revoke(ctx context.Context, token string, claims *domain.JwtClaims) error {
+	if !IsJWT(token) {
+		return v.opaque.RevokeOpaqueToken(ctx, token)
+	}
+	var expiresAt time.Time
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	return v.revocations.RevokeToken(ctx, claims.ID, expiresAt)
+}
@@ -0,0 +1,318 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	"github.com/bright-pentium/go-client-practice/internal/repository/user"
+	"github.com/bright-pentium/go-client-practice/internal/repository/webauthn"
+	"github.com/google/uuid"
+)
+
+// WebAuthnUseCase registers passkeys and security keys (WebAuthn Level 2) and verifies logins with them,
+// as a second factor after the password or as a passwordless login. Attestation is not asked for: the
+// credential is trusted as the one the user registered, not for its make or model.
+type WebAuthnUseCase struct {
+	repo         webauthn.IWebAuthnRepository
+	userRepo     user.IUserRepository
+	throttle     *LoginThrottleUseCase
+	rp           domain.WebAuthnRelyingParty
+	origins      []string
+	challengeTTL time.Duration
+}
+
+// NewWebAuthnUseCase takes the relying party the credentials are scoped to and the origins of the
+// pages allowed to run the ceremonies, which must be on the relying party ID or a subdomain of it.
+func NewWebAuthnUseCase(repo webauthn.IWebAuthnRepository, userRepo user.IUserRepository, throttle *LoginThrottleUseCase, rp domain.WebAuthnRelyingParty, origins []string, challengeTTL time.Duration) *WebAuthnUseCase {
+	return &WebAuthnUseCase{repo: repo, userRepo: userRepo, throttle: throttle, rp: rp, origins: origins, challengeTTL: challengeTTL}
+}
+
+// passkeyLoginKey is the key failed passwordless logins with a credential are counted under.
+func passkeyLoginKey(credentialID []byte) string {
+	return "passkey:" + base64.RawURLEncoding.EncodeToString(credentialID)
+}
+
+// BeginRegistration returns the options of navigator.credentials.create() for a new credential of the user.
+func (u *WebAuthnUseCase) BeginRegistration(ctx context.Context, user *domain.User) (*domain.WebAuthnCreationOptions, error) {
+	credentials, err := u.repo.ListCredentialsByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := u.newChallenge(ctx, &user.ID, domain.WebAuthnCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]domain.WebAuthnCredentialParameter, len(coseAlgorithms))
+	for i, alg := range coseAlgorithms {
+		params[i] = domain.WebAuthnCredentialParameter{Type: "public-key", Alg: alg}
+	}
+	return &domain.WebAuthnCreationOptions{
+		RP:               u.rp,
+		User:             domain.WebAuthnUserEntity{ID: base64.RawURLEncoding.EncodeToString(user.ID[:]), Name: user.Account, DisplayName: user.Name},
+		Challenge:        challenge,
+		PubKeyCredParams: params,
+		Timeout:          u.challengeTTL.Milliseconds(),
+		// an authenticator holding one of them refuses to register twice
+		ExcludeCredentials: descriptors(credentials),
+		// a discoverable credential also signs in without a password, user verification is only
+		// required by then
+		AuthenticatorSelection: domain.WebAuthnAuthenticatorSelection{ResidentKey: "preferred", UserVerification: "preferred"},
+		Attestation:            "none",
+	}, nil
+}
+
+// FinishRegistration verifies the response of the authenticator to the options of BeginRegistration and
+// stores its credential under name.
+func (u *WebAuthnUseCase) FinishRegistration(ctx context.Context, userID uuid.UUID, name string, attestation *domain.WebAuthnAttestation) (*domain.WebAuthnCredential, error) {
+	if err := u.verifyClientData(ctx, attestation.ClientDataJSON, domain.WebAuthnCreate, &userID); err != nil {
+		return nil, err
+	}
+
+	decoded, rest, err := decodeCBOR(attestation.AttestationObject)
+	if err != nil || len(rest) != 0 {
+		return nil, fmt.Errorf("%w: malformed attestation object", domain.ErrWebAuthnInvalid)
+	}
+	object, _ := decoded.(map[interface{}]interface{})
+	rawAuthData, _ := object["authData"].([]byte)
+	// the attestation statement is not verified, only the authenticator data is used
+	if _, ok := object["fmt"].(string); !ok {
+		return nil, fmt.Errorf("%w: attestation object without fmt", domain.ErrWebAuthnInvalid)
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrWebAuthnInvalid, err.Error())
+	}
+	if err := u.checkAuthenticatorData(authData, false); err != nil {
+		return nil, err
+	}
+	if authData.Flags&authDataAttestedCredData == 0 || !bytes.Equal(authData.CredentialID, attestation.CredentialID) {
+		return nil, fmt.Errorf("%w: attested credential does not match the credential id", domain.ErrWebAuthnInvalid)
+	}
+	_, alg, err := parseCOSEKey(authData.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrWebAuthnInvalid, err.Error())
+	}
+
+	return u.repo.CreateCredential(ctx, &domain.WebAuthnCredential{
+		ID:        authData.CredentialID,
+		UserID:    userID,
+		Name:      name,
+		PublicKey: authData.PublicKey,
+		Algorithm: alg,
+		SignCount: authData.SignCount,
+	})
+}
+
+func (u *WebAuthnUseCase) ListCredentials(ctx context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+	return u.repo.ListCredentialsByUser(ctx, userID)
+}
+
+// HasCredentials reports whether the user registered a credential, which then is a second factor.
+func (u *WebAuthnUseCase) HasCredentials(ctx context.Context, userID uuid.UUID) (bool, error) {
+	credentials, err := u.repo.ListCredentialsByUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return len(credentials) > 0, nil
+}
+
+func (u *WebAuthnUseCase) DeleteCredential(ctx context.Context, userID uuid.UUID, ID []byte) error {
+	return u.repo.DeleteCredential(ctx, ID, userID)
+}
+
+// DeleteCredentials removes every credential of the user and returns how many there were.
+func (u *WebAuthnUseCase) DeleteCredentials(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return u.repo.DeleteCredentialsByUser(ctx, userID)
+}
+
+// BeginLogin returns the options of navigator.credentials.get(). For a second factor userID is the user
+// whose password was verified and only their credentials are allowed, for a passwordless login it is
+// nil, the authenticator offers its discoverable credentials and must verify the user.
+func (u *WebAuthnUseCase) BeginLogin(ctx context.Context, userID *uuid.UUID) (*domain.WebAuthnRequestOptions, error) {
+	options := &domain.WebAuthnRequestOptions{
+		Timeout:          u.challengeTTL.Milliseconds(),
+		RPID:             u.rp.ID,
+		AllowCredentials: []domain.WebAuthnCredentialDescriptor{},
+		UserVerification: "required",
+	}
+	if userID != nil {
+		credentials, err := u.repo.ListCredentialsByUser(ctx, *userID)
+		if err != nil {
+			return nil, err
+		}
+		options.AllowCredentials = descriptors(credentials)
+		options.UserVerification = "preferred"
+	}
+
+	challenge, err := u.newChallenge(ctx, userID, domain.WebAuthnGet)
+	if err != nil {
+		return nil, err
+	}
+	options.Challenge = challenge
+	return options, nil
+}
+
+// VerifyAssertion verifies the response of the authenticator to the options of BeginLogin for the same
+// userID and returns the credential it signed with.
+func (u *WebAuthnUseCase) VerifyAssertion(ctx context.Context, userID *uuid.UUID, assertion *domain.WebAuthnAssertion) (*domain.WebAuthnCredential, error) {
+	if err := u.verifyClientData(ctx, assertion.ClientDataJSON, domain.WebAuthnGet, userID); err != nil {
+		return nil, err
+	}
+
+	credential, err := u.repo.GetCredentialByID(ctx, assertion.CredentialID)
+	if err != nil {
+		if errors.Is(err, domain.ErrWebAuthnCredentialNotFound) {
+			return nil, fmt.Errorf("%w: unknown credential", domain.ErrWebAuthnInvalid)
+		}
+		return nil, err
+	}
+	if userID != nil && credential.UserID != *userID {
+		return nil, fmt.Errorf("%w: credential of another user", domain.ErrWebAuthnInvalid)
+	}
+	if len(assertion.UserHandle) > 0 && !bytes.Equal(assertion.UserHandle, credential.UserID[:]) {
+		return nil, fmt.Errorf("%w: user handle does not match the credential", domain.ErrWebAuthnInvalid)
+	}
+
+	authData, err := parseAuthenticatorData(assertion.AuthenticatorData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrWebAuthnInvalid, err.Error())
+	}
+	// without a password the authenticator is the only factor, it must have verified the user
+	if err := u.checkAuthenticatorData(authData, userID == nil); err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(assertion.ClientDataJSON)
+	signed := append(append([]byte(nil), assertion.AuthenticatorData...), clientDataHash[:]...)
+	if err := verifyCOSESignature(credential.PublicKey, signed, assertion.Signature); err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrWebAuthnInvalid, err.Error())
+	}
+
+	if err := u.repo.UseCredential(ctx, credential.ID, authData.SignCount); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+// Login is a passwordless login from ip, the assertion answers options of BeginLogin without a user.
+// Failures are throttled like password logins, per credential and per source address.
+func (u *WebAuthnUseCase) Login(ctx context.Context, assertion *domain.WebAuthnAssertion, ip string) (*domain.User, error) {
+	keys := loginKeys(passkeyLoginKey(assertion.CredentialID), ip)
+	retryAfter, err := u.throttle.Check(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter > 0 {
+		return nil, &domain.LoginThrottledError{RetryAfter: retryAfter, Err: domain.ErrWebAuthnInvalid}
+	}
+
+	credential, err := u.VerifyAssertion(ctx, nil, assertion)
+	if err != nil {
+		if errors.Is(err, domain.ErrWebAuthnInvalid) || errors.Is(err, domain.ErrWebAuthnChallengeInvalid) {
+			return nil, u.throttle.failed(ctx, err, keys...)
+		}
+		return nil, err
+	}
+	if err := u.throttle.Reset(ctx, passkeyLoginKey(assertion.CredentialID)); err != nil {
+		return nil, err
+	}
+	return u.userRepo.GetUserByID(ctx, credential.UserID)
+}
+
+// Run periodically deletes the challenges of ceremonies that were never finished.
+func (u *WebAuthnUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := u.repo.DeleteExpiredWebAuthnChallenges(ctx); err != nil {
+				log.Printf("webauthn challenge purge failed: %v", err)
+			}
+		}
+	}
+}
+
+func (u *WebAuthnUseCase) newChallenge(ctx context.Context, userID *uuid.UUID, ceremony string) (string, error) {
+	challenge, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if err := u.repo.CreateWebAuthnChallenge(ctx, &domain.WebAuthnChallenge{
+		ChallengeHash: hashToken(challenge),
+		UserID:        userID,
+		Ceremony:      ceremony,
+		ExpiresAt:     time.Now().Add(u.challengeTTL),
+	}); err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// clientData is the CollectedClientData the browser hands to the authenticator (WebAuthn section 5.8.1).
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// verifyClientData checks the ceremony and origin of the client data and consumes its challenge, which
+// must have been issued for the same ceremony and user.
+func (u *WebAuthnUseCase) verifyClientData(ctx context.Context, raw []byte, ceremony string, userID *uuid.UUID) error {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return fmt.Errorf("%w: malformed client data", domain.ErrWebAuthnInvalid)
+	}
+	if data.Type != ceremony {
+		return fmt.Errorf("%w: client data of a %s ceremony", domain.ErrWebAuthnInvalid, data.Type)
+	}
+	// the origin is what makes WebAuthn phishing-resistant, a look-alike site gets signatures for its own
+	if !slices.Contains(u.origins, data.Origin) || data.CrossOrigin {
+		return fmt.Errorf("%w: origin %q is not allowed", domain.ErrWebAuthnInvalid, data.Origin)
+	}
+
+	challenge, err := u.repo.ConsumeWebAuthnChallenge(ctx, hashToken(data.Challenge))
+	if err != nil {
+		return err
+	}
+	sameUser := (challenge.UserID == nil && userID == nil) || (challenge.UserID != nil && userID != nil && *challenge.UserID == *userID)
+	if challenge.Ceremony != ceremony || !sameUser || !challenge.ExpiresAt.After(time.Now()) {
+		return domain.ErrWebAuthnChallengeInvalid
+	}
+	return nil
+}
+
+func (u *WebAuthnUseCase) checkAuthenticatorData(authData *authenticatorData, requireVerification bool) error {
+	rpIDHash := sha256.Sum256([]byte(u.rp.ID))
+	if !bytes.Equal(authData.RPIDHash, rpIDHash[:]) {
+		return fmt.Errorf("%w: credential of another relying party", domain.ErrWebAuthnInvalid)
+	}
+	if authData.Flags&authDataUserPresent == 0 {
+		return fmt.Errorf("%w: user was not present", domain.ErrWebAuthnInvalid)
+	}
+	if requireVerification && authData.Flags&authDataUserVerified == 0 {
+		return fmt.Errorf("%w: user was not verified", domain.ErrWebAuthnInvalid)
+	}
+	return nil
+}
+
+func descriptors(credentials []domain.WebAuthnCredential) []domain.WebAuthnCredentialDescriptor {
+	list := make([]domain.WebAuthnCredentialDescriptor, len(credentials))
+	for i, credential := range credentials {
+		list[i] = domain.WebAuthnCredentialDescriptor{Type: "public-key", ID: base64.RawURLEncoding.EncodeToString(credential.ID)}
+	}
+	return list
+}
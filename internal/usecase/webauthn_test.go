@@ -0,0 +1,487 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bright-pentium/go-client-practice/internal/domain"
+	attemptRepo "github.com/bright-pentium/go-client-practice/internal/repository/loginattempt"
+	mfaRepo "github.com/bright-pentium/go-client-practice/internal/repository/mfa"
+	userRepo "github.com/bright-pentium/go-client-practice/internal/repository/user"
+	webauthnRepo "github.com/bright-pentium/go-client-practice/internal/repository/webauthn"
+	"github.com/bright-pentium/go-client-practice/internal/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testRelyingParty = domain.WebAuthnRelyingParty{ID: "localhost", Name: "ClientApp"}
+
+const testOrigin = "http://localhost:8000"
+
+// testWebAuthn is a WebAuthnUseCase of users without credentials.
+func testWebAuthn() *usecase.WebAuthnUseCase {
+	mockRepo := new(webauthnRepo.MockWebAuthnRepository)
+	mockRepo.On("ListCredentialsByUser", mock.Anything, mock.Anything).Return([]domain.WebAuthnCredential{}, nil).Maybe()
+	mockRepo.On("DeleteCredentialsByUser", mock.Anything, mock.Anything).Return(int64(0), nil).Maybe()
+	return usecase.NewWebAuthnUseCase(mockRepo, nil, testThrottle(), testRelyingParty, []string{testOrigin}, time.Minute)
+}
+
+// cborPair is an entry of a cborMap, kept in order like the canonical CBOR of authenticators.
+type cborPair struct {
+	key   interface{}
+	value interface{}
+}
+
+type cborMap []cborPair
+
+func cborHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n <= 0xff:
+		return []byte{major<<5 | 24, byte(n)}
+	default:
+		head := []byte{major<<5 | 25, 0, 0}
+		binary.BigEndian.PutUint16(head[1:], uint16(n))
+		return head
+	}
+}
+
+// encodeCBOR encodes the few types a software authenticator needs.
+func encodeCBOR(v interface{}) []byte {
+	switch value := v.(type) {
+	case int:
+		if value < 0 {
+			return cborHead(1, uint64(-1-value))
+		}
+		return cborHead(0, uint64(value))
+	case []byte:
+		return append(cborHead(2, uint64(len(value))), value...)
+	case string:
+		return append(cborHead(3, uint64(len(value))), value...)
+	case cborMap:
+		out := cborHead(5, uint64(len(value)))
+		for _, pair := range value {
+			out = append(out, encodeCBOR(pair.key)...)
+			out = append(out, encodeCBOR(pair.value)...)
+		}
+		return out
+	}
+	panic("unsupported cbor value")
+}
+
+// softAuthenticator is an ES256 platform authenticator in software, holding a single credential.
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+	rpID         string
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+	return &softAuthenticator{key: key, credentialID: credentialID, rpID: testRelyingParty.ID}
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	pub, err := a.key.PublicKey.ECDH()
+	if err != nil {
+		panic(err)
+	}
+	point := pub.Bytes()
+	return encodeCBOR(cborMap{{1, 2}, {3, -7}, {-1, 1}, {-2, point[1:33]}, {-3, point[33:]}})
+}
+
+func (a *softAuthenticator) authenticatorData(flags byte, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	data := append(rpIDHash[:], flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[33:], a.signCount)
+	if attested {
+		data[32] |= 0x40
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func clientDataJSON(ceremony string, challenge string, origin string) []byte {
+	data, _ := json.Marshal(map[string]interface{}{"type": ceremony, "challenge": challenge, "origin": origin, "crossOrigin": false})
+	return data
+}
+
+// create answers navigator.credentials.create() with a verified user and no attestation.
+func (a *softAuthenticator) create(challenge string, origin string) *domain.WebAuthnAttestation {
+	object := cborMap{{"fmt", "none"}, {"attStmt", cborMap{}}, {"authData", a.authenticatorData(0x01|0x04, true)}}
+	return &domain.WebAuthnAttestation{
+		CredentialID:      a.credentialID,
+		ClientDataJSON:    clientDataJSON(domain.WebAuthnCreate, challenge, origin),
+		AttestationObject: encodeCBOR(object),
+	}
+}
+
+// get answers navigator.credentials.get() with the authenticator data flags, the user handle is what a
+// discoverable credential returns.
+func (a *softAuthenticator) get(t *testing.T, challenge string, origin string, flags byte, userHandle []byte) *domain.WebAuthnAssertion {
+	a.signCount++
+	authData := a.authenticatorData(flags, false)
+	clientData := clientDataJSON(domain.WebAuthnGet, challenge, origin)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+	return &domain.WebAuthnAssertion{
+		CredentialID:      a.credentialID,
+		ClientDataJSON:    clientData,
+		AuthenticatorData: authData,
+		Signature:         signature,
+		UserHandle:        userHandle,
+	}
+}
+
+// newWebAuthnRepo is a mock repository keeping challenges and credentials in memory, so ceremonies run
+// end to end.
+func newWebAuthnRepo() *webauthnRepo.MockWebAuthnRepository {
+	challenges := map[string]domain.WebAuthnChallenge{}
+	credentials := map[string]domain.WebAuthnCredential{}
+	mockRepo := new(webauthnRepo.MockWebAuthnRepository)
+	mockRepo.On("CreateWebAuthnChallenge", mock.Anything, mock.Anything).Return(func(_ context.Context, challenge *domain.WebAuthnChallenge) error {
+		challenges[string(challenge.ChallengeHash)] = *challenge
+		return nil
+	}).Maybe()
+	mockRepo.On("ConsumeWebAuthnChallenge", mock.Anything, mock.Anything).Return(func(_ context.Context, hash []byte) (*domain.WebAuthnChallenge, error) {
+		challenge, ok := challenges[string(hash)]
+		if !ok {
+			return nil, domain.ErrWebAuthnChallengeInvalid
+		}
+		delete(challenges, string(hash))
+		return &challenge, nil
+	}).Maybe()
+	mockRepo.On("ListCredentialsByUser", mock.Anything, mock.Anything).Return(func(_ context.Context, userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+		list := []domain.WebAuthnCredential{}
+		for _, credential := range credentials {
+			if credential.UserID == userID {
+				list = append(list, credential)
+			}
+		}
+		return list, nil
+	}).Maybe()
+	mockRepo.On("CreateCredential", mock.Anything, mock.Anything).Return(func(_ context.Context, credential *domain.WebAuthnCredential) (*domain.WebAuthnCredential, error) {
+		if _, ok := credentials[string(credential.ID)]; ok {
+			return nil, domain.ErrWebAuthnCredentialExists
+		}
+		credentials[string(credential.ID)] = *credential
+		return credential, nil
+	}).Maybe()
+	mockRepo.On("GetCredentialByID", mock.Anything, mock.Anything).Return(func(_ context.Context, ID []byte) (*domain.WebAuthnCredential, error) {
+		credential, ok := credentials[string(ID)]
+		if !ok {
+			return nil, domain.ErrWebAuthnCredentialNotFound
+		}
+		return &credential, nil
+	}).Maybe()
+	mockRepo.On("UseCredential", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, ID []byte, signCount uint32) error {
+		credential := credentials[string(ID)]
+		if credential.SignCount >= signCount && !(credential.SignCount == 0 && signCount == 0) {
+			return domain.ErrWebAuthnInvalid
+		}
+		credential.SignCount = signCount
+		credentials[string(ID)] = credential
+		return nil
+	}).Maybe()
+	return mockRepo
+}
+
+// register runs the registration ceremony of the authenticator for the user.
+func register(t *testing.T, useCase *usecase.WebAuthnUseCase, user *domain.User, authenticator *softAuthenticator) *domain.WebAuthnCredential {
+	options, err := useCase.BeginRegistration(context.Background(), user)
+	require.NoError(t, err)
+	credential, err := useCase.FinishRegistration(context.Background(), user.ID, "YubiKey 5", authenticator.create(options.Challenge, testOrigin))
+	require.NoError(t, err)
+	return credential
+}
+
+func TestWebAuthnRegistration(t *testing.T) {
+	ctx := context.Background()
+	user := &domain.User{ID: uuid.New(), Account: "alice", Name: "Alice"}
+
+	t.Run("software authenticator registers a credential", func(t *testing.T) {
+		useCase := usecase.NewWebAuthnUseCase(newWebAuthnRepo(), nil, testThrottle(), testRelyingParty, []string{testOrigin}, time.Minute)
+		authenticator := newSoftAuthenticator(t)
+
+		options, err := useCase.BeginRegistration(ctx, user)
+		require.NoError(t, err)
+		assert.Equal(t, testRelyingParty, options.RP)
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(user.ID[:]), options.User.ID)
+		assert.Equal(t, "alice", options.User.Name)
+		assert.NotEmpty(t, options.Challenge)
+		assert.Equal(t, -7, options.PubKeyCredParams[0].Alg)
+		assert.Empty(t, options.ExcludeCredentials)
+
+		credential, err := useCase.FinishRegistration(ctx, user.ID, "YubiKey 5", authenticator.create(options.Challenge, testOrigin))
+
+		require.NoError(t, err)
+		assert.Equal(t, authenticator.credentialID, []byte(credential.ID))
+		assert.Equal(t, user.ID, credential.UserID)
+		assert.Equal(t, "YubiKey 5", credential.Name)
+		assert.Equal(t, -7, credential.Algorithm)
+		assert.Equal(t, authenticator.coseKey(), credential.PublicKey)
+
+		options, err = useCase.BeginRegistration(ctx, user)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.WebAuthnCredentialDescriptor{{Type: "public-key", ID: base64.RawURLEncoding.EncodeToString(authenticator.credentialID)}}, options.ExcludeCredentials)
+	})
+
+	t.Run("response of another origin is refused", func(t *testing.T) {
+		useCase := usecase.NewWebAuthnUseCase(newWebAuthnRepo(), nil, testThrottle(), testRelyingParty, []string{testOrigin}, time.Minute)
+		options, err := useCase.BeginRegistration(ctx, user)
+		require.NoError(t, err)
+
+		_, err = useCase.FinishRegistration(ctx, user.ID, "key", newSoftAuthenticator(t).create(options.Challenge, "https://login.example.net"))
+
+		assert.ErrorIs(t, err, domain.ErrWebAuthnInvalid)
+	})
+
+	t.Run("credential of another relying party is refused", func(t *testing.T) {
+		useCase := usecase.NewWebAuthnUseCase(newWebAuthnRepo(), nil, testThrottle(), testRelyingParty, []string{testOrigin}, time.Minute)
+		options, err := useCase.BeginRegistration(ctx, user)
+		require.NoError(t, err)
+		authenticator := newSoftAuthenticator(t)
+		authenticator.rpID = "example.net"
+
+		_, err = useCase.FinishRegistration(ctx, user.ID, "key", authenticator.create(options.Challenge, testOrigin))
+
+		assert.ErrorIs(t, err, domain.ErrWebAuthnInvalid)
+	})
+
+	t.Run("challenge of another user is refused", func(t *testing.T) {
+		useCase := usecase.NewWebAuthnUseCase(newWebAuthnRepo(), nil, testThrottle(), testRelyingParty, []string{testOrigin}, time.Minute)
+		options, err := useCase.BeginRegistration(ctx, user)
+		require.NoError(t, err)
+
+		_, err = useCase.FinishRegistration(ctx, uuid.New(), "key", newSoftAuthenticator(t).create(options.Challenge, testOrigin))
+
+		assert.ErrorIs(t, err, domain.ErrWebAuthnChallengeInvalid)
+	})
+
+	t.Run("challenge answers a single ceremony", func(t *testing.T) {
+		useCase := usecase.NewWebAuthnUseCase(newWebAuthnRepo(), nil, testThrottle(), testRelyingParty, []string{testOrigin}, time.Minute)
+		options, err := useCase.BeginRegistration(ctx, user)
+		require.NoError(t, err)
+		_, err = useCase.FinishRegistration(ctx, user.ID, "key", newSoftAuthenticator(t).create(options.Challenge, testOrigin))
+		require.NoError(t, err)
+
+		_, err = useCase.FinishRegistration(ctx, user.ID, "key", newSoftAuthenticator(t).create(options.Challenge, testOrigin))
+
+		assert.ErrorIs(t, err, domain.ErrWebAuthnChallengeInvalid)
+	})
+}
+
+func TestWebAuthnLogin(t *testing.T) {
+	ctx := context.Background()
+	user := &domain.User{ID: uuid.New(), Account: "alice", Name: "Alice"}
+	const userPresent, userVerified = 0x01, 0x04
+
+	newUseCase := func(t *testing.T) (*usecase.WebAuthnUseCase, *softAuthenticator) {
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockUserRepo.On("GetUserByID", mock.Anything, user.ID).Return(user, nil).Maybe()
+		useCase := usecase.NewWebAuthnUseCase(newWebAuthnRepo(), mockUserRepo, testThrottle(), testRelyingParty, []string{testOrigin}, time.Minute)
+		authenticator := newSoftAuthenticator(t)
+		register(t, useCase, user, authenticator)
+		return useCase, authenticator
+	}
+
+	t.Run("second factor allows the credentials of the user", func(t *testing.T) {
+		useCase, authenticator := newUseCase(t)
+
+		options, err := useCase.BeginLogin(ctx, &user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "localhost", options.RPID)
+		assert.Equal(t, []domain.WebAuthnCredentialDescriptor{{Type: "public-key", ID: base64.RawURLEncoding.EncodeToString(authenticator.credentialID)}}, options.AllowCredentials)
+
+		credential, err := useCase.VerifyAssertion(ctx, &user.ID, authenticator.get(t, options.Challenge, testOrigin, userPresent, nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, authenticator.credentialID, []byte(credential.ID))
+	})
+
+	t.Run("passwordless login finds the user of the credential", func(t *testing.T) {
+		useCase, authenticator := newUseCase(t)
+
+		options, err := useCase.BeginLogin(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, options.AllowCredentials)
+		assert.Equal(t, "required", options.UserVerification)
+
+		got, err := useCase.Login(ctx, authenticator.get(t, options.Challenge, testOrigin, userPresent|userVerified, user.ID[:]), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, user, got)
+	})
+
+	t.Run("passwordless login requires user verification", func(t *testing.T) {
+		useCase, authenticator := newUseCase(t)
+		options, err := useCase.BeginLogin(ctx, nil)
+		require.NoError(t, err)
+
+		_, err = useCase.Login(ctx, authenticator.get(t, options.Challenge, testOrigin, userPresent, user.ID[:]), "")
+
+		assert.ErrorIs(t, err, domain.ErrWebAuthnInvalid)
+	})
+
+	t.Run("challenge of a passwordless login is no second factor", func(t *testing.T) {
+		useCase, authenticator := newUseCase(t)
+		options, err := useCase.BeginLogin(ctx, nil)
+		require.NoError(t, err)
+
+		_, err = useCase.VerifyAssertion(ctx, &user.ID, authenticator.get(t, options.Challenge, testOrigin, userPresent|userVerified, nil))
+
+		assert.ErrorIs(t, err, domain.ErrWebAuthnChallengeInvalid)
+	})
+
+	t.Run("credential of another user is refused", func(t *testing.T) {
+		useCase, authenticator := newUseCase(t)
+		other := uuid.New()
+		options, err := useCase.BeginLogin(ctx, &other)
+		require.NoError(t, err)
+
+		_, err = useCase.VerifyAssertion(ctx, &other, authenticator.get(t, options.Challenge, testOrigin, userPresent, nil))
+
+		assert.ErrorIs(t, err, domain.ErrWebAuthnInvalid)
+	})
+
+	t.Run("tampered signature is refused", func(t *testing.T) {
+		useCase, authenticator := newUseCase(t)
+		options, err := useCase.BeginLogin(ctx, &user.ID)
+		require.NoError(t, err)
+		assertion := authenticator.get(t, options.Challenge, testOrigin, userPresent, nil)
+		assertion.AuthenticatorData = bytes.Clone(assertion.AuthenticatorData)
+		assertion.AuthenticatorData[32] |= userVerified
+
+		_, err = useCase.VerifyAssertion(ctx, &user.ID, assertion)
+
+		assert.ErrorIs(t, err, domain.ErrWebAuthnInvalid)
+	})
+
+	t.Run("counter that went backwards is refused", func(t *testing.T) {
+		useCase, authenticator := newUseCase(t)
+		options, err := useCase.BeginLogin(ctx, &user.ID)
+		require.NoError(t, err)
+		authenticator.signCount = 10
+		_, err = useCase.VerifyAssertion(ctx, &user.ID, authenticator.get(t, options.Challenge, testOrigin, userPresent, nil))
+		require.NoError(t, err)
+
+		// a clone of the key still counts from where it was copied
+		options, err = useCase.BeginLogin(ctx, &user.ID)
+		require.NoError(t, err)
+		authenticator.signCount = 5
+		_, err = useCase.VerifyAssertion(ctx, &user.ID, authenticator.get(t, options.Challenge, testOrigin, userPresent, nil))
+
+		assert.ErrorIs(t, err, domain.ErrWebAuthnInvalid)
+	})
+}
+
+func TestPasskeyLoginThrottled(t *testing.T) {
+	ctx := context.Background()
+	user := &domain.User{ID: uuid.New(), Account: "alice", Name: "Alice"}
+	const userPresent, userVerified = 0x01, 0x04
+
+	newUseCase := func(t *testing.T, mockAttemptRepo *attemptRepo.MockLoginAttemptRepository) (*usecase.WebAuthnUseCase, *softAuthenticator, []string) {
+		mockUserRepo := new(userRepo.MockUserRepository)
+		mockUserRepo.On("GetUserByID", mock.Anything, user.ID).Return(user, nil).Maybe()
+		useCase := usecase.NewWebAuthnUseCase(newWebAuthnRepo(), mockUserRepo, usecase.NewLoginThrottleUseCase(mockAttemptRepo, testThrottlePolicy), testRelyingParty, []string{testOrigin}, time.Minute)
+		authenticator := newSoftAuthenticator(t)
+		register(t, useCase, user, authenticator)
+		return useCase, authenticator, []string{"passkey:" + base64.RawURLEncoding.EncodeToString(authenticator.credentialID), "ip:203.0.113.7"}
+	}
+
+	t.Run("locked out credential is not tried", func(t *testing.T) {
+		until := time.Now().Add(time.Minute)
+		mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+		useCase, authenticator, keys := newUseCase(t, mockAttemptRepo)
+		mockAttemptRepo.On("ListLoginAttempts", mock.Anything, keys).Return([]domain.LoginAttempt{{Key: keys[0], Failures: 3, LockedUntil: &until}}, nil)
+		options, err := useCase.BeginLogin(ctx, nil)
+		require.NoError(t, err)
+
+		logged, err := useCase.Login(ctx, authenticator.get(t, options.Challenge, testOrigin, userPresent|userVerified, user.ID[:]), "203.0.113.7")
+
+		assert.Nil(t, logged)
+		assert.ErrorIs(t, err, domain.ErrWebAuthnInvalid)
+		assert.ErrorIs(t, err, domain.ErrLoginThrottled)
+	})
+
+	t.Run("failure is counted per credential and ip", func(t *testing.T) {
+		mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+		useCase, authenticator, keys := newUseCase(t, mockAttemptRepo)
+		mockAttemptRepo.On("ListLoginAttempts", mock.Anything, keys).Return([]domain.LoginAttempt{}, nil)
+		mockAttemptRepo.On("RecordLoginFailure", mock.Anything, keys[0], mock.Anything).Return(&domain.LoginAttempt{Failures: 1}, nil)
+		mockAttemptRepo.On("RecordLoginFailure", mock.Anything, keys[1], mock.Anything).Return(&domain.LoginAttempt{Failures: 1}, nil)
+		options, err := useCase.BeginLogin(ctx, nil)
+		require.NoError(t, err)
+
+		_, err = useCase.Login(ctx, authenticator.get(t, options.Challenge, testOrigin, userPresent, user.ID[:]), "203.0.113.7")
+
+		assert.ErrorIs(t, err, domain.ErrWebAuthnInvalid)
+		assert.NotErrorIs(t, err, domain.ErrLoginThrottled)
+		mockAttemptRepo.AssertExpectations(t)
+	})
+
+	t.Run("success forgets the failures of the credential only", func(t *testing.T) {
+		mockAttemptRepo := new(attemptRepo.MockLoginAttemptRepository)
+		useCase, authenticator, keys := newUseCase(t, mockAttemptRepo)
+		mockAttemptRepo.On("ListLoginAttempts", mock.Anything, keys).Return([]domain.LoginAttempt{}, nil)
+		mockAttemptRepo.On("ResetLoginAttempts", mock.Anything, keys[0]).Return(nil)
+		options, err := useCase.BeginLogin(ctx, nil)
+		require.NoError(t, err)
+
+		logged, err := useCase.Login(ctx, authenticator.get(t, options.Challenge, testOrigin, userPresent|userVerified, user.ID[:]), "203.0.113.7")
+
+		require.NoError(t, err)
+		assert.Equal(t, user, logged)
+		mockAttemptRepo.AssertExpectations(t)
+		mockAttemptRepo.AssertNotCalled(t, "ResetLoginAttempts", mock.Anything, keys[1])
+	})
+}
+
+func TestVerifyMFAWebAuthnChallenge(t *testing.T) {
+	ctx := context.Background()
+	user := &domain.User{ID: uuid.New(), Account: "alice"}
+	challenge := &domain.MFAChallenge{ChallengeHash: []byte("hash"), UserID: user.ID, Mode: "token", AuthTime: time.Now(), ExpiresAt: time.Now().Add(time.Minute)}
+
+	mockMFARepo := new(mfaRepo.MockMFARepository)
+	mockUserRepo := new(userRepo.MockUserRepository)
+	mockMFARepo.On("GetMFAChallengeByHash", mock.Anything, mock.Anything).Return(challenge, nil)
+	mockMFARepo.On("DeleteMFAChallenge", mock.Anything, challenge.ChallengeHash).Return(nil)
+	mockUserRepo.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+	passkeys := usecase.NewWebAuthnUseCase(newWebAuthnRepo(), mockUserRepo, testThrottle(), testRelyingParty, []string{testOrigin}, time.Minute)
+	authenticator := newSoftAuthenticator(t)
+	register(t, passkeys, user, authenticator)
+	useCase := usecase.NewMFAUseCase(mockMFARepo, mockUserRepo, testThrottle(), passkeys, "ClientApp", time.Minute)
+
+	options, err := useCase.BeginWebAuthnChallenge(ctx, "token")
+	require.NoError(t, err)
+	require.Len(t, options.AllowCredentials, 1)
+
+	got, answered, err := useCase.VerifyWebAuthnChallenge(ctx, "token", authenticator.get(t, options.Challenge, testOrigin, 0x01, nil), "203.0.113.7")
+
+	require.NoError(t, err)
+	assert.Equal(t, user, got)
+	assert.Equal(t, challenge, answered)
+	mockMFARepo.AssertExpectations(t)
+}